@@ -0,0 +1,321 @@
+package mailnow
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultArchiveTimeout is how long SendEmail waits for the archiver unless
+// WithArchiveTimeout says otherwise
+const DefaultArchiveTimeout = 2 * time.Second
+
+// Archiver stores a copy of every email the client sends.
+//
+// Archive is called after each send that reached the API, whether it
+// succeeded or failed. Implementations must be safe for concurrent use.
+type Archiver interface {
+	Archive(ctx context.Context, msg ArchivedMessage) error
+}
+
+// ArchivedMessage is the record of a send passed to an Archiver
+type ArchivedMessage struct {
+	// Request is a copy of the request as sent to the API, with the
+	// fallback sender as From when one was used. The content of its
+	// attachments is removed when it is in Attachments.
+	Request *EmailRequest
+
+	// Attachments are the request's attachments with their content decoded.
+	// Content is nil for attachments referenced by URL or asset ID, and for
+	// content that is not valid base64, which is left in Request.
+	Attachments []ArchivedAttachment
+
+	// Response is the API response of a successful send
+	Response *EmailResponse
+
+	// Err is the error of a failed send
+	Err error
+
+	// StartedAt and FinishedAt bound the send, including retries
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// ArchivedAttachment is an attachment with its decoded content
+type ArchivedAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content,omitempty"`
+	URL         string `json:"url,omitempty"`
+	AssetID     string `json:"asset_id,omitempty"`
+}
+
+// WithArchiver archives every send with archiver.
+//
+// SendEmail waits for the archiver for at most the archive timeout, see
+// WithArchiveTimeout, and then returns while archiving continues in the
+// background. Archiver errors and panics are logged and never fail the
+// send.
+func WithArchiver(archiver Archiver) Option {
+	return clientOption(func(c *Client) error {
+		if archiver == nil {
+			return NewValidationError("archiver cannot be nil", nil)
+		}
+		c.archiver = archiver
+		return nil
+	})
+}
+
+// WithArchiveTimeout sets how long SendEmail waits for the archiver set
+// with WithArchiver. It defaults to DefaultArchiveTimeout.
+func WithArchiveTimeout(d time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if d <= 0 {
+			return NewValidationError("archive timeout must be positive", nil)
+		}
+		c.archiveTimeout = d
+		return nil
+	})
+}
+
+// archive passes the record of a send to the archiver, waiting for it for
+// at most the archive timeout
+func (c *Client) archive(ctx context.Context, req *EmailRequest, resp *EmailResponse, sendErr error, started time.Time) {
+	attachments := decodeAttachments(req.Attachments)
+	archived := archivedRequest(req, attachments)
+	if resp != nil && resp.FallbackFrom != "" {
+		archived.From = resp.FallbackFrom
+	}
+	msg := ArchivedMessage{
+		Request:     archived,
+		Attachments: attachments,
+		Response:    resp,
+		Err:         sendErr,
+		StartedAt:   started,
+		FinishedAt:  time.Now(),
+	}
+
+	timeout := c.archiveTimeout
+	if timeout == 0 {
+		timeout = DefaultArchiveTimeout
+	}
+
+	// Archive beyond the caller's cancellation, as the send already happened
+	archiveCtx := context.WithoutCancel(ctx)
+	done := make(chan struct{})
+	c.state.active.Add(1)
+	go func() {
+		defer c.state.active.Done()
+		defer close(done)
+		var err error
+		if panicErr := c.safeCall(archiveCtx, CallbackArchiver, nil, func() { err = c.archiver.Archive(archiveCtx, msg) }); panicErr != nil {
+			return
+		}
+		if err != nil && c.logger != nil {
+			c.logger.ErrorContext(archiveCtx, "failed to archive email", "to", req.To, "error", err)
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		if c.logger != nil {
+			c.logger.WarnContext(ctx, "archiver is slow; continuing in the background", "to", req.To, "timeout", timeout)
+		}
+	}
+}
+
+// decodeAttachments returns the attachments with their content decoded
+func decodeAttachments(attachments []Attachment) []ArchivedAttachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	decoded := make([]ArchivedAttachment, len(attachments))
+	for i, a := range attachments {
+		decoded[i] = ArchivedAttachment{Filename: a.Filename, ContentType: a.ContentType, URL: a.URL, AssetID: a.AssetID}
+		if a.Content == "" {
+			continue
+		}
+		encoding := base64.StdEncoding
+		if strings.ContainsAny(a.Content, "-_") {
+			encoding = base64.URLEncoding
+		}
+		if content, err := encoding.DecodeString(a.Content); err == nil {
+			decoded[i].Content = content
+		}
+	}
+	return decoded
+}
+
+// archivedRequest returns a deep copy of req for archiving, which can
+// continue after SendEmail returns, without the attachment content that
+// attachments hold decoded
+func archivedRequest(req *EmailRequest, attachments []ArchivedAttachment) *EmailRequest {
+	archived := *req
+	if req.Attachments != nil {
+		archived.Attachments = make([]Attachment, len(req.Attachments))
+		for i, a := range req.Attachments {
+			if attachments[i].Content != nil {
+				a.Content = ""
+			}
+			archived.Attachments[i] = a
+		}
+	}
+	if req.ReplyTo != nil {
+		archived.ReplyTo = append([]string(nil), req.ReplyTo...)
+	}
+	if req.Headers != nil {
+		archived.Headers = make(map[string]string, len(req.Headers))
+		for name, value := range req.Headers {
+			archived.Headers[name] = value
+		}
+	}
+	if req.DeliveryCallback != nil {
+		callback := *req.DeliveryCallback
+		if callback.Events != nil {
+			callback.Events = append([]string(nil), callback.Events...)
+		}
+		archived.DeliveryCallback = &callback
+	}
+	return &archived
+}
+
+// unsafeFilenameRegex matches runs of characters not allowed in archive
+// file names
+var unsafeFilenameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DirArchiver is an Archiver that writes each message as a JSON file in a
+// directory
+type DirArchiver struct {
+	dir       string
+	encryptor Encryptor
+	mu        sync.Mutex
+}
+
+// NewDirArchiver creates an archiver writing to dir, which must exist
+func NewDirArchiver(dir string) *DirArchiver {
+	return &DirArchiver{dir: dir}
+}
+
+// NewEncryptedDirArchiver creates an archiver writing to dir, which must
+// exist, that encrypts every file with encryptor. The files are named like
+// those of NewDirArchiver with an extra ".enc" extension. Load reads both
+// encrypted files and plain ones written before encryption was enabled.
+func NewEncryptedDirArchiver(dir string, encryptor Encryptor) (*DirArchiver, error) {
+	if encryptor == nil {
+		return nil, NewValidationError("archive encryptor cannot be nil", nil)
+	}
+	return &DirArchiver{dir: dir, encryptor: encryptor}, nil
+}
+
+// archiveRecord is the JSON form of an ArchivedMessage
+type archiveRecord struct {
+	Request     *EmailRequest        `json:"request"`
+	Attachments []ArchivedAttachment `json:"attachments,omitempty"`
+	Response    *EmailResponse       `json:"response,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	StartedAt   time.Time            `json:"started_at"`
+	FinishedAt  time.Time            `json:"finished_at"`
+}
+
+// Archive writes msg to a new file named after the send time, message ID
+// and recipient. Names that are already taken get a numeric suffix.
+func (a *DirArchiver) Archive(ctx context.Context, msg ArchivedMessage) error {
+	record := archiveRecord{
+		Request:     msg.Request,
+		Attachments: msg.Attachments,
+		Response:    msg.Response,
+		StartedAt:   msg.StartedAt,
+		FinishedAt:  msg.FinishedAt,
+	}
+	if msg.Err != nil {
+		record.Error = msg.Err.Error()
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	ext := ".json"
+	if a.encryptor != nil {
+		if data, err = a.encryptor.Encrypt(data); err != nil {
+			return err
+		}
+		ext = ".json.enc"
+	}
+
+	id := "failed"
+	if msg.Response != nil && msg.Response.Data.MessageID != "" {
+		id = msg.Response.Data.MessageID
+	}
+	base := fmt.Sprintf("%s_%s_%s", msg.StartedAt.UTC().Format("20060102T150405.000000000Z"), id, msg.Request.To)
+	base = strings.Trim(unsafeFilenameRegex.ReplaceAllString(base, "_"), "._")
+	if len(base) > 200 {
+		base = base[:200]
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for n := 0; ; n++ {
+		name := base + ext
+		if n > 0 {
+			name = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		f, err := os.OpenFile(filepath.Join(a.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// Load reads the archived message in the file name of the archive
+// directory, decrypting it if needed. The message's Err only carries the
+// text of the original error.
+func (a *DirArchiver) Load(name string) (ArchivedMessage, error) {
+	data, err := os.ReadFile(filepath.Join(a.dir, filepath.Base(name)))
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	if IsEncrypted(data) {
+		if a.encryptor == nil {
+			return ArchivedMessage{}, NewValidationError("archive file "+name+" is encrypted and the archiver has no encryptor", nil)
+		}
+		if data, err = a.encryptor.Decrypt(data); err != nil {
+			return ArchivedMessage{}, err
+		}
+	}
+
+	var record archiveRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return ArchivedMessage{}, NewValidationError("invalid archive file "+name, err)
+	}
+	msg := ArchivedMessage{
+		Request:     record.Request,
+		Attachments: record.Attachments,
+		Response:    record.Response,
+		StartedAt:   record.StartedAt,
+		FinishedAt:  record.FinishedAt,
+	}
+	if record.Error != "" {
+		msg.Err = errors.New(record.Error)
+	}
+	return msg, nil
+}
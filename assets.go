@@ -0,0 +1,71 @@
+package mailnow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+)
+
+// Asset represents a file stored in the Mailnow asset store.
+//
+// The AssetID can be referenced from Attachment.AssetID in any number of
+// sends without uploading the file again.
+type Asset struct {
+	AssetID     string `json:"asset_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// UploadAsset uploads a file to the Mailnow asset store.
+//
+// The content is read from r and sent as multipart/form-data. The content
+// type is derived from the filename extension, falling back to
+// application/octet-stream.
+//
+// Returns the stored Asset, whose AssetID can be used in Attachment.AssetID.
+func (c *Client) UploadAsset(ctx context.Context, filename string, r io.Reader) (*Asset, error) {
+	if filename == "" {
+		return nil, NewValidationError("asset filename is required", nil)
+	}
+	if r == nil {
+		return nil, NewValidationError("asset content reader cannot be nil", nil)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Build multipart body
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filepath.Base(filename)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, NewValidationError("failed to encode asset", err)
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, NewValidationError("failed to read asset content", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, NewValidationError("failed to encode asset", err)
+	}
+
+	asset, _, err := c.requestAPI().UploadAsset(ctx, writer.FormDataContentType(), body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if asset.AssetID == "" {
+		return nil, NewServerError("asset upload response is missing the asset ID", nil)
+	}
+
+	return asset, nil
+}
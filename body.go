@@ -0,0 +1,91 @@
+package mailnow
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// maxBodyDrain is the maximum number of unread response bytes discarded
+// on close so the connection can be reused
+const maxBodyDrain = 64 << 10
+
+// ErrResponseBodyClosed is returned when closing a response body that was
+// already closed
+var ErrResponseBodyClosed = errors.New("mailnow: response body already closed")
+
+// leakDetection reports whether unclosed response bodies are tracked
+var leakDetection atomic.Bool
+
+// openBodies maps each open tracked body to the stack that created it
+var openBodies sync.Map
+
+func init() {
+	leakDetection.Store(os.Getenv("MAILNOW_LEAK_DETECTION") == "1")
+}
+
+// SetLeakDetection enables or disables tracking of unclosed response
+// bodies returned by MakeRequest. It is meant for tests and can also be
+// enabled by setting MAILNOW_LEAK_DETECTION=1.
+//
+// Disabling leak detection forgets all tracked bodies.
+func SetLeakDetection(enabled bool) {
+	leakDetection.Store(enabled)
+	if !enabled {
+		openBodies.Range(func(key, _ interface{}) bool {
+			openBodies.Delete(key)
+			return true
+		})
+	}
+}
+
+// UnclosedResponseBodies returns the creation stacks of response bodies
+// that were opened while leak detection was enabled and have not been
+// closed yet.
+func UnclosedResponseBodies() []string {
+	var stacks []string
+	openBodies.Range(func(_, stack interface{}) bool {
+		stacks = append(stacks, stack.(string))
+		return true
+	})
+	return stacks
+}
+
+// trackedBody wraps a response body so that it is drained and closed
+// exactly once
+type trackedBody struct {
+	body   io.ReadCloser
+	closed atomic.Bool
+}
+
+// newTrackedBody wraps body, recording its creation stack when leak
+// detection is enabled
+func newTrackedBody(body io.ReadCloser) *trackedBody {
+	b := &trackedBody{body: body}
+	if leakDetection.Load() {
+		openBodies.Store(b, string(debug.Stack()))
+	}
+	return b
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	if b.closed.Load() {
+		return 0, ErrResponseBodyClosed
+	}
+	return b.body.Read(p)
+}
+
+// Close drains a bounded amount of unread data so the connection can be
+// reused, then closes the body. Closing twice returns ErrResponseBodyClosed.
+func (b *trackedBody) Close() error {
+	if !b.closed.CompareAndSwap(false, true) {
+		return ErrResponseBodyClosed
+	}
+	openBodies.Delete(b)
+
+	_, _ = io.CopyN(io.Discard, b.body, maxBodyDrain)
+	return b.body.Close()
+}
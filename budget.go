@@ -0,0 +1,210 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBudgetWarningThreshold is the fraction of the send budget at which
+// a warning is reported unless WithBudgetWarningThreshold says otherwise
+const DefaultBudgetWarningThreshold = 0.8
+
+// BudgetStore counts the sends made in each budget period. Implementations
+// must be safe for concurrent use, and a store shared by several clients
+// or processes enforces one budget across all of them.
+type BudgetStore interface {
+	// Reserve counts a send in the period starting at period if fewer than
+	// limit sends were counted, and returns the count including it. When
+	// the limit has been reached it returns the current count and false.
+	Reserve(ctx context.Context, period time.Time, limit int) (used int, ok bool, err error)
+
+	// Release removes a send reserved in the period starting at period,
+	// for a send that failed
+	Release(ctx context.Context, period time.Time) error
+}
+
+// MemoryBudgetStore is a BudgetStore that keeps counts in memory. It only
+// remembers the latest period.
+type MemoryBudgetStore struct {
+	mu     sync.Mutex
+	period time.Time
+	used   int
+}
+
+// NewMemoryBudgetStore creates an empty in-memory budget store
+func NewMemoryBudgetStore() *MemoryBudgetStore {
+	return &MemoryBudgetStore{}
+}
+
+// Reserve implements BudgetStore
+func (s *MemoryBudgetStore) Reserve(ctx context.Context, period time.Time, limit int) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !period.Equal(s.period) {
+		if period.Before(s.period) {
+			return s.used, false, nil
+		}
+		s.period = period
+		s.used = 0
+	}
+	if s.used >= limit {
+		return s.used, false, nil
+	}
+	s.used++
+	return s.used, true, nil
+}
+
+// Release implements BudgetStore
+func (s *MemoryBudgetStore) Release(ctx context.Context, period time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if period.Equal(s.period) && s.used > 0 {
+		s.used--
+	}
+	return nil
+}
+
+// BudgetExceededError represents a send refused locally because the send
+// budget set with WithSendBudget is used up
+type BudgetExceededError struct {
+	error *Error
+
+	// Used is the number of sends counted in the current period
+	Used int
+
+	// Limit is the number of sends allowed per period
+	Limit int
+
+	// ResetAt is when the current period ends
+	ResetAt time.Time
+}
+
+// NewBudgetExceededError creates a new BudgetExceededError
+func NewBudgetExceededError(message string, used, limit int, resetAt time.Time, err error) *BudgetExceededError {
+	return &BudgetExceededError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Used:    used,
+		Limit:   limit,
+		ResetAt: resetAt,
+	}
+}
+
+func (e *BudgetExceededError) Error() string {
+	return e.error.Error()
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *BudgetExceededError) base() *Error {
+	return e.error
+}
+
+// WithSendBudget limits the client to limit successful sends per period.
+// Periods are consecutive windows of the given length aligned to the Unix
+// epoch in UTC, so a period of 24 hours is a UTC calendar day and a period
+// of 7 days starts on Thursdays. Failed sends are not counted, but a send
+// whose outcome is unknown, such as after a connection error once the
+// request was written or a successful response that fails response
+// validation, is.
+//
+// Once the budget is used up SendEmail fails with a BudgetExceededError
+// without contacting the API. A warning with code "budget_threshold" is
+// reported when usage reaches the threshold set with
+// WithBudgetWarningThreshold. A nil store keeps counts in memory.
+func WithSendBudget(limit int, period time.Duration, store BudgetStore) Option {
+	return clientOption(func(c *Client) error {
+		if limit < 1 {
+			return NewValidationError("send budget limit must be at least 1", nil)
+		}
+		if period <= 0 {
+			return NewValidationError("send budget period must be positive", nil)
+		}
+		if store == nil {
+			store = NewMemoryBudgetStore()
+		}
+		c.budget = &sendBudget{limit: limit, period: period, store: store}
+		return nil
+	})
+}
+
+// WithBudgetWarningThreshold sets the fraction of the send budget, between
+// 0 and 1, at which a warning is reported. It defaults to
+// DefaultBudgetWarningThreshold.
+func WithBudgetWarningThreshold(threshold float64) Option {
+	return clientOption(func(c *Client) error {
+		if threshold <= 0 || threshold > 1 {
+			return NewValidationError("budget warning threshold must be greater than 0 and at most 1", nil)
+		}
+		c.budgetWarningThreshold = threshold
+		return nil
+	})
+}
+
+// sendBudget tracks sends against the client's send budget
+type sendBudget struct {
+	limit  int
+	period time.Duration
+	store  BudgetStore
+
+	// warned is the Unix time of the last period a warning was reported for
+	warned atomic.Int64
+}
+
+// reserveBudget counts a send in the current period, returning the period
+// so the send can be released if it fails
+func (c *Client) reserveBudget(ctx context.Context) (time.Time, error) {
+	b := c.budget
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	period := periodStart(now, b.period)
+	resetAt := period.Add(b.period)
+
+	used, ok, err := b.store.Reserve(ctx, period, b.limit)
+	if err != nil {
+		return time.Time{}, &Error{Message: "failed to reserve send budget", Err: err}
+	}
+	if !ok {
+		return time.Time{}, NewBudgetExceededError(fmt.Sprintf("send budget of %d emails exhausted (%d used), resets at %s", b.limit, used, resetAt.UTC().Format(time.RFC3339)), used, b.limit, resetAt, nil)
+	}
+
+	threshold := c.budgetWarningThreshold
+	if threshold == 0 {
+		threshold = DefaultBudgetWarningThreshold
+	}
+	warnAt := int(math.Ceil(threshold * float64(b.limit)))
+	if used >= warnAt {
+		last := b.warned.Load()
+		if last != period.Unix() && b.warned.CompareAndSwap(last, period.Unix()) {
+			c.warn(ctx, Warning{
+				Code:    "budget_threshold",
+				Message: fmt.Sprintf("%d of %d emails in the send budget used, resets at %s", used, b.limit, resetAt.UTC().Format(time.RFC3339)),
+			})
+		}
+	}
+
+	return period, nil
+}
+
+// periodStart returns the start of the window of length period containing
+// t, with windows aligned to the Unix epoch
+func periodStart(t time.Time, period time.Duration) time.Time {
+	ns := t.UnixNano()
+	offset := ns % int64(period)
+	if offset < 0 {
+		offset += int64(period)
+	}
+	return time.Unix(0, ns-offset).UTC()
+}
@@ -0,0 +1,158 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Names of the user callbacks reported in CallbackPanicError.Callback
+const (
+	// CallbackWarningHandler is the handler set with WithWarningHandler.
+	// A panic is logged and the send proceeds.
+	CallbackWarningHandler = "warning_handler"
+
+	// CallbackConnectionDiagnostics is the callback set with
+	// WithConnectionDiagnostics. A panic is logged and the request
+	// proceeds.
+	CallbackConnectionDiagnostics = "connection_diagnostics"
+
+	// CallbackErrorMapper is the mapper set with WithErrorMapper. A panic
+	// fails the request with a CallbackPanicError wrapping the default
+	// error.
+	CallbackErrorMapper = "error_mapper"
+
+	// CallbackClock is the clock set with WithClock. A panic fails the send
+	// with a CallbackPanicError.
+	CallbackClock = "clock"
+
+	// CallbackArchiver is the archiver set with WithArchiver. A panic is
+	// logged and the send result is returned unchanged.
+	CallbackArchiver = "archiver"
+
+	// CallbackSendTrace is the callback set with WithSendTrace. A panic is
+	// logged and the send result is returned unchanged.
+	CallbackSendTrace = "send_trace"
+
+	// CallbackRecipientPreferences is the function set with
+	// WithRecipientPreferences. A panic fails the send with a
+	// CallbackPanicError.
+	CallbackRecipientPreferences = "recipient_preferences"
+
+	// CallbackRecipientResolver is the function set with
+	// WithRecipientResolver. A panic fails the send with a
+	// CallbackPanicError.
+	CallbackRecipientResolver = "recipient_resolver"
+
+	// CallbackContentPolicy is a policy added with WithContentPolicy. A
+	// panic fails the send with a CallbackPanicError.
+	CallbackContentPolicy = "content_policy"
+)
+
+// CallbackPanicError represents a panic recovered from a user callback.
+// Whether the panic fails the send depends on the callback; see the
+// Callback constants.
+type CallbackPanicError struct {
+	error *Error
+
+	// Callback names the callback that panicked, such as
+	// CallbackErrorMapper
+	Callback string
+
+	// Value is the value passed to panic
+	Value interface{}
+
+	// Stack is the stack trace of the panic when enabled with
+	// WithCallbackStackTraces
+	Stack string
+}
+
+// NewCallbackPanicError creates a new CallbackPanicError
+func NewCallbackPanicError(callback string, value interface{}, stack string, err error) *CallbackPanicError {
+	return &CallbackPanicError{
+		error: &Error{
+			Message: fmt.Sprintf("%s callback panicked: %v", callback, value),
+			Err:     err,
+		},
+		Callback: callback,
+		Value:    value,
+		Stack:    stack,
+	}
+}
+
+func (e *CallbackPanicError) Error() string {
+	return e.error.Error()
+}
+
+func (e *CallbackPanicError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *CallbackPanicError) base() *Error {
+	return e.error
+}
+
+// WithCallbackStackTraces records the stack trace of panics recovered from
+// user callbacks in CallbackPanicError.Stack and in the log
+func WithCallbackStackTraces() Option {
+	return clientOption(func(c *Client) error {
+		c.callbackStacks = true
+		return nil
+	})
+}
+
+// safeCall runs the user callback fn, recovering a panic into a logged
+// CallbackPanicError that wraps cause
+func (c *Client) safeCall(ctx context.Context, callback string, cause error, fn func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			stack := ""
+			if c.callbackStacks {
+				stack = string(debug.Stack())
+			}
+			panicErr := NewCallbackPanicError(callback, v, stack, cause)
+			if c.logger != nil {
+				attrs := []interface{}{"callback", callback, "panic", fmt.Sprint(v)}
+				if stack != "" {
+					attrs = append(attrs, "stack", stack)
+				}
+				c.logger.ErrorContext(ctx, "recovered panic in user callback", attrs...)
+			}
+			err = panicErr
+		}
+	}()
+	fn()
+	return nil
+}
+
+// diagnosticsCallback returns the connection diagnostics callback guarded
+// against panics, or nil when none is set
+func (c *Client) diagnosticsCallback() func(ConnDiagnostics) {
+	if c.connDiagnostics == nil {
+		return nil
+	}
+	return func(d ConnDiagnostics) {
+		_ = c.safeCall(context.Background(), CallbackConnectionDiagnostics, nil, func() { c.connDiagnostics(d) })
+	}
+}
+
+// guardedErrorMapper returns the error mapper guarded against panics, or
+// nil when none is set
+func (c *Client) guardedErrorMapper(ctx context.Context) ErrorMapper {
+	if c.errorMapper == nil {
+		return nil
+	}
+	return func(statusCode int, body []byte, defaultErr error) (mapped error) {
+		if err := c.safeCall(ctx, CallbackErrorMapper, defaultErr, func() { mapped = c.errorMapper(statusCode, body, defaultErr) }); err != nil {
+			return err
+		}
+		return mapped
+	}
+}
+
+// currentTime reads the client clock, failing if it panics
+func (c *Client) currentTime(ctx context.Context) (now time.Time, err error) {
+	err = c.safeCall(ctx, CallbackClock, nil, func() { now = c.now() })
+	return now, err
+}
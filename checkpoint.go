@@ -0,0 +1,255 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxCheckpointSkew is how far in the future a rate limit checkpoint may
+// be dated before it is considered invalid
+const maxCheckpointSkew = time.Minute
+
+// RateLimitCheckpoint is the saved state of a rate limit token bucket
+type RateLimitCheckpoint struct {
+	// Tokens is the number of tokens left, negative while sends are
+	// waiting for tokens
+	Tokens float64
+
+	// At is when Tokens was computed
+	At time.Time
+}
+
+// RateLimitStore saves the state of the client-side rate limits so that a
+// restarted process does not start with full buckets. Implementations must
+// be safe for concurrent use.
+type RateLimitStore interface {
+	// Load returns the checkpoint saved for the bucket name, or false if
+	// there is none
+	Load(ctx context.Context, name string) (RateLimitCheckpoint, bool, error)
+
+	// Save replaces the checkpoint of the bucket name
+	Save(ctx context.Context, name string, checkpoint RateLimitCheckpoint) error
+}
+
+// MemoryRateLimitStore is a RateLimitStore that keeps checkpoints in
+// memory, for clients recreated within one process
+type MemoryRateLimitStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]RateLimitCheckpoint
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{checkpoints: make(map[string]RateLimitCheckpoint)}
+}
+
+// Load implements RateLimitStore
+func (s *MemoryRateLimitStore) Load(ctx context.Context, name string) (RateLimitCheckpoint, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint, ok := s.checkpoints[name]
+	return checkpoint, ok, nil
+}
+
+// Save implements RateLimitStore
+func (s *MemoryRateLimitStore) Save(ctx context.Context, name string, checkpoint RateLimitCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[name] = checkpoint
+	return nil
+}
+
+// WithRateLimitStore saves the token buckets of WithCategoryRateLimits,
+// which must also be set, to store and restores them in NewClient, so
+// that restarting the process does not reset the rate limits.
+//
+// The buckets are saved after the first send, after later sends when at
+// least interval has passed since the last save, and by Close; an interval
+// of zero saves after every send. Sends do not wait for a save made by
+// another send. A process that crashes forgets the sends made since the
+// last save.
+//
+// A restored bucket is refilled only for the time elapsed since its
+// checkpoint, and not at all if the clock moved backwards. A checkpoint
+// that is invalid or dated in the future, or that cannot be loaded, is
+// replaced by an empty bucket and reported with a warning with code
+// "rate_limit_checkpoint_invalid". Send budgets are persisted by their
+// BudgetStore, see WithSendBudget. WithRateLimitStore cannot be used with
+// Client.WithOptions.
+func WithRateLimitStore(store RateLimitStore, interval time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if store == nil {
+			return NewValidationError("rate limit store cannot be nil", nil)
+		}
+		if interval < 0 {
+			return NewValidationError("rate limit checkpoint interval cannot be negative", nil)
+		}
+		c.rateLimitStore = store
+		c.rateLimitCheckpointInterval = interval
+		return nil
+	})
+}
+
+// rateLimitCheckpoints tracks when the rate limits were last saved and
+// the save in progress, so that sends do not wait on the store
+type rateLimitCheckpoints struct {
+	mu       sync.Mutex
+	lastSave time.Time
+
+	// saving is closed when the save in progress finishes, nil when there
+	// is none; pending asks it to save once more
+	saving  chan struct{}
+	pending bool
+}
+
+// restoreRateLimits loads the saved state of every rate limit bucket
+func (c *Client) restoreRateLimits() error {
+	if c.rateLimitStore == nil {
+		return nil
+	}
+	if c.rateLimiter == nil {
+		return NewValidationError("WithRateLimitStore requires WithCategoryRateLimits", nil)
+	}
+
+	ctx := context.Background()
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range c.rateLimiter.names() {
+		bucket := c.rateLimiter.buckets[name]
+		checkpoint, ok, err := c.rateLimitStore.Load(ctx, name)
+		switch {
+		case err != nil:
+			bucket.restoreEmpty(now)
+			c.warnInvalidCheckpoint(ctx, name, fmt.Sprintf("could not be loaded: %v", err))
+		case !ok:
+		case math.IsNaN(checkpoint.Tokens) || math.IsInf(checkpoint.Tokens, 0) || checkpoint.At.IsZero():
+			bucket.restoreEmpty(now)
+			c.warnInvalidCheckpoint(ctx, name, "is corrupt")
+		case checkpoint.At.After(now.Add(maxCheckpointSkew)):
+			bucket.restoreEmpty(now)
+			c.warnInvalidCheckpoint(ctx, name, "is dated "+checkpoint.At.Sub(now).Round(time.Second).String()+" in the future")
+		default:
+			bucket.restore(checkpoint, now)
+		}
+	}
+	return nil
+}
+
+// warnInvalidCheckpoint reports a checkpoint replaced by an empty bucket
+func (c *Client) warnInvalidCheckpoint(ctx context.Context, name, problem string) {
+	c.warn(ctx, Warning{
+		Code:    "rate_limit_checkpoint_invalid",
+		Message: fmt.Sprintf("rate limit checkpoint for %q %s; starting with an empty bucket", name, problem),
+	})
+}
+
+// checkpointRateLimits saves every rate limit bucket when the checkpoint
+// interval has passed, or always when force is set. The store is called
+// without holding the lock: a call made while another one is saving asks
+// it to save again instead, and returns at once unless force is set.
+func (c *Client) checkpointRateLimits(ctx context.Context, force bool) {
+	if c.rateLimitStore == nil {
+		return
+	}
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return
+	}
+	cp := &c.state.rateLimitCheckpoints
+	cp.mu.Lock()
+	if !force && now.Sub(cp.lastSave) < c.rateLimitCheckpointInterval {
+		cp.mu.Unlock()
+		return
+	}
+	cp.lastSave = now
+	if saving := cp.saving; saving != nil {
+		cp.pending = true
+		cp.mu.Unlock()
+		if force {
+			<-saving
+		}
+		return
+	}
+	saving := make(chan struct{})
+	cp.saving = saving
+
+	ctx = context.WithoutCancel(ctx)
+	for {
+		cp.pending = false
+		cp.mu.Unlock()
+		c.saveRateLimits(ctx)
+		cp.mu.Lock()
+		if !cp.pending {
+			break
+		}
+	}
+	cp.saving = nil
+	cp.mu.Unlock()
+	close(saving)
+}
+
+// saveRateLimits saves the current state of every rate limit bucket
+func (c *Client) saveRateLimits(ctx context.Context) {
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return
+	}
+	for _, name := range c.rateLimiter.names() {
+		checkpoint := c.rateLimiter.buckets[name].checkpoint(now)
+		if err := c.rateLimitStore.Save(ctx, name, checkpoint); err != nil {
+			c.warn(ctx, Warning{
+				Code:    "rate_limit_checkpoint_failed",
+				Message: fmt.Sprintf("failed to save rate limit checkpoint for %q: %v", name, err),
+			})
+		}
+	}
+}
+
+// names returns the names of the limiter's buckets, sorted
+func (l *categoryRateLimiter) names() []string {
+	names := make([]string, 0, len(l.buckets))
+	for name := range l.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkpoint returns the state of the bucket at now
+func (b *tokenBucket) checkpoint(now time.Time) RateLimitCheckpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	return RateLimitCheckpoint{Tokens: b.tokens, At: now}
+}
+
+// restore sets the bucket to checkpoint, refilled for the time elapsed
+// since it was taken
+func (b *tokenBucket) restore(checkpoint RateLimitCheckpoint, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := now.Sub(checkpoint.At)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	b.tokens = math.Min(b.burst, checkpoint.Tokens+elapsed.Seconds()*b.rate)
+	b.last = now
+}
+
+// restoreEmpty empties the bucket
+func (b *tokenBucket) restoreEmpty(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = 0
+	b.last = now
+}
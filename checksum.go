@@ -0,0 +1,32 @@
+package mailnow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// checksumMismatchCode is the API error code for a request body that does
+// not match its checksum
+const checksumMismatchCode = "checksum_mismatch"
+
+// WithPayloadChecksum sends the SHA-256 checksum of every request body in
+// the PayloadChecksumHeader header, so that the API rejects bodies that
+// were truncated or altered in transit instead of accepting them.
+//
+// The checksum is computed once per request and reused for its retries,
+// which send the same bytes. When the API reports a checksum mismatch
+// (HTTP 422 with code "checksum_mismatch") the request is sent again once
+// straight away, regardless of the retry policy.
+func WithPayloadChecksum() Option {
+	return clientOption(func(c *Client) error {
+		c.payloadChecksum = true
+		return nil
+	})
+}
+
+// PayloadChecksum returns the value of the PayloadChecksumHeader header for
+// body: the lowercase hex encoding of its SHA-256 hash
+func PayloadChecksum(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
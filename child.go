@@ -0,0 +1,61 @@
+package mailnow
+
+// WithOptions returns a child client with opts applied on top of the
+// client's configuration, for parts of a program that send with different
+// defaults. Making a child is cheap:
+//
+//   - the child shares the parent's HTTP transport and connection pool,
+//     and the state of the parent's components, such as the rate limiter,
+//     send budget, verified sender cache, in-flight limit and recipient
+//     domain pacer, unless opts replace them
+//   - options replace the parent's setting, except WithContentPolicy and
+//     WithRoleAccounts, which add to the parent's policies and role
+//     accounts
+//   - options that configure the connection, WithHTTPClient,
+//     WithTLSConfig, WithMinTLSVersion, WithRootCAs,
+//     WithPinnedCertificates, WithLocalAddr, WithoutLocalAddrCheck and
+//     WithEagerConnectivityCheck, are rejected with a ValidationError
+//   - WithRateLimitStore is rejected with a ValidationError, and so is
+//     WithCategoryRateLimits when the parent has a rate limit store, as the
+//     child's buckets would not be restored and would overwrite the
+//     parent's checkpoints
+//
+// The parent is not changed, and neither is a child by later children of
+// the same parent. Close on a parent or any of its children closes all of
+// them, as they share their connections.
+func (c *Client) WithOptions(opts ...Option) (*Client, error) {
+	child := *c
+	child.contentPolicies = c.contentPolicies[:len(c.contentPolicies):len(c.contentPolicies)]
+	if c.roleAccounts != nil {
+		child.roleAccounts = make(map[string]bool, len(c.roleAccounts))
+		for local := range c.roleAccounts {
+			child.roleAccounts[local] = true
+		}
+	}
+
+	// Collect the connection options separately to reject them
+	child.tls = tlsSettings{}
+	child.localAddr, child.skipLocalAddrCheck, child.eagerConnectivityCheck = nil, false, false
+	for _, opt := range opts {
+		if err := opt.applyClient(&child); err != nil {
+			return nil, err
+		}
+	}
+	if child.tls.isSet() || child.httpClient != c.httpClient ||
+		child.localAddr != nil || child.skipLocalAddrCheck || child.eagerConnectivityCheck {
+		return nil, NewValidationError("options that configure the connection cannot be used with WithOptions", nil)
+	}
+	child.tls, child.localAddr, child.skipLocalAddrCheck = c.tls, c.localAddr, c.skipLocalAddrCheck
+	if err := validateAPIKey(child.apiKey, child.minAPIKeySecretLength); err != nil {
+		return nil, err
+	}
+	if child.dmarcAlignmentCheck && child.verifiedSenders == nil {
+		return nil, NewValidationError("WithDMARCAlignmentCheck requires WithVerifiedSenderCheck", nil)
+	}
+	if child.rateLimitStore != c.rateLimitStore || child.rateLimitCheckpointInterval != c.rateLimitCheckpointInterval ||
+		(c.rateLimitStore != nil && child.rateLimiter != c.rateLimiter) {
+		return nil, NewValidationError("WithRateLimitStore, and WithCategoryRateLimits on a client with a rate limit store, cannot be used with WithOptions", nil)
+	}
+
+	return &child, nil
+}
@@ -27,9 +27,19 @@
 package mailnow
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Client represents a Mailnow API client for sending emails.
@@ -39,11 +49,233 @@ import (
 // for efficient request handling.
 //
 // A Client should be created using NewClient and can be safely reused
-// across multiple goroutines for sending multiple emails.
+// across multiple goroutines for sending multiple emails. Its configuration
+// cannot change after NewClient returns; WithOptions makes a child client
+// with a different configuration. All of its methods, including
+// InMaintenance, InFlight and RefreshVerifiedSenders, are safe to call
+// while other goroutines are sending.
 type Client struct {
 	apiKey     string
 	httpClient *http.Client
 	baseURL    string
+
+	// minAPIKeySecretLength is the minimum length of the API key after its
+	// prefix
+	minAPIKeySecretLength int
+
+	// endpoints are the API paths requests are sent to
+	endpoints Endpoints
+
+	// responseValidator checks send responses when response validation is enabled
+	responseValidator *responseValidator
+
+	// strictEnvelopes rejects email responses that are not successful
+	// envelopes with data
+	strictEnvelopes bool
+
+	// connDiagnostics receives per-request connection diagnostics when set
+	connDiagnostics func(ConnDiagnostics)
+
+	// rateLimiter throttles sends per category when configured
+	rateLimiter *categoryRateLimiter
+
+	// rateLimitStore saves the rate limit buckets when set
+	rateLimitStore RateLimitStore
+
+	// rateLimitCheckpointInterval is the minimum time between saves of the
+	// rate limit buckets
+	rateLimitCheckpointInterval time.Duration
+
+	// logger reports client activity when set
+	logger *slog.Logger
+
+	// subaccount is the sub-account requests are made on behalf of, if any
+	subaccount string
+
+	// retryPolicy controls retries of transient failures
+	retryPolicy RetryPolicy
+
+	// validationCache caches recipient validation results when set
+	validationCache *validationCache
+
+	// unsafeRetries retries POST requests whose outcome is unknown even
+	// without an idempotency key
+	unsafeRetries bool
+
+	// encodeSubjects sends non-ASCII subjects RFC 2047 encoded
+	encodeSubjects bool
+
+	// encodeFilenames sends non-ASCII attachment filenames RFC 2231 encoded
+	encodeFilenames bool
+
+	// defaultMessageClass is the message class of requests without one
+	defaultMessageClass MessageClass
+
+	// inlineCSS moves <style> rules into style attributes of sent HTML
+	inlineCSS bool
+
+	// sanitizer removes dangerous content from sent HTML when set
+	sanitizer *htmlSanitizer
+
+	// utmParams are appended to the links in sent HTML when set
+	utmParams map[string]string
+
+	// fromFallbacks are tried in order when the sender is not verified
+	fromFallbacks []string
+
+	// tls holds the TLS options, applied once all options are set
+	tls tlsSettings
+
+	// errorMapper customizes the errors returned for API error responses
+	errorMapper ErrorMapper
+
+	// envGuard lists the From domains allowed per key environment when set
+	envGuard map[KeyEnvironment][]string
+
+	// warningHandler receives client warnings when set
+	warningHandler func(Warning)
+
+	// budget limits the number of sends per period when set
+	budget *sendBudget
+
+	// frequencyCap limits the number of sends per recipient when set
+	frequencyCap *frequencyCap
+
+	// budgetWarningThreshold is the fraction of the budget that triggers a
+	// warning, or zero for the default
+	budgetWarningThreshold float64
+
+	// now returns the current time for time-based client features
+	now func() time.Time
+
+	// callbackStacks records stack traces of panics in user callbacks
+	callbackStacks bool
+
+	// verifiedSenders caches verified sender identities when the check is enabled
+	verifiedSenders *verifiedSenders
+
+	// dmarcAlignmentCheck adds a DMARC alignment check to the verified
+	// sender check
+	dmarcAlignmentCheck bool
+
+	// archiver receives a copy of every send when set
+	archiver Archiver
+
+	// archiveTimeout bounds how long a send waits for the archiver, or zero
+	// for the default
+	archiveTimeout time.Duration
+
+	// cacheControl overrides the Cache-Control request header when set
+	cacheControl string
+
+	// idempotencyStore records sends made with an idempotency key
+	idempotencyStore IdempotencyStore
+
+	// sendTrace receives the trace of every send when set
+	sendTrace func(SendTrace)
+
+	// inFlightSlots limits the number of requests in flight when set
+	inFlightSlots chan struct{}
+
+	// inFlightWait bounds the wait for an in-flight slot, or zero for no bound
+	inFlightWait time.Duration
+
+	// linkValidation selects how link issues are handled, or zero to skip
+	// link validation
+	linkValidation LinkValidationMode
+
+	// linkCheckTimeout bounds the reachability check of links, or zero to
+	// skip it
+	linkCheckTimeout time.Duration
+
+	// markdownRawHTML passes HTML in Markdown bodies through unescaped
+	markdownRawHTML bool
+
+	// history records sends when set
+	history *sendHistory
+
+	// historyPlaintext stores recipient addresses in the history unhashed
+	historyPlaintext bool
+
+	// customHTTPClient reports that httpClient was set with WithHTTPClient
+	// and belongs to the caller
+	customHTTPClient bool
+
+	// sharedHTTPClient reports that httpClient is shared by a ClientPool,
+	// whose first client already configured it with the same options
+	sharedHTTPClient bool
+
+	// eagerConnectivityCheck makes NewClient check that the API host is reachable
+	eagerConnectivityCheck bool
+
+	// localAddr is the source IP of connections to the API, or nil for the
+	// system's choice
+	localAddr net.IP
+
+	// skipLocalAddrCheck allows a localAddr not assigned to an interface
+	skipLocalAddrCheck bool
+
+	// payloadChecksum sends a checksum of every request body
+	payloadChecksum bool
+
+	// roleAccountPolicy selects how sends to role addresses are handled
+	roleAccountPolicy RoleAccountPolicy
+
+	// roleAccounts are role address local parts added to the built-in ones
+	roleAccounts map[string]bool
+
+	// contentPolicies check every send before it is made, in order
+	contentPolicies []ContentPolicy
+
+	// spamWarnThreshold and spamBlockThreshold are the spam scores at which
+	// a send is reported or rejected, or zero when not set
+	spamWarnThreshold  float64
+	spamBlockThreshold float64
+
+	// domainPacer paces sends per recipient domain when set
+	domainPacer *domainPacer
+
+	// recipientResolver expands recipient aliases when set
+	recipientResolver func(ctx context.Context, addr string) ([]string, error)
+
+	// recipientPreferences returns the stored body preference of a
+	// recipient when set
+	recipientPreferences func(email string) RecipientPreference
+
+	// state holds everything the client changes after construction. It is
+	// shared with the children made by WithOptions.
+	state *clientState
+}
+
+// clientState is the state a Client changes while in use. Every other
+// Client field is set by NewClient or WithOptions and only read afterwards,
+// and the components with their own state, such as the rate limiter and
+// the verified sender cache, guard it themselves.
+type clientState struct {
+	// inMaintenance records whether the last response reported maintenance
+	inMaintenance atomic.Bool
+
+	// inFlight is the number of requests in flight
+	inFlight atomic.Int32
+
+	// lifecycle guards closed and adding to active
+	lifecycle sync.RWMutex
+
+	// closed records whether Close was called
+	closed bool
+
+	// active counts the requests and background tasks Close waits for
+	active sync.WaitGroup
+
+	// stats keeps the outcomes of recent sends
+	stats sendStats
+
+	// replays is the number of sends answered with an earlier send's
+	// response
+	replays atomic.Int64
+
+	// rateLimitCheckpoints tracks saves of the rate limit buckets
+	rateLimitCheckpoints rateLimitCheckpoints
 }
 
 // NewClient creates and initializes a new Mailnow API client.
@@ -51,29 +283,73 @@ type Client struct {
 // The apiKey parameter must be a valid Mailnow API key starting with
 // either "mn_live_" (for production) or "mn_test_" (for testing).
 //
+// Options are applied in order before the API key is validated, so that
+// WithMinAPIKeySecretLength applies to it.
+//
 // Returns a configured Client ready to send emails, or an error if
-// the API key is invalid.
+// the API key or any option is invalid.
 //
 // Example:
 //
 //	client, err := mailnow.NewClient("mn_live_7e59df7ce4a14545b443837804ec9722")
-func NewClient(apiKey string) (*Client, error) {
+func NewClient(apiKey string, opts ...Option) (*Client, error) {
+	// Initialize HTTP client with timeout configuration
+	httpClient := &http.Client{
+		Timeout: RequestTimeout,
+	}
+
+	// Create the client
+	client := &Client{
+		apiKey:                apiKey,
+		httpClient:            httpClient,
+		baseURL:               APIBaseURL,
+		minAPIKeySecretLength: DefaultMinAPIKeySecretLength,
+		endpoints:             DefaultEndpoints,
+		now:                   time.Now,
+		state:                 &clientState{},
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		if err := opt.applyClient(client); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate API key
-	if err := ValidateAPIKey(apiKey); err != nil {
+	if err := validateAPIKey(apiKey, client.minAPIKeySecretLength); err != nil {
 		return nil, err
 	}
 
-	// Initialize HTTP client with timeout configuration
-	httpClient := &http.Client{
-		Timeout: RequestTimeout,
+	if client.dmarcAlignmentCheck && client.verifiedSenders == nil {
+		return nil, NewValidationError("WithDMARCAlignmentCheck requires WithVerifiedSenderCheck", nil)
 	}
 
-	// Create and return the client
-	return &Client{
-		apiKey:     apiKey,
-		httpClient: httpClient,
-		baseURL:    APIBaseURL,
-	}, nil
+	// Configure TLS from the combined options
+	if client.customHTTPClient && (client.tls.isSet() || client.localAddr != nil) {
+		return nil, NewValidationError("WithHTTPClient cannot be combined with the TLS options or WithLocalAddr", nil)
+	}
+	if !client.sharedHTTPClient {
+		if err := client.configureTLS(); err != nil {
+			return nil, err
+		}
+		if err := client.configureLocalAddr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := client.restoreRateLimits(); err != nil {
+		return nil, err
+	}
+
+	if client.eagerConnectivityCheck {
+		if err := client.checkConnectivity(); err != nil {
+			return nil, err
+		}
+	}
+
+	client.warnPartialEnvironmentGuard()
+
+	return client, nil
 }
 
 // SendEmail sends an email via the Mailnow API.
@@ -84,6 +360,7 @@ func NewClient(apiKey string) (*Client, error) {
 // Parameters:
 //   - ctx: Context for request cancellation and timeout control
 //   - req: EmailRequest containing from, to, subject, and HTML body
+//   - opts: Optional per-send options such as WithCategory
 //
 // Returns:
 //   - EmailResponse: contains success status, message ID, and delivery status
@@ -91,35 +368,482 @@ func NewClient(apiKey string) (*Client, error) {
 //
 // Errors:
 //   - ValidationError: returned when request parameters are invalid (empty fields, malformed emails)
-//   - AuthError: returned when the API key is invalid or unauthorized (HTTP 401)
+//   - AuthError: returned when the API key is invalid or unauthorized (HTTP 401), or the sub-account is unknown (HTTP 403)
 //   - RateLimitError: returned when rate limits are exceeded (HTTP 429)
 //   - ServerError: returned when the API encounters an internal error (HTTP 5xx)
-func (c *Client) SendEmail(ctx context.Context, req *EmailRequest) (*EmailResponse, error) {
-	// Validate email request
+//   - DuplicateSendError: returned when the recipient already received an email in req.CampaignID (HTTP 409)
+//   - CallbackUnreachableError: returned when the API cannot reach req.DeliveryCallback.URL (HTTP 400)
+//   - BudgetExceededError: returned without contacting the API when the send budget set with WithSendBudget is used up
+//   - FrequencyCapError: returned without contacting the API when the recipient reached the cap set with WithFrequencyCap
+//   - SendWindowError: returned without contacting the API outside the allowed hours of the recipient domain set with WithRecipientDomainPolicies
+//   - PolicyViolationError: returned without contacting the API when a content policy set with WithContentPolicy blocks the send
+//   - ErrClientClosed: returned without contacting the API after Close
+//
+// When the sender is not verified and fallbacks were configured with
+// WithFromFallbacks, the email is resent from each fallback in turn and
+// EmailResponse.FallbackFrom names the sender that was used.
+func (c *Client) SendEmail(ctx context.Context, req *EmailRequest, opts ...SendOption) (sent *EmailResponse, sendErr error) {
+	ctx, endOperation, ok := c.beginOperation(ctx)
+	if !ok {
+		return nil, ErrClientClosed
+	}
+	defer endOperation()
+
+	// Collect per-send options
+	sendOpts, err := newSendOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Trace the send when requested
+	var tracer *sendTracer
+	if c.sendTrace != nil {
+		ctx, tracer = newSendTracer(ctx)
+		defer func() {
+			c.finishSendTrace(ctx, tracer, sent, sendErr)
+		}()
+	}
+
+	// Validate email request, expanding recipient aliases and rendering a
+	// Markdown body first
+	validateStart := time.Now()
+	if req != nil && c.recipientResolver != nil {
+		resolved, err := c.resolveRecipients(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		req = resolved
+	}
+	if req != nil && req.Markdown != "" && req.HTML == "" {
+		rendered := *req
+		rendered.HTML = RenderMarkdown(req.Markdown, MarkdownOptions{AllowRawHTML: c.markdownRawHTML})
+		rendered.Markdown = ""
+		req = &rendered
+	}
 	if err := ValidateEmailRequest(req); err != nil {
 		return nil, err
 	}
+	if err := c.checkLinks(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := c.checkRoleAccount(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// Build the request as sent and check its content, before anything is
+	// reserved or waited for
+	if c.recipientPreferences != nil && !sendOpts.textOnly {
+		textOnly, err := c.prefersTextOnly(ctx, req.To)
+		if err != nil {
+			return nil, err
+		}
+		sendOpts.textOnly = textOnly
+	}
+	wireReq, err := c.wireRequest(req, sendOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkContentPolicies(ctx, wireReq); err != nil {
+		return nil, err
+	}
+	if c.spamWarnThreshold > 0 || c.spamBlockThreshold > 0 {
+		if err := c.checkSpamScore(ctx, wireReq); err != nil {
+			return nil, err
+		}
+	}
+
+	tracer.step(StepValidated, "", time.Since(validateStart))
+	if c.envGuard != nil {
+		if err := c.checkEnvironmentGuard(req); err != nil {
+			return nil, err
+		}
+	}
+	if c.verifiedSenders != nil {
+		if err := c.checkVerifiedSender(ctx, req.From); err != nil {
+			return nil, err
+		}
+		if c.dmarcAlignmentCheck {
+			if err := c.checkDMARCAlignment(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Wait for client-side rate limits
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.wait(ctx, sendOpts.category, c.currentTime, c.logger); err != nil {
+			return nil, err
+		}
+		c.checkpointRateLimits(ctx, false)
+	}
+	if c.domainPacer != nil {
+		if err := c.waitDomainPolicy(ctx, req.To); err != nil {
+			return nil, err
+		}
+	}
 
-	// Build full URL
-	url := c.baseURL + EmailSendEndpoint
+	// Replay a completed send with the same idempotency key, reserving the
+	// key only after the local waits so that a cancelled wait leaves it free
+	if key := sendOpts.idempotencyKey; key != "" {
+		existing, err := c.reserveIdempotencyKey(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			existing.Replayed = true
+			c.state.replays.Add(1)
+			return existing, nil
+		}
+		defer func() {
+			c.finishIdempotentSend(ctx, key, sent, sendErr)
+		}()
+		ctx = withIdempotencyKey(ctx, key)
+	}
+
+	// Count the send against the recipient's frequency cap, releasing it
+	// if the send definitely failed
+	if c.frequencyCap != nil {
+		key, err := c.reserveFrequencyCap(ctx, req.To)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if sendErr != nil && !isOutcomeUnknown(sendErr) {
+				c.releaseFrequencyCap(ctx, key, req.To)
+			}
+		}()
+	}
+
+	// Count the send against the send budget, releasing it if the send
+	// definitely failed
+	if c.budget != nil {
+		period, err := c.reserveBudget(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if sendErr != nil && !isOutcomeUnknown(sendErr) {
+				_ = c.budget.store.Release(context.WithoutCancel(ctx), period)
+			}
+		}()
+	}
 
 	// Make HTTP POST request
-	resp, err := MakeRequest(ctx, c.httpClient, "POST", url, c.apiKey, req)
+	subaccount := c.subaccount
+	if sendOpts.subaccount != "" {
+		subaccount = sendOpts.subaccount
+	}
+	if c.archiver != nil {
+		started := time.Now()
+		defer func() {
+			c.archive(ctx, wireReq, sent, sendErr, started)
+		}()
+	}
+	if c.history != nil {
+		defer func() {
+			c.recordHistory(ctx, req, sendOpts.category, sent, sendErr)
+		}()
+	}
+	finishStats := c.startSendStats(ctx)
+	defer func() {
+		finishStats(sendErr)
+	}()
+	emailResp, statusCode, err := c.sendEmailRequest(ctx, wireReq, subaccount)
+
+	// Fall back to other senders while the sender is not verified
+	fallbackFrom := ""
+	if err != nil && isSenderNotVerified(err) && len(c.fromFallbacks) > 0 {
+		emailResp, statusCode, fallbackFrom, err = c.sendFromFallbacks(ctx, wireReq, subaccount, err)
+	}
+	if err != nil && statusCode == http.StatusConflict && ErrorCode(err) == idempotentReplayCode {
+		if replayed, ok := replayResponse(err); ok {
+			c.state.replays.Add(1)
+			return replayed, nil
+		}
+	}
 	if err != nil {
+		if statusCode == http.StatusConflict && req.CampaignID != "" {
+			return nil, NewDuplicateSendError(fmt.Sprintf("email already sent to %s in campaign %s", req.To, req.CampaignID), req.CampaignID, req.To, err)
+		}
+		var callbackErr *CallbackUnreachableError
+		if errors.As(err, &callbackErr) && callbackErr.URL == "" && req.DeliveryCallback != nil {
+			callbackErr.URL = req.DeliveryCallback.URL
+		}
 		return nil, err
 	}
 
-	// Handle response
-	body, err := HandleResponse(resp)
+	emailResp.FallbackFrom = fallbackFrom
+	if emailResp.Replayed {
+		c.state.replays.Add(1)
+	}
+
+	// Check response consistency if enabled
+	if c.responseValidator != nil {
+		if err := c.responseValidator.validate(statusCode, emailResp); err != nil {
+			return nil, withAcceptedStatus(err, statusCode)
+		}
+	}
+
+	return emailResp, nil
+}
+
+// sendEmailRequest sends req to the send endpoint on behalf of subaccount,
+// returning the status code of the last response received, if any
+func (c *Client) sendEmailRequest(ctx context.Context, req *EmailRequest, subaccount string) (*EmailResponse, int, error) {
+	resp, meta, err := c.subaccountAPI(subaccount).SendEmail(ctx, req)
+	if err != nil && meta.StatusCode >= 200 && meta.StatusCode < 300 {
+		// The API accepted the send but its response was rejected
+		err = withAcceptedStatus(err, meta.StatusCode)
+	}
+	return resp, meta.StatusCode, err
+}
+
+// InMaintenance reports whether the API last reported a maintenance window.
+// It is cleared by the next successful response, and is safe to read while
+// sends are in flight.
+func (c *Client) InMaintenance() bool {
+	return c.state.inMaintenance.Load()
+}
+
+// wireRequest returns the request to send to the API for req, applying the
+// client's transformations to a copy so that req is left unchanged
+func (c *Client) wireRequest(req *EmailRequest, o *sendOptions) (*EmailRequest, error) {
+	utmParams := c.utmParams
+	if o.utmParams != nil {
+		utmParams = o.utmParams
+	}
+	attachments, err := wireAttachments(req.Attachments, c.encodeFilenames)
 	if err != nil {
 		return nil, err
 	}
+	class := req.MessageClass
+	if class == "" {
+		class = c.defaultMessageClass
+	}
+	if !c.encodeSubjects && o.inlineImages == nil && c.sanitizer == nil && !c.inlineCSS && len(utmParams) == 0 && len(req.ReplyTo) == 0 && !req.RequestReadReceipt && !o.textOnly && attachments == nil && class == "" {
+		return req, nil
+	}
 
-	// Parse successful response JSON into EmailResponse struct
-	var emailResp EmailResponse
-	if err := json.Unmarshal(body, &emailResp); err != nil {
-		return nil, NewServerError("failed to parse response", err)
+	wireReq := *req
+	if attachments != nil {
+		wireReq.Attachments = attachments
+	}
+	if len(req.ReplyTo) > 0 {
+		wireReq.ReplyTo = normalizeReplyTo(req.ReplyTo)
+	}
+	if req.RequestReadReceipt {
+		wireReq.Headers = withReceiptHeaders(req.Headers, req)
+	}
+	if class != "" {
+		wireReq.Headers = withMessageClassHeaders(wireReq.Headers, class)
+	}
+	if c.encodeSubjects {
+		subject, err := encodeSubject(req.Subject)
+		if err != nil {
+			return nil, err
+		}
+		wireReq.Subject = subject
+	}
+	if o.inlineImages != nil {
+		html, images, err := o.inlineImages.Extract(wireReq.HTML)
+		if err != nil {
+			return nil, err
+		}
+		wireReq.HTML = html
+		if len(images) > 0 {
+			wireReq.Attachments = append(append([]Attachment(nil), wireReq.Attachments...), images...)
+		}
+	}
+	if c.sanitizer != nil {
+		wireReq.HTML = c.sanitizer.sanitize(wireReq.HTML)
+	}
+	if c.inlineCSS {
+		html, err := InlineCSS(wireReq.HTML)
+		if err != nil {
+			return nil, err
+		}
+		wireReq.HTML = html
+	}
+	if len(utmParams) > 0 {
+		html, err := InjectUTMParams(wireReq.HTML, utmParams)
+		if err != nil {
+			return nil, err
+		}
+		wireReq.HTML = html
+	}
+	if o.textOnly {
+		if req.Text == "" {
+			return nil, NewValidationError(fmt.Sprintf("text-only send to %s requires a Text body", req.To), nil)
+		}
+		wireReq.HTML = ""
+	}
+	return &wireReq, nil
+}
+
+// newRequestMeta returns the metadata for the first attempt of a request
+// made with ctx starting now, made on behalf of subaccount when it is set
+func (c *Client) newRequestMeta(ctx context.Context, subaccount string, body []byte) requestMeta {
+	meta := requestMeta{
+		start:       time.Now(),
+		attempt:     1,
+		diagnostics: c.diagnosticsCallback(),
+	}
+	checksum := c.payloadChecksum && body != nil
+	idempotencyKey := idempotencyKeyFrom(ctx)
+	if subaccount != "" || c.cacheControl != "" || checksum || idempotencyKey != "" {
+		meta.headers = make(http.Header)
+	}
+	if subaccount != "" {
+		meta.headers.Set(SubaccountHeader, subaccount)
+	}
+	if idempotencyKey != "" {
+		meta.headers.Set(IdempotencyKeyHeader, idempotencyKey)
+	}
+	if c.cacheControl != "" {
+		meta.headers.Set("Cache-Control", c.cacheControl)
+	}
+	if checksum {
+		meta.headers.Set(PayloadChecksumHeader, PayloadChecksum(body))
+	}
+	return meta
+}
+
+// api returns the low-level API used for requests made with ctx
+func (c *Client) api(ctx context.Context) *API {
+	return &API{
+		APIKey:          c.apiKey,
+		BaseURL:         c.baseURL,
+		HTTPClient:      c.httpClient,
+		Endpoints:       c.endpoints,
+		ErrorMapper:     c.guardedErrorMapper(ctx),
+		StrictEnvelopes: c.strictEnvelopes,
 	}
+}
+
+// requestAPI returns the API the Client methods are built on. Its requests
+// are sent with send, on behalf of the client's sub-account.
+func (c *Client) requestAPI() *API {
+	return c.subaccountAPI(c.subaccount)
+}
+
+// subaccountAPI returns the API the Client methods are built on, sending
+// its requests on behalf of subaccount
+func (c *Client) subaccountAPI(subaccount string) *API {
+	return &API{
+		Endpoints:       c.endpoints,
+		StrictEnvelopes: c.strictEnvelopes,
+		send: func(ctx context.Context, method, path, contentType string, body []byte) ([]byte, HTTPMeta, error) {
+			statusCode, respBody, err := c.send(ctx, method, path, contentType, body, subaccount)
+			return respBody, HTTPMeta{StatusCode: statusCode}, err
+		},
+	}
+}
 
+// send sends a request to path on behalf of subaccount, retrying transient
+// failures according to the client's retry policy. It returns the status
+// code of the last response received, if any, and the body of a successful
+// response. Every attempt sends the same body bytes.
+func (c *Client) send(ctx context.Context, method, path, contentType string, body []byte, subaccount string) (int, []byte, error) {
+	ctx, endOperation, ok := c.beginOperation(ctx)
+	if !ok {
+		return 0, nil, ErrClientClosed
+	}
+	defer endOperation()
+
+	api := c.api(ctx)
+	meta := c.newRequestMeta(ctx, subaccount, body)
+	tracer := tracerFrom(ctx)
+	checksumRetried := false
+	for {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+
+		tracer.step(StepAttempt, strconv.Itoa(meta.attempt), 0)
+		var respBody []byte
+		var httpMeta HTTPMeta
+		err := c.acquireInFlight(ctx)
+		if err == nil {
+			respBody, httpMeta, err = api.do(ctx, method, path, contentType, reqBody, meta)
+			c.releaseInFlight()
+		}
+		statusCode := httpMeta.StatusCode
+		tracer.step(StepResponse, responseDetail(statusCode, err), httpMeta.Duration)
+		if err == nil {
+			c.state.inMaintenance.Store(false)
+			return statusCode, respBody, nil
+		}
+		var maintenanceErr *MaintenanceError
+		if errors.As(err, &maintenanceErr) {
+			c.state.inMaintenance.Store(true)
+		}
+		err = withSubaccountDetails(err, subaccount)
+
+		// A checksum mismatch means the body was corrupted in transit, so
+		// it is sent again once straight away
+		if c.payloadChecksum && !checksumRetried && ErrorCode(err) == checksumMismatchCode {
+			checksumRetried = true
+			meta.attempt++
+			continue
+		}
+
+		// Retry transient failures
+		retry, err := c.shouldRetry(ctx, method, body, meta, err)
+		if !retry {
+			return statusCode, nil, err
+		}
+		delay := c.retryPolicy.delay(meta.attempt, err)
+		tracer.step(StepBackoff, "", delay)
+		if sleepContext(ctx, delay) != nil {
+			// Report the last failure rather than the cancellation
+			return statusCode, nil, err
+		}
+		meta.attempt++
+	}
+}
+
+// withSubaccountDetails names the sub-account in errors reporting that it
+// does not exist
+func withSubaccountDetails(err error, subaccount string) error {
+	if e := baseOf(err); e != nil && e.Code == subaccountNotFoundCode && subaccount != "" {
+		e.Message = fmt.Sprintf("sub-account %q not found: %s", subaccount, e.Message)
+	}
+	return err
+}
+
+// decodeEmailResponse parses a response carrying email data. When strict
+// is set the body must be a successful envelope with data, as checked by
+// DecodeEnvelope; otherwise a body without data is read in the flat shape,
+// with the message ID and status at the top level.
+func decodeEmailResponse(body []byte, strict bool) (*EmailResponse, error) {
+	if strict {
+		data, env, err := DecodeEnvelope[Data](body)
+		if err != nil {
+			return nil, err
+		}
+		return &EmailResponse{
+			Data:       *data,
+			Message:    env.Message,
+			StatusCode: env.StatusCode,
+			Success:    env.Success,
+			Replayed:   isIdempotentReplay(body),
+		}, nil
+	}
+
+	var flat struct {
+		EmailResponse
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &flat); err != nil {
+		return nil, NewServerError("failed to parse response", err)
+	}
+	emailResp := flat.EmailResponse
+	if emailResp.Data.MessageID == "" {
+		emailResp.Data.MessageID = flat.MessageID
+		emailResp.Data.Status = flat.Status
+	}
+	emailResp.Replayed = isIdempotentReplay(body)
 	return &emailResp, nil
 }
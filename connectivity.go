@@ -0,0 +1,40 @@
+package mailnow
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ConnectivityCheckTimeout bounds the request made by
+// WithEagerConnectivityCheck
+const ConnectivityCheckTimeout = 3 * time.Second
+
+// WithEagerConnectivityCheck makes NewClient send a HEAD request to the
+// base URL through the client's HTTP client, so with its proxy, TLS and
+// local address settings, failing with a ConnectionError when no response
+// arrives within ConnectivityCheckTimeout. Any response, whatever its
+// status, counts as reachable. By default the API is first contacted on
+// the first request.
+func WithEagerConnectivityCheck() Option {
+	return clientOption(func(c *Client) error {
+		c.eagerConnectivityCheck = true
+		return nil
+	})
+}
+
+// checkConnectivity sends a HEAD request to the base URL
+func (c *Client) checkConnectivity() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectivityCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL, nil)
+	if err != nil {
+		return NewValidationError("invalid base URL "+c.baseURL, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return NewConnectionError("NewClient could not reach the API at "+c.baseURL+"; check the base URL", err)
+	}
+	return resp.Body.Close()
+}
@@ -12,12 +12,60 @@ const (
 	// EmailSendEndpoint is the endpoint for sending emails
 	EmailSendEndpoint = "/v1/email/send"
 
+	// EmailEndpoint is the endpoint for individual emails, addressed by message ID
+	EmailEndpoint = "/v1/email"
+
+	// BulkValidateEndpoint is the endpoint for validating recipient addresses in bulk
+	BulkValidateEndpoint = "/v1/email/validate/bulk"
+
+	// AssetsEndpoint is the endpoint for uploading attachment assets
+	AssetsEndpoint = "/v1/assets"
+
+	// TrackingDomainsEndpoint is the endpoint for managing tracking domains
+	TrackingDomainsEndpoint = "/v1/tracking-domains"
+
+	// SenderIdentitiesEndpoint is the endpoint for listing sender identities
+	SenderIdentitiesEndpoint = "/v1/sender-identities"
+
+	// SuppressionsEndpoint is the endpoint for managing suppressed recipients
+	SuppressionsEndpoint = "/v1/suppressions"
+
+	// PlanEndpoint is the endpoint for the account's plan and its features
+	PlanEndpoint = "/v1/plan"
+
+	// SubaccountHeader is the header naming the sub-account a request acts for
+	SubaccountHeader = "X-Subaccount-Id"
+
+	// IdempotencyKeyHeader is the header carrying the key of a send made
+	// with WithIdempotencyKey, so that the API can detect a reused key
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// PayloadChecksumHeader is the header carrying the SHA-256 checksum of
+	// the request body when WithPayloadChecksum is used
+	PayloadChecksumHeader = "X-Content-SHA256"
+
+	// DefaultCacheControl is the Cache-Control header sent with every
+	// request unless WithCacheControl says otherwise
+	DefaultCacheControl = "no-store"
+
 	// RequestTimeout is the default timeout for API requests
 	RequestTimeout = 30 * time.Second
 
+	// MaxScheduleHorizon is how far in the future an email can be scheduled
+	MaxScheduleHorizon = 72 * time.Hour
+
 	// APIKeyPrefixLive is the prefix for live API keys
 	APIKeyPrefixLive = "mn_live_"
 
 	// APIKeyPrefixTest is the prefix for test API keys
 	APIKeyPrefixTest = "mn_test_"
+
+	// DefaultMinAPIKeySecretLength is the default minimum length of the
+	// part of an API key after its prefix. Keys of any length are accepted
+	// unless a client sets a minimum with WithMinAPIKeySecretLength.
+	DefaultMinAPIKeySecretLength = 0
+
+	// RecommendedMinAPIKeySecretLength is a minimum secret length for
+	// WithMinAPIKeySecretLength that rejects truncated keys
+	RecommendedMinAPIKeySecretLength = 12
 )
@@ -0,0 +1,644 @@
+package mailnow
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// styleBlockRegex matches a <style> element and captures its attributes
+	// and contents
+	styleBlockRegex = regexp.MustCompile(`(?is)<style\b([^>]*)>(.*?)</style\s*>`)
+
+	// styleOpenRegex and styleCloseRegex match the tags of a <style> element
+	styleOpenRegex  = regexp.MustCompile(`(?i)<style\b`)
+	styleCloseRegex = regexp.MustCompile(`(?i)</style\s*>`)
+
+	// cssCommentRegex matches a CSS comment
+	cssCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+	// htmlTokenRegex matches an HTML comment, start tag or end tag, allowing
+	// '>' inside quoted attributes
+	htmlTokenRegex = regexp.MustCompile(`(?s)<!--.*?-->|</?[a-zA-Z](?:[^>"']|"[^"]*"|'[^']*')*>`)
+
+	// tagNameRegex captures the name of a start or end tag
+	tagNameRegex = regexp.MustCompile(`^</?([a-zA-Z][a-zA-Z0-9-]*)`)
+
+	// attrRegex matches an attribute and its optional value
+	attrRegex = regexp.MustCompile(`([^\s"'<>/=]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s>"']+))?`)
+
+	// styleAttrRegex matches a style attribute and its value
+	styleAttrRegex = regexp.MustCompile(`(?i)(\sstyle\s*=\s*)("[^"]*"|'[^']*'|[^\s>"']+)`)
+)
+
+// voidElements are HTML elements that have no end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements maps the HTML elements whose contents are not markup to
+// a pattern matching their end tag
+var rawTextElements = map[string]*regexp.Regexp{
+	"script":   regexp.MustCompile(`(?i)</script\s*>`),
+	"style":    styleCloseRegex,
+	"textarea": regexp.MustCompile(`(?i)</textarea\s*>`),
+	"title":    regexp.MustCompile(`(?i)</title\s*>`),
+}
+
+// unstyledElements are HTML elements that never receive inline styles
+var unstyledElements = map[string]bool{
+	"head": true, "title": true, "meta": true, "link": true, "style": true, "script": true, "base": true,
+}
+
+// InlineCSS moves the rules of the <style> elements in html into the style
+// attributes of the elements they match, for mail clients such as Gmail
+// that ignore <style> elements.
+//
+// Declarations are applied in CSS cascade order: by selector specificity,
+// then source order. Existing style attributes take precedence over the
+// style sheet unless the style sheet declaration is !important.
+//
+// Type, class, ID and attribute selectors are supported, combined with the
+// descendant and child combinators. Rules that cannot be inlined, such as
+// media queries, other at-rules and selectors with pseudo-classes, are
+// kept in their <style> element; style elements left empty are removed.
+// Malformed rules and declarations are skipped.
+func InlineCSS(html string) (string, error) {
+	if len(styleOpenRegex.FindAllStringIndex(html, -1)) != len(styleCloseRegex.FindAllStringIndex(html, -1)) {
+		return "", NewValidationError("HTML has an unterminated <style> element", nil)
+	}
+
+	var rules []cssRule
+	order := 0
+	html = styleBlockRegex.ReplaceAllStringFunc(html, func(block string) string {
+		m := styleBlockRegex.FindStringSubmatch(block)
+		blockRules, kept := parseStylesheet(m[2], &order)
+		rules = append(rules, blockRules...)
+		if len(kept) == 0 {
+			return ""
+		}
+		return "<style" + m[1] + ">\n" + strings.Join(kept, "\n") + "\n</style>"
+	})
+	if len(rules) == 0 {
+		return html, nil
+	}
+
+	return applyCSSRules(html, rules), nil
+}
+
+// WithCSSInlining inlines the <style> rules of the HTML body of every send,
+// as InlineCSS does. The request passed to SendEmail keeps its original
+// HTML; only the copy sent to the API is rewritten.
+func WithCSSInlining() Option {
+	return clientOption(func(c *Client) error {
+		c.inlineCSS = true
+		return nil
+	})
+}
+
+// cssDeclaration is a single property declaration
+type cssDeclaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+// cssRule is a style rule with a single selector
+type cssRule struct {
+	selector    []cssCompound
+	specificity [3]int
+	order       int
+	decls       []cssDeclaration
+}
+
+// cssCompound is a compound selector such as "p.note", along with the
+// combinator relating it to the compound before it
+type cssCompound struct {
+	combinator byte
+	tag        string
+	id         string
+	classes    []string
+	attrs      []cssAttrSelector
+}
+
+// cssAttrSelector is an attribute selector such as [type="text"]
+type cssAttrSelector struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// htmlElement is an open element in the document
+type htmlElement struct {
+	tag     string
+	attrs   map[string]string
+	classes []string
+	parent  *htmlElement
+}
+
+// parseStylesheet parses the rules of a style sheet, returning the rules
+// that can be inlined and the source of those that must be kept
+func parseStylesheet(css string, order *int) ([]cssRule, []string) {
+	css = cssCommentRegex.ReplaceAllString(css, "")
+
+	var rules []cssRule
+	var kept []string
+	for i := 0; i < len(css); {
+		// Skip whitespace and stray separators
+		if c := css[i]; c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == ';' || c == '}' {
+			i++
+			continue
+		}
+
+		if css[i] == '@' {
+			// Keep at-rules whole, with or without a block
+			end := cssStatementEnd(css, i)
+			kept = append(kept, strings.TrimSpace(css[i:end]))
+			i = end
+			continue
+		}
+
+		open := strings.IndexByte(css[i:], '{')
+		if open < 0 {
+			break
+		}
+		open += i
+		end := cssBlockEnd(css, open)
+		prelude := strings.TrimSpace(css[i:open])
+		body := css[open+1 : end]
+		i = end + 1
+
+		decls := parseDeclarations(body)
+		if len(decls) == 0 {
+			continue
+		}
+		for _, sel := range strings.Split(prelude, ",") {
+			sel = strings.TrimSpace(sel)
+			compounds, ok := parseSelector(sel)
+			if !ok {
+				if sel != "" {
+					kept = append(kept, sel+" {"+body+"}")
+				}
+				continue
+			}
+			*order++
+			rules = append(rules, cssRule{
+				selector:    compounds,
+				specificity: selectorSpecificity(compounds),
+				order:       *order,
+				decls:       decls,
+			})
+		}
+	}
+	return rules, kept
+}
+
+// cssStatementEnd returns the end of the at-rule starting at i: after its
+// terminating ';' or its block
+func cssStatementEnd(css string, i int) int {
+	for j := i; j < len(css); j++ {
+		switch css[j] {
+		case ';':
+			return j + 1
+		case '{':
+			end := cssBlockEnd(css, j)
+			if end < len(css) {
+				end++
+			}
+			return end
+		case '"', '\'':
+			j = cssStringEnd(css, j)
+		}
+	}
+	return len(css)
+}
+
+// cssBlockEnd returns the index of the '}' closing the block opened at
+// open, or the end of css if the block is not closed
+func cssBlockEnd(css string, open int) int {
+	depth := 0
+	for j := open; j < len(css); j++ {
+		switch css[j] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case '"', '\'':
+			j = cssStringEnd(css, j)
+		}
+	}
+	return len(css)
+}
+
+// cssStringEnd returns the index of the quote closing the string starting
+// at i
+func cssStringEnd(css string, i int) int {
+	quote := css[i]
+	for j := i + 1; j < len(css); j++ {
+		switch css[j] {
+		case '\\':
+			j++
+		case quote:
+			return j
+		}
+	}
+	return len(css)
+}
+
+// parseDeclarations parses a declaration block, skipping declarations
+// without a property or value
+func parseDeclarations(body string) []cssDeclaration {
+	var decls []cssDeclaration
+	for _, part := range splitDeclarations(body) {
+		colon := strings.IndexByte(part, ':')
+		if colon < 0 {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(part[:colon]))
+		value := strings.TrimSpace(part[colon+1:])
+		important := false
+		if i := strings.LastIndex(value, "!"); i >= 0 && strings.EqualFold(strings.TrimSpace(value[i+1:]), "important") {
+			important = true
+			value = strings.TrimSpace(value[:i])
+		}
+		if property == "" || value == "" || strings.ContainsAny(property, " {}") {
+			continue
+		}
+		decls = append(decls, cssDeclaration{property: property, value: value, important: important})
+	}
+	return decls
+}
+
+// splitDeclarations splits a declaration block on the semicolons that are
+// not inside strings or parentheses
+func splitDeclarations(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for j := 0; j < len(body); j++ {
+		switch body[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case '"', '\'':
+			j = cssStringEnd(body, j)
+		case ';':
+			if depth == 0 {
+				parts = append(parts, body[start:j])
+				start = j + 1
+			}
+		}
+	}
+	return append(parts, body[start:])
+}
+
+// parseSelector parses a selector made of type, class, ID and attribute
+// selectors joined by descendant or child combinators. It reports false
+// for anything else, such as pseudo-classes.
+func parseSelector(sel string) ([]cssCompound, bool) {
+	var compounds []cssCompound
+	var current *cssCompound
+	combinator := byte(' ')
+
+	for i := 0; i < len(sel); {
+		c := sel[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if current != nil {
+				compounds = append(compounds, *current)
+				current = nil
+				combinator = ' '
+			}
+			i++
+			continue
+		case c == '>':
+			if current != nil {
+				compounds = append(compounds, *current)
+				current = nil
+			} else if len(compounds) == 0 {
+				return nil, false
+			}
+			combinator = '>'
+			i++
+			continue
+		}
+
+		if current == nil {
+			current = &cssCompound{combinator: combinator}
+		}
+		switch {
+		case c == '*':
+			i++
+		case c == '.' || c == '#':
+			name, n := cssIdent(sel[i+1:])
+			if n == 0 {
+				return nil, false
+			}
+			if c == '.' {
+				current.classes = append(current.classes, name)
+			} else {
+				current.id = name
+			}
+			i += 1 + n
+		case c == '[':
+			end := strings.IndexByte(sel[i:], ']')
+			if end < 0 {
+				return nil, false
+			}
+			attr, ok := parseAttrSelector(sel[i+1 : i+end])
+			if !ok {
+				return nil, false
+			}
+			current.attrs = append(current.attrs, attr)
+			i += end + 1
+		default:
+			name, n := cssIdent(sel[i:])
+			if n == 0 || current.tag != "" {
+				return nil, false
+			}
+			current.tag = strings.ToLower(name)
+			i += n
+		}
+	}
+	if current == nil {
+		return nil, false
+	}
+	return append(compounds, *current), true
+}
+
+// cssIdent returns the identifier at the start of s and its length
+func cssIdent(s string) (string, int) {
+	n := 0
+	for n < len(s) {
+		c := s[n]
+		if c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || n > 0 && c >= '0' && c <= '9' || c >= 0x80 {
+			n++
+			continue
+		}
+		break
+	}
+	return s[:n], n
+}
+
+// parseAttrSelector parses the inside of an attribute selector, supporting
+// presence and exact value matches
+func parseAttrSelector(s string) (cssAttrSelector, bool) {
+	name, value, hasValue := strings.Cut(s, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, n := cssIdent(name); n == 0 || n != len(name) {
+		return cssAttrSelector{}, false
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return cssAttrSelector{name: name, value: value, hasValue: hasValue}, true
+}
+
+// selectorSpecificity counts the IDs, classes and attributes, and types in
+// a selector
+func selectorSpecificity(compounds []cssCompound) [3]int {
+	var s [3]int
+	for _, c := range compounds {
+		if c.id != "" {
+			s[0]++
+		}
+		s[1] += len(c.classes) + len(c.attrs)
+		if c.tag != "" {
+			s[2]++
+		}
+	}
+	return s
+}
+
+// matchSelector reports whether the selector compounds[:n] matches el
+func matchSelector(compounds []cssCompound, n int, el *htmlElement) bool {
+	c := compounds[n-1]
+	if !matchCompound(c, el) {
+		return false
+	}
+	if n == 1 {
+		return true
+	}
+	if c.combinator == '>' {
+		return el.parent != nil && matchSelector(compounds, n-1, el.parent)
+	}
+	for ancestor := el.parent; ancestor != nil; ancestor = ancestor.parent {
+		if matchSelector(compounds, n-1, ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCompound reports whether a compound selector matches el
+func matchCompound(c cssCompound, el *htmlElement) bool {
+	if c.tag != "" && c.tag != el.tag {
+		return false
+	}
+	if c.id != "" && el.attrs["id"] != c.id {
+		return false
+	}
+	for _, class := range c.classes {
+		found := false
+		for _, have := range el.classes {
+			if have == class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, attr := range c.attrs {
+		value, ok := el.attrs[attr.name]
+		if !ok || attr.hasValue && value != attr.value {
+			return false
+		}
+	}
+	return true
+}
+
+// applyCSSRules writes the declarations of the matching rules into the
+// style attribute of every element in html
+func applyCSSRules(doc string, rules []cssRule) string {
+	var b strings.Builder
+	var current *htmlElement
+	last := 0
+
+	tokens := htmlTokenRegex.FindAllStringIndex(doc, -1)
+	for t := 0; t < len(tokens); t++ {
+		start, end := tokens[t][0], tokens[t][1]
+		if start < last {
+			continue
+		}
+		token := doc[start:end]
+		if strings.HasPrefix(token, "<!--") {
+			continue
+		}
+		name := strings.ToLower(tagNameRegex.FindStringSubmatch(token)[1])
+
+		if token[1] == '/' {
+			// Close the innermost open element with this name
+			for el := current; el != nil; el = el.parent {
+				if el.tag == name {
+					current = el.parent
+					break
+				}
+			}
+			continue
+		}
+
+		el := newHTMLElement(name, token, current)
+		if !unstyledElements[name] {
+			if decls := matchingDeclarations(rules, el); len(decls) > 0 {
+				b.WriteString(doc[last:start])
+				b.WriteString(withInlineStyle(token, decls))
+				last = end
+			}
+		}
+
+		if closeTag, ok := rawTextElements[name]; ok {
+			// Skip to the end tag without interpreting the contents
+			if loc := closeTag.FindStringIndex(doc[end:]); loc != nil {
+				for t+1 < len(tokens) && tokens[t+1][0] < end+loc[1] {
+					t++
+				}
+			}
+			continue
+		}
+		if !voidElements[name] && !strings.HasSuffix(token, "/>") {
+			current = el
+		}
+	}
+	b.WriteString(doc[last:])
+	return b.String()
+}
+
+// newHTMLElement describes the element opened by a start tag
+func newHTMLElement(name, tag string, parent *htmlElement) *htmlElement {
+	el := &htmlElement{tag: name, attrs: make(map[string]string), parent: parent}
+	rest := tag[1+len(name) : len(tag)-1]
+	for _, m := range attrRegex.FindAllStringSubmatch(rest, -1) {
+		value := m[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		attr := strings.ToLower(m[1])
+		if _, ok := el.attrs[attr]; !ok {
+			el.attrs[attr] = html.UnescapeString(value)
+		}
+	}
+	el.classes = strings.Fields(el.attrs["class"])
+	return el
+}
+
+// matchingDeclarations returns the declarations of the rules matching el,
+// in cascade order
+func matchingDeclarations(rules []cssRule, el *htmlElement) []cssDeclaration {
+	var matched []cssRule
+	for _, r := range rules {
+		if matchSelector(r.selector, len(r.selector), el) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.specificity != b.specificity {
+			for k := range a.specificity {
+				if a.specificity[k] != b.specificity[k] {
+					return a.specificity[k] < b.specificity[k]
+				}
+			}
+		}
+		return a.order < b.order
+	})
+
+	var decls []cssDeclaration
+	for _, r := range matched {
+		decls = append(decls, r.decls...)
+	}
+	return decls
+}
+
+// withInlineStyle returns the start tag with the style sheet declarations
+// merged into its style attribute
+func withInlineStyle(tag string, sheet []cssDeclaration) string {
+	var inline []cssDeclaration
+	loc := styleAttrRegex.FindStringSubmatchIndex(tag)
+	if loc != nil {
+		value := tag[loc[4]:loc[5]]
+		if value[0] == '"' || value[0] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		inline = parseDeclarations(html.UnescapeString(value))
+	}
+
+	// Apply normal declarations, then inline ones, then important ones
+	var merged cssStyle
+	for _, d := range sheet {
+		if !d.important {
+			merged.set(d)
+		}
+	}
+	for _, d := range inline {
+		if !d.important {
+			merged.set(d)
+		}
+	}
+	for _, d := range sheet {
+		if d.important {
+			merged.set(d)
+		}
+	}
+	for _, d := range inline {
+		if d.important {
+			merged.set(d)
+		}
+	}
+
+	attr := `style="` + html.EscapeString(merged.String()) + `"`
+	if loc != nil {
+		return tag[:loc[2]] + " " + attr + tag[loc[5]:]
+	}
+	if strings.HasSuffix(tag, "/>") {
+		return strings.TrimRight(tag[:len(tag)-2], " ") + " " + attr + " />"
+	}
+	return tag[:len(tag)-1] + " " + attr + ">"
+}
+
+// cssStyle is an ordered set of declarations keyed by property
+type cssStyle struct {
+	decls []cssDeclaration
+}
+
+// set adds d, replacing an earlier declaration of the same property
+func (s *cssStyle) set(d cssDeclaration) {
+	for i := range s.decls {
+		if s.decls[i].property == d.property {
+			s.decls[i] = d
+			return
+		}
+	}
+	s.decls = append(s.decls, d)
+}
+
+func (s *cssStyle) String() string {
+	parts := make([]string, len(s.decls))
+	for i, d := range s.decls {
+		parts[i] = d.property + ": " + d.value
+		if d.important {
+			parts[i] += " !important"
+		}
+	}
+	return strings.Join(parts, "; ")
+}
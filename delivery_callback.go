@@ -0,0 +1,242 @@
+package mailnow
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Delivery outcomes a CallbackSpec can subscribe to
+const (
+	CallbackEventDelivered  = "delivered"
+	CallbackEventDeferred   = "deferred"
+	CallbackEventBounced    = "bounced"
+	CallbackEventRejected   = "rejected"
+	CallbackEventComplained = "complained"
+
+	// CallbackEventUnsubscribed is posted when a recipient uses the
+	// one-click List-Unsubscribe link of an email
+	CallbackEventUnsubscribed = "unsubscribed"
+)
+
+// callbackEvents are the events a CallbackSpec can subscribe to
+var callbackEvents = map[string]bool{
+	CallbackEventDelivered:    true,
+	CallbackEventDeferred:     true,
+	CallbackEventBounced:      true,
+	CallbackEventRejected:     true,
+	CallbackEventComplained:   true,
+	CallbackEventUnsubscribed: true,
+}
+
+// MinCallbackSecretLength is the shortest secret a CallbackSpec accepts
+const MinCallbackSecretLength = 16
+
+// MaxDeliveryCallbackSize is the largest delivery callback payload
+// ParseDeliveryCallback reads
+const MaxDeliveryCallbackSize = 1 << 20
+
+// DeliveryCallbackSignatureHeader is the header carrying the signature of
+// a delivery callback: "sha256=" followed by the hex HMAC-SHA256 of the
+// body keyed with the message's callback secret
+const DeliveryCallbackSignatureHeader = "X-Mailnow-Signature"
+
+// callbackUnreachableCode is the API error code for a callback URL that
+// failed the API's reachability check
+const callbackUnreachableCode = "callback_unreachable"
+
+// CallbackSpec registers a URL the API notifies of the delivery outcome of
+// a single email, without a webhook configured for the account. The API
+// checks that the URL is reachable before accepting the send.
+type CallbackSpec struct {
+	// URL is the absolute https URL the outcome is posted to
+	URL string `json:"url"`
+
+	// Secret signs the callbacks for this email, see
+	// DeliveryCallbackSignatureHeader. It must be at least
+	// MinCallbackSecretLength characters and should be unique per email.
+	Secret string `json:"secret"`
+
+	// Events are the outcomes to notify, such as CallbackEventBounced.
+	// When empty every outcome is notified.
+	Events []string `json:"events,omitempty"`
+}
+
+// ValidateCallbackSpec validates a delivery callback: the URL must be an
+// absolute https URL, the secret long enough and the events known and
+// listed once
+func ValidateCallbackSpec(spec *CallbackSpec) error {
+	u, err := url.Parse(spec.URL)
+	if err != nil {
+		return NewValidationError("invalid delivery callback URL", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return NewValidationError("delivery callback URL must be an absolute https URL: "+spec.URL, nil)
+	}
+	if len(spec.Secret) < MinCallbackSecretLength {
+		return NewValidationError(fmt.Sprintf("delivery callback secret must be at least %d characters", MinCallbackSecretLength), nil)
+	}
+
+	seen := make(map[string]bool, len(spec.Events))
+	for _, event := range spec.Events {
+		if !callbackEvents[event] {
+			return NewValidationError(fmt.Sprintf("unknown delivery callback event %q", event), nil)
+		}
+		if seen[event] {
+			return NewValidationError(fmt.Sprintf("delivery callback event %q is listed twice", event), nil)
+		}
+		seen[event] = true
+	}
+	return nil
+}
+
+// CallbackUnreachableError represents a send rejected because the API
+// could not reach its delivery callback URL (HTTP 400 with code
+// "callback_unreachable"). The email was not sent.
+type CallbackUnreachableError struct {
+	error *Error
+
+	// URL is the callback URL of the rejected send
+	URL string
+}
+
+// NewCallbackUnreachableError creates a new CallbackUnreachableError
+func NewCallbackUnreachableError(message, url string, err error) *CallbackUnreachableError {
+	return &CallbackUnreachableError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		URL: url,
+	}
+}
+
+func (e *CallbackUnreachableError) Error() string {
+	return e.error.Error()
+}
+
+func (e *CallbackUnreachableError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *CallbackUnreachableError) base() *Error {
+	return e.error
+}
+
+// DeliveryEvent is the delivery outcome posted to a delivery callback
+type DeliveryEvent struct {
+	MessageID string `json:"message_id"`
+	Recipient string `json:"recipient"`
+
+	// Event is the outcome, such as CallbackEventDelivered
+	Event string `json:"event"`
+
+	// Reason is the receiving server's response for bounces, deferrals and
+	// rejections
+	Reason string `json:"reason,omitempty"`
+
+	// Token is the List-Unsubscribe token of an unsubscribed event, and
+	// ListID the mailing list the recipient left, empty for every list
+	Token  string `json:"token,omitempty"`
+	ListID string `json:"list_id,omitempty"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CallbackSecretFunc returns the callback secret of the email with the
+// given message ID, or "" if the email is unknown
+type CallbackSecretFunc func(ctx context.Context, messageID string) (string, error)
+
+// SignDeliveryCallback returns the DeliveryCallbackSignatureHeader value
+// of a callback body signed with secret
+func SignDeliveryCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseDeliveryCallback parses a delivery callback request and verifies
+// its signature with the secret secrets returns for its message ID, so a
+// callback can only report on the email whose secret signed it.
+//
+// A missing or wrong signature, or an unknown message ID, is an AuthError.
+// Payloads larger than MaxDeliveryCallbackSize or that cannot be decoded
+// are rejected with a ValidationError. Errors from secrets are returned
+// as is.
+func ParseDeliveryCallback(r *http.Request, secrets CallbackSecretFunc) (*DeliveryEvent, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxDeliveryCallbackSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, NewValidationError(fmt.Sprintf("delivery callback payload exceeds %d bytes", int64(MaxDeliveryCallbackSize)), err)
+		}
+		return nil, NewValidationError("failed to read delivery callback payload", err)
+	}
+
+	var event DeliveryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, NewValidationError("invalid delivery callback JSON payload", err)
+	}
+	if event.MessageID == "" {
+		return nil, NewValidationError("delivery callback has no message ID", nil)
+	}
+	if event.Event == CallbackEventUnsubscribed && event.Recipient == "" {
+		return nil, NewValidationError("unsubscribed delivery callback has no recipient", nil)
+	}
+
+	secret, err := secrets(r.Context(), event.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	signature := r.Header.Get(DeliveryCallbackSignatureHeader)
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") ||
+		!hmac.Equal([]byte(signature), []byte(SignDeliveryCallback(secret, body))) {
+		return nil, NewAuthError("invalid delivery callback signature for message "+event.MessageID, nil)
+	}
+	return &event, nil
+}
+
+// DeliveryCallbackHandler returns an http.Handler that verifies delivery
+// callbacks with ParseDeliveryCallback and passes them to handle. It
+// responds 204 when handle succeeds, 401 to callbacks that fail
+// verification, 400 to malformed ones and 500 when secrets or handle
+// fail, so that the API retries the callback.
+func DeliveryCallbackHandler(secrets CallbackSecretFunc, handle func(ctx context.Context, event *DeliveryEvent) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		event, err := ParseDeliveryCallback(r, secrets)
+		var authErr *AuthError
+		var validationErr *ValidationError
+		switch {
+		case errors.As(err, &authErr):
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		case errors.As(err, &validationErr):
+			http.Error(w, validationErr.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := handle(r.Context(), event); err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,109 @@
+package mailnow
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ConnDiagnostics describes the connection used for a single API request.
+//
+// Durations are zero for phases that did not happen, for example when a
+// pooled connection was reused no DNS lookup or connect takes place.
+type ConnDiagnostics struct {
+	// DNSLookup is the time spent resolving the API host
+	DNSLookup time.Duration
+
+	// Connect is the time spent establishing the TCP connection
+	Connect time.Duration
+
+	// TLSHandshake is the time spent on the TLS handshake
+	TLSHandshake time.Duration
+
+	// Reused reports whether a pooled connection was reused
+	Reused bool
+
+	// RemoteAddr is the address of the remote end of the connection
+	RemoteAddr string
+}
+
+// WithConnectionDiagnostics registers a callback that receives connection
+// diagnostics for every API request made by the client.
+//
+// Diagnostics are gathered with net/http/httptrace, which is only installed
+// when this option is set. The callback is invoked synchronously after the
+// response headers are received or the request fails.
+func WithConnectionDiagnostics(fn func(d ConnDiagnostics)) Option {
+	return clientOption(func(c *Client) error {
+		if fn == nil {
+			return NewValidationError("connection diagnostics callback cannot be nil", nil)
+		}
+		c.connDiagnostics = fn
+		return nil
+	})
+}
+
+// connTracer collects connection diagnostics from httptrace callbacks,
+// which may run on other goroutines
+type connTracer struct {
+	mu         sync.Mutex
+	dnsStart   time.Time
+	connStart  time.Time
+	tlsStart   time.Time
+	gotConn    bool
+	diagnostic ConnDiagnostics
+}
+
+// withTrace returns a context that reports connection events to t
+func (t *connTracer) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.diagnostic.DNSLookup = time.Since(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			t.connStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			t.diagnostic.Connect = time.Since(t.connStart)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.diagnostic.TLSHandshake = time.Since(t.tlsStart)
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.gotConn = true
+			t.diagnostic.Reused = info.Reused
+			if info.Conn != nil {
+				t.diagnostic.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+		},
+	})
+}
+
+// result returns the collected diagnostics
+func (t *connTracer) result() ConnDiagnostics {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.diagnostic
+}
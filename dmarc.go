@@ -0,0 +1,171 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// freemailDomains are domains of free webmail providers
+var freemailDomains = map[string]bool{
+	"aol.com":        true,
+	"gmail.com":      true,
+	"gmx.com":        true,
+	"gmx.net":        true,
+	"googlemail.com": true,
+	"hotmail.com":    true,
+	"icloud.com":     true,
+	"live.com":       true,
+	"mail.com":       true,
+	"me.com":         true,
+	"msn.com":        true,
+	"outlook.com":    true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"yahoo.com":      true,
+	"yandex.com":     true,
+	"zoho.com":       true,
+}
+
+// AlignmentIssue is a DMARC alignment problem found by
+// CheckDMARCAlignment
+type AlignmentIssue struct {
+	// Code identifies the kind of problem: "from_domain_not_verified",
+	// "relaxed_alignment" or "freemail_reply_to"
+	Code string
+
+	// Message describes the problem
+	Message string
+
+	// Field is the request field the problem relates to
+	Field string
+
+	// Fails reports whether the problem makes DMARC fail. Relaxed
+	// alignment passes DMARC and is reported as a note; a freemail
+	// Reply-To does not affect DMARC but hurts reputation.
+	Fails bool
+}
+
+// CheckDMARCAlignment checks req for DMARC alignment problems against the
+// domains the account sends from:
+//
+//   - a From domain that is not one of verifiedDomains fails DMARC
+//   - a From domain that is a subdomain of a verified domain, or the
+//     other way round, passes only with relaxed alignment
+//   - a Reply-To at a free webmail provider while From is not one is
+//     typical of phishing and hurts reputation
+//
+// Relaxed alignment compares domains by suffix rather than by their
+// registered domain. With no verifiedDomains only Reply-To is checked.
+func CheckDMARCAlignment(req *EmailRequest, verifiedDomains []string) []AlignmentIssue {
+	if req == nil {
+		return nil
+	}
+
+	var issues []AlignmentIssue
+	fromDomain := addressDomain(req.From)
+	if fromDomain != "" && len(verifiedDomains) > 0 {
+		if issue, ok := fromAlignment(fromDomain, verifiedDomains); ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	if fromDomain != "" && !freemailDomains[fromDomain] {
+		for _, addr := range splitAddressList(req.ReplyTo) {
+			if domain := addressDomain(addr); freemailDomains[domain] {
+				issues = append(issues, AlignmentIssue{
+					Code:    "freemail_reply_to",
+					Message: fmt.Sprintf("reply-to address %s is at a free webmail provider while from domain %s is not", addr, fromDomain),
+					Field:   "ReplyTo",
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// fromAlignment returns the alignment issue of fromDomain against the
+// verified domains, if any
+func fromAlignment(fromDomain string, verifiedDomains []string) (AlignmentIssue, bool) {
+	relaxed := ""
+	for _, domain := range verifiedDomains {
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		switch {
+		case domain == fromDomain:
+			return AlignmentIssue{}, false
+		case relaxed == "" && (strings.HasSuffix(fromDomain, "."+domain) || strings.HasSuffix(domain, "."+fromDomain)):
+			relaxed = domain
+		}
+	}
+
+	if relaxed != "" {
+		return AlignmentIssue{
+			Code:    "relaxed_alignment",
+			Message: fmt.Sprintf("from domain %s aligns with verified domain %s only under relaxed DMARC alignment", fromDomain, relaxed),
+			Field:   "From",
+		}, true
+	}
+	return AlignmentIssue{
+		Code:    "from_domain_not_verified",
+		Message: fmt.Sprintf("from domain %s is not a verified sending domain, so DMARC will fail", fromDomain),
+		Field:   "From",
+		Fails:   true,
+	}, true
+}
+
+// addressDomain returns the lowercase domain of an email address, or "" if
+// it has none
+func addressDomain(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(address[at+1:])
+}
+
+// WithDMARCAlignmentCheck extends the check of WithVerifiedSenderCheck,
+// which must also be set, with CheckDMARCAlignment against the verified
+// domains and the domains of verified addresses. Issues that make DMARC
+// fail reject the send with a ValidationError; the others are reported as
+// warnings with the issue code as the warning code. NewClient and
+// Client.WithOptions fail with a ValidationError when the verified sender
+// check is not set.
+func WithDMARCAlignmentCheck() Option {
+	return clientOption(func(c *Client) error {
+		c.dmarcAlignmentCheck = true
+		return nil
+	})
+}
+
+// checkDMARCAlignment applies WithDMARCAlignmentCheck to req
+func (c *Client) checkDMARCAlignment(ctx context.Context, req *EmailRequest) error {
+	for _, issue := range CheckDMARCAlignment(req, c.verifiedSenders.verifiedDomains()) {
+		if issue.Fails {
+			return NewValidationError(issue.Message, nil)
+		}
+		c.warn(ctx, Warning{Code: issue.Code, Message: issue.Message})
+	}
+	return nil
+}
+
+// verifiedDomains returns the cached verified domains and the domains of
+// the cached verified addresses, sorted
+func (v *verifiedSenders) verifiedDomains() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	seen := make(map[string]bool, len(v.domains)+len(v.addresses))
+	for domain := range v.domains {
+		seen[domain] = true
+	}
+	for addr := range v.addresses {
+		seen[addressDomain(addr)] = true
+	}
+	domains := make([]string, 0, len(seen))
+	for domain := range seen {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
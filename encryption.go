@@ -0,0 +1,183 @@
+package mailnow
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Encryptor encrypts data persisted by the SDK, such as archived messages.
+//
+// Decrypt must accept data that was stored before encryption was enabled
+// and return it unchanged; IsEncrypted tells the two apart.
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// encryptionMagic starts every encrypted envelope and carries its version
+var encryptionMagic = []byte("MNENC1")
+
+// ErrUnknownEncryptionKey is returned when data was encrypted with a key
+// the Encryptor does not have
+var ErrUnknownEncryptionKey = errors.New("unknown encryption key")
+
+// IsEncrypted reports whether data is an envelope written by an Encryptor
+// of this package
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptionMagic)
+}
+
+// AESGCMEncryptor encrypts data with AES-256-GCM under a single key.
+//
+// Each envelope records the ID of the key that encrypted it, so that data
+// encrypted under older keys can be decrypted with a KeyRing after the key
+// is rotated.
+type AESGCMEncryptor struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewAESGCMEncryptor creates an encryptor using the 32-byte key identified
+// by keyID, which is stored in the clear in every envelope and must be 1
+// to 255 bytes long
+func NewAESGCMEncryptor(keyID string, key []byte) (*AESGCMEncryptor, error) {
+	if keyID == "" || len(keyID) > 255 {
+		return nil, NewValidationError("encryption key ID must be 1 to 255 bytes long", nil)
+	}
+	if len(key) != 32 {
+		return nil, NewValidationError(fmt.Sprintf("encryption key must be 32 bytes, got %d", len(key)), nil)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, NewValidationError("invalid encryption key", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, NewValidationError("invalid encryption key", err)
+	}
+	return &AESGCMEncryptor{keyID: keyID, aead: aead}, nil
+}
+
+// KeyID returns the ID of the encryptor's key
+func (e *AESGCMEncryptor) KeyID() string {
+	return e.keyID
+}
+
+// Encrypt returns an envelope holding the key ID, a random nonce and the
+// sealed plaintext. The envelope header is authenticated along with the
+// plaintext.
+func (e *AESGCMEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	header := make([]byte, 0, len(encryptionMagic)+1+len(e.keyID))
+	header = append(header, encryptionMagic...)
+	header = append(header, byte(len(e.keyID)))
+	header = append(header, e.keyID...)
+
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, &Error{Message: "failed to generate nonce", Err: err}
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+e.aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return e.aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Decrypt opens an envelope written by Encrypt. Data that is not an
+// envelope is returned unchanged.
+func (e *AESGCMEncryptor) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	keyID, _, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	if keyID != e.keyID {
+		return nil, &Error{Message: fmt.Sprintf("data was encrypted with key %q", keyID), Err: ErrUnknownEncryptionKey}
+	}
+	return e.open(data)
+}
+
+// open opens an envelope encrypted under e's key
+func (e *AESGCMEncryptor) open(data []byte) ([]byte, error) {
+	_, headerLen, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := e.aead.NonceSize()
+	if len(data) < headerLen+nonceSize {
+		return nil, &Error{Message: "encrypted data is truncated"}
+	}
+
+	header, nonce, ciphertext := data[:headerLen], data[headerLen:headerLen+nonceSize], data[headerLen+nonceSize:]
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, &Error{Message: "failed to decrypt data; it was modified or the key is wrong", Err: err}
+	}
+	return plaintext, nil
+}
+
+// parseEnvelope returns the key ID of an envelope and the length of its
+// header
+func parseEnvelope(data []byte) (keyID string, headerLen int, err error) {
+	n := len(encryptionMagic)
+	if len(data) < n+1 || len(data) < n+1+int(data[n]) {
+		return "", 0, &Error{Message: "encrypted data is truncated"}
+	}
+	headerLen = n + 1 + int(data[n])
+	return string(data[n+1 : headerLen]), headerLen, nil
+}
+
+// KeyRing is an Encryptor for key rotation. It encrypts with its current
+// key and decrypts data encrypted under the current or any previous key.
+type KeyRing struct {
+	current *AESGCMEncryptor
+	keys    map[string]*AESGCMEncryptor
+}
+
+// NewKeyRing creates a key ring encrypting with current and also
+// decrypting with previous
+func NewKeyRing(current *AESGCMEncryptor, previous ...*AESGCMEncryptor) (*KeyRing, error) {
+	if current == nil {
+		return nil, NewValidationError("current encryption key cannot be nil", nil)
+	}
+	keys := map[string]*AESGCMEncryptor{current.keyID: current}
+	for _, e := range previous {
+		if e == nil {
+			return nil, NewValidationError("previous encryption key cannot be nil", nil)
+		}
+		if _, ok := keys[e.keyID]; ok {
+			return nil, NewValidationError("duplicate encryption key ID "+e.keyID, nil)
+		}
+		keys[e.keyID] = e
+	}
+	return &KeyRing{current: current, keys: keys}, nil
+}
+
+// Encrypt encrypts plaintext with the current key
+func (r *KeyRing) Encrypt(plaintext []byte) ([]byte, error) {
+	return r.current.Encrypt(plaintext)
+}
+
+// Decrypt opens an envelope with the key it names. Data that is not an
+// envelope is returned unchanged.
+func (r *KeyRing) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	keyID, _, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := r.keys[keyID]
+	if !ok {
+		return nil, &Error{Message: fmt.Sprintf("data was encrypted with key %q", keyID), Err: ErrUnknownEncryptionKey}
+	}
+	return e.open(data)
+}
@@ -0,0 +1,99 @@
+package mailnow
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Endpoints are the API paths the client sends requests to. Paths of
+// individual resources, such as an email by message ID, are built by
+// appending to them.
+type Endpoints struct {
+	SendEmail        string
+	Email            string
+	BulkValidate     string
+	Assets           string
+	TrackingDomains  string
+	SenderIdentities string
+	Suppressions     string
+	Plan             string
+}
+
+// DefaultEndpoints are the paths of the Mailnow API
+var DefaultEndpoints = Endpoints{
+	SendEmail:        EmailSendEndpoint,
+	Email:            EmailEndpoint,
+	BulkValidate:     BulkValidateEndpoint,
+	Assets:           AssetsEndpoint,
+	TrackingDomains:  TrackingDomainsEndpoint,
+	SenderIdentities: SenderIdentitiesEndpoint,
+	Suppressions:     SuppressionsEndpoint,
+	Plan:             PlanEndpoint,
+}
+
+// WithEndpoints overrides API paths, for gateways that lay out the API
+// differently. Empty fields keep the default path. Paths must start with a
+// slash and cannot include a scheme, host, query or fragment; use
+// WithBaseURL to change the host.
+func WithEndpoints(endpoints Endpoints) Option {
+	return clientOption(func(c *Client) error {
+		if err := endpoints.validate(); err != nil {
+			return err
+		}
+		c.endpoints = endpoints.withDefaults()
+		return nil
+	})
+}
+
+// endpointPath is a path of Endpoints with its field name
+type endpointPath struct {
+	name string
+	path *string
+}
+
+// paths returns the paths of e
+func (e *Endpoints) paths() []endpointPath {
+	return []endpointPath{
+		{"SendEmail", &e.SendEmail},
+		{"Email", &e.Email},
+		{"BulkValidate", &e.BulkValidate},
+		{"Assets", &e.Assets},
+		{"TrackingDomains", &e.TrackingDomains},
+		{"SenderIdentities", &e.SenderIdentities},
+		{"Suppressions", &e.Suppressions},
+		{"Plan", &e.Plan},
+	}
+}
+
+// withDefaults returns e with empty fields set to the default paths
+func (e Endpoints) withDefaults() Endpoints {
+	defaults := DefaultEndpoints
+	defaultPaths := defaults.paths()
+	for i, p := range e.paths() {
+		if *p.path == "" {
+			*p.path = *defaultPaths[i].path
+		}
+	}
+	return e
+}
+
+// validate checks that every path set in e is a plain absolute path
+func (e Endpoints) validate() error {
+	for _, p := range e.paths() {
+		path := *p.path
+		if path == "" {
+			continue
+		}
+		if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+			return NewValidationError(p.name+" endpoint must be an absolute path starting with a single slash: "+path, nil)
+		}
+		u, err := url.Parse(path)
+		if err != nil {
+			return NewValidationError("invalid "+p.name+" endpoint", err)
+		}
+		if u.Scheme != "" || u.Host != "" || u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(path, "?") {
+			return NewValidationError(p.name+" endpoint cannot include a scheme, host, query or fragment: "+path, nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package mailnow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Envelope represents the wrapper the API puts around every successful
+// response body: {"success", "message", "status_code", "data"}.
+type Envelope struct {
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	StatusCode int             `json:"status_code"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// DecodeEnvelope decodes a response envelope and its data into T.
+//
+// It returns a ServerError when the body is not a valid envelope, when
+// Success is false (using the envelope message as the error text), or when
+// the envelope has no data. T may be any JSON-decodable type, including a
+// slice for endpoints that return lists.
+func DecodeEnvelope[T any](body []byte) (*T, *Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, nil, NewServerError("failed to parse response", err)
+	}
+
+	if !env.Success {
+		message := env.Message
+		if message == "" {
+			message = "API reported an unsuccessful request"
+		}
+		return nil, &env, NewServerError(message, nil)
+	}
+
+	if len(env.Data) == 0 || bytes.Equal(env.Data, []byte("null")) {
+		return nil, &env, NewServerError("response is missing data", nil)
+	}
+
+	var data T
+	if err := json.Unmarshal(env.Data, &data); err != nil {
+		return nil, &env, NewServerError(fmt.Sprintf("failed to parse response data as %T", data), err)
+	}
+
+	return &data, &env, nil
+}
+
+// WithStrictEnvelopes makes the methods returning an EmailResponse, such as
+// SendEmail, decode successful responses with the checks of DecodeEnvelope:
+// a response with success set to false or without data fails with a
+// ServerError. By default such responses are accepted as they are, and a
+// response without data is read in the flat shape
+// {"success", "message_id", "status"} that some gateways and mocks return.
+func WithStrictEnvelopes() Option {
+	return clientOption(func(c *Client) error {
+		c.strictEnvelopes = true
+		return nil
+	})
+}
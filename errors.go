@@ -1,11 +1,27 @@
 package mailnow
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Error represents the base error type for all Mailnow SDK errors
 type Error struct {
 	Message string
 	Err     error
+
+	// StatusCode is the HTTP status code of the API response, if any
+	StatusCode int
+
+	// Code is the error code reported by the API, if any
+	Code string
+
+	// Details holds the details reported by the API, if any
+	Details map[string]interface{}
 }
 
 func (e *Error) Error() string {
@@ -19,6 +35,38 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+// apiError is implemented by all SDK error types to expose their base Error
+type apiError interface {
+	base() *Error
+}
+
+// baseOf returns the base Error of the first SDK error in err's chain
+func baseOf(err error) *Error {
+	var e apiError
+	if errors.As(err, &e) {
+		return e.base()
+	}
+	return nil
+}
+
+// ErrorCode returns the error code reported by the API for err, such as
+// "subaccount_not_found", or "" if err did not come from an API response.
+func ErrorCode(err error) string {
+	if e := baseOf(err); e != nil {
+		return e.Code
+	}
+	return ""
+}
+
+// ErrorStatusCode returns the HTTP status code of the API response that
+// caused err, or 0 if err did not come from an API response.
+func ErrorStatusCode(err error) int {
+	if e := baseOf(err); e != nil {
+		return e.StatusCode
+	}
+	return 0
+}
+
 // ValidationError represents input validation failures
 type ValidationError struct {
 	error *Error
@@ -35,13 +83,85 @@ func NewValidationError(message string, err error) *ValidationError {
 }
 
 func (e *ValidationError) Error() string {
-	return e.error.Error()
+	fields := e.FieldErrors()
+	if len(fields) == 0 {
+		return e.error.Error()
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = name + ": " + fields[name]
+	}
+	return fmt.Sprintf("%s (%s)", e.error.Error(), strings.Join(names, ", "))
+}
+
+// FieldErrors returns the field-level errors reported by the API, keyed by
+// field name, or nil if there are none. Both a "fields" object mapping
+// field names to messages and a "fields" or "errors" list of objects with
+// "field" and "message" members are understood; other shapes are ignored.
+func (e *ValidationError) FieldErrors() map[string]string {
+	details := e.error.Details
+	if details == nil {
+		return nil
+	}
+
+	var fields map[string]string
+	add := func(name string, message interface{}) {
+		if name == "" {
+			return
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[name] = detailMessage(message)
+	}
+
+	for _, key := range []string{"fields", "errors"} {
+		switch v := details[key].(type) {
+		case map[string]interface{}:
+			for name, message := range v {
+				add(name, message)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if entry, ok := item.(map[string]interface{}); ok {
+					name, _ := entry["field"].(string)
+					add(name, entry["message"])
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// detailMessage returns the message of a field error detail, which is
+// either a string or an object with a "message" member
+func detailMessage(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if message, ok := v["message"].(string); ok {
+			return message
+		}
+	case nil:
+		return "invalid"
+	}
+	return fmt.Sprint(v)
 }
 
 func (e *ValidationError) Unwrap() error {
 	return e.error.Unwrap()
 }
 
+func (e *ValidationError) base() *Error {
+	return e.error
+}
+
 // AuthError represents authentication failures
 type AuthError struct {
 	error *Error
@@ -65,6 +185,10 @@ func (e *AuthError) Unwrap() error {
 	return e.error.Unwrap()
 }
 
+func (e *AuthError) base() *Error {
+	return e.error
+}
+
 // RateLimitError represents rate limit exceeded errors
 type RateLimitError struct {
 	error *Error
@@ -88,6 +212,10 @@ func (e *RateLimitError) Unwrap() error {
 	return e.error.Unwrap()
 }
 
+func (e *RateLimitError) base() *Error {
+	return e.error
+}
+
 // ServerError represents server errors (5xx)
 type ServerError struct {
 	error *Error
@@ -111,9 +239,41 @@ func (e *ServerError) Unwrap() error {
 	return e.error.Unwrap()
 }
 
+func (e *ServerError) base() *Error {
+	return e.error
+}
+
+// TooEarly reports whether the API rejected the request as sent too early
+// (HTTP 425), which is retried after a short fixed delay
+func (e *ServerError) TooEarly() bool {
+	return e.error.StatusCode == http.StatusTooEarly
+}
+
 // ConnectionError represents network connection failures
+//
+// When the failure happened while sending a request, the request metadata
+// is recorded on the error to help with timeout tuning. The API key is
+// never included.
 type ConnectionError struct {
 	error *Error
+
+	// Host is the target host of the failed request
+	Host string
+
+	// Attempt is the attempt number of the failed request, starting at 1
+	Attempt int
+
+	// Elapsed is how long the request ran before failing
+	Elapsed time.Duration
+
+	// Deadline is the effective deadline of the request, taking both the
+	// context deadline and the HTTP client timeout into account. It is the
+	// zero time when the request had no deadline.
+	Deadline time.Time
+
+	// RequestWritten reports whether the request was fully written before
+	// the failure, in which case the API may have processed it
+	RequestWritten bool
 }
 
 // NewConnectionError creates a new ConnectionError
@@ -133,3 +293,170 @@ func (e *ConnectionError) Error() string {
 func (e *ConnectionError) Unwrap() error {
 	return e.error.Unwrap()
 }
+
+func (e *ConnectionError) base() *Error {
+	return e.error
+}
+
+// DuplicateSendError represents a rejected send because the recipient
+// already received an email in the same campaign (HTTP 409).
+//
+// Callers that send campaign emails at least once can usually treat this
+// error as a success.
+type DuplicateSendError struct {
+	error *Error
+
+	// CampaignID is the campaign the email was sent in
+	CampaignID string
+
+	// Recipient is the recipient that already received the email
+	Recipient string
+}
+
+// NewDuplicateSendError creates a new DuplicateSendError
+func NewDuplicateSendError(message, campaignID, recipient string, err error) *DuplicateSendError {
+	return &DuplicateSendError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		CampaignID: campaignID,
+		Recipient:  recipient,
+	}
+}
+
+func (e *DuplicateSendError) Error() string {
+	return e.error.Error()
+}
+
+func (e *DuplicateSendError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *DuplicateSendError) base() *Error {
+	return e.error
+}
+
+// AlreadySentError represents a rejected change to a scheduled email that
+// has already been sent (HTTP 409)
+type AlreadySentError struct {
+	error *Error
+
+	// MessageID is the ID of the email that was already sent
+	MessageID string
+}
+
+// NewAlreadySentError creates a new AlreadySentError
+func NewAlreadySentError(message, messageID string, err error) *AlreadySentError {
+	return &AlreadySentError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		MessageID: messageID,
+	}
+}
+
+func (e *AlreadySentError) Error() string {
+	return e.error.Error()
+}
+
+func (e *AlreadySentError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *AlreadySentError) base() *Error {
+	return e.error
+}
+
+// MaintenanceError represents a request rejected because the API is in a
+// maintenance window (HTTP 503 with code "maintenance")
+type MaintenanceError struct {
+	error *Error
+
+	// RetryAfter is how long the API asked clients to wait before retrying,
+	// or zero if it did not say
+	RetryAfter time.Duration
+}
+
+// NewMaintenanceError creates a new MaintenanceError
+func NewMaintenanceError(message string, retryAfter time.Duration, err error) *MaintenanceError {
+	return &MaintenanceError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *MaintenanceError) Error() string {
+	return e.error.Error()
+}
+
+func (e *MaintenanceError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *MaintenanceError) base() *Error {
+	return e.error
+}
+
+// PlanFeatureError represents a request rejected because the account's
+// plan does not include the feature it uses (HTTP 402 or 403 with code
+// "feature_not_available"). It is not retryable.
+type PlanFeatureError struct {
+	error *Error
+
+	// Feature is the feature the plan does not include, if the API said
+	Feature string
+
+	// Plan is the name of the account's plan, if the API said
+	Plan string
+}
+
+// NewPlanFeatureError creates a new PlanFeatureError
+func NewPlanFeatureError(message, feature, plan string, err error) *PlanFeatureError {
+	return &PlanFeatureError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Feature: feature,
+		Plan:    plan,
+	}
+}
+
+func (e *PlanFeatureError) Error() string {
+	return e.error.Error()
+}
+
+func (e *PlanFeatureError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *PlanFeatureError) base() *Error {
+	return e.error
+}
+
+// newRequestConnectionError creates a ConnectionError for a failed request,
+// formatting the request metadata into the message
+func newRequestConnectionError(message string, err error, host string, attempt int, start, deadline time.Time) *ConnectionError {
+	elapsed := time.Since(start)
+
+	budget := "no deadline"
+	if !deadline.IsZero() {
+		budget = fmt.Sprintf("deadline %s", deadline.Sub(start).Round(time.Millisecond))
+	}
+
+	return &ConnectionError{
+		error: &Error{
+			Message: fmt.Sprintf("%s to %s (attempt %d, elapsed %s, %s)", message, host, attempt, elapsed.Round(time.Millisecond), budget),
+			Err:     err,
+		},
+		Host:     host,
+		Attempt:  attempt,
+		Elapsed:  elapsed,
+		Deadline: deadline,
+	}
+}
@@ -0,0 +1,171 @@
+package mailnow_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func ExampleNewClient() {
+	// API keys are validated before any request is made
+	_, err := mailnow.NewClient("not-a-key")
+	var validationErr *mailnow.ValidationError
+	fmt.Println("invalid key rejected:", errors.As(err, &validationErr))
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithRetry(mailnow.DefaultRetryPolicy),
+		mailnow.WithCacheControl("no-store"))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println("client ready:", client != nil)
+	// Output:
+	// invalid key rejected: true
+	// client ready: true
+}
+
+func ExampleClient_SendEmail() {
+	// A fake API stands in for Mailnow; use mailnow.NewClient(apiKey) to
+	// send real email
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	resp, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "billing@example.com",
+		To:      "customer@example.com",
+		Subject: "Your invoice",
+		HTML:    "<p>Thanks for your order.</p>",
+	}, mailnow.WithCategory("transactional"))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println("status:", resp.Data.Status)
+	// Output:
+	// status: queued
+}
+
+func ExampleClient_SendEmail_errorHandling() {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.SentTo("locked@example.com"), mailnowtest.Fail(http.StatusUnauthorized, "unauthorized", "invalid API key"))
+	server.AddRule(mailnowtest.SentTo("busy@example.com"), mailnowtest.Fail(http.StatusTooManyRequests, "rate_limited", "slow down"))
+	server.AddRule(mailnowtest.SentTo("broken@example.com"), mailnowtest.Fail(http.StatusInternalServerError, "internal", "something went wrong"))
+	server.AddRule(mailnowtest.SentTo("repeat@example.com"), mailnowtest.Fail(http.StatusConflict, "duplicate_send", "already sent"))
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	for _, to := range []string{"not an address", "locked@example.com", "busy@example.com", "broken@example.com", "repeat@example.com"} {
+		_, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+			From:       "news@example.com",
+			To:         to,
+			Subject:    "Spring sale",
+			HTML:       "<p>Everything is 20% off.</p>",
+			CampaignID: "spring-sale",
+		})
+
+		var (
+			validationErr *mailnow.ValidationError
+			authErr       *mailnow.AuthError
+			rateLimitErr  *mailnow.RateLimitError
+			duplicateErr  *mailnow.DuplicateSendError
+			serverErr     *mailnow.ServerError
+		)
+		switch {
+		case err == nil:
+			fmt.Println(to, "=> sent")
+		case errors.As(err, &validationErr):
+			fmt.Println(to, "=> fix the request")
+		case errors.As(err, &authErr):
+			fmt.Println(to, "=> check the API key")
+		case errors.As(err, &rateLimitErr):
+			fmt.Println(to, "=> retry later")
+		case errors.As(err, &duplicateErr):
+			fmt.Println(to, "=> already sent in campaign", duplicateErr.CampaignID)
+		case errors.As(err, &serverErr):
+			fmt.Println(to, "=> API failure, retryable:", mailnow.IsRetryable(err))
+		}
+	}
+	// Output:
+	// not an address => fix the request
+	// locked@example.com => check the API key
+	// busy@example.com => retry later
+	// broken@example.com => API failure, retryable: true
+	// repeat@example.com => already sent in campaign spring-sale
+}
+
+func ExampleWithRetry() {
+	// An API that fails twice with 503 before accepting the send
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "unavailable", "message": "try again"}}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	resp, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "alerts@example.com",
+		To:      "oncall@example.com",
+		Subject: "Disk almost full",
+		HTML:    "<p>/var is 95% full.</p>",
+	})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(resp.Data.MessageID, "after", attempts, "attempts")
+	// Output:
+	// msg_1 after 3 attempts
+}
+
+func ExampleIsRetryable() {
+	fmt.Println(mailnow.IsRetryable(mailnow.NewRateLimitError("too many requests", nil)))
+	fmt.Println(mailnow.IsRetryable(mailnow.NewValidationError("invalid recipient", nil)))
+	fmt.Println(mailnow.IsRetryable(context.Canceled))
+	// Output:
+	// true
+	// false
+	// false
+}
+
+func ExampleValidateEmailRequest() {
+	err := mailnow.ValidateEmailRequest(&mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example",
+		Subject: "Hello",
+		HTML:    "<p>Hello</p>",
+	})
+	fmt.Println(err)
+	// Output:
+	// invalid to address: invalid email address format: recipient@example
+}
@@ -0,0 +1,74 @@
+package mailnow
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// senderNotVerifiedCode is the API error code for a send from an address
+// that is not a verified sender
+const senderNotVerifiedCode = "sender_not_verified"
+
+// WithFromFallbacks sets fallback From addresses for sends rejected because
+// the sender is not verified (HTTP 403 with code "sender_not_verified"),
+// which can happen while a sending domain's DNS records propagate.
+//
+// Fallbacks are tried in order until one is accepted; other errors never
+// trigger a fallback. If every fallback is rejected, SendEmail returns an
+// AuthError listing the attempted senders and wrapping the original error.
+//
+// A send made with WithIdempotencyKey is resent from a fallback with the
+// key followed by ":" and the fallback address, as the API would otherwise
+// answer the resend with the rejected send. The archive and history record
+// the fallback sender used.
+func WithFromFallbacks(addrs ...string) Option {
+	return clientOption(func(c *Client) error {
+		if len(addrs) == 0 {
+			return NewValidationError("at least one fallback from address is required", nil)
+		}
+		for _, addr := range addrs {
+			if err := ValidateEmailAddress(addr); err != nil {
+				return NewValidationError("invalid fallback from address", err)
+			}
+		}
+		c.fromFallbacks = append([]string(nil), addrs...)
+		return nil
+	})
+}
+
+// sendFromFallbacks resends req from each fallback sender in turn after it
+// failed with err because its sender is not verified. It returns the
+// fallback sender used on success.
+func (c *Client) sendFromFallbacks(ctx context.Context, req *EmailRequest, subaccount string, err error) (*EmailResponse, int, string, error) {
+	attempted := []string{req.From}
+	for _, from := range c.fromFallbacks {
+		if from == req.From {
+			continue
+		}
+		attempted = append(attempted, from)
+
+		fallbackReq := *req
+		fallbackReq.From = from
+		fallbackCtx := ctx
+		if key := idempotencyKeyFrom(ctx); key != "" {
+			fallbackCtx = withIdempotencyKey(ctx, key+":"+from)
+		}
+		resp, statusCode, fallbackErr := c.sendEmailRequest(fallbackCtx, &fallbackReq, subaccount)
+		if fallbackErr == nil {
+			return resp, statusCode, from, nil
+		}
+		if !isSenderNotVerified(fallbackErr) {
+			return nil, statusCode, "", fallbackErr
+		}
+	}
+
+	exhausted := NewAuthError("no verified sender among "+strings.Join(attempted, ", "), err)
+	return nil, http.StatusForbidden, "", withAPIDetails(exhausted, http.StatusForbidden, senderNotVerifiedCode)
+}
+
+// isSenderNotVerified reports whether err rejected a send because the
+// sender is not verified
+func isSenderNotVerified(err error) bool {
+	return ErrorStatusCode(err) == http.StatusForbidden && ErrorCode(err) == senderNotVerifiedCode
+}
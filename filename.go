@@ -0,0 +1,131 @@
+package mailnow
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxFilenameLength is the maximum length in bytes of an attachment
+// filename, the common file system limit
+const MaxFilenameLength = 255
+
+// reservedFilenameChars are characters rejected in attachment filenames
+// because file systems or mail clients treat them specially
+const reservedFilenameChars = `<>:"|?*`
+
+// SanitizeFilename returns the attachment filename name will be sent with:
+// directory components are removed, so "../../etc/passwd" becomes
+// "passwd", and surrounding spaces are trimmed. It fails with a
+// ValidationError when nothing is left, or the name contains control or
+// reserved characters or exceeds MaxFilenameLength.
+func SanitizeFilename(name string) (string, error) {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "", NewValidationError("filename cannot be empty", nil)
+	}
+
+	for _, r := range name {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return "", NewValidationError(fmt.Sprintf("filename %q contains a control or invalid character", name), nil)
+		}
+		if strings.ContainsRune(reservedFilenameChars, r) {
+			return "", NewValidationError(fmt.Sprintf("filename %q contains the reserved character %q", name, r), nil)
+		}
+	}
+	if len(name) > MaxFilenameLength {
+		return "", NewValidationError(fmt.Sprintf("filename is %d bytes, exceeding the limit of %d", len(name), MaxFilenameLength), nil)
+	}
+	return name, nil
+}
+
+// SanitizedFilename returns the filename the attachment will be sent with,
+// see SanitizeFilename
+func (a Attachment) SanitizedFilename() (string, error) {
+	return SanitizeFilename(a.Filename)
+}
+
+// EncodeFilenameRFC2231 encodes a filename containing non-ASCII characters
+// as an RFC 2231 extended parameter value for use in filename*=, for
+// example "März.pdf" becomes:
+//
+//	UTF-8''M%C3%A4rz.pdf
+//
+// Pure-ASCII filenames are returned unchanged.
+func EncodeFilenameRFC2231(name string) string {
+	if isASCII(name) {
+		return name
+	}
+
+	var b strings.Builder
+	b.WriteString("UTF-8''")
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if isAttrChar(ch) {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+// isAttrChar reports whether ch may appear unencoded in an RFC 2231
+// extended value
+func isAttrChar(ch byte) bool {
+	switch {
+	case 'a' <= ch && ch <= 'z', 'A' <= ch && ch <= 'Z', '0' <= ch && ch <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", ch) >= 0
+}
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// WithEncodedFilenames makes the client send non-ASCII attachment
+// filenames RFC 2231 encoded, see EncodeFilenameRFC2231. The request passed
+// to SendEmail keeps its UTF-8 filenames; only the copy sent to the API is
+// encoded.
+func WithEncodedFilenames() Option {
+	return clientOption(func(c *Client) error {
+		c.encodeFilenames = true
+		return nil
+	})
+}
+
+// wireAttachments returns a copy of attachments with sanitized filenames,
+// encoded if encode is set, or nil when no filename changes
+func wireAttachments(attachments []Attachment, encode bool) ([]Attachment, error) {
+	var wire []Attachment
+	for i, a := range attachments {
+		if a.Filename == "" {
+			continue
+		}
+		name, err := SanitizeFilename(a.Filename)
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("invalid attachment at index %d", i), err)
+		}
+		if encode {
+			name = EncodeFilenameRFC2231(name)
+		}
+		if name == a.Filename {
+			continue
+		}
+		if wire == nil {
+			wire = append([]Attachment(nil), attachments...)
+		}
+		wire[i].Filename = name
+	}
+	return wire, nil
+}
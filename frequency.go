@@ -0,0 +1,222 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FrequencyStore counts the sends to each recipient. Keys name a
+// recipient and a window, so a count never has to be reset; a store may
+// forget a key once window has passed since its first send, as a Redis
+// INCR followed by EXPIRE does. Implementations must be safe for
+// concurrent use, and a store shared by several clients or processes
+// enforces one cap across all of them.
+type FrequencyStore interface {
+	// Incr counts a send for key and returns the new count
+	Incr(ctx context.Context, key string, window time.Duration) (int, error)
+
+	// Decr removes a send counted for key, for a send that was refused or
+	// failed
+	Decr(ctx context.Context, key string) error
+}
+
+// MemoryFrequencyStore is a FrequencyStore that keeps counts in memory.
+// Expired keys are forgotten when they are next used, and all of them once
+// the number of keys has doubled since they were last swept.
+type MemoryFrequencyStore struct {
+	mu        sync.Mutex
+	counts    map[string]frequencyCount
+	nextSweep int
+}
+
+// frequencyCount is the count of a key and when it can be forgotten
+type frequencyCount struct {
+	count   int
+	expires time.Time
+}
+
+// minFrequencySweep is the number of keys a MemoryFrequencyStore holds
+// before it first sweeps expired keys
+const minFrequencySweep = 1024
+
+// NewMemoryFrequencyStore creates an empty in-memory frequency store
+func NewMemoryFrequencyStore() *MemoryFrequencyStore {
+	return &MemoryFrequencyStore{counts: make(map[string]frequencyCount), nextSweep: minFrequencySweep}
+}
+
+// Count returns the number of sends counted for key
+func (s *MemoryFrequencyStore) Count(ctx context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, _ := s.current(key, time.Now())
+	return c.count, nil
+}
+
+// Incr implements FrequencyStore
+func (s *MemoryFrequencyStore) Incr(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	c, ok := s.current(key, now)
+	if !ok {
+		c.expires = now.Add(window)
+	}
+	c.count++
+	s.counts[key] = c
+
+	if len(s.counts) >= s.nextSweep {
+		for k, c := range s.counts {
+			if now.After(c.expires) {
+				delete(s.counts, k)
+			}
+		}
+		s.nextSweep = max(2*len(s.counts), minFrequencySweep)
+	}
+	return c.count, nil
+}
+
+// Decr implements FrequencyStore
+func (s *MemoryFrequencyStore) Decr(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.current(key, time.Now()); ok && c.count > 0 {
+		c.count--
+		s.counts[key] = c
+	}
+	return nil
+}
+
+// current returns the count of key, forgetting it if it expired
+func (s *MemoryFrequencyStore) current(key string, now time.Time) (frequencyCount, bool) {
+	c, ok := s.counts[key]
+	if ok && now.After(c.expires) {
+		delete(s.counts, key)
+		return frequencyCount{}, false
+	}
+	return c, ok
+}
+
+// FrequencyCapError represents a send refused locally because its
+// recipient already received the maximum number of emails in the current
+// window
+type FrequencyCapError struct {
+	error *Error
+
+	// Recipient is the capped address as given in the request
+	Recipient string
+
+	// Count is the number of sends to the recipient in the window
+	Count int
+
+	// Limit is the maximum number of sends per recipient and window
+	Limit int
+
+	// ResetAt is when the window ends and the recipient can be sent to again
+	ResetAt time.Time
+}
+
+// NewFrequencyCapError creates a new FrequencyCapError
+func NewFrequencyCapError(message, recipient string, count, limit int, resetAt time.Time, err error) *FrequencyCapError {
+	return &FrequencyCapError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Recipient: recipient,
+		Count:     count,
+		Limit:     limit,
+		ResetAt:   resetAt,
+	}
+}
+
+func (e *FrequencyCapError) Error() string {
+	return e.error.Error()
+}
+
+func (e *FrequencyCapError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *FrequencyCapError) base() *Error {
+	return e.error
+}
+
+// WithFrequencyCap limits each recipient to maxPerRecipient successful
+// sends per window. Windows are consecutive periods of the given length
+// aligned to the Unix epoch in UTC, as for WithSendBudget, and recipients
+// are counted by their canonical address under DefaultProviderRules, so
+// "Jane.Doe+news@gmail.com" and "janedoe@gmail.com" share a count. Failed
+// sends are not counted.
+//
+// A send to a capped recipient fails with a FrequencyCapError without
+// contacting the API. A send is counted before it is made and released if
+// it definitely failed, so concurrent sends to the same recipient cannot
+// exceed the cap. A send whose outcome is unknown, such as after a
+// connection error once the request was written or a successful response
+// that fails response validation, stays counted. A nil store keeps counts
+// in memory.
+func WithFrequencyCap(maxPerRecipient int, window time.Duration, store FrequencyStore) Option {
+	return clientOption(func(c *Client) error {
+		if maxPerRecipient < 1 {
+			return NewValidationError("frequency cap must be at least 1", nil)
+		}
+		if window <= 0 {
+			return NewValidationError("frequency cap window must be positive", nil)
+		}
+		if store == nil {
+			store = NewMemoryFrequencyStore()
+		}
+		c.frequencyCap = &frequencyCap{limit: maxPerRecipient, window: window, store: store}
+		return nil
+	})
+}
+
+// frequencyCap is the per-recipient cap of a client
+type frequencyCap struct {
+	limit  int
+	window time.Duration
+	store  FrequencyStore
+}
+
+// reserveFrequencyCap counts a send to recipient in the current window,
+// failing if recipient already reached the cap, and returns the key to
+// release the send under if it fails
+func (c *Client) reserveFrequencyCap(ctx context.Context, recipient string) (string, error) {
+	fc := c.frequencyCap
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return "", err
+	}
+	window := periodStart(now, fc.window)
+	resetAt := window.Add(fc.window)
+
+	canonical, err := CanonicalizeEmail(recipient, nil)
+	if err != nil {
+		canonical = strings.ToLower(recipient)
+	}
+	key := canonical + "|" + strconv.FormatInt(window.Unix(), 10)
+
+	count, err := fc.store.Incr(ctx, key, fc.window)
+	if err != nil {
+		return "", &Error{Message: "failed to count send against the frequency cap", Err: err}
+	}
+	if count > fc.limit {
+		c.releaseFrequencyCap(ctx, key, recipient)
+		count--
+		return "", NewFrequencyCapError(fmt.Sprintf("%s already received %d of %d emails allowed per %s, resets at %s", recipient, count, fc.limit, fc.window, resetAt.UTC().Format(time.RFC3339)), recipient, count, fc.limit, resetAt, nil)
+	}
+	return key, nil
+}
+
+// releaseFrequencyCap removes a send counted by reserveFrequencyCap
+func (c *Client) releaseFrequencyCap(ctx context.Context, key, recipient string) {
+	if err := c.frequencyCap.store.Decr(context.WithoutCancel(ctx), key); err != nil {
+		c.warn(ctx, Warning{Code: "frequency_cap_release_failed", Message: fmt.Sprintf("failed to release send to %s from its frequency cap: %v", recipient, err)})
+	}
+}
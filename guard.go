@@ -0,0 +1,116 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeyEnvironment is the environment an API key belongs to, derived from
+// its prefix
+type KeyEnvironment string
+
+const (
+	// EnvironmentLive is the environment of "mn_live_" keys
+	EnvironmentLive KeyEnvironment = "live"
+
+	// EnvironmentTest is the environment of "mn_test_" keys
+	EnvironmentTest KeyEnvironment = "test"
+)
+
+// APIKeyEnvironment returns the environment of apiKey, or "" if it has
+// neither the live nor the test prefix
+func APIKeyEnvironment(apiKey string) KeyEnvironment {
+	switch {
+	case IsLiveAPIKey(apiKey):
+		return EnvironmentLive
+	case IsTestAPIKey(apiKey):
+		return EnvironmentTest
+	default:
+		return ""
+	}
+}
+
+// WithEnvironmentGuard restricts the From domains that can be used with
+// each API key environment, so that a test key cannot send production
+// looking email and the other way round. Sends whose From domain is not in
+// the allowlist for the client's key environment fail with a
+// ValidationError.
+//
+// Patterns are domains such as "example.com", matched exactly, or
+// wildcards such as "*.staging.example.com", which match any subdomain.
+// When allowed only covers one environment, keys of the other environment
+// are not checked and NewClient reports a warning.
+func WithEnvironmentGuard(allowed map[KeyEnvironment][]string) Option {
+	return clientOption(func(c *Client) error {
+		if len(allowed) == 0 {
+			return NewValidationError("environment guard needs at least one environment", nil)
+		}
+
+		guard := make(map[KeyEnvironment][]string, len(allowed))
+		for env, patterns := range allowed {
+			if env != EnvironmentLive && env != EnvironmentTest {
+				return NewValidationError(fmt.Sprintf("unknown key environment %q", env), nil)
+			}
+			if len(patterns) == 0 {
+				return NewValidationError(fmt.Sprintf("environment guard for %s keys has no domains", env), nil)
+			}
+			for _, pattern := range patterns {
+				pattern = strings.ToLower(pattern)
+				if err := ValidateDomain(strings.TrimPrefix(pattern, "*.")); err != nil {
+					return NewValidationError("invalid environment guard domain", err)
+				}
+				guard[env] = append(guard[env], pattern)
+			}
+		}
+		c.envGuard = guard
+		return nil
+	})
+}
+
+// warnPartialEnvironmentGuard reports a warning when the environment guard
+// leaves one environment unchecked
+func (c *Client) warnPartialEnvironmentGuard() {
+	if c.envGuard == nil {
+		return
+	}
+	for _, env := range []KeyEnvironment{EnvironmentLive, EnvironmentTest} {
+		if _, ok := c.envGuard[env]; !ok {
+			c.warn(context.Background(), Warning{
+				Code:    "environment_guard_partial",
+				Message: fmt.Sprintf("environment guard does not cover %s keys; their sends are not checked", env),
+			})
+		}
+	}
+}
+
+// checkEnvironmentGuard checks that the From domain of req is allowed for
+// the client's key environment
+func (c *Client) checkEnvironmentGuard(req *EmailRequest) error {
+	env := APIKeyEnvironment(c.apiKey)
+	patterns, ok := c.envGuard[env]
+	if !ok {
+		return nil
+	}
+
+	domain := strings.ToLower(req.From[strings.LastIndex(req.From, "@")+1:])
+	for _, pattern := range patterns {
+		if matchDomainPattern(pattern, domain) {
+			return nil
+		}
+	}
+
+	allowed := append([]string(nil), patterns...)
+	sort.Strings(allowed)
+	return NewValidationError(fmt.Sprintf("from domain %s is not allowed with a %s API key (allowed: %s)", domain, env, strings.Join(allowed, ", ")), nil)
+}
+
+// matchDomainPattern reports whether domain matches pattern, where a
+// leading "*." matches any subdomain
+func matchDomainPattern(pattern, domain string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(domain, suffix) && len(domain) > len(suffix)
+	}
+	return domain == pattern
+}
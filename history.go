@@ -0,0 +1,265 @@
+package mailnow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryStatusFailed is the status of a history entry for a failed send
+const HistoryStatusFailed = "failed"
+
+// HistoryEntry is the record of a send kept by WithHistory
+type HistoryEntry struct {
+	// MessageID is the API message ID, or "" if the send failed
+	MessageID string
+
+	// Recipient is the recipient address hashed with HashRecipient, or the
+	// address itself when WithHistoryPlaintextRecipients is set
+	Recipient string
+
+	Subject    string
+	Category   string
+	CampaignID string
+
+	// FallbackFrom is the fallback sender the email was sent from, or ""
+	// if no fallback was used; see WithFromFallbacks
+	FallbackFrom string
+
+	// Status is the status reported by the API, such as "queued", or
+	// HistoryStatusFailed
+	Status string
+
+	// Error is the error of a failed send
+	Error string
+
+	// SentAt is when the send finished, according to the client clock
+	SentAt time.Time
+}
+
+// HistoryFilter selects history entries. Empty fields match every entry.
+type HistoryFilter struct {
+	// Recipient is a recipient address. Client.History converts it to the
+	// stored form, so stores compare it with HistoryEntry.Recipient as is.
+	Recipient string
+
+	Category   string
+	CampaignID string
+	Status     string
+
+	// Since and Until bound SentAt, inclusively
+	Since time.Time
+	Until time.Time
+
+	// Limit caps the number of entries returned, newest first, when positive
+	Limit int
+}
+
+// Matches reports whether entry is selected by f
+func (f HistoryFilter) Matches(entry HistoryEntry) bool {
+	switch {
+	case f.Recipient != "" && entry.Recipient != f.Recipient,
+		f.Category != "" && entry.Category != f.Category,
+		f.CampaignID != "" && entry.CampaignID != f.CampaignID,
+		f.Status != "" && entry.Status != f.Status,
+		!f.Since.IsZero() && entry.SentAt.Before(f.Since),
+		!f.Until.IsZero() && entry.SentAt.After(f.Until):
+		return false
+	default:
+		return true
+	}
+}
+
+// HistoryStore persists the send history kept by WithHistory.
+//
+// Query returns the entries matching filter, newest first, at most
+// filter.Limit of them when it is positive. Prune removes the entries sent
+// before cutoff. Implementations must be safe for concurrent use.
+type HistoryStore interface {
+	Add(ctx context.Context, entry HistoryEntry) error
+	Query(ctx context.Context, filter HistoryFilter) ([]HistoryEntry, error)
+	Prune(ctx context.Context, cutoff time.Time) error
+}
+
+// HashRecipient returns the form in which recipient addresses are stored
+// in the send history by default: the hex SHA-256 of the lowercased
+// address, prefixed with "sha256:"
+func HashRecipient(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// WithHistory records every send that reaches the API in store, for
+// querying with Client.History. Entries older than retention are pruned
+// as new sends are recorded. Store errors are reported as warnings with
+// code "history_store_failed" and never fail the send.
+func WithHistory(store HistoryStore, retention time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if store == nil {
+			return NewValidationError("history store cannot be nil", nil)
+		}
+		if retention <= 0 {
+			return NewValidationError("history retention must be positive", nil)
+		}
+		c.history = &sendHistory{store: store, retention: retention}
+		return nil
+	})
+}
+
+// WithHistoryPlaintextRecipients stores recipient addresses in the send
+// history as they are rather than hashed
+func WithHistoryPlaintextRecipients() Option {
+	return clientOption(func(c *Client) error {
+		c.historyPlaintext = true
+		return nil
+	})
+}
+
+// sendHistory is the history configuration of a client
+type sendHistory struct {
+	store     HistoryStore
+	retention time.Duration
+}
+
+// History returns the sends recorded with WithHistory that match filter,
+// newest first
+func (c *Client) History(ctx context.Context, filter HistoryFilter) ([]HistoryEntry, error) {
+	if c.history == nil {
+		return nil, NewValidationError("send history is not enabled; use WithHistory", nil)
+	}
+	if filter.Recipient != "" {
+		filter.Recipient = c.historyRecipient(filter.Recipient)
+	}
+	return c.history.store.Query(ctx, filter)
+}
+
+// historyRecipient returns the stored form of a recipient address
+func (c *Client) historyRecipient(email string) string {
+	if c.historyPlaintext {
+		return email
+	}
+	return HashRecipient(email)
+}
+
+// recordHistory adds the outcome of a send to the history and prunes
+// expired entries
+func (c *Client) recordHistory(ctx context.Context, req *EmailRequest, category string, resp *EmailResponse, sendErr error) {
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return
+	}
+	entry := HistoryEntry{
+		Recipient:  c.historyRecipient(req.To),
+		Subject:    req.Subject,
+		Category:   category,
+		CampaignID: req.CampaignID,
+		SentAt:     now,
+	}
+	if sendErr != nil {
+		entry.Status = HistoryStatusFailed
+		entry.Error = sendErr.Error()
+	} else {
+		entry.MessageID = resp.Data.MessageID
+		entry.Status = resp.Data.Status
+		entry.FallbackFrom = resp.FallbackFrom
+	}
+
+	// Record beyond the caller's cancellation, as the send already happened
+	storeCtx := context.WithoutCancel(ctx)
+	err = c.history.store.Add(storeCtx, entry)
+	if err == nil {
+		err = c.history.store.Prune(storeCtx, now.Add(-c.history.retention))
+	}
+	if err != nil {
+		c.warn(ctx, Warning{Code: "history_store_failed", Message: "failed to record send history: " + err.Error()})
+	}
+}
+
+// MemoryHistoryStore is a HistoryStore keeping the most recent entries in
+// memory, up to a fixed capacity
+type MemoryHistoryStore struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+// NewMemoryHistoryStore creates a store keeping at most capacity entries,
+// dropping the oldest when full
+func NewMemoryHistoryStore(capacity int) (*MemoryHistoryStore, error) {
+	if capacity <= 0 {
+		return nil, NewValidationError("history capacity must be positive", nil)
+	}
+	return &MemoryHistoryStore{entries: make([]HistoryEntry, capacity)}, nil
+}
+
+// Add records entry, overwriting the oldest entry when the store is full
+func (s *MemoryHistoryStore) Add(ctx context.Context, entry HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[s.next] = entry
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+	return nil
+}
+
+// Query returns the entries matching filter, newest first
+func (s *MemoryHistoryStore) Query(ctx context.Context, filter HistoryFilter) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []HistoryEntry
+	for _, entry := range s.stored() {
+		if filter.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].SentAt.After(result[j].SentAt) })
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// Prune removes the entries sent before cutoff
+func (s *MemoryHistoryStore) Prune(ctx context.Context, cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Nothing to do unless the oldest entry has expired
+	oldest := 0
+	if s.full {
+		oldest = s.next
+	}
+	if (!s.full && s.next == 0) || !s.entries[oldest].SentAt.Before(cutoff) {
+		return nil
+	}
+
+	var kept []HistoryEntry
+	for _, entry := range s.stored() {
+		if !entry.SentAt.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	entries := make([]HistoryEntry, len(s.entries))
+	copy(entries, kept)
+	s.entries = entries
+	s.next = len(kept) % len(entries)
+	s.full = len(kept) == len(entries)
+	return nil
+}
+
+// stored returns the stored entries, oldest first
+func (s *MemoryHistoryStore) stored() []HistoryEntry {
+	if !s.full {
+		return s.entries[:s.next]
+	}
+	return append(append([]HistoryEntry(nil), s.entries[s.next:]...), s.entries[:s.next]...)
+}
@@ -4,50 +4,170 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
+	"time"
 )
 
-// MakeRequest builds and sends an HTTP request with proper headers
+// requestMeta carries per-request metadata used to enrich errors
+type requestMeta struct {
+	// start is when the logical request started
+	start time.Time
+
+	// attempt is the attempt number, starting at 1
+	attempt int
+
+	// diagnostics receives connection diagnostics when set
+	diagnostics func(ConnDiagnostics)
+
+	// headers are added to the request
+	headers http.Header
+}
+
+// MakeRequest builds and sends an HTTP request with proper headers.
+//
+// The caller must close the body of the returned response, or pass the
+// response to HandleResponse which closes it. The body can only be closed
+// once; a second Close returns ErrResponseBodyClosed.
 func MakeRequest(ctx context.Context, client *http.Client, method, url, apiKey string, body interface{}) (*http.Response, error) {
+	return makeRequest(ctx, client, method, url, apiKey, body, requestMeta{start: time.Now(), attempt: 1})
+}
+
+// makeRequest builds and sends an HTTP request, using meta to describe
+// the request in connection errors
+func makeRequest(ctx context.Context, client *http.Client, method, url, apiKey string, body interface{}, meta requestMeta) (*http.Response, error) {
 	// Encode request body as JSON
+	jsonData, err := encodeJSON(body)
+	if err != nil {
+		return nil, err
+	}
 	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, NewValidationError("failed to encode request body", err)
-		}
+	if jsonData != nil {
 		reqBody = bytes.NewBuffer(jsonData)
 	}
 
+	return sendRequest(ctx, client, method, url, apiKey, "application/json", reqBody, meta)
+}
+
+// encodeJSON encodes a request body as JSON, returning nil for a nil body
+func encodeJSON(body interface{}) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, NewValidationError("failed to encode request body", err)
+	}
+	return jsonData, nil
+}
+
+// MarshalEmailRequest returns the JSON body SendEmail sends for req.
+//
+// The encoding is deterministic: the same request always produces the same
+// bytes, with struct fields in declaration order and map keys such as
+// Headers sorted. A send marshals its request once and reuses the same
+// bytes for every retry attempt, so gateways that sign or cache request
+// bodies see identical payloads across retries.
+func MarshalEmailRequest(req *EmailRequest) ([]byte, error) {
+	if req == nil {
+		return nil, NewValidationError("email request cannot be nil", nil)
+	}
+	return encodeJSON(req)
+}
+
+// sendRequest sends an HTTP request with the given body and content type
+func sendRequest(ctx context.Context, client *http.Client, method, url, apiKey, contentType string, body io.Reader, meta requestMeta) (*http.Response, error) {
+	// Trace the connection if diagnostics were requested
+	var tracer *connTracer
+	if meta.diagnostics != nil {
+		tracer = &connTracer{}
+		ctx = tracer.withTrace(ctx)
+	}
+
+	// Record whether the request was written, after which a failure
+	// leaves its outcome unknown
+	var written atomic.Bool
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			written.Store(info.Err == nil)
+		},
+	})
+
 	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, NewConnectionError("failed to create request", err)
 	}
 
 	// Add required headers
 	req.Header.Set("X-API-Key", apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+
+	// Ask caches not to store requests, which can carry personal data
+	req.Header.Set("Cache-Control", DefaultCacheControl)
+	req.Header.Set("Pragma", "no-cache")
+	for name, values := range meta.headers {
+		req.Header[name] = values
+	}
+	if _, ok := meta.headers["Cache-Control"]; ok {
+		req.Header.Del("Pragma")
+	}
 
 	// Send the request
 	resp, err := client.Do(req)
+	if tracer != nil {
+		meta.diagnostics(tracer.result())
+	}
 	if err != nil {
-		return nil, NewConnectionError("failed to send request", err)
+		connErr := newRequestConnectionError("failed to send request", err, req.URL.Host, meta.attempt, meta.start, effectiveDeadline(ctx, client, meta.start))
+		connErr.RequestWritten = written.Load()
+		return nil, connErr
 	}
+	resp.Body = newTrackedBody(resp.Body)
 
 	return resp, nil
 }
 
+// effectiveDeadline returns the earlier of the context deadline and the
+// HTTP client timeout, or the zero time if neither applies
+func effectiveDeadline(ctx context.Context, client *http.Client, start time.Time) time.Time {
+	deadline, _ := ctx.Deadline()
+	if client.Timeout > 0 {
+		clientDeadline := start.Add(client.Timeout)
+		if deadline.IsZero() || clientDeadline.Before(deadline) {
+			deadline = clientDeadline
+		}
+	}
+	return deadline
+}
+
 // HandleResponse processes HTTP responses and maps status codes to error types
 func HandleResponse(resp *http.Response) ([]byte, error) {
+	return handleResponse(resp, nil)
+}
+
+// ErrorMapper maps an API error response to the error returned to the
+// caller. It receives the status code, the raw response body and the error
+// produced by DefaultErrorMapper, and can return that error unchanged to
+// keep the built-in behavior. Returning nil also keeps the default error.
+type ErrorMapper func(statusCode int, body []byte, defaultErr error) error
+
+// handleResponse processes an HTTP response, passing errors through mapper
+// when it is set
+func handleResponse(resp *http.Response, mapper ErrorMapper) ([]byte, error) {
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, NewConnectionError("failed to read response body", err)
+		connErr := NewConnectionError("failed to read response body", err)
+		connErr.RequestWritten = true
+		return nil, connErr
 	}
 
 	// Handle successful responses (2xx)
@@ -55,20 +175,104 @@ func HandleResponse(resp *http.Response) ([]byte, error) {
 		return body, nil
 	}
 
+	err = DefaultErrorMapper(resp.StatusCode, body)
+	var maintenanceErr *MaintenanceError
+	if errors.As(err, &maintenanceErr) {
+		maintenanceErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
+	if mapper != nil {
+		if mapped := mapper(resp.StatusCode, body, err); mapped != nil {
+			// Errors built by the mapper report the response status, so
+			// that IsRetryable classifies them like the default errors
+			if e := baseOf(mapped); e != nil && e.StatusCode == 0 {
+				e.StatusCode = resp.StatusCode
+			}
+			return nil, mapped
+		}
+	}
+	return nil, err
+}
+
+// DefaultErrorMapper maps an API error response to one of the SDK error
+// types using its status code and JSON error body. Bodies that are not
+// JSON are used as the error message.
+//
+// A MaintenanceError returned by DefaultErrorMapper has no RetryAfter, as
+// that comes from the response headers.
+func DefaultErrorMapper(statusCode int, body []byte) error {
 	// Parse error response
 	var errResp ErrorResponse
 	if err := json.Unmarshal(body, &errResp); err != nil {
 		// If we can't parse the error response, create a generic error message
-		return nil, mapStatusCodeToError(resp.StatusCode, string(body))
+		return withAPIDetails(mapStatusCodeToError(statusCode, string(body)), statusCode, "")
 	}
 
 	// Map status code to appropriate error type with parsed message
 	errorMessage := errResp.Error.Message
 	if errorMessage == "" {
-		errorMessage = fmt.Sprintf("API request failed with status %d", resp.StatusCode)
+		errorMessage = fmt.Sprintf("API request failed with status %d", statusCode)
+	}
+
+	if statusCode == http.StatusServiceUnavailable && errResp.Error.Code == maintenanceCode {
+		return withAPIDetails(NewMaintenanceError(errorMessage, 0, nil), statusCode, errResp.Error.Code)
 	}
 
-	return nil, mapStatusCodeToError(resp.StatusCode, errorMessage)
+	var err error
+	if (statusCode == http.StatusPaymentRequired || statusCode == http.StatusForbidden) && errResp.Error.Code == featureNotAvailableCode {
+		feature, _ := errResp.Error.Details["feature"].(string)
+		plan, _ := errResp.Error.Details["plan"].(string)
+		err = withAPIDetails(NewPlanFeatureError(errorMessage, feature, plan, nil), statusCode, errResp.Error.Code)
+	} else if statusCode == http.StatusForbidden && errResp.Error.Code == subaccountNotFoundCode {
+		err = withAPIDetails(NewAuthError(errorMessage, nil), statusCode, errResp.Error.Code)
+	} else if statusCode == http.StatusBadRequest && errResp.Error.Code == callbackUnreachableCode {
+		callbackURL, _ := errResp.Error.Details["url"].(string)
+		err = withAPIDetails(NewCallbackUnreachableError(errorMessage, callbackURL, nil), statusCode, errResp.Error.Code)
+	} else {
+		err = withAPIDetails(mapStatusCodeToError(statusCode, errorMessage), statusCode, errResp.Error.Code)
+	}
+	if e := baseOf(err); e != nil {
+		e.Details = errResp.Error.Details
+	}
+	return err
+}
+
+// maintenanceCode is the API error code for a maintenance window
+const maintenanceCode = "maintenance"
+
+// subaccountNotFoundCode is the API error code for a request on behalf of
+// an unknown sub-account
+const subaccountNotFoundCode = "subaccount_not_found"
+
+// featureNotAvailableCode is the API error code for a feature the
+// account's plan does not include
+const featureNotAvailableCode = "feature_not_available"
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date, returning zero if it is missing or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// withAPIDetails records the response status code and API error code on err
+func withAPIDetails(err error, statusCode int, code string) error {
+	if e := baseOf(err); e != nil {
+		e.StatusCode = statusCode
+		e.Code = code
+	}
+	return err
 }
 
 // mapStatusCodeToError maps HTTP status codes to specific error types
@@ -78,6 +282,10 @@ func mapStatusCodeToError(statusCode int, message string) error {
 		return NewValidationError(message, nil)
 	case 401:
 		return NewAuthError(message, nil)
+	case 408:
+		return NewConnectionError(message, nil)
+	case 425:
+		return NewServerError(message, nil)
 	case 429:
 		return NewRateLimitError(message, nil)
 	default:
@@ -0,0 +1,246 @@
+package mailnow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrIdempotencyKeyInUse is returned by IdempotencyStore.Reserve when
+// another send with the same key is in progress or ended without a known
+// outcome
+var ErrIdempotencyKeyInUse = errors.New("mailnow: idempotency key is in use")
+
+// IdempotencyStore records sends made with an idempotency key so that each
+// key is sent at most once, across processes when the store is shared.
+//
+// Implementations must be safe for concurrent use and must make Reserve
+// atomic: of several concurrent Reserve calls for a new key, exactly one
+// succeeds. A SQL store can do this with an INSERT on a unique key column
+// holding the key, a state column (reserved or completed) and the encoded
+// response, where Complete updates the row and Release deletes it if it is
+// still reserved.
+type IdempotencyStore interface {
+	// Reserve claims key for a send. It returns done and the stored
+	// response if a send with key already completed, and
+	// ErrIdempotencyKeyInUse if key is reserved but not completed.
+	Reserve(ctx context.Context, key string) (done bool, existing *EmailResponse, err error)
+
+	// Complete records the response of the send that reserved key
+	Complete(ctx context.Context, key string, resp *EmailResponse) error
+
+	// Release frees a reserved key after a send that definitely failed, so
+	// that a later send with the key can proceed
+	Release(ctx context.Context, key string) error
+}
+
+// WithIdempotencyStore sets the store used for sends made with
+// WithIdempotencyKey
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return clientOption(func(c *Client) error {
+		if store == nil {
+			return NewValidationError("idempotency store cannot be nil", nil)
+		}
+		c.idempotencyStore = store
+		return nil
+	})
+}
+
+// WithIdempotencyKey makes a send exactly-once for key using the store set
+// with WithIdempotencyStore.
+//
+// A send with a completed key returns the stored response without calling
+// the API. A send whose key is reserved by a send in progress fails with
+// an error wrapping ErrIdempotencyKeyInUse. The key is also sent to the API
+// in IdempotencyKeyHeader; when the API answers with the original send's
+// response instead, the send completes as usual. Replayed responses, from
+// the store or the API, have EmailResponse.Replayed set.
+//
+// The key is released when the send definitely failed, and kept reserved
+// when the outcome is unknown, such as after a connection error or a
+// successful response that could not be decoded; call Release on the store
+// once the outcome has been checked.
+func WithIdempotencyKey(key string) SendOption {
+	return sendOption(func(o *sendOptions) error {
+		if key == "" {
+			return NewValidationError("idempotency key cannot be empty", nil)
+		}
+		o.idempotencyKey = key
+		return nil
+	})
+}
+
+// reserveIdempotencyKey reserves key, returning the stored response of a
+// completed send with the key
+func (c *Client) reserveIdempotencyKey(ctx context.Context, key string) (*EmailResponse, error) {
+	if c.idempotencyStore == nil {
+		return nil, NewValidationError("idempotency key requires an idempotency store; use WithIdempotencyStore", nil)
+	}
+
+	done, existing, err := c.idempotencyStore.Reserve(ctx, key)
+	if err != nil {
+		return nil, &Error{Message: fmt.Sprintf("failed to reserve idempotency key %q", key), Err: err}
+	}
+	if done {
+		return existing, nil
+	}
+	return nil, nil
+}
+
+// finishIdempotentSend completes or releases key according to the outcome
+// of the send
+func (c *Client) finishIdempotentSend(ctx context.Context, key string, resp *EmailResponse, sendErr error) {
+	ctx = context.WithoutCancel(ctx)
+
+	var err error
+	switch {
+	case sendErr == nil:
+		err = c.idempotencyStore.Complete(ctx, key, resp)
+	case isOutcomeUnknown(sendErr):
+		// The email may have been sent; keep the key reserved
+		return
+	default:
+		err = c.idempotencyStore.Release(ctx, key)
+	}
+	if err != nil {
+		c.warn(ctx, Warning{
+			Code:    "idempotency_store_failed",
+			Message: fmt.Sprintf("failed to update idempotency key %q: %v", key, err),
+		})
+	}
+}
+
+// isOutcomeUnknown reports whether err leaves it unknown if the API
+// accepted the request: the connection failed without a response after the
+// request was written, or the API answered with a successful status but the
+// response was rejected, such as one that cannot be decoded. Failures before
+// that, such as a cancelled wait for a rate limit token, definitely did not
+// send the email.
+func isOutcomeUnknown(err error) bool {
+	status := ErrorStatusCode(err)
+	if status >= 200 && status < 300 {
+		return true
+	}
+	var connErr *ConnectionError
+	return errors.As(err, &connErr) && connErr.RequestWritten && status == 0
+}
+
+// withAcceptedStatus records on err, raised for a response the API sent
+// with the successful statusCode, that status code
+func withAcceptedStatus(err error, statusCode int) error {
+	if e := baseOf(err); e != nil {
+		e.StatusCode = statusCode
+	}
+	return err
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore that keeps keys in memory.
+// Keys are never expired and do not survive a restart.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*EmailResponse
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]*EmailResponse)}
+}
+
+// Reserve implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string) (bool, *EmailResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, ok := s.entries[key]
+	switch {
+	case !ok:
+		s.entries[key] = nil
+		return false, nil, nil
+	case resp == nil:
+		return false, nil, ErrIdempotencyKeyInUse
+	default:
+		copied := *resp
+		return true, &copied, nil
+	}
+}
+
+// Complete implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, resp *EmailResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *resp
+	s.entries[key] = &copied
+	return nil
+}
+
+// Release implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp, ok := s.entries[key]; ok && resp == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// idempotentReplayCode is the API error code of the 409 response some API
+// versions give for a reused idempotency key
+const idempotentReplayCode = "idempotent_replay"
+
+// idempotencyKeyContextKey carries the idempotency key of a send to its
+// requests
+type idempotencyKeyContextKey struct{}
+
+// withIdempotencyKey returns ctx carrying key, sent by the client in
+// IdempotencyKeyHeader
+func withIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// idempotencyKeyFrom returns the idempotency key carried by ctx, or ""
+func idempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// isIdempotentReplay reports whether a successful send response carries
+// the idempotent_replay flag, set by the API next to the envelope fields
+// when it answers a reused idempotency key with the original send's data
+func isIdempotentReplay(body []byte) bool {
+	var replay struct {
+		IdempotentReplay bool `json:"idempotent_replay"`
+	}
+	return json.Unmarshal(body, &replay) == nil && replay.IdempotentReplay
+}
+
+// replayResponse converts the 409 idempotent_replay error, whose details
+// hold the original message ID and status, into the replayed response
+func replayResponse(err error) (*EmailResponse, bool) {
+	e := baseOf(err)
+	if e == nil {
+		return nil, false
+	}
+	messageID, _ := e.Details["message_id"].(string)
+	if messageID == "" {
+		return nil, false
+	}
+	status, _ := e.Details["status"].(string)
+	return &EmailResponse{
+		Data:       Data{MessageID: messageID, Status: status},
+		Message:    e.Message,
+		StatusCode: e.StatusCode,
+		Success:    true,
+		Replayed:   true,
+	}, true
+}
+
+// ReplayedSends returns the number of sends answered with the response of
+// an earlier send with the same idempotency key, by the idempotency store
+// or by the API; see EmailResponse.Replayed
+func (c *Client) ReplayedSends() int64 {
+	return c.state.replays.Load()
+}
@@ -0,0 +1,181 @@
+package mailnow
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// MaxInboundPayloadSize is the largest inbound parse payload
+// ParseInboundEmail reads
+const MaxInboundPayloadSize = 30 << 20
+
+// InboundEmail is an email received through the inbound parse webhook
+type InboundEmail struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Subject   string `json:"subject"`
+	MessageID string `json:"message_id"`
+	InReplyTo string `json:"in_reply_to"`
+
+	// Headers are the email headers, keyed by header name
+	Headers map[string]string `json:"headers"`
+
+	Text string `json:"text"`
+	HTML string `json:"html"`
+
+	// Attachments carry their content base64 encoded, as for sending
+	Attachments []Attachment `json:"attachments"`
+
+	// RawMIME is the original message when the webhook includes it
+	RawMIME []byte `json:"-"`
+}
+
+// inboundPayload is the JSON encoding of the inbound parse webhook
+type inboundPayload struct {
+	InboundEmail
+	Raw string `json:"raw"`
+}
+
+// ParseInboundEmail parses an inbound parse webhook request, sent either as
+// JSON or as multipart/form-data with the attachments as file parts.
+//
+// Missing fields are left empty. MessageID and InReplyTo fall back to the
+// Message-ID and In-Reply-To headers. Payloads larger than
+// MaxInboundPayloadSize are rejected with a ValidationError, as are
+// payloads that cannot be decoded.
+func ParseInboundEmail(r *http.Request) (*InboundEmail, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, NewValidationError("invalid inbound payload content type", err)
+	}
+	body := http.MaxBytesReader(nil, r.Body, MaxInboundPayloadSize)
+
+	var email *InboundEmail
+	switch mediaType {
+	case "application/json":
+		email, err = parseInboundJSON(body)
+	case "multipart/form-data":
+		email, err = parseInboundMultipart(multipart.NewReader(body, params["boundary"]))
+	default:
+		return nil, NewValidationError("unsupported inbound payload content type "+mediaType, nil)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, NewValidationError(fmt.Sprintf("inbound payload exceeds %d bytes", int64(MaxInboundPayloadSize)), err)
+		}
+		return nil, err
+	}
+
+	if email.MessageID == "" {
+		email.MessageID = headerValue(email.Headers, "Message-ID")
+	}
+	if email.InReplyTo == "" {
+		email.InReplyTo = headerValue(email.Headers, "In-Reply-To")
+	}
+	return email, nil
+}
+
+// parseInboundJSON decodes a JSON inbound payload
+func parseInboundJSON(body io.Reader) (*InboundEmail, error) {
+	var payload inboundPayload
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, NewValidationError("invalid inbound JSON payload", err)
+	}
+
+	email := payload.InboundEmail
+	if payload.Raw != "" {
+		raw, err := base64.StdEncoding.DecodeString(payload.Raw)
+		if err != nil {
+			return nil, NewValidationError("inbound raw message must be base64 encoded", err)
+		}
+		email.RawMIME = raw
+	}
+	return &email, nil
+}
+
+// parseInboundMultipart decodes a multipart/form-data inbound payload
+func parseInboundMultipart(reader *multipart.Reader) (*InboundEmail, error) {
+	email := &InboundEmail{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return email, nil
+		}
+		if err != nil {
+			return nil, inboundReadError(err)
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, inboundReadError(err)
+		}
+
+		if part.FileName() != "" && part.FormName() != "raw" {
+			contentType := part.Header.Get("Content-Type")
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			email.Attachments = append(email.Attachments, Attachment{
+				Filename:    part.FileName(),
+				Content:     base64.StdEncoding.EncodeToString(content),
+				ContentType: contentType,
+			})
+			continue
+		}
+
+		value := string(content)
+		switch part.FormName() {
+		case "from":
+			email.From = value
+		case "to":
+			email.To = value
+		case "subject":
+			email.Subject = value
+		case "message_id":
+			email.MessageID = value
+		case "in_reply_to":
+			email.InReplyTo = value
+		case "text":
+			email.Text = value
+		case "html":
+			email.HTML = value
+		case "raw":
+			email.RawMIME = content
+		case "headers":
+			if err := json.Unmarshal(content, &email.Headers); err != nil {
+				return nil, NewValidationError("inbound headers must be a JSON object", err)
+			}
+		}
+	}
+}
+
+// inboundReadError describes a failure to read a multipart payload
+func inboundReadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return err
+	}
+	return NewValidationError("invalid inbound multipart payload", err)
+}
+
+// headerValue returns the value of the named header, ignoring case
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TooManyInFlightError represents a request refused locally because the
+// client had the maximum number of requests in flight, set with
+// WithMaxInFlight, for longer than the wait set with WithMaxInFlightWait
+type TooManyInFlightError struct {
+	error *Error
+
+	// Limit is the maximum number of requests in flight
+	Limit int
+
+	// Waited is how long the request waited for a slot
+	Waited time.Duration
+}
+
+// NewTooManyInFlightError creates a new TooManyInFlightError
+func NewTooManyInFlightError(message string, limit int, waited time.Duration, err error) *TooManyInFlightError {
+	return &TooManyInFlightError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Limit:  limit,
+		Waited: waited,
+	}
+}
+
+func (e *TooManyInFlightError) Error() string {
+	return e.error.Error()
+}
+
+func (e *TooManyInFlightError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *TooManyInFlightError) base() *Error {
+	return e.error
+}
+
+// WithMaxInFlight limits the client to n HTTP requests at a time. Further
+// requests wait for a slot until their context is done, when they fail with
+// an error wrapping the context's error, or for at most the wait set with
+// WithMaxInFlightWait. Retries wait for a slot again.
+func WithMaxInFlight(n int) Option {
+	return clientOption(func(c *Client) error {
+		if n <= 0 {
+			return NewValidationError("max in-flight requests must be positive", nil)
+		}
+		c.inFlightSlots = make(chan struct{}, n)
+		return nil
+	})
+}
+
+// WithMaxInFlightWait bounds how long a request waits for a slot when the
+// limit set with WithMaxInFlight is reached. Requests that wait longer fail
+// with a TooManyInFlightError, which is not retried.
+func WithMaxInFlightWait(d time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if d <= 0 {
+			return NewValidationError("max in-flight wait must be positive", nil)
+		}
+		c.inFlightWait = d
+		return nil
+	})
+}
+
+// InFlight returns the number of HTTP requests the client has in flight.
+// It is safe to call at any time.
+func (c *Client) InFlight() int {
+	return int(c.state.inFlight.Load())
+}
+
+// acquireInFlight waits for an in-flight slot. Each successful call must
+// be followed by a call to releaseInFlight.
+func (c *Client) acquireInFlight(ctx context.Context) error {
+	if c.inFlightSlots != nil {
+		select {
+		case c.inFlightSlots <- struct{}{}:
+		default:
+			if err := c.waitInFlight(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	c.state.inFlight.Add(1)
+	return nil
+}
+
+// waitInFlight blocks until an in-flight slot is free
+func (c *Client) waitInFlight(ctx context.Context) error {
+	var timeout <-chan time.Time
+	if c.inFlightWait > 0 {
+		timer := time.NewTimer(c.inFlightWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	start := time.Now()
+	select {
+	case c.inFlightSlots <- struct{}{}:
+		return nil
+	case <-timeout:
+		limit := cap(c.inFlightSlots)
+		return NewTooManyInFlightError(fmt.Sprintf("%d requests already in flight after waiting %s", limit, c.inFlightWait), limit, time.Since(start), nil)
+	case <-ctx.Done():
+		return fmt.Errorf("in-flight request wait interrupted: %w", ctx.Err())
+	}
+}
+
+// releaseInFlight frees the slot taken by acquireInFlight
+func (c *Client) releaseInFlight() {
+	c.state.inFlight.Add(-1)
+	if c.inFlightSlots != nil {
+		<-c.inFlightSlots
+	}
+}
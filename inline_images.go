@@ -0,0 +1,139 @@
+package mailnow
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// srcRegex matches a src attribute and its quoted or unquoted value
+var srcRegex = regexp.MustCompile(`(?i)(\ssrc\s*=\s*)("[^"]*"|'[^']*'|[^\s>"']+)`)
+
+// imageExtensions maps image content types to a filename extension
+var imageExtensions = map[string]string{
+	"image/bmp":     "bmp",
+	"image/gif":     "gif",
+	"image/jpeg":    "jpg",
+	"image/png":     "png",
+	"image/svg+xml": "svg",
+	"image/webp":    "webp",
+}
+
+// InlineImageExtractor moves images embedded in HTML as base64 data URIs
+// into inline attachments
+type InlineImageExtractor struct {
+	// MinSize is the decoded size in bytes below which an image is left
+	// inline; zero extracts every image
+	MinSize int
+}
+
+// ExtractInlineImages extracts every data URI image of html, as an
+// InlineImageExtractor with no MinSize does.
+func ExtractInlineImages(html string) (string, []Attachment, error) {
+	return InlineImageExtractor{}.Extract(html)
+}
+
+// Extract finds the <img> elements of html whose src is a base64 data URI
+// such as data:image/png;base64,..., turns each image into an inline
+// attachment with a Content-ID derived from its content, and points the
+// src at cid:<Content-ID>. Identical images share one attachment. It
+// returns the rewritten HTML and the attachments, in order of first use.
+//
+// Images smaller than MinSize, data URIs that are not base64 encoded
+// images, and every other src are left unchanged. A base64 image data URI
+// that cannot be decoded is an error.
+func (e InlineImageExtractor) Extract(html string) (string, []Attachment, error) {
+	var attachments []Attachment
+	seen := make(map[string]bool)
+	var extractErr error
+
+	out := tagRegex.ReplaceAllStringFunc(html, func(tag string) string {
+		if extractErr != nil || !strings.EqualFold(tagName(tag), "img") {
+			return tag
+		}
+		m := srcRegex.FindStringSubmatchIndex(tag)
+		if m == nil {
+			return tag
+		}
+		value := tag[m[4]:m[5]]
+		src := strings.Trim(value, `"'`)
+		contentType, data, ok, err := parseImageDataURI(src)
+		if err != nil {
+			extractErr = err
+			return tag
+		}
+		if !ok || len(data) < e.MinSize {
+			return tag
+		}
+
+		sum := sha256.Sum256(data)
+		id := "img-" + hex.EncodeToString(sum[:8]) + "@mailnow"
+		if !seen[id] {
+			seen[id] = true
+			attachments = append(attachments, Attachment{
+				Filename:    fmt.Sprintf("image-%x.%s", sum[:8], imageExtensions[contentType]),
+				Content:     base64.StdEncoding.EncodeToString(data),
+				ContentType: contentType,
+				ContentID:   id,
+			})
+		}
+		return tag[:m[4]] + `"cid:` + id + `"` + tag[m[5]:]
+	})
+	if extractErr != nil {
+		return "", nil, extractErr
+	}
+	return out, attachments, nil
+}
+
+// parseImageDataURI decodes a base64 data URI of a known image type,
+// reporting false for any other src
+func parseImageDataURI(src string) (contentType string, data []byte, ok bool, err error) {
+	if len(src) < 5 || !strings.EqualFold(src[:5], "data:") {
+		return "", nil, false, nil
+	}
+	meta, payload, found := strings.Cut(src[5:], ",")
+	params := strings.Split(meta, ";")
+	contentType = strings.ToLower(strings.TrimSpace(params[0]))
+	if _, known := imageExtensions[contentType]; !known || !strings.EqualFold(strings.TrimSpace(params[len(params)-1]), "base64") {
+		return "", nil, false, nil
+	}
+	if !found {
+		return "", nil, false, NewValidationError("image data URI has no data: "+truncateDataURI(src), nil)
+	}
+
+	payload = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, payload)
+	data, err = base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", nil, false, NewValidationError("image data URI is not valid base64: "+truncateDataURI(src), err)
+	}
+	return contentType, data, true, nil
+}
+
+// truncateDataURI shortens a data URI for error messages
+func truncateDataURI(src string) string {
+	if len(src) > 40 {
+		return src[:40] + "..."
+	}
+	return src
+}
+
+// WithInlineImageExtraction moves the data URI images of the send's HTML
+// body into inline attachments, as InlineImageExtractor does with minSize
+// as its MinSize. The request passed to SendEmail is left unchanged.
+func WithInlineImageExtraction(minSize int) SendOption {
+	return sendOption(func(o *sendOptions) error {
+		if minSize < 0 {
+			return NewValidationError("inline image minimum size cannot be negative", nil)
+		}
+		o.inlineImages = &InlineImageExtractor{MinSize: minSize}
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+package mailnow
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClientClosed is returned by requests made after Client.Close
+var ErrClientClosed = errors.New("mailnow: client is closed")
+
+// Close shuts the client down. Requests started after Close fail with
+// ErrClientClosed, while requests already in flight, including their
+// retries and the requests they make, such as sends from fallback senders,
+// run to completion. Close waits for them and for archiving that
+// continues in the background, see WithArchiver, until ctx is done, then
+// saves the rate limits, see WithRateLimitStore, and closes the idle
+// connections of the HTTP client unless it was set with WithHTTPClient.
+//
+// Close returns ctx's error when it stopped waiting early; the remaining
+// work still finishes in the background. Close is safe to call more than
+// once and concurrently with sends.
+func (c *Client) Close(ctx context.Context) error {
+	c.state.lifecycle.Lock()
+	c.state.closed = true
+	c.state.lifecycle.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.state.active.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	c.checkpointRateLimits(ctx, true)
+	if !c.customHTTPClient {
+		c.httpClient.CloseIdleConnections()
+	}
+	return err
+}
+
+// operationKey is the context key marking requests made as part of an
+// operation registered with beginOperation; its value is the clientState
+// the operation is registered with
+type operationKey struct{}
+
+// beginOperation registers a request or background task with the client,
+// returning false if the client is closed. Otherwise it returns ctx marked
+// as part of the operation and the function that marks it finished, which
+// must be called once. Requests made with a ctx that is already part of
+// an operation of the client, such as the sub-requests of a send, belong
+// to it: they are not registered again and are not failed by a Close that
+// happened since it began.
+func (c *Client) beginOperation(ctx context.Context) (context.Context, func(), bool) {
+	if state, _ := ctx.Value(operationKey{}).(*clientState); state == c.state {
+		return ctx, func() {}, true
+	}
+
+	c.state.lifecycle.RLock()
+	defer c.state.lifecycle.RUnlock()
+	if c.state.closed {
+		return ctx, nil, false
+	}
+	c.state.active.Add(1)
+	return context.WithValue(ctx, operationKey{}, c.state), c.state.active.Done, true
+}
@@ -0,0 +1,322 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultLinkCheckConcurrency is the number of links checked at the same
+// time by CheckLinks
+const DefaultLinkCheckConcurrency = 8
+
+// LinkIssue is a problem with a link found by ValidateLinks or CheckLinks
+type LinkIssue struct {
+	// Code identifies the kind of problem: "empty_link", "invalid_link",
+	// "insecure_link", "private_link", "javascript_link" or "broken_link"
+	Code string
+
+	// URL is the href as written in the HTML
+	URL string
+
+	// Message describes the problem
+	Message string
+}
+
+// hrefValues returns the unescaped href values of the tags in content, in
+// order of appearance
+func hrefValues(content string) []string {
+	var values []string
+	for _, tag := range tagRegex.FindAllString(content, -1) {
+		for _, m := range hrefRegex.FindAllStringSubmatch(tag, -1) {
+			value := m[2]
+			if value[0] == '"' || value[0] == '\'' {
+				value = value[1 : len(value)-1]
+			}
+			values = append(values, strings.TrimSpace(html.UnescapeString(value)))
+		}
+	}
+	return values
+}
+
+// ValidateLinks checks the links of an HTML body without contacting them.
+// It reports empty hrefs, URLs that cannot be parsed or are not absolute,
+// plain http links, links to localhost or private IP addresses, and
+// javascript: URIs.
+//
+// mailto:, tel: and cid: links and in-page anchors are not checked, nor are
+// hrefs containing a {{var}}-style placeholder, which are only complete
+// once the template is rendered.
+func ValidateLinks(content string) []LinkIssue {
+	var issues []LinkIssue
+	for _, href := range hrefValues(content) {
+		if issue, ok := validateLink(href); !ok {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// validateLink checks a single href
+func validateLink(href string) (LinkIssue, bool) {
+	issue := func(code, format string, args ...interface{}) (LinkIssue, bool) {
+		return LinkIssue{Code: code, URL: href, Message: fmt.Sprintf(format, args...)}, false
+	}
+
+	if href == "" {
+		return issue("empty_link", "link has an empty href")
+	}
+	if strings.HasPrefix(href, "#") || strings.Contains(href, DefaultDelimiters.Left) {
+		return LinkIssue{}, true
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return issue("invalid_link", "link %q cannot be parsed: %v", href, err)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "mailto", "tel", "cid":
+		return LinkIssue{}, true
+	case "javascript":
+		return issue("javascript_link", "link %q runs JavaScript", href)
+	case "http":
+		if issue, ok := validateLinkHost(href, u); !ok {
+			return issue, false
+		}
+		return issue("insecure_link", "link %q does not use https", href)
+	case "https":
+		return validateLinkHost(href, u)
+	case "":
+		return issue("invalid_link", "link %q is not an absolute URL", href)
+	default:
+		return issue("invalid_link", "link %q has unsupported scheme %q", href, u.Scheme)
+	}
+}
+
+// validateLinkHost checks that the host of an http(s) link is public
+func validateLinkHost(href string, u *url.URL) (LinkIssue, bool) {
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return LinkIssue{Code: "invalid_link", URL: href, Message: fmt.Sprintf("link %q has no host", href)}, false
+	}
+	private := host == "localhost" || strings.HasSuffix(host, ".localhost")
+	if ip := net.ParseIP(host); ip != nil {
+		private = isNonPublicIP(ip)
+	}
+	if private {
+		return LinkIssue{Code: "private_link", URL: href, Message: fmt.Sprintf("link %q points to a local or private host", href)}, false
+	}
+	return LinkIssue{}, true
+}
+
+// isNonPublicIP reports whether ip is a loopback, private, link-local,
+// multicast or unspecified address
+func isNonPublicIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// linkCheckClient is the client CheckLinks uses by default. It connects
+// directly, without a proxy, and its dialer refuses non-public addresses,
+// so a public host name resolving to one cannot make the check reach
+// internal services.
+var linkCheckClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   refuseNonPublicAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// refuseNonPublicAddress is a dialer control function failing connections
+// to non-public addresses
+func refuseNonPublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isNonPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// CheckLinks requests every distinct http(s) link of an HTML body with
+// HEAD and reports the links that fail or answer with an error status as
+// "broken_link" issues. Up to concurrency links are checked at the same
+// time, DefaultLinkCheckConcurrency when it is not positive; ctx bounds
+// the whole check.
+//
+// Links are never requested with GET, which could trigger one-click
+// actions such as unsubscribing, so a link answering HEAD with 405 or 501
+// counts as reachable. Redirects are not followed, and links to local or
+// private hosts, which ValidateLinks reports, are not requested. client
+// sends the requests; when nil, a client that refuses to connect to
+// non-public addresses is used.
+func CheckLinks(ctx context.Context, client *http.Client, content string, concurrency int) []LinkIssue {
+	if client == nil {
+		client = linkCheckClient
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	client = &noRedirects
+	if concurrency <= 0 {
+		concurrency = DefaultLinkCheckConcurrency
+	}
+
+	var links []string
+	seen := make(map[string]bool)
+	for _, href := range hrefValues(content) {
+		u, err := url.Parse(href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || seen[href] {
+			continue
+		}
+		if _, ok := validateLinkHost(href, u); !ok {
+			continue
+		}
+		seen[href] = true
+		links = append(links, href)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, concurrency)
+		issues = make([]*LinkIssue, len(links))
+	)
+	for i, link := range links {
+		wg.Add(1)
+		go func(i int, link string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				issues[i] = &LinkIssue{Code: "broken_link", URL: link, Message: fmt.Sprintf("link %q was not checked: %v", link, ctx.Err())}
+				return
+			}
+			issues[i] = checkLink(ctx, client, link)
+		}(i, link)
+	}
+	wg.Wait()
+
+	var result []LinkIssue
+	for _, issue := range issues {
+		if issue != nil {
+			result = append(result, *issue)
+		}
+	}
+	return result
+}
+
+// checkLink requests a single link, returning an issue if it is broken
+func checkLink(ctx context.Context, client *http.Client, link string) *LinkIssue {
+	status, err := requestLink(ctx, client, http.MethodHead, link)
+	switch {
+	case err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented):
+		return nil
+	case err != nil:
+		return &LinkIssue{Code: "broken_link", URL: link, Message: fmt.Sprintf("link %q could not be reached: %v", link, err)}
+	case status >= 400:
+		return &LinkIssue{Code: "broken_link", URL: link, Message: fmt.Sprintf("link %q returned status %d", link, status)}
+	default:
+		return nil
+	}
+}
+
+// requestLink requests link with method and returns the response status
+func requestLink(ctx context.Context, client *http.Client, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// LinkValidationMode selects what WithLinkValidation does with link issues
+type LinkValidationMode int
+
+const (
+	// LinkValidationWarn reports each issue as a warning, with the issue
+	// code as the warning code, and sends the email
+	LinkValidationWarn LinkValidationMode = iota + 1
+
+	// LinkValidationStrict fails the send with a ValidationError listing
+	// the issues
+	LinkValidationStrict
+)
+
+// WithLinkValidation checks the links of every HTML body with
+// ValidateLinks before sending, handling issues according to mode.
+func WithLinkValidation(mode LinkValidationMode) Option {
+	return clientOption(func(c *Client) error {
+		if mode != LinkValidationWarn && mode != LinkValidationStrict {
+			return NewValidationError(fmt.Sprintf("invalid link validation mode %d", mode), nil)
+		}
+		c.linkValidation = mode
+		return nil
+	})
+}
+
+// WithLinkReachabilityCheck adds a CheckLinks request to every link to
+// the checks of WithLinkValidation, which must also be set. The check of
+// each send is bounded by timeout and made with the default CheckLinks
+// client, not the API client, so it never connects to non-public
+// addresses.
+func WithLinkReachabilityCheck(timeout time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if timeout <= 0 {
+			return NewValidationError("link check timeout must be positive", nil)
+		}
+		c.linkCheckTimeout = timeout
+		return nil
+	})
+}
+
+// checkLinks validates the links of req as configured with
+// WithLinkValidation
+func (c *Client) checkLinks(ctx context.Context, req *EmailRequest) error {
+	if c.linkValidation == 0 {
+		return nil
+	}
+
+	issues := ValidateLinks(req.HTML)
+	if c.linkCheckTimeout > 0 {
+		checkCtx, cancel := context.WithTimeout(ctx, c.linkCheckTimeout)
+		issues = append(issues, CheckLinks(checkCtx, nil, req.HTML, DefaultLinkCheckConcurrency)...)
+		cancel()
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if c.linkValidation == LinkValidationWarn {
+		for _, issue := range issues {
+			c.warn(ctx, Warning{Code: issue.Code, Message: issue.Message})
+		}
+		return nil
+	}
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.Message
+	}
+	return NewValidationError("invalid links: "+strings.Join(messages, "; "), nil)
+}
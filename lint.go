@@ -0,0 +1,220 @@
+package mailnow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Lint thresholds used by LintEmailRequest
+const (
+	// LintMaxSubjectLength is the subject length above which a warning is reported
+	LintMaxSubjectLength = 150
+
+	// LintMaxAttachments is the attachment count above which a warning is reported
+	LintMaxAttachments = 5
+
+	// LintLargeAttachmentSize is the decoded inline attachment size above
+	// which a warning is reported
+	LintLargeAttachmentSize = 5 << 20
+)
+
+// LintIssue is a single problem found by LintEmailRequest
+type LintIssue struct {
+	// Code identifies the kind of problem, such as "subject_all_caps"
+	Code string
+
+	// Message describes the problem
+	Message string
+
+	// Field is the request field the problem relates to, such as "Subject"
+	// or "Attachments[2]", or "" for the request as a whole
+	Field string
+}
+
+// LintReport lists the problems LintEmailRequest found in a request
+type LintReport struct {
+	// Errors are problems that make SendEmail reject the request
+	Errors []LintIssue
+
+	// Warnings are problems that do not prevent sending but are likely to
+	// hurt deliverability or rendering
+	Warnings []LintIssue
+}
+
+// HasErrors reports whether the report contains any errors
+func (r *LintReport) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+var (
+	// htmlTagRegex matches an HTML tag or comment
+	htmlTagRegex = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+
+	// imgTagRegex matches an image tag
+	imgTagRegex = regexp.MustCompile(`(?i)<img\b`)
+)
+
+// LintEmailRequest checks req for every problem at once, for showing to a
+// user before sending.
+//
+// Errors contain every problem ValidateEmailRequest would report, rather
+// than only the first. Warnings come from heuristics: an empty, all-caps
+// or very long subject, many or large attachments, HTML made only of images,
+// campaign email without an unsubscribe link, and a Reply-To at a free
+// webmail provider (see CheckDMARCAlignment). Linting never changes how
+// SendEmail behaves.
+func LintEmailRequest(req *EmailRequest) *LintReport {
+	r := &LintReport{}
+	if req == nil {
+		r.addError("nil_request", "email request cannot be nil", "")
+		return r
+	}
+
+	r.lintErrors(req)
+	r.lintWarnings(req)
+	return r
+}
+
+// lintErrors records every validation error in req
+func (r *LintReport) lintErrors(req *EmailRequest) {
+	r.checkAddress("From", req.From, true)
+	r.checkAddress("To", req.To, true)
+	r.checkAddress("ReturnPath", req.ReturnPath, false)
+
+	for _, addr := range splitAddressList(req.ReplyTo) {
+		r.checkAddress("ReplyTo", addr, false)
+	}
+	if err := ValidateHeaders(req.Headers); err != nil {
+		r.addError("invalid_header", err.Error(), "Headers")
+	}
+	if len(req.ReplyTo) > 0 && hasHeader(req.Headers, "Reply-To") {
+		r.addError("conflicting_header", "Reply-To cannot be set both in ReplyTo and in Headers", "Headers")
+	}
+	if req.MessageClass != "" {
+		if _, err := MessageClassHeaders(req.MessageClass); err != nil {
+			r.addError("invalid_message_class", err.Error(), "MessageClass")
+		}
+	}
+	if req.ReceiptTo != "" && !req.RequestReadReceipt {
+		r.addError("missing_field", "receipt address requires RequestReadReceipt", "RequestReadReceipt")
+	}
+	r.checkAddress("ReceiptTo", req.ReceiptTo, false)
+	if name := receiptHeaderConflict(req); name != "" {
+		r.addError("conflicting_header", name+" cannot be set in Headers when RequestReadReceipt is set", "Headers")
+	}
+	if req.CustomMessageID != "" {
+		if err := ValidateCustomMessageID(req.CustomMessageID, req.From); err != nil {
+			r.addError("invalid_message_id", err.Error(), "CustomMessageID")
+		}
+		if hasHeader(req.Headers, "Message-ID") {
+			r.addError("conflicting_header", "Message-ID cannot be set both in CustomMessageID and in Headers", "Headers")
+		}
+	}
+
+	if req.Subject == "" && !req.AllowEmptySubject {
+		r.addError("missing_field", "subject is required", "Subject")
+	}
+	if req.HTML != "" && req.Markdown != "" {
+		r.addError("conflicting_body", "HTML and Markdown bodies cannot both be set", "Markdown")
+	}
+	if req.HTML == "" && req.Markdown == "" {
+		r.addError("missing_field", "HTML body is required", "HTML")
+	}
+	if req.CampaignID != "" {
+		if err := ValidateCampaignID(req.CampaignID); err != nil {
+			r.addError("invalid_campaign_id", err.Error(), "CampaignID")
+		}
+	}
+	if req.DeliveryCallback != nil {
+		if err := ValidateCallbackSpec(req.DeliveryCallback); err != nil {
+			r.addError("invalid_delivery_callback", err.Error(), "DeliveryCallback")
+		}
+	}
+	for i, attachment := range req.Attachments {
+		if err := ValidateAttachment(attachment); err != nil {
+			r.addError("invalid_attachment", err.Error(), fmt.Sprintf("Attachments[%d]", i))
+		}
+	}
+}
+
+// checkAddress records an error if the address in field is missing or invalid
+func (r *LintReport) checkAddress(field, address string, required bool) {
+	if address == "" {
+		if required {
+			r.addError("missing_field", strings.ToLower(field)+" address is required", field)
+		}
+		return
+	}
+	if err := ValidateEmailAddress(address); err != nil {
+		r.addError("invalid_address", err.Error(), field)
+	}
+}
+
+// lintWarnings records heuristic warnings for req
+func (r *LintReport) lintWarnings(req *EmailRequest) {
+	if req.Subject == "" && req.AllowEmptySubject {
+		r.addWarning("empty_subject", "email has no subject, which spam filters penalize and recipients often ignore", "Subject")
+	}
+	if isAllCaps(req.Subject) {
+		r.addWarning("subject_all_caps", "subject is written in capitals, which spam filters penalize", "Subject")
+	}
+	if n := utf8.RuneCountInString(req.Subject); n > LintMaxSubjectLength {
+		r.addWarning("subject_too_long", fmt.Sprintf("subject is %d characters and will be truncated by most clients; keep it under %d", n, LintMaxSubjectLength), "Subject")
+	}
+
+	if len(req.Attachments) > LintMaxAttachments {
+		r.addWarning("too_many_attachments", fmt.Sprintf("email has %d attachments; more than %d often triggers spam filters", len(req.Attachments), LintMaxAttachments), "Attachments")
+	}
+	for i, attachment := range req.Attachments {
+		if attachment.Content == "" {
+			continue
+		}
+		if size, err := ValidateBase64(attachment.Content); err == nil && size > LintLargeAttachmentSize {
+			r.addWarning("large_attachment", fmt.Sprintf("attachment %q is %d bytes; consider uploading it with UploadAsset", attachment.Filename, size), fmt.Sprintf("Attachments[%d]", i))
+		}
+	}
+
+	if req.HTML != "" && imgTagRegex.MatchString(req.HTML) && strings.TrimSpace(visibleText(req.HTML)) == "" {
+		r.addWarning("image_only_html", "HTML body contains only images, which spam filters penalize and text-only clients cannot show", "HTML")
+	}
+	if req.CampaignID != "" && !strings.Contains(strings.ToLower(req.HTML), "unsubscribe") {
+		r.addWarning("missing_unsubscribe", "campaign email has no unsubscribe link", "HTML")
+	}
+	for _, issue := range CheckDMARCAlignment(req, nil) {
+		r.addWarning(issue.Code, issue.Message, issue.Field)
+	}
+}
+
+// addError records an error
+func (r *LintReport) addError(code, message, field string) {
+	r.Errors = append(r.Errors, LintIssue{Code: code, Message: message, Field: field})
+}
+
+// addWarning records a warning
+func (r *LintReport) addWarning(code, message, field string) {
+	r.Warnings = append(r.Warnings, LintIssue{Code: code, Message: message, Field: field})
+}
+
+// isAllCaps reports whether s has several letters and all are upper case
+func isAllCaps(s string) bool {
+	letters := 0
+	for _, c := range s {
+		if unicode.IsLetter(c) {
+			if !unicode.IsUpper(c) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters > 3
+}
+
+// visibleText returns the text of an HTML body with tags and comments
+// removed
+func visibleText(html string) string {
+	text := htmlTagRegex.ReplaceAllString(html, "")
+	return strings.ReplaceAll(text, "&nbsp;", "")
+}
@@ -0,0 +1,87 @@
+package mailnow
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// WithLocalAddr makes every connection to the API originate from the
+// source IP ip, for hosts with several egress addresses of which only one
+// is allowlisted. NewClient fails with a ValidationError when ip is not
+// assigned to a local interface, unless WithoutLocalAddrCheck is also set.
+func WithLocalAddr(ip string) Option {
+	return clientOption(func(c *Client) error {
+		addr := net.ParseIP(ip)
+		if addr == nil {
+			return NewValidationError("invalid local address "+ip, nil)
+		}
+		if addr.IsUnspecified() {
+			return NewValidationError("local address cannot be unspecified: "+ip, nil)
+		}
+		c.localAddr = addr
+		return nil
+	})
+}
+
+// WithoutLocalAddrCheck skips the check that the address set with
+// WithLocalAddr is assigned to a local interface, for environments where
+// the interface list does not show every usable address.
+func WithoutLocalAddrCheck() Option {
+	return clientOption(func(c *Client) error {
+		c.skipLocalAddrCheck = true
+		return nil
+	})
+}
+
+// configureLocalAddr binds the client's connections to the local address,
+// if one was set
+func (c *Client) configureLocalAddr() error {
+	if c.localAddr == nil {
+		return nil
+	}
+	if !c.skipLocalAddrCheck {
+		assigned, err := isLocalAddr(c.localAddr)
+		if err != nil {
+			return NewValidationError("failed to list local interface addresses", err)
+		}
+		if !assigned {
+			return NewValidationError("local address "+c.localAddr.String()+" is not assigned to any interface", nil)
+		}
+	}
+
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.DialContext = c.dialer().DialContext
+	c.httpClient.Transport = transport
+	return nil
+}
+
+// dialer returns a dialer for connections to the API, matching the
+// defaults of http.DefaultTransport
+func (c *Client) dialer() *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if c.localAddr != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: c.localAddr}
+	}
+	return dialer
+}
+
+// isLocalAddr reports whether ip is assigned to a local interface
+func isLocalAddr(ip net.IP) (bool, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false, err
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,97 @@
+package mailnow
+
+import "strings"
+
+// ProviderRule describes how a mail provider maps addresses to mailboxes
+type ProviderRule struct {
+	// IgnoreDots removes dots from the local part
+	IgnoreDots bool
+
+	// StripPlusTags removes everything from the first '+' in the local part
+	StripPlusTags bool
+
+	// CaseInsensitive lowercases the local part
+	CaseInsensitive bool
+
+	// Domain replaces the domain when set, for providers with several
+	// domains for the same mailboxes
+	Domain string
+}
+
+// ProviderRules maps lowercase domains to their provider rule. Domains
+// without a rule only have their domain lowercased.
+type ProviderRules map[string]ProviderRule
+
+var (
+	// GmailRule is the rule for Gmail, which ignores dots and plus tags
+	GmailRule = ProviderRule{IgnoreDots: true, StripPlusTags: true, CaseInsensitive: true, Domain: "gmail.com"}
+
+	// OutlookRule is the rule for Outlook, which ignores plus tags but not
+	// dots
+	OutlookRule = ProviderRule{StripPlusTags: true, CaseInsensitive: true}
+)
+
+// DefaultProviderRules returns the built-in rules for Gmail and Outlook
+// domains. The result is a new map that can be extended with rules for
+// other domains, such as a corporate domain.
+func DefaultProviderRules() ProviderRules {
+	return ProviderRules{
+		"gmail.com":      GmailRule,
+		"googlemail.com": GmailRule,
+		"outlook.com":    OutlookRule,
+		"hotmail.com":    OutlookRule,
+		"live.com":       OutlookRule,
+		"msn.com":        OutlookRule,
+	}
+}
+
+// CanonicalizeEmail returns the canonical form of email under rules, so
+// that addresses delivered to the same mailbox compare equal. For example,
+// with the default rules "John.Doe+promo@Gmail.com" becomes
+// "johndoe@gmail.com". A nil rules uses DefaultProviderRules.
+func CanonicalizeEmail(email string, rules ProviderRules) (string, error) {
+	if err := ValidateEmailAddress(email); err != nil {
+		return "", err
+	}
+	if rules == nil {
+		rules = DefaultProviderRules()
+	}
+
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+
+	rule := rules[domain]
+	if rule.StripPlusTags {
+		if i := strings.IndexByte(local, '+'); i >= 0 {
+			local = local[:i]
+		}
+	}
+	if rule.IgnoreDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if rule.CaseInsensitive {
+		local = strings.ToLower(local)
+	}
+	if rule.Domain != "" {
+		domain = strings.ToLower(rule.Domain)
+	}
+	if local == "" {
+		return "", NewValidationError("email address has an empty mailbox: "+email, nil)
+	}
+
+	return local + "@" + domain, nil
+}
+
+// SameMailbox reports whether a and b are delivered to the same mailbox
+// under DefaultProviderRules. Invalid addresses never match.
+func SameMailbox(a, b string) bool {
+	ca, err := CanonicalizeEmail(a, nil)
+	if err != nil {
+		return false
+	}
+	cb, err := CanonicalizeEmail(b, nil)
+	if err != nil {
+		return false
+	}
+	return ca == cb
+}
@@ -0,0 +1,54 @@
+package mailnowtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// Matcher reports whether a recorded request is the expected one
+type Matcher func(req *mailnow.EmailRequest) bool
+
+// Equal returns a Matcher that matches requests with no differences from
+// want according to DiffRequests and opts.
+func Equal(want *mailnow.EmailRequest, opts ...DiffOption) Matcher {
+	return func(req *mailnow.EmailRequest) bool {
+		return DiffRequests(want, req, opts...) == ""
+	}
+}
+
+// SentTo returns a Matcher that matches requests to the given recipient
+func SentTo(to string) Matcher {
+	return func(req *mailnow.EmailRequest) bool {
+		return req.To == to
+	}
+}
+
+// AssertSent fails t unless server recorded at least one request matched
+// by match. The failure message summarizes every recorded request.
+func AssertSent(t testing.TB, server *Server, match Matcher) {
+	t.Helper()
+
+	requests := server.Requests()
+	for _, req := range requests {
+		if match(req) {
+			return
+		}
+	}
+
+	var b strings.Builder
+	for _, req := range requests {
+		b.WriteString("\n\t" + summarize(req))
+	}
+	if len(requests) == 0 {
+		t.Errorf("no matching email sent: server recorded no requests")
+		return
+	}
+	t.Errorf("no matching email sent: server recorded %d requests:%s", len(requests), b.String())
+}
+
+// summarize describes a request in one line
+func summarize(req *mailnow.EmailRequest) string {
+	return req.From + " -> " + req.To + ": " + req.Subject
+}
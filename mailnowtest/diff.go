@@ -0,0 +1,224 @@
+package mailnowtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// DiffOption configures DiffRequests
+type DiffOption func(o *diffOptions)
+
+// diffOptions holds the settings collected from DiffOptions
+type diffOptions struct {
+	// ignore holds the field paths to skip, such as "Attachments.URL"
+	ignore map[string]bool
+
+	// normalizeHTML collapses whitespace in HTML before comparing
+	normalizeHTML bool
+
+	// hashAttachments compares attachment content by SHA-256 hash
+	hashAttachments bool
+}
+
+// IgnoreFields skips the named fields when comparing. Fields are named by
+// their Go path from EmailRequest without slice indexes, for example
+// "CampaignID" or "Attachments.ContentType". Use it for volatile fields
+// such as generated IDs.
+func IgnoreFields(paths ...string) DiffOption {
+	return func(o *diffOptions) {
+		for _, path := range paths {
+			o.ignore[path] = true
+		}
+	}
+}
+
+// NormalizeHTMLWhitespace compares HTML bodies after collapsing runs of
+// whitespace and removing whitespace between tags, so that differences in
+// indentation or line breaks are not reported.
+func NormalizeHTMLWhitespace() DiffOption {
+	return func(o *diffOptions) {
+		o.normalizeHTML = true
+	}
+}
+
+// CompareAttachmentsByHash compares inline attachment content by its
+// SHA-256 hash and reports the hashes rather than the base64 content.
+func CompareAttachmentsByHash() DiffOption {
+	return func(o *diffOptions) {
+		o.hashAttachments = true
+	}
+}
+
+// DiffRequests returns a readable field-by-field description of the
+// differences between want and got, one line per differing field, or ""
+// when they are equal.
+func DiffRequests(want, got *mailnow.EmailRequest, opts ...DiffOption) string {
+	o := &diffOptions{ignore: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	switch {
+	case want == nil && got == nil:
+		return ""
+	case want == nil:
+		return "EmailRequest: want nil, got non-nil\n"
+	case got == nil:
+		return "EmailRequest: want non-nil, got nil\n"
+	}
+
+	d := &differ{opts: o}
+	d.diff("", "", reflect.ValueOf(o.normalize(*want)), reflect.ValueOf(o.normalize(*got)))
+	return d.out.String()
+}
+
+// normalize returns a copy of req with the configured normalizations applied
+func (o *diffOptions) normalize(req mailnow.EmailRequest) mailnow.EmailRequest {
+	if o.normalizeHTML {
+		req.HTML = normalizeHTML(req.HTML)
+	}
+	if o.hashAttachments && req.Attachments != nil {
+		attachments := make([]mailnow.Attachment, len(req.Attachments))
+		for i, a := range req.Attachments {
+			if a.Content != "" {
+				sum := sha256.Sum256([]byte(a.Content))
+				a.Content = "sha256:" + hex.EncodeToString(sum[:])
+			}
+			attachments[i] = a
+		}
+		req.Attachments = attachments
+	}
+	return req
+}
+
+var (
+	// whitespaceRegex matches runs of whitespace
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+
+	// interTagSpaceRegex matches whitespace between two tags
+	interTagSpaceRegex = regexp.MustCompile(`>\s+<`)
+)
+
+// normalizeHTML collapses whitespace in an HTML body
+func normalizeHTML(html string) string {
+	html = interTagSpaceRegex.ReplaceAllString(html, "><")
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(html, " "))
+}
+
+// timeType is the reflect type of time.Time, which is compared with Equal
+var timeType = reflect.TypeOf(time.Time{})
+
+// differ accumulates differences between two values
+type differ struct {
+	opts *diffOptions
+	out  strings.Builder
+}
+
+// diff compares want and got, which have the same type. path is the
+// display path including slice indexes and field is the path used to
+// match ignored fields.
+func (d *differ) diff(path, field string, want, got reflect.Value) {
+	if d.opts.ignore[field] {
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Struct:
+		if want.Type() == timeType {
+			if !want.Interface().(time.Time).Equal(got.Interface().(time.Time)) {
+				d.report(path, want, got)
+			}
+			return
+		}
+		for i := 0; i < want.NumField(); i++ {
+			f := want.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			d.diff(join(path, f.Name), join(field, f.Name), want.Field(i), got.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		n := want.Len()
+		if got.Len() > n {
+			n = got.Len()
+		}
+		for i := 0; i < n; i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= want.Len():
+				d.out.WriteString(fmt.Sprintf("%s: unexpected %s\n", elemPath, format(got.Index(i))))
+			case i >= got.Len():
+				d.out.WriteString(fmt.Sprintf("%s: missing %s\n", elemPath, format(want.Index(i))))
+			default:
+				d.diff(elemPath, field, want.Index(i), got.Index(i))
+			}
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(want.MapKeys(), got.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			k := keys[name]
+			elemPath := fmt.Sprintf("%s[%q]", path, name)
+			w, g := want.MapIndex(k), got.MapIndex(k)
+			switch {
+			case !w.IsValid():
+				d.out.WriteString(fmt.Sprintf("%s: unexpected %s\n", elemPath, format(g)))
+			case !g.IsValid():
+				d.out.WriteString(fmt.Sprintf("%s: missing %s\n", elemPath, format(w)))
+			default:
+				d.diff(elemPath, field, w, g)
+			}
+		}
+	case reflect.Pointer, reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				d.report(path, want, got)
+			}
+			return
+		}
+		d.diff(path, field, want.Elem(), got.Elem())
+	default:
+		if !reflect.DeepEqual(want.Interface(), got.Interface()) {
+			d.report(path, want, got)
+		}
+	}
+}
+
+// report records a difference at path
+func (d *differ) report(path string, want, got reflect.Value) {
+	d.out.WriteString(fmt.Sprintf("%s: want %s, got %s\n", path, format(want), format(got)))
+}
+
+// join appends a field name to a path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// format renders a value for a diff line, quoting strings
+func format(v reflect.Value) string {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "nil"
+	}
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
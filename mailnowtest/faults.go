@@ -0,0 +1,188 @@
+package mailnowtest
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// faults holds the fault injection settings of a Server
+type faults struct {
+	latency time.Duration
+	jitter  time.Duration
+
+	failRate   float64
+	failStatus int
+
+	dropRate float64
+
+	bytesPerSecond int
+}
+
+// sampledFaults are the faults chosen for a single request
+type sampledFaults struct {
+	latency        time.Duration
+	drop           bool
+	failStatus     int
+	bytesPerSecond int
+}
+
+// Latency delays every response by d plus a uniformly random extra delay
+// of up to jitter. Zero values disable the delay.
+func (s *Server) Latency(d, jitter time.Duration) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.latency, s.faults.jitter = d, jitter
+	return s
+}
+
+// FailRate makes each request fail with probability p with the given
+// status code and an API error body. A p of 0 disables failures.
+func (s *Server) FailRate(p float64, status int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.failRate, s.faults.failStatus = p, status
+	return s
+}
+
+// DropConnections makes each request fail with probability p by closing
+// the connection without responding. A p of 0 disables drops.
+func (s *Server) DropConnections(p float64) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.dropRate = p
+	return s
+}
+
+// SlowBody writes response bodies at about bytesPerSecond. Zero disables
+// the limit.
+func (s *Server) SlowBody(bytesPerSecond int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults.bytesPerSecond = bytesPerSecond
+	return s
+}
+
+// Seed reseeds the random source used to inject faults, making the
+// sequence of faults reproducible for a given sequence of requests.
+func (s *Server) Seed(seed int64) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rng = rand.New(rand.NewSource(seed))
+	return s
+}
+
+// ResetFaults disables all fault injection
+func (s *Server) ResetFaults() *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults{}
+	return s
+}
+
+// Scenario is a preset combination of faults
+type Scenario func(s *Server)
+
+// Apply applies scenarios to the server in order
+func (s *Server) Apply(scenarios ...Scenario) *Server {
+	for _, scenario := range scenarios {
+		scenario(s)
+	}
+	return s
+}
+
+// RateLimitStorm rejects most requests with HTTP 429, as when many
+// clients share an exhausted quota
+func RateLimitStorm() Scenario {
+	return func(s *Server) {
+		s.FailRate(0.8, http.StatusTooManyRequests)
+	}
+}
+
+// Rolling502s fails a share of requests with HTTP 502 and adds latency,
+// as when an upstream deploy is rolling through the API's backends
+func Rolling502s() Scenario {
+	return func(s *Server) {
+		s.FailRate(0.3, http.StatusBadGateway)
+		s.Latency(5*time.Millisecond, 10*time.Millisecond)
+	}
+}
+
+// sampleFaults chooses the faults to inject into one request
+func (s *Server) sampleFaults() sampledFaults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := sampledFaults{
+		latency:        s.faults.latency,
+		bytesPerSecond: s.faults.bytesPerSecond,
+	}
+	if s.faults.jitter > 0 {
+		f.latency += time.Duration(s.rng.Int63n(int64(s.faults.jitter) + 1))
+	}
+	if s.faults.dropRate > 0 && s.rng.Float64() < s.faults.dropRate {
+		f.drop = true
+	}
+	if s.faults.failRate > 0 && s.rng.Float64() < s.faults.failRate {
+		f.failStatus = s.faults.failStatus
+	}
+	return f
+}
+
+// faultCode returns the API error code for an injected failure status
+func faultCode(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rate_limited"
+	case status >= 500:
+		return "server_error"
+	default:
+		return "injected_fault"
+	}
+}
+
+// dropConnection closes the connection without writing a response
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("mailnowtest: response writer does not support dropping connections")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic("mailnowtest: failed to drop connection: " + err.Error())
+	}
+	conn.Close()
+}
+
+// writeBody writes a response, at about bytesPerSecond when it is positive
+func writeBody(w http.ResponseWriter, statusCode int, body []byte, bytesPerSecond int) {
+	w.WriteHeader(statusCode)
+	if bytesPerSecond <= 0 {
+		w.Write(body)
+		return
+	}
+
+	// Write in chunks ten times a second
+	flusher, _ := w.(http.Flusher)
+	chunk := bytesPerSecond / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	interval := time.Duration(chunk) * time.Second / time.Duration(bytesPerSecond)
+	for len(body) > 0 {
+		n := chunk
+		if n > len(body) {
+			n = len(body)
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+		if len(body) > 0 {
+			time.Sleep(interval)
+		}
+	}
+}
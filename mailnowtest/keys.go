@@ -0,0 +1,29 @@
+package mailnowtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// TestAPIKey returns a random, syntactically valid test API key
+func TestAPIKey() string {
+	return mailnow.APIKeyPrefixTest + randomSecret()
+}
+
+// LiveAPIKey returns a random, syntactically valid live API key. It is
+// meant for negative tests, such as checking that code refuses to run
+// with live credentials; the key is not accepted by the API.
+func LiveAPIKey() string {
+	return mailnow.APIKeyPrefixLive + randomSecret()
+}
+
+// randomSecret returns 32 random hex characters, the format of real keys
+func randomSecret() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("mailnowtest: failed to generate API key: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,119 @@
+package mailnowtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// NoSendsOption configures ExpectNoSends
+type NoSendsOption func(*noSends)
+
+// CaptureStacks records the stack of every SendEmail call that reaches the
+// server, so that the failure shows where the unexpected send came from.
+// It uses mailnow.WithSendTrace, replacing a send trace callback passed
+// with ClientOptions.
+func CaptureStacks() NoSendsOption {
+	return func(n *noSends) {
+		n.captureStacks = true
+	}
+}
+
+// ClientOptions configures the client returned by ExpectNoSends, so that
+// it can mirror the client used in production
+func ClientOptions(opts ...mailnow.Option) NoSendsOption {
+	return func(n *noSends) {
+		n.clientOpts = append(n.clientOpts, opts...)
+	}
+}
+
+// noSends is the state of an ExpectNoSends assertion
+type noSends struct {
+	captureStacks bool
+	clientOpts    []mailnow.Option
+
+	mu     sync.Mutex
+	stacks []string
+}
+
+// ExpectNoSends returns a client that must not send any email. It is backed
+// by a fake server that accepts every send, so the code under test runs
+// its success path, and when the test finishes any email received fails t
+// with a dump of each request.
+//
+// The assertion is registered with t.Cleanup and runs without any further
+// call:
+//
+//	client := mailnowtest.ExpectNoSends(t, mailnowtest.CaptureStacks())
+//	svc := NewSignupService(client)
+//	svc.Register(ctx, userWithEmailOptOut) // must not send
+func ExpectNoSends(t testing.TB, opts ...NoSendsOption) *mailnow.Client {
+	t.Helper()
+
+	n := &noSends{}
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	server := NewServer()
+	clientOpts := append([]mailnow.Option{mailnow.WithBaseURL(server.URL)}, n.clientOpts...)
+	if n.captureStacks {
+		clientOpts = append(clientOpts, mailnow.WithSendTrace(n.recordStack))
+	}
+
+	client, err := mailnow.NewClient(TestAPIKey(), clientOpts...)
+	if err != nil {
+		server.Close()
+		t.Fatalf("mailnowtest: failed to create client: %v", err)
+	}
+
+	t.Cleanup(func() {
+		server.Close()
+		n.check(t, server.Requests())
+	})
+	return client
+}
+
+// recordStack records the stack of a send that reached the server. The
+// trace callback runs on the goroutine that called SendEmail.
+func (n *noSends) recordStack(trace mailnow.SendTrace) {
+	for _, step := range trace {
+		if step.Kind == mailnow.StepAttempt {
+			stack := string(debug.Stack())
+			n.mu.Lock()
+			n.stacks = append(n.stacks, stack)
+			n.mu.Unlock()
+			return
+		}
+	}
+}
+
+// check fails t if any requests were received
+func (n *noSends) check(t testing.TB, requests []*mailnow.EmailRequest) {
+	t.Helper()
+	if len(requests) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	for i, req := range requests {
+		fmt.Fprintf(&b, "\n\nemail %d: %s", i+1, summarize(req))
+		if dump, err := json.MarshalIndent(req, "\t", "  "); err == nil {
+			b.WriteString("\n\t" + string(dump))
+		}
+	}
+
+	n.mu.Lock()
+	stacks := n.stacks
+	n.mu.Unlock()
+	for i, stack := range stacks {
+		fmt.Fprintf(&b, "\n\nSendEmail call %d:\n%s", i+1, strings.TrimRight(stack, "\n"))
+	}
+
+	t.Errorf("expected no emails to be sent, but %d were:%s", len(requests), b.String())
+}
@@ -0,0 +1,246 @@
+package mailnowtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// PreviewMessage is an email received by a PreviewServer
+type PreviewMessage struct {
+	ID         string                `json:"id"`
+	ReceivedAt time.Time             `json:"received_at"`
+	Request    *mailnow.EmailRequest `json:"request"`
+}
+
+// PreviewServer is a development server that implements the Mailnow send
+// endpoint and shows the emails it receives instead of delivering them.
+//
+// Point a client at it with mailnow.WithBaseURL(server.URL()) and open the
+// same URL in a browser to list the received emails, view their rendered
+// HTML and headers, and download their attachments. The same data is
+// available as JSON under /api/messages.
+type PreviewServer struct {
+	listener net.Listener
+	server   *http.Server
+
+	mu       sync.Mutex
+	messages []*PreviewMessage
+}
+
+// NewPreviewServer starts a preview server listening on addr, such as
+// "localhost:8025". Use "127.0.0.1:0" to pick a free port.
+func NewPreviewServer(addr string) (*PreviewServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("mailnowtest: failed to listen on %s: %w", addr, err)
+	}
+
+	p := &PreviewServer{listener: listener}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	go p.server.Serve(listener)
+	return p, nil
+}
+
+// URL returns the base URL of the server
+func (p *PreviewServer) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// Close shuts the server down
+func (p *PreviewServer) Close() error {
+	return p.server.Close()
+}
+
+// Messages returns the emails received so far, in order
+func (p *PreviewServer) Messages() []*PreviewMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messages := make([]*PreviewMessage, len(p.messages))
+	copy(messages, p.messages)
+	return messages
+}
+
+// message returns the email with the given ID
+func (p *PreviewServer) message(id string) (*PreviewMessage, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, m := range p.messages {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// ServeHTTP routes requests to the send endpoint, the JSON API and the UI
+func (p *PreviewServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == mailnow.EmailSendEndpoint:
+		p.handleSend(w, r)
+	case r.Method != http.MethodGet:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	case path == "":
+		p.handleIndex(w)
+	case path == "api/messages":
+		writeJSON(w, p.Messages())
+	case len(parts) == 3 && parts[0] == "api" && parts[1] == "messages":
+		if m, ok := p.message(parts[2]); ok {
+			writeJSON(w, m)
+		} else {
+			http.NotFound(w, r)
+		}
+	case len(parts) >= 2 && parts[0] == "messages":
+		p.handleMessage(w, r, parts[1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleSend stores a sent email
+func (p *PreviewServer) handleSend(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req mailnow.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", "invalid JSON body: "+err.Error(), 0)
+		return
+	}
+
+	p.mu.Lock()
+	m := &PreviewMessage{
+		ID:         fmt.Sprintf("%s%d", mailnow.MessageIDPrefix, len(p.messages)+1),
+		ReceivedAt: time.Now(),
+		Request:    &req,
+	}
+	p.messages = append(p.messages, m)
+	p.mu.Unlock()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"success":     true,
+		"status_code": http.StatusOK,
+		"message":     "Email captured by preview server",
+		"data": mailnow.Data{
+			MessageID: m.ID,
+			Status:    "queued",
+		},
+	})
+	writeBody(w, http.StatusOK, body, 0)
+}
+
+// handleMessage serves /messages/{id}, its HTML body and its attachments
+func (p *PreviewServer) handleMessage(w http.ResponseWriter, r *http.Request, parts []string) {
+	m, ok := p.message(parts[0])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		messageTemplate.Execute(w, m)
+	case len(parts) == 2 && parts[1] == "html":
+		// Sandbox the email so its scripts cannot reach the preview UI
+		w.Header().Set("Content-Security-Policy", "sandbox")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(m.Request.HTML))
+	case len(parts) == 3 && parts[1] == "attachments":
+		i, err := strconv.Atoi(parts[2])
+		if err != nil || i < 0 || i >= len(m.Request.Attachments) {
+			http.NotFound(w, r)
+			return
+		}
+		serveAttachment(w, r, m.Request.Attachments[i])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleIndex serves the list of received emails
+func (p *PreviewServer) handleIndex(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	indexTemplate.Execute(w, p.Messages())
+}
+
+// serveAttachment serves the content of an attachment for download
+func serveAttachment(w http.ResponseWriter, r *http.Request, a mailnow.Attachment) {
+	switch {
+	case a.Content != "":
+		content, err := base64.StdEncoding.DecodeString(a.Content)
+		if err != nil {
+			content, err = base64.URLEncoding.DecodeString(a.Content)
+		}
+		if err != nil {
+			http.Error(w, "attachment content is not valid base64", http.StatusUnprocessableEntity)
+			return
+		}
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", a.Filename))
+		w.Write(content)
+	case a.URL != "":
+		http.Redirect(w, r, a.URL, http.StatusFound)
+	default:
+		http.Error(w, "attachment "+a.AssetID+" is an uploaded asset and has no local content", http.StatusNotFound)
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Mailnow preview</title></head>
+<body>
+<h1>Mailnow preview</h1>
+{{if not .}}<p>No emails received yet.</p>{{else}}
+<table>
+<tr><th>Received</th><th>From</th><th>To</th><th>Subject</th></tr>
+{{range .}}<tr>
+<td>{{.ReceivedAt.Format "15:04:05"}}</td>
+<td>{{.Request.From}}</td>
+<td>{{.Request.To}}</td>
+<td><a href="/messages/{{.ID}}">{{.Request.Subject}}</a></td>
+</tr>{{end}}
+</table>{{end}}
+</body></html>
+`))
+
+var messageTemplate = template.Must(template.New("message").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Request.Subject}}</title></head>
+<body>
+<p><a href="/">All emails</a></p>
+<h1>{{.Request.Subject}}</h1>
+<table>
+<tr><th>From</th><td>{{.Request.From}}</td></tr>
+<tr><th>To</th><td>{{.Request.To}}</td></tr>
+{{range .Request.ReplyTo}}<tr><th>Reply-To</th><td>{{.}}</td></tr>{{end}}
+{{with .Request.CampaignID}}<tr><th>Campaign</th><td>{{.}}</td></tr>{{end}}
+{{range $name, $value := .Request.Headers}}<tr><th>{{$name}}</th><td>{{$value}}</td></tr>{{end}}
+</table>
+{{with .Request.Attachments}}<h2>Attachments</h2>
+<ul>{{range $i, $a := .}}<li><a href="/messages/{{$.ID}}/attachments/{{$i}}">{{$a.Filename}}</a></li>{{end}}</ul>{{end}}
+<h2>HTML</h2>
+<iframe src="/messages/{{.ID}}/html" style="width: 100%; height: 600px; border: 1px solid #ccc"></iframe>
+</body></html>
+`))
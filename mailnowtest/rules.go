@@ -0,0 +1,167 @@
+package mailnowtest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// Behavior describes how a Server responds to a send matched by a rule
+type Behavior struct {
+	// Delay is waited before responding
+	Delay time.Duration
+
+	// StatusCode is the error status to respond with; zero responds with
+	// success
+	StatusCode int
+
+	// ErrorCode and Message make up the API error body of an error response
+	ErrorCode string
+	Message   string
+
+	// RetryAfter sets the Retry-After header of an error response
+	RetryAfter time.Duration
+
+	// DropConnection closes the connection without responding
+	DropConnection bool
+
+	// Data is returned for a successful send; the message ID is generated
+	// when empty
+	Data *mailnow.Data
+}
+
+// Respond returns a Behavior that accepts the send and returns data
+func Respond(data mailnow.Data) Behavior {
+	return Behavior{Data: &data}
+}
+
+// Fail returns a Behavior that rejects the send with an API error
+func Fail(status int, code, message string) Behavior {
+	return Behavior{StatusCode: status, ErrorCode: code, Message: message}
+}
+
+// Delay returns a Behavior that accepts the send after waiting for d
+func Delay(d time.Duration) Behavior {
+	return Behavior{Delay: d}
+}
+
+// FailWith returns a Behavior whose response makes the client return an
+// error of the same type as err: ValidationError, AuthError,
+// RateLimitError, MaintenanceError, ServerError, DuplicateSendError or
+// ConnectionError. Other errors are reported as server errors.
+func FailWith(err error) Behavior {
+	var (
+		validationErr  *mailnow.ValidationError
+		authErr        *mailnow.AuthError
+		rateLimitErr   *mailnow.RateLimitError
+		maintenanceErr *mailnow.MaintenanceError
+		duplicateErr   *mailnow.DuplicateSendError
+		connErr        *mailnow.ConnectionError
+	)
+	message := err.Error()
+	switch {
+	case errors.As(err, &validationErr):
+		return Fail(http.StatusBadRequest, "validation_error", message)
+	case errors.As(err, &authErr):
+		return Fail(http.StatusUnauthorized, "auth_error", message)
+	case errors.As(err, &rateLimitErr):
+		return Fail(http.StatusTooManyRequests, "rate_limited", message)
+	case errors.As(err, &maintenanceErr):
+		b := Fail(http.StatusServiceUnavailable, "maintenance", message)
+		b.RetryAfter = maintenanceErr.RetryAfter
+		return b
+	case errors.As(err, &duplicateErr):
+		return Fail(http.StatusConflict, "duplicate_send", message)
+	case errors.As(err, &connErr):
+		return Behavior{DropConnection: true}
+	default:
+		return Fail(http.StatusInternalServerError, "server_error", message)
+	}
+}
+
+// rule pairs a matcher with the behavior for the sends it matches
+type rule struct {
+	match    Matcher
+	behavior Behavior
+}
+
+// AddRule makes the server respond with behavior to sends matched by
+// match. Rules are checked in the order they were added and the first
+// match wins; sends matching no rule succeed. Rules can be added while the
+// server is running.
+func (s *Server) AddRule(match Matcher, behavior Behavior) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule{match: match, behavior: behavior})
+	return s
+}
+
+// ClearRules removes all rules
+func (s *Server) ClearRules() *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = nil
+	return s
+}
+
+// matchRule returns the behavior of the first rule matching req
+func (s *Server) matchRule(req *mailnow.EmailRequest) (Behavior, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.rules {
+		if r.match(req) {
+			return r.behavior, true
+		}
+	}
+	return Behavior{}, false
+}
+
+// RecipientDomain returns a Matcher that matches sends to addresses at
+// domain, such as "bounce.test"
+func RecipientDomain(domain string) Matcher {
+	suffix := "@" + strings.ToLower(domain)
+	return func(req *mailnow.EmailRequest) bool {
+		return strings.HasSuffix(strings.ToLower(req.To), suffix)
+	}
+}
+
+// SubjectContains returns a Matcher that matches sends whose subject
+// contains s
+func SubjectContains(s string) Matcher {
+	return func(req *mailnow.EmailRequest) bool {
+		return strings.Contains(req.Subject, s)
+	}
+}
+
+// HasHeader returns a Matcher that matches sends with the named custom
+// header, ignoring case
+func HasHeader(name string) Matcher {
+	return func(req *mailnow.EmailRequest) bool {
+		for k := range req.Headers {
+			if strings.EqualFold(k, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InCampaign returns a Matcher that matches sends in the given campaign
+func InCampaign(campaignID string) Matcher {
+	return func(req *mailnow.EmailRequest) bool {
+		return req.CampaignID == campaignID
+	}
+}
+
+// writeBehaviorError writes the error response of b
+func writeBehaviorError(w http.ResponseWriter, b Behavior, bytesPerSecond int) {
+	if b.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((b.RetryAfter+time.Second-1)/time.Second)))
+	}
+	writeError(w, b.StatusCode, b.ErrorCode, b.Message, bytesPerSecond)
+}
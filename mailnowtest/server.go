@@ -0,0 +1,139 @@
+// Package mailnowtest provides utilities for testing code that sends email
+// with the Mailnow SDK.
+//
+// Server is a fake Mailnow API that records the emails sent to it:
+//
+//	server := mailnowtest.NewServer()
+//	defer server.Close()
+//
+//	client, _ := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+//	// ... exercise code that sends email with client ...
+//
+//	mailnowtest.AssertSent(t, server, mailnowtest.Equal(want))
+package mailnowtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// Server is a fake Mailnow API server that accepts every valid send and
+// records the decoded requests. Faults can be injected to simulate an
+// unreliable API; see Latency, FailRate, DropConnections and SlowBody.
+// Rules added with AddRule make specific sends behave differently.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*mailnow.EmailRequest
+	faults   faults
+	rng      *rand.Rand
+	rules    []rule
+}
+
+// NewServer starts a fake Mailnow API server. The caller should call Close
+// when finished to shut it down.
+func NewServer() *Server {
+	s := &Server{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Requests returns the email requests received so far, in order
+func (s *Server) Requests() []*mailnow.EmailRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]*mailnow.EmailRequest, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// handle serves the send endpoint
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Inject faults
+	f := s.sampleFaults()
+	if f.latency > 0 {
+		select {
+		case <-time.After(f.latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if f.drop {
+		dropConnection(w)
+		return
+	}
+	if f.failStatus != 0 {
+		writeError(w, f.failStatus, faultCode(f.failStatus), "injected fault", f.bytesPerSecond)
+		return
+	}
+
+	if r.Method != http.MethodPost || r.URL.Path != mailnow.EmailSendEndpoint {
+		writeError(w, http.StatusNotFound, "not_found", "unknown endpoint "+r.Method+" "+r.URL.Path, f.bytesPerSecond)
+		return
+	}
+
+	var req mailnow.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", "invalid JSON body: "+err.Error(), f.bytesPerSecond)
+		return
+	}
+
+	// Apply the first matching rule
+	behavior, _ := s.matchRule(&req)
+	if behavior.Delay > 0 {
+		select {
+		case <-time.After(behavior.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if behavior.DropConnection {
+		dropConnection(w)
+		return
+	}
+	if behavior.StatusCode != 0 {
+		writeBehaviorError(w, behavior, f.bytesPerSecond)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, &req)
+	messageID := fmt.Sprintf("%s%d", mailnow.MessageIDPrefix, len(s.requests))
+	s.mu.Unlock()
+
+	data := mailnow.Data{MessageID: messageID, Status: "queued"}
+	if behavior.Data != nil {
+		data = *behavior.Data
+		if data.MessageID == "" {
+			data.MessageID = messageID
+		}
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"success":     true,
+		"status_code": http.StatusOK,
+		"message":     "Email queued",
+		"data":        data,
+	})
+	writeBody(w, http.StatusOK, body, f.bytesPerSecond)
+}
+
+// writeError writes an API error response
+func writeError(w http.ResponseWriter, statusCode int, code, message string, bytesPerSecond int) {
+	var resp mailnow.ErrorResponse
+	resp.Error.Code = code
+	resp.Error.Message = message
+
+	body, _ := json.Marshal(resp)
+	writeBody(w, statusCode, body, bytesPerSecond)
+}
@@ -0,0 +1,230 @@
+package mailnow
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownOptions configures RenderMarkdown
+type MarkdownOptions struct {
+	// AllowRawHTML passes HTML written in the Markdown through unchanged.
+	// By default it is escaped and shows as text.
+	AllowRawHTML bool
+}
+
+var (
+	// markdownHeadingRegex matches an ATX heading
+	markdownHeadingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+
+	// markdownRuleRegex matches a thematic break
+	markdownRuleRegex = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+
+	// markdownListItemRegex matches a bullet or ordered list item
+	markdownListItemRegex = regexp.MustCompile(`^(?:([-*+])|(\d{1,9})[.)])\s+(.*)$`)
+
+	// markdownLinkRegex matches an inline link at the start of the text,
+	// allowing balanced parentheses in the URL
+	markdownLinkRegex = regexp.MustCompile(`^\[([^\]]*)\]\(\s*((?:[^\s()]|\([^\s()]*\))*)(?:\s+"([^"]*)")?\s*\)`)
+)
+
+// RenderMarkdown converts a subset of CommonMark to HTML: ATX headings,
+// paragraphs, fenced code blocks, bullet and ordered lists without
+// nesting, block quotes, thematic breaks, and inline code, links, strong
+// emphasis and emphasis. Links with a javascript: URL are rendered as
+// plain text.
+func RenderMarkdown(markdown string, opts MarkdownOptions) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	var b strings.Builder
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderMarkdownInline(strings.Join(paragraph, "\n"), opts) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			class := ""
+			if lang := strings.Fields(trimmed[3:]); len(lang) > 0 {
+				class = ` class="language-` + html.EscapeString(lang[0]) + `"`
+			}
+			body := html.EscapeString(strings.Join(code, "\n"))
+			if len(code) > 0 {
+				body += "\n"
+			}
+			b.WriteString("<pre><code" + class + ">" + body + "</code></pre>\n")
+
+		case markdownHeadingRegex.MatchString(trimmed):
+			flush()
+			m := markdownHeadingRegex.FindStringSubmatch(trimmed)
+			level := len(m[1])
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, renderMarkdownInline(m[2], opts), level)
+
+		case markdownRuleRegex.MatchString(trimmed):
+			flush()
+			b.WriteString("<hr>\n")
+
+		case markdownListItemRegex.MatchString(trimmed):
+			flush()
+			i = renderMarkdownList(&b, lines, i, opts) - 1
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				line := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(line, " "))
+			}
+			i--
+			b.WriteString("<blockquote>\n" + RenderMarkdown(strings.Join(quoted, "\n"), opts) + "</blockquote>\n")
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return b.String()
+}
+
+// renderMarkdownList renders the list starting at lines[start] and returns
+// the index of the first line after it
+func renderMarkdownList(b *strings.Builder, lines []string, start int, opts MarkdownOptions) int {
+	first := markdownListItemRegex.FindStringSubmatch(strings.TrimSpace(lines[start]))
+	ordered := first[1] == ""
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if n, _ := strconv.Atoi(first[2]); n != 1 {
+			tag = fmt.Sprintf(`ol start="%d"`, n)
+		}
+	}
+	b.WriteString("<" + tag + ">\n")
+
+	var items [][]string
+	i := start
+lines:
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		m := markdownListItemRegex.FindStringSubmatch(trimmed)
+		switch {
+		case trimmed == "":
+			break lines
+		case m != nil && (m[1] == "") == ordered && !markdownRuleRegex.MatchString(trimmed):
+			items = append(items, []string{m[3]})
+		case m == nil && len(items) > 0 && lines[i] != trimmed:
+			// Indented continuation of the previous item
+			items[len(items)-1] = append(items[len(items)-1], trimmed)
+		default:
+			break lines
+		}
+	}
+	for _, item := range items {
+		b.WriteString("<li>" + renderMarkdownInline(strings.Join(item, "\n"), opts) + "</li>\n")
+	}
+	b.WriteString("</" + strings.Fields(tag)[0] + ">\n")
+	return i
+}
+
+// renderMarkdownInline renders the inline elements of text
+func renderMarkdownInline(text string, opts MarkdownOptions) string {
+	escape := html.EscapeString
+	if opts.AllowRawHTML {
+		escape = func(s string) string { return s }
+	}
+
+	var b strings.Builder
+	var prev byte
+	for len(text) > 0 {
+		// Underscores inside words do not start emphasis
+		intraword := text[0] == '_' && (isASCIIAlnum(prev) || prev == '_')
+		if out, n := renderMarkdownElement(text, opts); n > 0 && !intraword {
+			b.WriteString(out)
+			prev = text[n-1]
+			text = text[n:]
+			continue
+		}
+
+		// Copy plain text up to the next special character
+		n := strings.IndexAny(text[1:], "\\`[*_") + 1
+		if n == 0 {
+			n = len(text)
+		}
+		b.WriteString(escape(text[:n]))
+		prev = text[n-1]
+		text = text[n:]
+	}
+	return b.String()
+}
+
+// renderMarkdownElement renders the inline element at the start of text
+// and returns the number of bytes it spans, or zero if text does not
+// start with one
+func renderMarkdownElement(text string, opts MarkdownOptions) (string, int) {
+	switch c := text[0]; c {
+	case '\\':
+		if len(text) > 1 && strings.IndexByte("\\`*_[]()#+-.!<>", text[1]) >= 0 {
+			return html.EscapeString(text[1:2]), 2
+		}
+
+	case '`':
+		ticks := len(text) - len(strings.TrimLeft(text, "`"))
+		if end := strings.Index(text[ticks:], text[:ticks]); end >= 0 {
+			code := strings.TrimSpace(text[ticks : ticks+end])
+			return "<code>" + html.EscapeString(code) + "</code>", ticks + end + ticks
+		}
+
+	case '[':
+		m := markdownLinkRegex.FindStringSubmatch(text)
+		if m == nil {
+			break
+		}
+		label := renderMarkdownInline(m[1], opts)
+		if strings.HasPrefix(strings.ToLower(m[2]), "javascript:") {
+			return label, len(m[0])
+		}
+		link := `<a href="` + html.EscapeString(m[2]) + `"`
+		if m[3] != "" {
+			link += ` title="` + html.EscapeString(m[3]) + `"`
+		}
+		return link + ">" + label + "</a>", len(m[0])
+
+	case '*', '_':
+		for _, delim := range []string{strings.Repeat(string(c), 2), string(c)} {
+			if !strings.HasPrefix(text, delim) || len(text) == len(delim) || text[len(delim)] == ' ' {
+				continue
+			}
+			end := strings.Index(text[len(delim):], delim)
+			if end <= 0 {
+				continue
+			}
+			inner := renderMarkdownInline(text[len(delim):len(delim)+end], opts)
+			if len(delim) == 2 {
+				return "<strong>" + inner + "</strong>", end + 2*len(delim)
+			}
+			return "<em>" + inner + "</em>", end + 2*len(delim)
+		}
+	}
+	return "", 0
+}
+
+// isASCIIAlnum reports whether c is an ASCII letter or digit
+func isASCIIAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
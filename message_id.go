@@ -0,0 +1,43 @@
+package mailnow
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// messageIDLocalPartRegex matches a dot-atom local part (RFC 5322)
+var messageIDLocalPartRegex = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+/=?^_`{|}~-]+(\\.[a-zA-Z0-9!#$%&'*+/=?^_`{|}~-]+)*$")
+
+// ValidateCustomMessageID validates a custom Message-ID for an email sent
+// from the address from. The ID is written without angle brackets as
+// local@domain, where local is a dot-atom and domain is the domain of
+// from, which is the domain the account is verified to send from. IDs in
+// other domains are rejected so that they cannot look like another
+// sender's.
+func ValidateCustomMessageID(id, from string) error {
+	local, domain, ok := strings.Cut(id, "@")
+	if !ok || strings.Contains(domain, "@") {
+		return NewValidationError("custom message ID must have the form local@domain: "+id, nil)
+	}
+	if len(local) > 64 || !messageIDLocalPartRegex.MatchString(local) {
+		return NewValidationError("custom message ID local part must be a dot-atom of at most 64 characters: "+id, nil)
+	}
+
+	_, fromDomain, _ := strings.Cut(from, "@")
+	if fromDomain == "" || !strings.EqualFold(domain, fromDomain) {
+		return NewValidationError("custom message ID domain "+domain+" must match the sender domain "+fromDomain, nil)
+	}
+	return nil
+}
+
+// GetEmailByCustomID returns the email sent with the custom Message-ID id
+// set in EmailRequest.CustomMessageID.
+func (c *Client) GetEmailByCustomID(ctx context.Context, id string) (*EmailResponse, error) {
+	if id == "" {
+		return nil, NewValidationError("custom message ID is required", nil)
+	}
+
+	resp, _, err := c.requestAPI().GetEmailByCustomID(ctx, id)
+	return resp, err
+}
@@ -0,0 +1,84 @@
+package mailnow
+
+import "fmt"
+
+// MessageClass describes the kind of mail a request is, selecting the
+// headers that keep auto-responders and mailbox providers handling it
+// correctly; see MessageClassHeaders
+type MessageClass string
+
+// Message classes
+const (
+	// MessageClassTransactional is mail triggered by a user's action, such
+	// as a receipt or a password reset
+	MessageClassTransactional MessageClass = "transactional"
+
+	// MessageClassNotification is an automated alert or digest
+	MessageClassNotification MessageClass = "notification"
+
+	// MessageClassMarketing is promotional mail sent in bulk
+	MessageClassMarketing MessageClass = "marketing"
+)
+
+// messageClassHeaders are the headers sent for each message class
+var messageClassHeaders = map[MessageClass]map[string]string{
+	MessageClassTransactional: {
+		"Auto-Submitted": "auto-generated",
+	},
+	MessageClassNotification: {
+		"Auto-Submitted":           "auto-generated",
+		"X-Auto-Response-Suppress": "All",
+	},
+	MessageClassMarketing: {
+		"Auto-Submitted": "auto-generated",
+		"Precedence":     "bulk",
+	},
+}
+
+// MessageClassHeaders returns the headers sent for class:
+//
+//   - every class: Auto-Submitted: auto-generated (RFC 3834), so
+//     vacation responders and ticketing systems do not reply
+//   - MessageClassNotification: X-Auto-Response-Suppress: All, which
+//     Exchange honors in place of Auto-Submitted
+//   - MessageClassMarketing: Precedence: bulk
+//
+// It fails with a ValidationError for an unknown class.
+func MessageClassHeaders(class MessageClass) (map[string]string, error) {
+	headers, ok := messageClassHeaders[class]
+	if !ok {
+		return nil, NewValidationError(fmt.Sprintf("unknown message class %q", class), nil)
+	}
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+	return copied, nil
+}
+
+// WithDefaultMessageClass sets the message class of requests that do not
+// set EmailRequest.MessageClass.
+func WithDefaultMessageClass(class MessageClass) Option {
+	return clientOption(func(c *Client) error {
+		if _, err := MessageClassHeaders(class); err != nil {
+			return err
+		}
+		c.defaultMessageClass = class
+		return nil
+	})
+}
+
+// withMessageClassHeaders returns headers with the headers of class added
+// where headers does not already set them, leaving headers unchanged
+func withMessageClassHeaders(headers map[string]string, class MessageClass) map[string]string {
+	merged := make(map[string]string, len(headers)+len(messageClassHeaders[class]))
+	for name, value := range headers {
+		merged[name] = value
+	}
+	for name, value := range messageClassHeaders[class] {
+		if !hasHeader(headers, name) {
+			merged[name] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,241 @@
+package mailnow
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures a Client. Options are passed to NewClient and applied
+// in order before the API key is validated.
+type Option interface {
+	applyClient(c *Client) error
+}
+
+// SendOption configures a single send. Send options are passed to
+// SendEmail and take precedence over the equivalent client options.
+type SendOption interface {
+	applySend(o *sendOptions) error
+}
+
+// ClientSendOption is an option that can be used both with NewClient and
+// with a single send.
+type ClientSendOption interface {
+	Option
+	SendOption
+}
+
+// clientOption adapts a function to the Option interface
+type clientOption func(c *Client) error
+
+func (f clientOption) applyClient(c *Client) error {
+	return f(c)
+}
+
+// sendOption adapts a function to the SendOption interface
+type sendOption func(o *sendOptions) error
+
+func (f sendOption) applySend(o *sendOptions) error {
+	return f(o)
+}
+
+// sendOptions holds the per-send settings collected from SendOptions
+type sendOptions struct {
+	// category selects the rate limit bucket for the send
+	category string
+
+	// subaccount overrides the client's sub-account for the send
+	subaccount string
+
+	// utmParams replaces the client's UTM parameters for the send when set
+	utmParams map[string]string
+
+	// idempotencyKey makes the send exactly-once when set
+	idempotencyKey string
+
+	// textOnly sends only the plain-text body
+	textOnly bool
+
+	// inlineImages moves data URI images into inline attachments when set
+	inlineImages *InlineImageExtractor
+}
+
+// newSendOptions applies opts to a fresh set of send settings
+func newSendOptions(opts []SendOption) (*sendOptions, error) {
+	o := &sendOptions{}
+	for _, opt := range opts {
+		if err := opt.applySend(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WithBaseURL overrides the base URL of the Mailnow API.
+//
+// This is mainly useful for pointing the client at a test server or a
+// Mailnow-compatible gateway. The URL must use http or https and name a
+// host; whether the host is reachable is only checked with
+// WithEagerConnectivityCheck.
+func WithBaseURL(baseURL string) Option {
+	return clientOption(func(c *Client) error {
+		if baseURL == "" {
+			return NewValidationError("base URL cannot be empty", nil)
+		}
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return NewValidationError("invalid base URL "+baseURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return NewValidationError(fmt.Sprintf("base URL %s must use http or https, not %q", baseURL, u.Scheme), nil)
+		}
+		if u.Hostname() == "" {
+			return NewValidationError("base URL "+baseURL+" has no host", nil)
+		}
+		c.baseURL = strings.TrimRight(baseURL, "/")
+		return nil
+	})
+}
+
+// WithHTTPClient sets the HTTP client used for every request in place of
+// the client NewClient creates, so that requests go through the
+// application's transport, proxy settings and instrumentation. The client
+// is used as is: its Timeout replaces RequestTimeout, and Close leaves its
+// idle connections open. It cannot be combined with the options that
+// configure the transport, WithTLSConfig, WithMinTLSVersion, WithRootCAs,
+// WithPinnedCertificates and WithLocalAddr; configure its transport
+// instead.
+func WithHTTPClient(client *http.Client) Option {
+	return clientOption(func(c *Client) error {
+		if client == nil {
+			return NewValidationError("HTTP client cannot be nil", nil)
+		}
+		c.httpClient = client
+		c.customHTTPClient = true
+		return nil
+	})
+}
+
+// WithMinAPIKeySecretLength sets the minimum length of the part of the API
+// key after its prefix, which defaults to DefaultMinAPIKeySecretLength so
+// that keys of any length are accepted. RecommendedMinAPIKeySecretLength
+// rejects truncated keys, but keys issued before a minimum was introduced
+// may be shorter.
+func WithMinAPIKeySecretLength(n int) Option {
+	return clientOption(func(c *Client) error {
+		if n < 0 {
+			return NewValidationError(fmt.Sprintf("minimum API key secret length cannot be negative, got %d", n), nil)
+		}
+		c.minAPIKeySecretLength = n
+		return nil
+	})
+}
+
+// WithLogger sets the logger used to report client activity such as
+// rate limit waits. By default the client does not log.
+func WithLogger(logger *slog.Logger) Option {
+	return clientOption(func(c *Client) error {
+		if logger == nil {
+			return NewValidationError("logger cannot be nil", nil)
+		}
+		c.logger = logger
+		return nil
+	})
+}
+
+// WithErrorMapper sets a mapper that customizes the errors returned for API
+// error responses, such as mapping statuses added by a Mailnow-compatible
+// gateway to application error types. The mapper is called after the
+// built-in mapping and receives its result; see ErrorMapper.
+func WithErrorMapper(mapper ErrorMapper) Option {
+	return clientOption(func(c *Client) error {
+		if mapper == nil {
+			return NewValidationError("error mapper cannot be nil", nil)
+		}
+		c.errorMapper = mapper
+		return nil
+	})
+}
+
+// WithCacheControl overrides the Cache-Control header sent with every
+// request, which defaults to DefaultCacheControl. The Pragma: no-cache
+// header is only sent with the default.
+func WithCacheControl(value string) Option {
+	return clientOption(func(c *Client) error {
+		if value == "" {
+			return NewValidationError("Cache-Control value cannot be empty", nil)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return NewValidationError("Cache-Control value cannot contain line breaks", nil)
+		}
+		c.cacheControl = value
+		return nil
+	})
+}
+
+// WithClock sets the function the client uses to read the current time for
+// time-based features such as send budgets and category rate limits. It is
+// mainly useful in tests.
+func WithClock(now func() time.Time) Option {
+	return clientOption(func(c *Client) error {
+		if now == nil {
+			return NewValidationError("clock cannot be nil", nil)
+		}
+		c.now = now
+		return nil
+	})
+}
+
+// WithCategory assigns a send to a rate limit category configured with
+// WithCategoryRateLimits.
+func WithCategory(category string) SendOption {
+	return sendOption(func(o *sendOptions) error {
+		if category == "" {
+			return NewValidationError("category cannot be empty", nil)
+		}
+		o.category = category
+		return nil
+	})
+}
+
+// WithSubaccount makes requests on behalf of the sub-account with the given
+// ID by sending it in the X-Subaccount-Id header.
+//
+// Passed to NewClient it applies to every request made by the client; passed
+// to SendEmail it applies to that send only and takes precedence over the
+// client's sub-account.
+func WithSubaccount(id string) ClientSendOption {
+	return subaccountOption(id)
+}
+
+// subaccountOption sets the sub-account for a client or a single send
+type subaccountOption string
+
+func (id subaccountOption) applyClient(c *Client) error {
+	if err := ValidateSubaccountID(string(id)); err != nil {
+		return err
+	}
+	c.subaccount = string(id)
+	return nil
+}
+
+func (id subaccountOption) applySend(o *sendOptions) error {
+	if err := ValidateSubaccountID(string(id)); err != nil {
+		return err
+	}
+	o.subaccount = string(id)
+	return nil
+}
+
+// WithMarkdownRawHTML passes HTML written in Markdown bodies through
+// unchanged instead of escaping it. Only use it for Markdown from trusted
+// authors.
+func WithMarkdownRawHTML() Option {
+	return clientOption(func(c *Client) error {
+		c.markdownRawHTML = true
+		return nil
+	})
+}
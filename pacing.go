@@ -0,0 +1,260 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AllDomains is the recipient domain policy key for the policy of domains
+// without their own entry
+const AllDomains = "*"
+
+// DomainPolicy paces sends to a recipient domain, as mailbox providers
+// defer mail from senders that deliver too much at once
+type DomainPolicy struct {
+	// MaxPerMinute is the number of sends per minute allowed to the domain.
+	// Sends are spaced evenly; zero means no limit.
+	MaxPerMinute int
+
+	// StartHour and EndHour limit sends to the hours from StartHour up to
+	// EndHour, which may wrap past midnight. Both zero allows every hour.
+	StartHour int
+	EndHour   int
+
+	// Location is the time zone of StartHour and EndHour; nil means UTC
+	Location *time.Location
+}
+
+// validate checks that the policy's limits are in range
+func (p DomainPolicy) validate() error {
+	if p.MaxPerMinute < 0 {
+		return fmt.Errorf("max per minute cannot be negative")
+	}
+	if p.StartHour < 0 || p.StartHour > 23 || p.EndHour < 0 || p.EndHour > 23 {
+		return fmt.Errorf("hours must be between 0 and 23")
+	}
+	if p.StartHour == p.EndHour && p.StartHour != 0 {
+		return fmt.Errorf("start and end hour cannot be equal")
+	}
+	return nil
+}
+
+// interval is the spacing between sends, or zero for no limit
+func (p DomainPolicy) interval() time.Duration {
+	if p.MaxPerMinute == 0 {
+		return 0
+	}
+	return time.Minute / time.Duration(p.MaxPerMinute)
+}
+
+// opensAt returns now if the policy allows sending at now, or else the
+// start of the next allowed hour
+func (p DomainPolicy) opensAt(now time.Time) time.Time {
+	if p.StartHour == 0 && p.EndHour == 0 {
+		return now
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	hour := local.Hour()
+	allowed := hour >= p.StartHour && hour < p.EndHour
+	if p.StartHour > p.EndHour {
+		allowed = hour >= p.StartHour || hour < p.EndHour
+	}
+	if allowed {
+		return now
+	}
+
+	opens := time.Date(local.Year(), local.Month(), local.Day(), p.StartHour, 0, 0, 0, loc)
+	if !opens.After(local) {
+		opens = opens.AddDate(0, 0, 1)
+	}
+	return opens
+}
+
+// WithRecipientDomainPolicies paces sends per recipient domain, matched
+// case-insensitively on the domain of To. The AllDomains key sets the
+// policy of domains without their own entry; each such domain is paced
+// separately.
+//
+// A send waits, respecting its context, until its domain's next slot, so
+// concurrent sends to other domains proceed while a throttled domain
+// waits; when its context ends first it fails with an error wrapping the
+// context's error. A send outside the domain's allowed hours fails with a
+// SendWindowError. Slots and allowed hours are computed with the client
+// clock, see WithClock; DomainPacing reports the current state.
+func WithRecipientDomainPolicies(policies map[string]DomainPolicy) Option {
+	return clientOption(func(c *Client) error {
+		normalized := make(map[string]DomainPolicy, len(policies))
+		for domain, policy := range policies {
+			if err := policy.validate(); err != nil {
+				return NewValidationError(fmt.Sprintf("invalid policy for recipient domain %q", domain), err)
+			}
+			normalized[strings.ToLower(domain)] = policy
+		}
+		c.domainPacer = &domainPacer{policies: normalized, domains: make(map[string]*domainPace)}
+		return nil
+	})
+}
+
+// DomainPacing is the pacing state of a recipient domain
+type DomainPacing struct {
+	// Domain is the recipient domain
+	Domain string
+
+	// NextSlot is the earliest time the next send to the domain can start
+	NextSlot time.Time
+
+	// Waiting is the number of sends waiting for their slot
+	Waiting int
+}
+
+// DomainPacing returns the pacing state of every recipient domain sent to
+// under WithRecipientDomainPolicies, sorted by domain
+func (c *Client) DomainPacing() []DomainPacing {
+	if c.domainPacer == nil {
+		return nil
+	}
+	return c.domainPacer.state()
+}
+
+// domainPacer schedules sends according to recipient domain policies
+type domainPacer struct {
+	policies map[string]DomainPolicy
+
+	mu      sync.Mutex
+	domains map[string]*domainPace
+}
+
+// domainPace is the pacing state of one domain
+type domainPace struct {
+	next    time.Time
+	waiting int
+}
+
+// policy returns the policy for domain, if any
+func (p *domainPacer) policy(domain string) (DomainPolicy, bool) {
+	if policy, ok := p.policies[domain]; ok {
+		return policy, true
+	}
+	policy, ok := p.policies[AllDomains]
+	return policy, ok
+}
+
+// reserve takes the next slot of domain and returns how long to wait for
+// it, counting the caller as waiting when the wait is positive
+func (p *domainPacer) reserve(domain string, interval time.Duration, now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pace, ok := p.domains[domain]
+	if !ok {
+		pace = &domainPace{}
+		p.domains[domain] = pace
+	}
+	slot := pace.next
+	if slot.Before(now) {
+		slot = now
+	}
+	pace.next = slot.Add(interval)
+
+	delay := slot.Sub(now)
+	if delay > 0 {
+		pace.waiting++
+	}
+	return delay
+}
+
+// done records that a waiting send to domain got its slot or gave up
+func (p *domainPacer) done(domain string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.domains[domain].waiting--
+}
+
+// state returns the pacing state of every domain
+func (p *domainPacer) state() []DomainPacing {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	states := make([]DomainPacing, 0, len(p.domains))
+	for domain, pace := range p.domains {
+		states = append(states, DomainPacing{Domain: domain, NextSlot: pace.next, Waiting: pace.waiting})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Domain < states[j].Domain })
+	return states
+}
+
+// waitDomainPolicy waits for a slot for a send to to under the recipient
+// domain policies
+func (c *Client) waitDomainPolicy(ctx context.Context, to string) error {
+	domain := addressDomain(to)
+	policy, ok := c.domainPacer.policy(domain)
+	if !ok {
+		return nil
+	}
+
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return err
+	}
+	if opens := policy.opensAt(now); opens.After(now) {
+		return NewSendWindowError(fmt.Sprintf("sends to %s are not allowed until %s", domain, opens.Format(time.RFC3339)), domain, opens, nil)
+	}
+
+	delay := c.domainPacer.reserve(domain, policy.interval(), now)
+	if delay == 0 {
+		return nil
+	}
+	defer c.domainPacer.done(domain)
+
+	if c.logger != nil {
+		c.logger.DebugContext(ctx, "waiting for recipient domain policy", "domain", domain, "wait", delay)
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		return fmt.Errorf("recipient domain policy wait for %s interrupted: %w", domain, err)
+	}
+	return nil
+}
+
+// SendWindowError represents a send rejected because it is outside the
+// allowed hours of its recipient domain, see WithRecipientDomainPolicies
+type SendWindowError struct {
+	error *Error
+
+	// Domain is the recipient domain
+	Domain string
+
+	// OpensAt is when sends to the domain are next allowed
+	OpensAt time.Time
+}
+
+// NewSendWindowError creates a new SendWindowError
+func NewSendWindowError(message, domain string, opensAt time.Time, err error) *SendWindowError {
+	return &SendWindowError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Domain:  domain,
+		OpensAt: opensAt,
+	}
+}
+
+func (e *SendWindowError) Error() string {
+	return e.error.Error()
+}
+
+func (e *SendWindowError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *SendWindowError) base() *Error {
+	return e.error
+}
@@ -0,0 +1,47 @@
+package mailnow
+
+import "context"
+
+// Features that are only available on some plans
+const (
+	// FeatureValidation is recipient validation, including bulk validation
+	FeatureValidation = "validation"
+
+	// FeatureTemplates is stored templates
+	FeatureTemplates = "templates"
+
+	// FeatureDedicatedIPs is sending from dedicated IP addresses
+	FeatureDedicatedIPs = "dedicated_ips"
+)
+
+// Plan describes the account's plan: the features it includes and its
+// quotas. Requests that use a feature the plan does not include fail with
+// a PlanFeatureError.
+type Plan struct {
+	// Name is the name of the plan
+	Name string `json:"name"`
+
+	// Features reports whether each feature is included, keyed by feature
+	// name such as FeatureTemplates
+	Features map[string]bool `json:"features"`
+
+	// Quotas are the plan's limits, keyed by quota name such as
+	// "emails_per_month"
+	Quotas map[string]int64 `json:"quotas,omitempty"`
+}
+
+// Has reports whether the plan includes feature. Features the API did not
+// report are treated as not included.
+func (p *Plan) Has(feature string) bool {
+	if p == nil {
+		return false
+	}
+	return p.Features[feature]
+}
+
+// GetPlan returns the account's plan, so that applications can hide
+// features the plan does not include.
+func (c *Client) GetPlan(ctx context.Context) (*Plan, error) {
+	plan, _, err := c.requestAPI().GetPlan(ctx)
+	return plan, err
+}
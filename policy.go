@@ -0,0 +1,76 @@
+package mailnow
+
+import "context"
+
+// ContentPolicy checks an email before it is sent, returning an error to
+// block the send. Policies should return a PolicyViolationError so that
+// callers can tell a blocked send from an invalid request. A policy must
+// not modify req.
+type ContentPolicy func(ctx context.Context, req *EmailRequest) error
+
+// WithContentPolicy adds policy to the content policies checked before
+// every send. Policies run in the order they were added, after the
+// request has been validated and transformed into the form sent to the
+// API, and the first error aborts the send and is returned unchanged by
+// SendEmail. Policies receive the context passed to SendEmail, so values
+// such as a request ID set by the caller are available for logging.
+func WithContentPolicy(policy ContentPolicy) Option {
+	return clientOption(func(c *Client) error {
+		if policy == nil {
+			return NewValidationError("content policy cannot be nil", nil)
+		}
+		c.contentPolicies = append(c.contentPolicies, policy)
+		return nil
+	})
+}
+
+// checkContentPolicies runs the content policies on req in order,
+// stopping at the first violation
+func (c *Client) checkContentPolicies(ctx context.Context, req *EmailRequest) error {
+	for _, policy := range c.contentPolicies {
+		var err error
+		if panicErr := c.safeCall(ctx, CallbackContentPolicy, nil, func() { err = policy(ctx, req) }); panicErr != nil {
+			return panicErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PolicyViolationError represents a send blocked by a content policy set
+// with WithContentPolicy
+type PolicyViolationError struct {
+	error *Error
+
+	// Rule identifies the policy rule that blocked the send
+	Rule string
+
+	// Excerpt is the content that matched the rule, if any
+	Excerpt string
+}
+
+// NewPolicyViolationError creates a new PolicyViolationError
+func NewPolicyViolationError(message, rule, excerpt string, err error) *PolicyViolationError {
+	return &PolicyViolationError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Rule:    rule,
+		Excerpt: excerpt,
+	}
+}
+
+func (e *PolicyViolationError) Error() string {
+	return e.error.Error()
+}
+
+func (e *PolicyViolationError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *PolicyViolationError) base() *Error {
+	return e.error
+}
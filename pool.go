@@ -0,0 +1,173 @@
+package mailnow
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// DefaultClientPoolSize is the number of clients a ClientPool keeps unless
+// SetMaxSize says otherwise
+const DefaultClientPoolSize = 128
+
+// ClientPool caches one Client per API key for multi-tenant applications.
+// Every client is created with the pool's options and shares a single HTTP
+// client and transport, so connections are pooled across tenants.
+//
+// The pool keeps at most a fixed number of clients and evicts the least
+// recently used one when full. Eviction only drops the pool's reference;
+// the shared transport stays open and evicted clients keep working.
+//
+// A ClientPool is safe for concurrent use.
+type ClientPool struct {
+	opts []Option
+
+	// firstMu makes the first client, which creates the shared HTTP
+	// client, be created alone
+	firstMu sync.Mutex
+
+	mu         sync.Mutex
+	maxSize    int
+	clients    map[string]*list.Element
+	order      *list.List
+	httpClient *http.Client
+}
+
+// poolEntry is an element of the pool's recency list
+type poolEntry struct {
+	apiKey string
+	client *Client
+}
+
+// NewClientPool creates an empty pool whose clients are created with opts,
+// such as WithBaseURL, WithLogger or the TLS options. Options are checked
+// when the first client is created.
+func NewClientPool(opts ...Option) *ClientPool {
+	return &ClientPool{
+		opts:    opts,
+		maxSize: DefaultClientPoolSize,
+		clients: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the client for apiKey, creating it on first use. It fails
+// when the API key or the pool's options are invalid. Clients are created
+// without holding the pool's lock, so a slow NewClient, such as one with
+// WithEagerConnectivityCheck, does not hold up Get for other keys.
+func (p *ClientPool) Get(apiKey string) (*Client, error) {
+	if client, ok := p.cached(apiKey); ok {
+		return client, nil
+	}
+
+	client, err := p.newClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Keep the client another Get created meanwhile
+	if elem, ok := p.clients[apiKey]; ok {
+		p.order.MoveToFront(elem)
+		return elem.Value.(*poolEntry).client, nil
+	}
+	p.clients[apiKey] = p.order.PushFront(&poolEntry{apiKey: apiKey, client: client})
+	p.evict()
+	return client, nil
+}
+
+// cached returns the pool's client for apiKey, if any, marking it as the
+// most recently used
+func (p *ClientPool) cached(apiKey string) (*Client, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elem, ok := p.clients[apiKey]
+	if !ok {
+		return nil, false
+	}
+	p.order.MoveToFront(elem)
+	return elem.Value.(*poolEntry).client, true
+}
+
+// newClient creates a client for apiKey with the pool's options and shared
+// HTTP client. The first client creates the HTTP client the others share.
+func (p *ClientPool) newClient(apiKey string) (*Client, error) {
+	p.mu.Lock()
+	httpClient := p.httpClient
+	p.mu.Unlock()
+	if httpClient == nil {
+		p.firstMu.Lock()
+		defer p.firstMu.Unlock()
+		p.mu.Lock()
+		httpClient = p.httpClient
+		p.mu.Unlock()
+	}
+
+	opts := p.opts
+	if httpClient != nil {
+		opts = append(opts[:len(opts):len(opts)], withSharedHTTPClient(httpClient))
+	}
+	client, err := NewClient(apiKey, opts...)
+	if err != nil || httpClient != nil {
+		return client, err
+	}
+
+	p.mu.Lock()
+	p.httpClient = client.httpClient
+	p.mu.Unlock()
+	return client, nil
+}
+
+// withSharedHTTPClient makes a client use the HTTP client of a ClientPool,
+// which is already configured for the pool's options
+func withSharedHTTPClient(httpClient *http.Client) Option {
+	return clientOption(func(c *Client) error {
+		c.httpClient = httpClient
+		c.sharedHTTPClient = true
+		return nil
+	})
+}
+
+// Remove drops the client for apiKey, such as when a tenant's key is
+// revoked. The next Get creates a new client.
+func (p *ClientPool) Remove(apiKey string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if elem, ok := p.clients[apiKey]; ok {
+		p.order.Remove(elem)
+		delete(p.clients, apiKey)
+	}
+}
+
+// Len returns the number of cached clients
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.order.Len()
+}
+
+// SetMaxSize sets the number of clients the pool keeps, evicting the least
+// recently used clients if it holds more. Sizes below 1 are treated as 1.
+func (p *ClientPool) SetMaxSize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n < 1 {
+		n = 1
+	}
+	p.maxSize = n
+	p.evict()
+}
+
+// evict drops the least recently used clients until the pool fits
+func (p *ClientPool) evict() {
+	for p.order.Len() > p.maxSize {
+		elem := p.order.Back()
+		p.order.Remove(elem)
+		delete(p.clients, elem.Value.(*poolEntry).apiKey)
+	}
+}
@@ -0,0 +1,161 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCategory is the rate limit category used for sends without
+	// an explicit category
+	DefaultCategory = "default"
+
+	// AllCategories is the rate limit key for the overall limit that
+	// applies to every send regardless of category
+	AllCategories = "*"
+)
+
+// RateLimit describes a token bucket: sends are allowed at Rate per second
+// on average, with bursts of up to Burst sends.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// WithCategoryRateLimits enables client-side rate limiting per category.
+//
+// Each category in limits gets its own token bucket. Sends choose their
+// category with WithCategory; sends without one use DefaultCategory. The
+// AllCategories key sets an overall limit that applies in addition to the
+// category limit. Categories without an entry are only subject to the
+// overall limit.
+//
+// Waiting for a token respects the send context and is logged at debug
+// level with the name of the bucket being waited on. A send whose context
+// ends before its token is available, or whose deadline is too close to
+// wait for it, fails with an error wrapping the context's error.
+func WithCategoryRateLimits(limits map[string]RateLimit) Option {
+	return clientOption(func(c *Client) error {
+		buckets := make(map[string]*tokenBucket, len(limits))
+		for category, limit := range limits {
+			if limit.Rate <= 0 || limit.Burst < 1 {
+				return NewValidationError(fmt.Sprintf("rate limit for category %q must have a positive rate and burst", category), nil)
+			}
+			buckets[category] = newTokenBucket(limit)
+		}
+		c.rateLimiter = &categoryRateLimiter{buckets: buckets}
+		return nil
+	})
+}
+
+// categoryRateLimiter holds the token buckets for each rate limit category
+type categoryRateLimiter struct {
+	buckets map[string]*tokenBucket
+}
+
+// wait blocks until both the category bucket and the overall bucket allow
+// a send, or ctx is done. When the overall wait fails, the token taken
+// from the category bucket is returned. Token refills are measured with
+// clock.
+func (l *categoryRateLimiter) wait(ctx context.Context, category string, clock func(context.Context) (time.Time, error), logger *slog.Logger) error {
+	if category == "" {
+		category = DefaultCategory
+	}
+
+	var taken []*tokenBucket
+	for _, name := range []string{category, AllCategories} {
+		bucket, ok := l.buckets[name]
+		if !ok {
+			continue
+		}
+		if err := bucket.wait(ctx, name, clock, logger); err != nil {
+			for _, b := range taken {
+				b.cancel()
+			}
+			return err
+		}
+		taken = append(taken, bucket)
+	}
+
+	return nil
+}
+
+// tokenBucket is a token bucket rate limiter that supports reservations
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full token bucket for limit. The zero last
+// time keeps it full until the first reservation reads the client clock.
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  float64(limit.Burst),
+		tokens: float64(limit.Burst),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. The token balance may go negative to queue waiters fairly.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// wait reserves a token and waits until it can be used, or ctx is done
+func (b *tokenBucket) wait(ctx context.Context, name string, clock func(context.Context) (time.Time, error), logger *slog.Logger) error {
+	now, err := clock(ctx)
+	if err != nil {
+		return err
+	}
+	delay := b.reserve(now)
+	if delay == 0 {
+		return nil
+	}
+
+	// Fail fast when the context would expire before the token is available
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		b.cancel()
+		return fmt.Errorf("rate limit wait for category %q of %s exceeds the context deadline: %w", name, delay.Round(time.Millisecond), context.DeadlineExceeded)
+	}
+
+	if logger != nil {
+		logger.DebugContext(ctx, "waiting for client-side rate limit", "category", name, "wait", delay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return fmt.Errorf("rate limit wait for category %q interrupted: %w", name, ctx.Err())
+	}
+}
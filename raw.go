@@ -0,0 +1,239 @@
+package mailnow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// API is a low-level Mailnow API client. Each method makes exactly one
+// request to one endpoint: inputs are sent as given without validation,
+// failures are not retried, and responses are returned decoded together
+// with their HTTPMeta. Error responses are mapped to the SDK error types in
+// the same way as for Client.
+//
+// API is meant for programs that manage transport, retries and concurrency
+// themselves. Client is built on API and adds validation, retries and the
+// other client options; most programs should use Client.
+type API struct {
+	// APIKey authenticates the requests
+	APIKey string
+
+	// BaseURL is the API base URL, or APIBaseURL when empty
+	BaseURL string
+
+	// HTTPClient sends the requests. When nil, a client with RequestTimeout
+	// is used.
+	HTTPClient *http.Client
+
+	// Header holds extra headers added to every request, such as
+	// SubaccountHeader
+	Header http.Header
+
+	// Endpoints overrides API paths; empty fields use DefaultEndpoints
+	Endpoints Endpoints
+
+	// ErrorMapper maps error responses when set; see WithErrorMapper
+	ErrorMapper ErrorMapper
+
+	// StrictEnvelopes rejects email responses that are not successful
+	// envelopes with data; see WithStrictEnvelopes
+	StrictEnvelopes bool
+
+	// send replaces the single request made by Do when set, so that the
+	// Client methods built on API retry and apply the client options
+	send func(ctx context.Context, method, path, contentType string, body []byte) ([]byte, HTTPMeta, error)
+}
+
+// HTTPMeta describes the HTTP response to a request made with API
+type HTTPMeta struct {
+	// StatusCode is the HTTP status code, or zero when no response was
+	// received
+	StatusCode int
+
+	// Header holds the response headers
+	Header http.Header
+
+	// Duration is how long the request took
+	Duration time.Duration
+}
+
+// defaultAPIHTTPClient is the HTTP client of an API without one
+var defaultAPIHTTPClient = &http.Client{Timeout: RequestTimeout}
+
+// endpoints returns the API paths with defaults filled in
+func (a *API) endpoints() Endpoints {
+	return a.Endpoints.withDefaults()
+}
+
+// SendEmail sends req to the send endpoint
+func (a *API) SendEmail(ctx context.Context, req *EmailRequest) (*EmailResponse, HTTPMeta, error) {
+	start := time.Now()
+	payload, err := MarshalEmailRequest(req)
+	if err != nil {
+		return nil, HTTPMeta{}, err
+	}
+	tracerFrom(ctx).step(StepMarshaled, fmt.Sprintf("%d bytes", len(payload)), time.Since(start))
+	body, meta, err := a.Do(ctx, "POST", a.endpoints().SendEmail, "application/json", payload)
+	if err != nil {
+		return nil, meta, err
+	}
+	resp, err := decodeEmailResponse(body, a.StrictEnvelopes)
+	return resp, meta, err
+}
+
+// RescheduleEmail moves the scheduled email messageID to scheduledAt
+func (a *API) RescheduleEmail(ctx context.Context, messageID string, scheduledAt time.Time) (*EmailResponse, HTTPMeta, error) {
+	payload, err := encodeJSON(&rescheduleRequest{ScheduledAt: scheduledAt.UTC()})
+	if err != nil {
+		return nil, HTTPMeta{}, err
+	}
+	body, meta, err := a.Do(ctx, "PATCH", a.endpoints().Email+"/"+url.PathEscape(messageID), "application/json", payload)
+	if err != nil {
+		return nil, meta, err
+	}
+	resp, err := decodeEmailResponse(body, a.StrictEnvelopes)
+	return resp, meta, err
+}
+
+// UploadAsset uploads a multipart/form-data body to the asset store;
+// contentType must carry the multipart boundary
+func (a *API) UploadAsset(ctx context.Context, contentType string, body []byte) (*Asset, HTTPMeta, error) {
+	respBody, meta, err := a.Do(ctx, "POST", a.endpoints().Assets, contentType, body)
+	if err != nil {
+		return nil, meta, err
+	}
+	asset, _, err := DecodeEnvelope[Asset](respBody)
+	return asset, meta, err
+}
+
+// CreateTrackingDomain registers a tracking domain
+func (a *API) CreateTrackingDomain(ctx context.Context, domain string) (*TrackingDomain, HTTPMeta, error) {
+	payload, err := encodeJSON(map[string]string{"domain": domain})
+	if err != nil {
+		return nil, HTTPMeta{}, err
+	}
+	body, meta, err := a.Do(ctx, "POST", a.endpoints().TrackingDomains, "application/json", payload)
+	if err != nil {
+		return nil, meta, err
+	}
+	td, _, err := DecodeEnvelope[TrackingDomain](body)
+	return td, meta, err
+}
+
+// GetTrackingDomain returns a tracking domain
+func (a *API) GetTrackingDomain(ctx context.Context, domain string) (*TrackingDomain, HTTPMeta, error) {
+	body, meta, err := a.Do(ctx, "GET", a.endpoints().TrackingDomains+"/"+url.PathEscape(domain), "application/json", nil)
+	if err != nil {
+		return nil, meta, err
+	}
+	td, _, err := DecodeEnvelope[TrackingDomain](body)
+	return td, meta, err
+}
+
+// DeleteTrackingDomain removes a tracking domain
+func (a *API) DeleteTrackingDomain(ctx context.Context, domain string) (HTTPMeta, error) {
+	_, meta, err := a.Do(ctx, "DELETE", a.endpoints().TrackingDomains+"/"+url.PathEscape(domain), "application/json", nil)
+	return meta, err
+}
+
+// ListSenderIdentities returns the sender identities of the account
+func (a *API) ListSenderIdentities(ctx context.Context) ([]SenderIdentity, HTTPMeta, error) {
+	body, meta, err := a.Do(ctx, "GET", a.endpoints().SenderIdentities, "application/json", nil)
+	if err != nil {
+		return nil, meta, err
+	}
+	identities, _, err := DecodeEnvelope[[]SenderIdentity](body)
+	if err != nil {
+		return nil, meta, err
+	}
+	return *identities, meta, nil
+}
+
+// GetEmailByCustomID returns the email sent with the custom Message-ID id
+func (a *API) GetEmailByCustomID(ctx context.Context, id string) (*EmailResponse, HTTPMeta, error) {
+	body, meta, err := a.Do(ctx, "GET", a.endpoints().Email+"/custom/"+url.PathEscape(id), "application/json", nil)
+	if err != nil {
+		return nil, meta, err
+	}
+	resp, err := decodeEmailResponse(body, a.StrictEnvelopes)
+	return resp, meta, err
+}
+
+// GetPlan returns the account's plan
+func (a *API) GetPlan(ctx context.Context) (*Plan, HTTPMeta, error) {
+	body, meta, err := a.Do(ctx, "GET", a.endpoints().Plan, "application/json", nil)
+	if err != nil {
+		return nil, meta, err
+	}
+	plan, _, err := DecodeEnvelope[Plan](body)
+	return plan, meta, err
+}
+
+// Unsubscribe records that email opted out of the mailing list listID, or
+// of every list when listID is empty
+func (a *API) Unsubscribe(ctx context.Context, email, listID string) (HTTPMeta, error) {
+	payload, err := encodeJSON(&suppressionRequest{Email: email, ListID: listID, Reason: SuppressionReasonUnsubscribe})
+	if err != nil {
+		return HTTPMeta{}, err
+	}
+	_, meta, err := a.Do(ctx, "POST", a.endpoints().Suppressions, "application/json", payload)
+	return meta, err
+}
+
+// ValidateRecipients validates emails in a single request to the bulk
+// validation endpoint, which accepts at most MaxBulkValidateBatchSize
+// addresses
+func (a *API) ValidateRecipients(ctx context.Context, emails []string) ([]RecipientValidation, HTTPMeta, error) {
+	payload, err := encodeJSON(&bulkValidateRequest{Emails: emails})
+	if err != nil {
+		return nil, HTTPMeta{}, err
+	}
+	body, meta, err := a.Do(ctx, "POST", a.endpoints().BulkValidate, "application/json", payload)
+	if err != nil {
+		return nil, meta, err
+	}
+	validations, _, err := DecodeEnvelope[[]RecipientValidation](body)
+	if err != nil {
+		return nil, meta, err
+	}
+	return *validations, meta, nil
+}
+
+// Do sends a request with body to path and returns the body of a
+// successful response. It can be used for endpoints without a dedicated
+// method.
+func (a *API) Do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, HTTPMeta, error) {
+	if a.send != nil {
+		return a.send(ctx, method, path, contentType, body)
+	}
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	return a.do(ctx, method, path, contentType, reqBody, requestMeta{start: time.Now(), attempt: 1, headers: a.Header})
+}
+
+// do sends a single request described by meta
+func (a *API) do(ctx context.Context, method, path, contentType string, body io.Reader, meta requestMeta) ([]byte, HTTPMeta, error) {
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultAPIHTTPClient
+	}
+	baseURL := a.BaseURL
+	if baseURL == "" {
+		baseURL = APIBaseURL
+	}
+
+	start := time.Now()
+	resp, err := sendRequest(ctx, httpClient, method, baseURL+path, a.APIKey, contentType, body, meta)
+	if err != nil {
+		return nil, HTTPMeta{Duration: time.Since(start)}, err
+	}
+	respBody, err := handleResponse(resp, a.ErrorMapper)
+	return respBody, HTTPMeta{StatusCode: resp.StatusCode, Header: resp.Header, Duration: time.Since(start)}, err
+}
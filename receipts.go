@@ -0,0 +1,49 @@
+package mailnow
+
+import "strings"
+
+// Headers set for requests with RequestReadReceipt
+const (
+	// DispositionNotificationHeader requests a message disposition
+	// notification (RFC 8098)
+	DispositionNotificationHeader = "Disposition-Notification-To"
+
+	// ReturnReceiptHeader is the older read receipt header some clients
+	// still honor
+	ReturnReceiptHeader = "Return-Receipt-To"
+)
+
+// receiptHeaders are the headers generated for read receipts
+var receiptHeaders = []string{DispositionNotificationHeader, ReturnReceiptHeader}
+
+// receiptHeaderConflict returns the read receipt header that req sets in
+// Headers while also requesting a read receipt, or "" if there is none
+func receiptHeaderConflict(req *EmailRequest) string {
+	if !req.RequestReadReceipt {
+		return ""
+	}
+	for _, name := range receiptHeaders {
+		if hasHeader(req.Headers, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// withReceiptHeaders returns headers with the read receipt headers for req
+// added, leaving headers unchanged
+func withReceiptHeaders(headers map[string]string, req *EmailRequest) map[string]string {
+	receiptTo := strings.TrimSpace(req.ReceiptTo)
+	if receiptTo == "" {
+		receiptTo = req.From
+	}
+
+	merged := make(map[string]string, len(headers)+len(receiptHeaders))
+	for name, value := range headers {
+		merged[name] = value
+	}
+	for _, name := range receiptHeaders {
+		merged[name] = receiptTo
+	}
+	return merged
+}
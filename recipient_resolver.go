@@ -0,0 +1,151 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxRecipientAliasDepth is how deeply recipient aliases can nest: an alias
+// resolving to another alias counts as one level
+const MaxRecipientAliasDepth = 8
+
+// WithRecipientResolver expands recipient aliases, such as "team:payments",
+// into the addresses they stand for before a send is validated. Every To
+// and ReplyTo entry without an '@' is an alias and is passed to resolve,
+// which returns its members; members that are aliases themselves are
+// resolved in turn, up to MaxRecipientAliasDepth levels.
+//
+// The expanded ReplyTo list has no duplicates, ignoring case, even across
+// aliases. Requests have a single To address, so To must expand to exactly
+// one address. The send fails with a ValidationError when resolve fails or
+// returns no members, when aliases form a cycle or nest too deeply, and
+// when To expands to several addresses. The request passed to SendEmail is
+// left unchanged.
+func WithRecipientResolver(resolve func(ctx context.Context, addr string) ([]string, error)) Option {
+	return clientOption(func(c *Client) error {
+		if resolve == nil {
+			return NewValidationError("recipient resolver cannot be nil", nil)
+		}
+		c.recipientResolver = resolve
+		return nil
+	})
+}
+
+// isRecipientAlias reports whether a recipient entry names an alias rather
+// than an address
+func isRecipientAlias(entry string) bool {
+	return entry != "" && !strings.Contains(entry, "@")
+}
+
+// resolveRecipients returns req with its recipient aliases expanded, or
+// req itself when it has none
+func (c *Client) resolveRecipients(ctx context.Context, req *EmailRequest) (*EmailRequest, error) {
+	to := strings.TrimSpace(req.To)
+	replyTo := splitAddressList(req.ReplyTo)
+	hasAlias := isRecipientAlias(to)
+	for _, entry := range replyTo {
+		hasAlias = hasAlias || isRecipientAlias(entry)
+	}
+	if !hasAlias {
+		return req, nil
+	}
+
+	r := &recipientResolution{client: c, members: make(map[string][]string)}
+	resolved := *req
+	if isRecipientAlias(to) {
+		addrs, err := r.expand(ctx, []string{to})
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) != 1 {
+			return nil, NewValidationError(fmt.Sprintf("recipient alias %s resolves to %d addresses, but a request has a single To address", to, len(addrs)), nil)
+		}
+		resolved.To = addrs[0]
+	}
+	if len(replyTo) > 0 {
+		addrs, err := r.expand(ctx, replyTo)
+		if err != nil {
+			return nil, err
+		}
+		resolved.ReplyTo = addrs
+	}
+	return &resolved, nil
+}
+
+// recipientResolution expands the aliases of one request, resolving each
+// alias once
+type recipientResolution struct {
+	client  *Client
+	members map[string][]string
+}
+
+// expand returns the addresses of entries with their aliases expanded,
+// without duplicates
+func (r *recipientResolution) expand(ctx context.Context, entries []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var out []string
+	var walk func(entry string, path []string) error
+	walk = func(entry string, path []string) error {
+		entry = strings.TrimSpace(entry)
+		if !isRecipientAlias(entry) {
+			if key := strings.ToLower(entry); entry != "" && !seen[key] {
+				seen[key] = true
+				out = append(out, entry)
+			}
+			return nil
+		}
+
+		for _, alias := range path {
+			if alias == entry {
+				return NewValidationError("recipient aliases form a cycle: "+strings.Join(append(path, entry), " -> "), nil)
+			}
+		}
+		if len(path) == MaxRecipientAliasDepth {
+			return NewValidationError(fmt.Sprintf("recipient alias %s nests more than %d levels deep: %s", path[0], MaxRecipientAliasDepth, strings.Join(append(path, entry), " -> ")), nil)
+		}
+
+		members, err := r.resolve(ctx, entry)
+		if err != nil {
+			return err
+		}
+		path = append(path[:len(path):len(path)], entry)
+		for _, member := range members {
+			if err := walk(member, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		if err := walk(entry, nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// resolve returns the members of alias, calling the resolver the first
+// time alias is seen
+func (r *recipientResolution) resolve(ctx context.Context, alias string) ([]string, error) {
+	if members, ok := r.members[alias]; ok {
+		return members, nil
+	}
+
+	var members []string
+	var err error
+	if panicErr := r.client.safeCall(ctx, CallbackRecipientResolver, nil, func() {
+		members, err = r.client.recipientResolver(ctx, alias)
+	}); panicErr != nil {
+		return nil, panicErr
+	}
+	if err != nil {
+		return nil, NewValidationError("failed to resolve recipient alias "+alias, err)
+	}
+	if len(members) == 0 {
+		return nil, NewValidationError("recipient alias "+alias+" resolves to no addresses", nil)
+	}
+	r.members[alias] = members
+	return members, nil
+}
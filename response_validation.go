@@ -0,0 +1,72 @@
+package mailnow
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// MessageIDPrefix is the prefix of message IDs issued by the API
+	MessageIDPrefix = "msg_"
+
+	// responseValidationHistory is the number of recent message IDs
+	// remembered for duplicate detection
+	responseValidationHistory = 64
+)
+
+// responseValidator performs defensive consistency checks on send responses.
+//
+// It guards against intermediaries replaying cached responses by remembering
+// the most recent message IDs seen by the client in a small ring buffer.
+type responseValidator struct {
+	mu   sync.Mutex
+	ids  [responseValidationHistory]string
+	next int
+}
+
+// WithResponseValidation enables defensive validation of send responses.
+//
+// When enabled, SendEmail verifies that the returned message ID has the
+// expected prefix, that it does not repeat one of the recent message IDs
+// seen by this client unless the response is an idempotent replay, and
+// that the echoed status_code field, when present, matches the HTTP
+// status. Violations are returned as a ServerError.
+func WithResponseValidation() Option {
+	return clientOption(func(c *Client) error {
+		c.responseValidator = &responseValidator{}
+		return nil
+	})
+}
+
+// validate checks resp against the HTTP status code it was received with
+// and records its message ID
+func (v *responseValidator) validate(statusCode int, resp *EmailResponse) error {
+	if resp.StatusCode != 0 && resp.StatusCode != statusCode {
+		return NewServerError(fmt.Sprintf("inconsistent response: body reports status code %d but HTTP status was %d", resp.StatusCode, statusCode), nil)
+	}
+
+	messageID := resp.Data.MessageID
+	if !strings.HasPrefix(messageID, MessageIDPrefix) {
+		return NewServerError(fmt.Sprintf("inconsistent response: message ID %q does not have the %q prefix", messageID, MessageIDPrefix), nil)
+	}
+
+	// A replay returns the original send's message ID by design
+	if resp.Replayed {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, id := range v.ids {
+		if id == messageID {
+			return NewServerError(fmt.Sprintf("inconsistent response: message ID %q was already returned for a previous send, the response may have been replayed", messageID), nil)
+		}
+	}
+
+	v.ids[v.next] = messageID
+	v.next = (v.next + 1) % len(v.ids)
+
+	return nil
+}
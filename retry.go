@@ -0,0 +1,203 @@
+package mailnow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TooEarlyRetryDelay is the fixed delay before retrying a request rejected
+// with HTTP 425 Too Early. The condition clears as soon as the request is
+// resent without TLS early data, so no backoff is needed.
+const TooEarlyRetryDelay = 100 * time.Millisecond
+
+// RetryPolicy configures automatic retries of failed requests.
+//
+// A request is retried when IsRetryable reports its error as transient.
+// The delay before retry n is BaseDelay doubled n-1 times. After a
+// MaintenanceError the client waits for its RetryAfter instead, when the
+// API gave one. Both delays are capped at MaxDelay. After HTTP 425 the
+// client waits TooEarlyRetryDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts; zero means no cap
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is a reasonable policy for WithRetry
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// WithRetry enables automatic retries of transient failures using policy.
+// By default requests are not retried.
+//
+// A POST request that failed after it was fully written, such as a timeout
+// waiting for the response, may have been processed by the API, so it is
+// only retried when it carries an idempotency key, see WithIdempotencyKey.
+// Without one it fails with an AmbiguousResultError instead; use
+// WithUnsafeRetries to retry it anyway. Failures before the request was
+// written, and error responses, are retried regardless.
+func WithRetry(policy RetryPolicy) Option {
+	return clientOption(func(c *Client) error {
+		if policy.MaxAttempts < 1 {
+			return NewValidationError(fmt.Sprintf("retry max attempts must be at least 1, got %d", policy.MaxAttempts), nil)
+		}
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return NewValidationError("retry delays cannot be negative", nil)
+		}
+		c.retryPolicy = policy
+		return nil
+	})
+}
+
+// WithUnsafeRetries retries POST requests that failed after they were
+// written even without an idempotency key, at the risk of sending an email
+// twice. They are retried as if they carried a key, including attempts
+// that timed out while the caller's context is still live.
+func WithUnsafeRetries() Option {
+	return clientOption(func(c *Client) error {
+		c.unsafeRetries = true
+		return nil
+	})
+}
+
+// AmbiguousResultError represents a send that failed after the request was
+// written, so the API may or may not have accepted it, and that was not
+// retried because it had no idempotency key. Unwrap returns the
+// ConnectionError of the failed attempt.
+type AmbiguousResultError struct {
+	error *Error
+
+	// Fingerprint is the PayloadChecksum of the request body, to match
+	// the send against the API's logs or webhooks
+	Fingerprint string
+}
+
+// NewAmbiguousResultError creates a new AmbiguousResultError
+func NewAmbiguousResultError(message, fingerprint string, err error) *AmbiguousResultError {
+	return &AmbiguousResultError{
+		error: &Error{
+			Message: message,
+			Err:     err,
+		},
+		Fingerprint: fingerprint,
+	}
+}
+
+func (e *AmbiguousResultError) Error() string {
+	return e.error.Error()
+}
+
+func (e *AmbiguousResultError) Unwrap() error {
+	return e.error.Unwrap()
+}
+
+func (e *AmbiguousResultError) base() *Error {
+	return e.error
+}
+
+// IsRetryable reports whether err is a transient failure that may succeed
+// if the request is sent again: connection failures (including HTTP 408),
+// rate limiting, maintenance windows, HTTP 425 and server errors. Errors
+// caused by the caller's context being done, and AmbiguousResultError, are
+// not retryable. Neither is a ServerError reporting a problem found in a
+// successful response, such as one that cannot be decoded, as the API
+// already accepted the request.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var ambiguousErr *AmbiguousResultError
+	if errors.As(err, &ambiguousErr) {
+		return false
+	}
+
+	var connErr *ConnectionError
+	var rateLimitErr *RateLimitError
+	var maintenanceErr *MaintenanceError
+	var serverErr *ServerError
+	switch {
+	case errors.As(err, &connErr), errors.As(err, &rateLimitErr), errors.As(err, &maintenanceErr):
+		return true
+	case errors.As(err, &serverErr):
+		status := serverErr.error.StatusCode
+		return status == http.StatusTooEarly || status >= 500
+	default:
+		return false
+	}
+}
+
+// shouldRetry reports whether the request described by meta, whose attempt
+// failed with err, is retried. A POST or PATCH request whose outcome is
+// unknown is only retried with an idempotency key, and otherwise fails with
+// an AmbiguousResultError when it would have been retried.
+func (c *Client) shouldRetry(ctx context.Context, method string, body []byte, meta requestMeta, err error) (bool, error) {
+	if meta.attempt >= c.retryPolicy.MaxAttempts {
+		return false, err
+	}
+	var connErr *ConnectionError
+	ambiguous := errors.As(err, &connErr) && connErr.RequestWritten && (method == http.MethodPost || method == http.MethodPatch)
+	if !ambiguous {
+		return IsRetryable(err), err
+	}
+
+	// An attempt that timed out while ctx is still live can be retried
+	timedOut := errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+	if !IsRetryable(err) && !timedOut {
+		return false, err
+	}
+	if c.unsafeRetries || meta.headers.Get(IdempotencyKeyHeader) != "" {
+		return true, err
+	}
+	fingerprint := PayloadChecksum(body)
+	return false, NewAmbiguousResultError(fmt.Sprintf("request failed after it was sent and was not retried without an idempotency key; it may or may not have been processed (payload fingerprint %s)", fingerprint), fingerprint, err)
+}
+
+// delay returns how long to wait before retrying after attempt failed
+// with err
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) && serverErr.TooEarly() {
+		return TooEarlyRetryDelay
+	}
+
+	delay := p.BaseDelay
+	var maintenanceErr *MaintenanceError
+	if errors.As(err, &maintenanceErr) && maintenanceErr.RetryAfter > 0 {
+		delay = maintenanceErr.RetryAfter
+	} else {
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if p.MaxDelay > 0 && delay >= p.MaxDelay {
+				break
+			}
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// sleepContext waits for d, returning ctx.Err() if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
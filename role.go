@@ -0,0 +1,129 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// roleAccounts are the normalized local parts of common role addresses
+var roleAccounts = map[string]bool{
+	"abuse":         true,
+	"admin":         true,
+	"administrator": true,
+	"billing":       true,
+	"compliance":    true,
+	"contact":       true,
+	"donotreply":    true,
+	"help":          true,
+	"hostmaster":    true,
+	"info":          true,
+	"legal":         true,
+	"mailerdaemon":  true,
+	"marketing":     true,
+	"noc":           true,
+	"noreply":       true,
+	"office":        true,
+	"postmaster":    true,
+	"privacy":       true,
+	"root":          true,
+	"sales":         true,
+	"security":      true,
+	"support":       true,
+	"webmaster":     true,
+}
+
+// IsRoleAccount reports whether email is a role address, such as
+// postmaster@ or noreply@, rather than a person's mailbox. The local part
+// is compared case-insensitively, ignoring a "+tag" suffix and the
+// separators '.', '-' and '_', so no-reply@ and No_Reply@ match noreply.
+func IsRoleAccount(email string) bool {
+	return roleAccounts[roleLocalPart(email)]
+}
+
+// roleLocalPart returns the normalized local part of email used to look it
+// up in role account lists
+func roleLocalPart(email string) string {
+	local := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		local = email[:at]
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(local))
+}
+
+// RoleAccountPolicy selects what the client does with sends to role
+// addresses; see IsRoleAccount
+type RoleAccountPolicy int
+
+const (
+	// RoleAccountAllow sends to role addresses. It is the default.
+	RoleAccountAllow RoleAccountPolicy = iota
+
+	// RoleAccountWarn reports a warning with code "role_account" and sends
+	// the email
+	RoleAccountWarn
+
+	// RoleAccountBlock fails the send with a ValidationError naming the
+	// address
+	RoleAccountBlock
+)
+
+// WithRoleAccountPolicy sets what the client does with sends to role
+// addresses such as postmaster@ or noreply@. Marketing mail to role
+// addresses hurts sender reputation.
+func WithRoleAccountPolicy(policy RoleAccountPolicy) Option {
+	return clientOption(func(c *Client) error {
+		if policy < RoleAccountAllow || policy > RoleAccountBlock {
+			return NewValidationError(fmt.Sprintf("invalid role account policy %d", policy), nil)
+		}
+		c.roleAccountPolicy = policy
+		return nil
+	})
+}
+
+// WithRoleAccounts adds local parts, such as "team" or "careers", to the
+// role addresses the client's role account policy applies to. They are
+// normalized the same way as in IsRoleAccount.
+func WithRoleAccounts(localParts ...string) Option {
+	return clientOption(func(c *Client) error {
+		for _, local := range localParts {
+			normalized := roleLocalPart(local)
+			if normalized == "" {
+				return NewValidationError(fmt.Sprintf("invalid role account %q", local), nil)
+			}
+			if c.roleAccounts == nil {
+				c.roleAccounts = make(map[string]bool)
+			}
+			c.roleAccounts[normalized] = true
+		}
+		return nil
+	})
+}
+
+// isRoleAccount reports whether email is a built-in or client role address
+func (c *Client) isRoleAccount(email string) bool {
+	local := roleLocalPart(email)
+	return roleAccounts[local] || c.roleAccounts[local]
+}
+
+// checkRoleAccount applies the role account policy to the recipient of req
+func (c *Client) checkRoleAccount(ctx context.Context, req *EmailRequest) error {
+	if c.roleAccountPolicy == RoleAccountAllow || !c.isRoleAccount(req.To) {
+		return nil
+	}
+
+	if c.roleAccountPolicy == RoleAccountWarn {
+		c.warn(ctx, Warning{Code: "role_account", Message: fmt.Sprintf("recipient %s is a role account", req.To)})
+		return nil
+	}
+	return NewValidationError(fmt.Sprintf("recipient %s is a role account", req.To), nil)
+}
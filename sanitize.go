@@ -0,0 +1,338 @@
+package mailnow
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// tagTokenRegex matches a start or end tag at the start of the input,
+	// allowing '<' and '>' only inside quoted attributes
+	tagTokenRegex = regexp.MustCompile(`^</?[a-zA-Z](?:[^<>"']|"[^"]*"|'[^']*')*>`)
+
+	// cssEscapeRegex matches a CSS escape sequence
+	cssEscapeRegex = regexp.MustCompile(`\\(?:([0-9a-fA-F]{1,6})[ \t\n\r\f]?|(.))`)
+
+	// conditionalCommentRegex matches the opening of an Outlook conditional
+	// comment such as <!--[if mso]>
+	conditionalCommentRegex = regexp.MustCompile(`(?i)^<!--\[if[^\]]*\]>`)
+
+	// elementNameRegex matches an element name
+	elementNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9:-]*$`)
+
+	// conditionalCommentEnd closes an Outlook conditional comment
+	conditionalCommentEnd = "<![endif]-->"
+)
+
+// urlAttributes are the attributes whose values are URLs
+var urlAttributes = map[string]bool{
+	"action": true, "background": true, "cite": true, "dynsrc": true, "formaction": true, "href": true,
+	"longdesc": true, "lowsrc": true, "poster": true, "src": true, "xlink:href": true,
+}
+
+// dangerousCSS are the CSS fragments, lowercased and without whitespace,
+// that execute code or load content
+var dangerousCSS = []string{"expression(", "javascript:", "vbscript:", "behavior:", "-moz-binding", "@import"}
+
+// SanitizePolicy describes what SanitizeHTML removes
+type SanitizePolicy struct {
+	// RemoveElements are the elements removed together with their content
+	RemoveElements []string
+
+	// RemoveAttributes are the attributes removed from every element, in
+	// addition to event handlers such as onclick, which are always removed
+	RemoveAttributes []string
+
+	// URLSchemes are the schemes allowed in URL attributes such as href and
+	// src. Attributes with other schemes are removed; relative URLs are
+	// always allowed.
+	URLSchemes []string
+}
+
+// DefaultSanitizePolicy is an email-safe policy: it removes scripts,
+// frames, plugins, embedded SVG and MathML, and the base, link and meta
+// elements, which can change where links point, load remote resources or
+// redirect, and allows http, https, mailto, tel and cid URLs
+var DefaultSanitizePolicy = SanitizePolicy{
+	RemoveElements: []string{"script", "iframe", "frame", "frameset", "object", "embed", "applet", "base", "link", "meta", "noscript", "svg", "math"},
+	URLSchemes:     []string{"http", "https", "mailto", "tel", "cid"},
+}
+
+// SanitizeHTML removes dangerous content from html according to policy:
+// the elements in RemoveElements with their content, event handler
+// attributes and those in RemoveAttributes, URL attributes whose scheme is
+// not in URLSchemes such as javascript: URLs, and CSS that runs code, such
+// as expression(), in style attributes and <style> elements.
+//
+// Everything else, including tables, inline styles, images and Outlook
+// conditional comments, is kept as written and in the same order; other
+// comments are removed. Malformed markup is never passed through as a tag:
+// a '<' that does not start a complete tag with a valid name is escaped,
+// and an unterminated removed element is removed up to the end of the
+// document.
+func SanitizeHTML(doc string, policy SanitizePolicy) (string, error) {
+	s, err := newSanitizer(policy)
+	if err != nil {
+		return "", err
+	}
+	return s.sanitize(doc), nil
+}
+
+// WithHTMLSanitization sanitizes the HTML body of every send with policy,
+// as SanitizeHTML does, before any other transformation except
+// WithInlineImageExtraction, whose cid: URLs it keeps. The request passed
+// to SendEmail keeps its original HTML; only the copy sent to the API is
+// rewritten.
+func WithHTMLSanitization(policy SanitizePolicy) Option {
+	return clientOption(func(c *Client) error {
+		s, err := newSanitizer(policy)
+		if err != nil {
+			return err
+		}
+		c.sanitizer = s
+		return nil
+	})
+}
+
+// htmlSanitizer applies a SanitizePolicy
+type htmlSanitizer struct {
+	// removeElements maps the removed elements to a pattern matching
+	// their end tag
+	removeElements   map[string]*regexp.Regexp
+	removeAttributes map[string]bool
+	schemes          map[string]bool
+}
+
+// newSanitizer validates policy and indexes it
+func newSanitizer(policy SanitizePolicy) (*htmlSanitizer, error) {
+	s := &htmlSanitizer{
+		removeElements:   make(map[string]*regexp.Regexp),
+		removeAttributes: make(map[string]bool),
+		schemes:          make(map[string]bool),
+	}
+	for _, name := range policy.RemoveElements {
+		if !elementNameRegex.MatchString(name) {
+			return nil, NewValidationError("invalid element name in sanitize policy: "+name, nil)
+		}
+		name = strings.ToLower(name)
+		end, ok := rawTextElements[name]
+		if !ok {
+			end = regexp.MustCompile(`(?i)</` + regexp.QuoteMeta(name) + `\s*>`)
+		}
+		s.removeElements[name] = end
+	}
+	for _, name := range policy.RemoveAttributes {
+		s.removeAttributes[strings.ToLower(name)] = true
+	}
+	for _, scheme := range policy.URLSchemes {
+		s.schemes[strings.ToLower(strings.TrimSuffix(scheme, ":"))] = true
+	}
+	return s, nil
+}
+
+// sanitize scans doc, copying text and allowed tags
+func (s *htmlSanitizer) sanitize(doc string) string {
+	var b strings.Builder
+	b.Grow(len(doc))
+	for i := 0; i < len(doc); {
+		lt := strings.IndexByte(doc[i:], '<')
+		if lt < 0 {
+			b.WriteString(doc[i:])
+			break
+		}
+		b.WriteString(doc[i : i+lt])
+		i += lt
+		rest := doc[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			if m := conditionalCommentRegex.FindString(rest); m != "" {
+				end := strings.Index(rest, conditionalCommentEnd)
+				if end < 0 {
+					return b.String()
+				}
+				b.WriteString(m)
+				b.WriteString(s.sanitize(rest[len(m):end]))
+				b.WriteString(conditionalCommentEnd)
+				i += end + len(conditionalCommentEnd)
+				continue
+			}
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return b.String()
+			}
+			i += 4 + end + 3
+
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return b.String()
+			}
+			if decl := rest[:end+1]; strings.HasPrefix(strings.ToLower(decl), "<!doctype") && !strings.ContainsAny(decl[2:end], "<") {
+				b.WriteString(decl)
+			}
+			i += end + 1
+
+		default:
+			token := tagTokenRegex.FindString(rest)
+			if token == "" || !elementNameRegex.MatchString(tagName(token)) {
+				b.WriteString("&lt;")
+				i++
+				continue
+			}
+			i += len(token)
+			name := strings.ToLower(tagName(token))
+			if token[1] == '/' {
+				if s.removeElements[name] == nil {
+					b.WriteString(token)
+				}
+				continue
+			}
+
+			if endTag := s.removeElements[name]; endTag != nil {
+				if voidElements[name] || strings.HasSuffix(token, "/>") {
+					continue
+				}
+				end := endTag.FindStringIndex(doc[i:])
+				if end == nil {
+					return b.String()
+				}
+				i += end[1]
+				continue
+			}
+
+			b.WriteString(s.sanitizeTag(token))
+			if name == "style" {
+				end := styleCloseRegex.FindStringIndex(doc[i:])
+				if end == nil {
+					return b.String()
+				}
+				if css := doc[i : i+end[0]]; !isDangerousCSS(css) {
+					b.WriteString(css)
+				}
+				b.WriteString(doc[i+end[0] : i+end[1]])
+				i += end[1]
+			}
+		}
+	}
+	return b.String()
+}
+
+// tagName returns the name of a start or end tag as written, which runs
+// up to whitespace, '/' or '>'
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(tag[1:], "/")
+	return tag[:strings.IndexAny(tag, " \t\n\r\f/>")]
+}
+
+// sanitizeTag removes the disallowed attributes of a start tag, returning
+// the tag unchanged when they are all allowed
+func (s *htmlSanitizer) sanitizeTag(tag string) string {
+	name := tagName(tag)
+	body := tag[1+len(name) : len(tag)-1]
+	selfClosing := strings.HasSuffix(body, "/")
+	body = strings.TrimSuffix(body, "/")
+
+	changed := false
+	var kept []string
+	for _, m := range attrRegex.FindAllStringSubmatch(body, -1) {
+		attr, value := m[0], m[2]
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+			value = value[1 : len(value)-1]
+		}
+		value = html.UnescapeString(value)
+
+		key := strings.ToLower(m[1])
+		switch {
+		case strings.HasPrefix(key, "on") || s.removeAttributes[key]:
+			changed = true
+			continue
+		case urlAttributes[key] && !s.allowedURL(value):
+			changed = true
+			continue
+		case key == "style":
+			if safe, ok := sanitizeStyle(value); !ok {
+				changed = true
+				if safe == "" {
+					continue
+				}
+				attr = `style="` + html.EscapeString(safe) + `"`
+			}
+		}
+		kept = append(kept, attr)
+	}
+	if !changed {
+		return tag
+	}
+
+	var b strings.Builder
+	b.WriteString(tag[:1+len(name)])
+	for _, attr := range kept {
+		b.WriteByte(' ')
+		b.WriteString(attr)
+	}
+	if selfClosing {
+		b.WriteString(" /")
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// allowedURL reports whether the scheme of url is allowed. Browsers ignore
+// whitespace and control characters in schemes, so they are removed first.
+func (s *htmlSanitizer) allowedURL(url string) bool {
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+	colon := strings.IndexByte(url, ':')
+	if colon < 0 || strings.ContainsAny(url[:colon], "/?#") {
+		return true
+	}
+	return s.schemes[strings.ToLower(url[:colon])]
+}
+
+// sanitizeStyle removes the dangerous declarations of a style attribute,
+// reporting false and the remaining declarations if there were any
+func sanitizeStyle(style string) (string, bool) {
+	if !isDangerousCSS(style) {
+		return style, true
+	}
+	var kept []string
+	for _, decl := range splitDeclarations(style) {
+		if strings.TrimSpace(decl) != "" && !isDangerousCSS(decl) {
+			kept = append(kept, strings.TrimSpace(decl))
+		}
+	}
+	return strings.Join(kept, "; "), false
+}
+
+// isDangerousCSS reports whether css contains code or imports, ignoring
+// comments, escapes, case and whitespace
+func isDangerousCSS(css string) bool {
+	css = cssCommentRegex.ReplaceAllString(html.UnescapeString(css), "")
+	css = cssEscapeRegex.ReplaceAllStringFunc(css, func(escape string) string {
+		m := cssEscapeRegex.FindStringSubmatch(escape)
+		if m[1] == "" {
+			return m[2]
+		}
+		code, _ := strconv.ParseUint(m[1], 16, 32)
+		return string(rune(code))
+	})
+	css = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(css))
+	for _, fragment := range dangerousCSS {
+		if strings.Contains(css, fragment) {
+			return true
+		}
+	}
+	return false
+}
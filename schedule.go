@@ -0,0 +1,35 @@
+package mailnow
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// rescheduleRequest represents the body of a reschedule request
+type rescheduleRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// RescheduleEmail moves a scheduled email to a new send time, keeping its
+// message ID.
+//
+// The new time must satisfy ValidateScheduledTime; invalid times are
+// rejected without contacting the API.
+//
+// Returns the updated EmailResponse, or an AlreadySentError when the email
+// has already been sent (HTTP 409).
+func (c *Client) RescheduleEmail(ctx context.Context, messageID string, newTime time.Time) (*EmailResponse, error) {
+	if messageID == "" {
+		return nil, NewValidationError("message ID is required", nil)
+	}
+	if err := ValidateScheduledTime(newTime); err != nil {
+		return nil, err
+	}
+
+	resp, meta, err := c.requestAPI().RescheduleEmail(ctx, messageID, newTime)
+	if err != nil && meta.StatusCode == http.StatusConflict {
+		return nil, NewAlreadySentError("email "+messageID+" has already been sent", messageID, err)
+	}
+	return resp, err
+}
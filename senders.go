@@ -0,0 +1,198 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SenderIdentity is an address or domain the account can send from.
+//
+// A domain identity has no Email and covers every address at Domain.
+type SenderIdentity struct {
+	ID       string `json:"id"`
+	Email    string `json:"email,omitempty"`
+	Domain   string `json:"domain"`
+	Verified bool   `json:"verified"`
+}
+
+// ListSenderIdentities returns the sender addresses and domains of the
+// account and their verification status.
+func (c *Client) ListSenderIdentities(ctx context.Context) ([]SenderIdentity, error) {
+	identities, _, err := c.requestAPI().ListSenderIdentities(ctx)
+	return identities, err
+}
+
+// verifiedSendersRetryDelay is how long a failure to fetch the verified
+// identities is remembered before the next send tries again
+const verifiedSendersRetryDelay = 30 * time.Second
+
+// WithVerifiedSenderCheck rejects sends from addresses that are not
+// verified sender identities before they reach the API, saving the request
+// that would fail with sender_not_verified. When fallbacks are set with
+// WithFromFallbacks, unverified senders are left to the API so that the
+// fallbacks can be tried.
+//
+// The verified identities are fetched with ListSenderIdentities on the
+// first send and again once they are older than ttl; RefreshVerifiedSenders
+// forces an update. Concurrent sends share a single fetch. When the
+// identities cannot be fetched the send is allowed and a warning with code
+// "verified_senders_unavailable" is reported; sends are then allowed
+// without fetching again for 30 seconds, or ttl if shorter.
+func WithVerifiedSenderCheck(ttl time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if ttl <= 0 {
+			return NewValidationError("verified sender TTL must be positive", nil)
+		}
+		c.verifiedSenders = &verifiedSenders{ttl: ttl}
+		return nil
+	})
+}
+
+// verifiedSenders caches the verified sender identities of the account.
+// The identity maps are replaced, never modified, so they can be read
+// after the lock is released.
+type verifiedSenders struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	fetchedAt time.Time
+	addresses map[string]bool
+	domains   map[string]bool
+
+	// failedAt and failure record the last failed fetch
+	failedAt time.Time
+	failure  error
+
+	// fetching is closed when the fetch in progress ends, and nil when
+	// there is none
+	fetching chan struct{}
+}
+
+// RefreshVerifiedSenders fetches the verified sender identities used by
+// WithVerifiedSenderCheck, replacing the cached ones. Sends in flight keep
+// using the identities they have already checked against.
+func (c *Client) RefreshVerifiedSenders(ctx context.Context) error {
+	if c.verifiedSenders == nil {
+		return NewValidationError("verified sender check is not enabled; use WithVerifiedSenderCheck", nil)
+	}
+	return c.refreshVerifiedSenders(ctx)
+}
+
+// refreshVerifiedSenders fetches the verified identities and stores them,
+// or records the failure unless ctx was cancelled
+func (c *Client) refreshVerifiedSenders(ctx context.Context) error {
+	now, err := c.currentTime(ctx)
+	if err != nil {
+		return err
+	}
+	identities, err := c.ListSenderIdentities(ctx)
+
+	v := c.verifiedSenders
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			v.failedAt, v.failure = now, err
+		}
+		return err
+	}
+
+	addresses := make(map[string]bool)
+	domains := make(map[string]bool)
+	for _, identity := range identities {
+		if !identity.Verified {
+			continue
+		}
+		if identity.Email != "" {
+			addresses[strings.ToLower(identity.Email)] = true
+		} else if identity.Domain != "" {
+			domains[strings.ToLower(identity.Domain)] = true
+		}
+	}
+	v.addresses, v.domains, v.fetchedAt = addresses, domains, now
+	v.failedAt, v.failure = time.Time{}, nil
+	return nil
+}
+
+// currentVerifiedSenders returns the cached verified addresses and domains,
+// fetching them when they have expired. Only one fetch runs at a time;
+// other callers wait for it.
+func (c *Client) currentVerifiedSenders(ctx context.Context) (addresses, domains map[string]bool, err error) {
+	v := c.verifiedSenders
+	for {
+		now, err := c.currentTime(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v.mu.Lock()
+		if !v.fetchedAt.IsZero() && now.Sub(v.fetchedAt) < v.ttl {
+			addresses, domains := v.addresses, v.domains
+			v.mu.Unlock()
+			return addresses, domains, nil
+		}
+		if v.failure != nil && now.Sub(v.failedAt) < min(v.ttl, verifiedSendersRetryDelay) {
+			failure := v.failure
+			v.mu.Unlock()
+			return nil, nil, failure
+		}
+		if fetching := v.fetching; fetching != nil {
+			v.mu.Unlock()
+			select {
+			case <-fetching:
+				continue
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
+		}
+		fetching := make(chan struct{})
+		v.fetching = fetching
+		v.mu.Unlock()
+
+		err = c.refreshVerifiedSenders(ctx)
+		v.mu.Lock()
+		v.fetching = nil
+		close(fetching)
+		addresses, domains := v.addresses, v.domains
+		v.mu.Unlock()
+		if err != nil {
+			return nil, nil, err
+		}
+		return addresses, domains, nil
+	}
+}
+
+// checkVerifiedSender checks that from is a verified sender, refreshing the
+// cached identities when they have expired
+func (c *Client) checkVerifiedSender(ctx context.Context, from string) error {
+	addresses, domains, err := c.currentVerifiedSenders(ctx)
+	if err != nil {
+		c.warn(ctx, Warning{
+			Code:    "verified_senders_unavailable",
+			Message: fmt.Sprintf("could not fetch verified senders, allowing send from %s: %v", from, err),
+		})
+		return nil
+	}
+
+	from = strings.ToLower(from)
+	if addresses[from] || domains[from[strings.LastIndex(from, "@")+1:]] || len(c.fromFallbacks) > 0 {
+		return nil
+	}
+
+	verified := make([]string, 0, len(addresses)+len(domains))
+	for addr := range addresses {
+		verified = append(verified, addr)
+	}
+	for domain := range domains {
+		verified = append(verified, domain)
+	}
+	sort.Strings(verified)
+	if len(verified) == 0 {
+		return NewValidationError(fmt.Sprintf("from address %s is not a verified sender; the account has no verified senders", from), nil)
+	}
+	return NewValidationError(fmt.Sprintf("from address %s is not a verified sender; verified senders: %s", from, strings.Join(verified, ", ")), nil)
+}
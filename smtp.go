@@ -0,0 +1,206 @@
+package mailnow
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SMTPCategory is a coarse classification of an SMTP response, see
+// SMTPResponse.Category
+type SMTPCategory string
+
+// SMTP response categories
+const (
+	// SMTPCategoryUnknown is a response that matches no other category
+	SMTPCategoryUnknown SMTPCategory = "unknown"
+
+	// SMTPCategorySpamBlock is a rejection or deferral by the receiver's
+	// spam or policy filters
+	SMTPCategorySpamBlock SMTPCategory = "spam_block"
+
+	// SMTPCategoryGreylisting is a temporary deferral of a sender the
+	// receiver has not seen before; a retry later normally succeeds
+	SMTPCategoryGreylisting SMTPCategory = "greylisting"
+
+	// SMTPCategoryRateLimited is a deferral because the sender delivered
+	// too much at once
+	SMTPCategoryRateLimited SMTPCategory = "rate_limited"
+
+	// SMTPCategoryMailboxFull is a mailbox over its storage quota
+	SMTPCategoryMailboxFull SMTPCategory = "mailbox_full"
+
+	// SMTPCategoryMailboxUnknown is a recipient address that does not exist
+	SMTPCategoryMailboxUnknown SMTPCategory = "mailbox_unknown"
+)
+
+// EnhancedStatus is an RFC 3463 enhanced status code such as 5.7.1
+type EnhancedStatus struct {
+	// Class is 2 for success, 4 for a temporary and 5 for a permanent
+	// failure
+	Class int
+
+	// Subject is the category of the status, such as 1 for addressing or
+	// 7 for security and policy
+	Subject int
+
+	// Detail is the specific status within Subject
+	Detail int
+}
+
+// String returns the status in its dotted form, or "" for the zero status
+func (s EnhancedStatus) String() string {
+	if s == (EnhancedStatus{}) {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", s.Class, s.Subject, s.Detail)
+}
+
+// enhancedStatusRegex matches an enhanced status code
+var enhancedStatusRegex = regexp.MustCompile(`^([245])\.(\d{1,3})\.(\d{1,3})$`)
+
+// ParseEnhancedStatus parses an enhanced status code such as "5.7.1"
+func ParseEnhancedStatus(s string) (EnhancedStatus, bool) {
+	m := enhancedStatusRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return EnhancedStatus{}, false
+	}
+	class, _ := strconv.Atoi(m[1])
+	subject, _ := strconv.Atoi(m[2])
+	detail, _ := strconv.Atoi(m[3])
+	return EnhancedStatus{Class: class, Subject: subject, Detail: detail}, true
+}
+
+// SMTPResponse is the response of a receiving mail server to a delivery
+// attempt
+type SMTPResponse struct {
+	// Code is the three-digit reply code, or zero if the response could not
+	// be parsed
+	Code int `json:"code,omitempty"`
+
+	// EnhancedStatus is the enhanced status code, if the response has one
+	EnhancedStatus EnhancedStatus `json:"-"`
+
+	// Message is the text of the response
+	Message string `json:"message,omitempty"`
+
+	// RemoteMX is the MX host that gave the response
+	RemoteMX string `json:"remote_mx,omitempty"`
+
+	// TLS reports whether the connection to RemoteMX used TLS
+	TLS bool `json:"tls"`
+
+	// Raw is the response as received, kept so responses of an unknown
+	// shape are not lost
+	Raw json.RawMessage `json:"-"`
+}
+
+// smtpReplyRegex matches a reply line: the code, the separator and the
+// rest of the line
+var smtpReplyRegex = regexp.MustCompile(`^(\d{3})([ -]|$)(.*)$`)
+
+// ParseSMTPResponse parses an SMTP response such as
+// "550 5.7.1 Message rejected as spam". Multiline responses are joined into
+// one message. A string that is not an SMTP response is kept as the message
+// with a zero Code.
+func ParseSMTPResponse(s string) SMTPResponse {
+	raw, _ := json.Marshal(s)
+	resp := SMTPResponse{Raw: raw}
+
+	var parts []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		line = strings.TrimSpace(line)
+		m := smtpReplyRegex.FindStringSubmatch(line)
+		if m == nil {
+			return SMTPResponse{Message: strings.TrimSpace(s), Raw: raw}
+		}
+		code, _ := strconv.Atoi(m[1])
+		if resp.Code == 0 {
+			resp.Code = code
+		}
+
+		text := strings.TrimSpace(m[3])
+		// The enhanced status is repeated on every line of the response
+		if fields := strings.Fields(text); len(fields) > 0 {
+			if status, ok := ParseEnhancedStatus(fields[0]); ok {
+				resp.EnhancedStatus = status
+				text = strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+			}
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	resp.Message = strings.Join(parts, " ")
+	return resp
+}
+
+// UnmarshalJSON decodes a response given either as an object with the
+// fields of SMTPResponse and an "enhanced_status" string, or as the
+// response string itself. Raw is set to data in both cases, and a value of
+// another shape is only kept in Raw.
+func (r *SMTPResponse) UnmarshalJSON(data []byte) error {
+	raw := append(json.RawMessage(nil), data...)
+
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		*r = ParseSMTPResponse(line)
+		r.Raw = raw
+		return nil
+	}
+
+	type fields SMTPResponse
+	var obj struct {
+		fields
+		EnhancedStatus string `json:"enhanced_status"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		*r = SMTPResponse{Raw: raw}
+		return nil
+	}
+	*r = SMTPResponse(obj.fields)
+	r.EnhancedStatus, _ = ParseEnhancedStatus(obj.EnhancedStatus)
+	r.Raw = raw
+	return nil
+}
+
+// Temporary reports whether the response is a 4xx deferral
+func (r SMTPResponse) Temporary() bool {
+	return r.Code >= 400 && r.Code < 500
+}
+
+// Permanent reports whether the response is a 5xx rejection
+func (r SMTPResponse) Permanent() bool {
+	return r.Code >= 500 && r.Code < 600
+}
+
+// Category classifies the response from its enhanced status and, since
+// receivers use the codes loosely, common phrases in its message
+func (r SMTPResponse) Category() SMTPCategory {
+	msg := strings.ToLower(r.Message)
+	status := r.EnhancedStatus
+	containsAny := func(phrases ...string) bool {
+		for _, phrase := range phrases {
+			if strings.Contains(msg, phrase) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case containsAny("greylist", "graylist"):
+		return SMTPCategoryGreylisting
+	case status.Subject == 2 && status.Detail == 2, containsAny("mailbox full", "mailbox is full", "over quota", "quota exceeded", "insufficient storage"):
+		return SMTPCategoryMailboxFull
+	case status.Subject == 1 && status.Detail == 1, containsAny("user unknown", "no such user", "does not exist"):
+		return SMTPCategoryMailboxUnknown
+	case containsAny("rate limit", "unusual rate", "too many"):
+		return SMTPCategoryRateLimited
+	case status.Subject == 7 && status.Detail == 1, containsAny("spam", "blocklist", "blacklist", "blocked"):
+		return SMTPCategorySpamBlock
+	}
+	return SMTPCategoryUnknown
+}
@@ -0,0 +1,309 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Spam signal weights used by SpamScorer
+const (
+	// SpamWeightSubjectCaps is scored when most subject letters are
+	// capitals, scaled by the share of capitals
+	SpamWeightSubjectCaps = 2.0
+
+	// SpamWeightExclamation is scored for each exclamation mark beyond
+	// the first in the subject and the third in the body, up to three times
+	SpamWeightExclamation = 0.5
+
+	// SpamWeightPhrase is scored for each trigger phrase found, up to
+	// three times
+	SpamWeightPhrase = 1.0
+
+	// SpamWeightImageHeavy is scored when the HTML has little text for its
+	// images, or doubled when it has none
+	SpamWeightImageHeavy = 1.5
+
+	// SpamWeightManyLinks is scored when the HTML has more than
+	// SpamMaxLinks links
+	SpamWeightManyLinks = 1.0
+
+	// SpamWeightShortenedURL is scored once per URL shortener linked to
+	SpamWeightShortenedURL = 1.5
+
+	// SpamWeightMissingUnsubscribe is scored for marketing email without
+	// an unsubscribe link or List-Unsubscribe header
+	SpamWeightMissingUnsubscribe = 2.0
+
+	// SpamWeightTextDivergence is scored when the Text body shares few
+	// words with the HTML body
+	SpamWeightTextDivergence = 1.5
+)
+
+// SpamMaxLinks is the number of links above which SpamWeightManyLinks is
+// scored
+const SpamMaxLinks = 10
+
+// spamWordsPerImage is the number of words of text per image below which
+// the HTML counts as image-heavy
+const spamWordsPerImage = 25
+
+// DefaultSpamPhrases are the trigger phrases of DefaultSpamScorer
+var DefaultSpamPhrases = []string{
+	"100% free",
+	"act now",
+	"being a millionaire",
+	"cash bonus",
+	"click here",
+	"double your",
+	"earn extra cash",
+	"guaranteed",
+	"limited time offer",
+	"no credit check",
+	"risk-free",
+	"winner",
+	"you have been selected",
+}
+
+// urlShorteners are hosts of common URL shortening services
+var urlShorteners = map[string]bool{
+	"bit.ly":      true,
+	"buff.ly":     true,
+	"cutt.ly":     true,
+	"goo.gl":      true,
+	"is.gd":       true,
+	"ow.ly":       true,
+	"rebrand.ly":  true,
+	"shorturl.at": true,
+	"t.co":        true,
+	"tiny.cc":     true,
+	"tinyurl.com": true,
+}
+
+// SpamSignal is one heuristic that contributed to a SpamReport
+type SpamSignal struct {
+	// Code identifies the heuristic: "subject_caps", "exclamation_marks",
+	// "trigger_phrase", "image_heavy", "many_links", "shortened_url",
+	// "missing_unsubscribe" or "text_html_divergence"
+	Code string
+
+	// Score is the signal's contribution to the report's Score
+	Score float64
+
+	// Message explains the signal
+	Message string
+}
+
+// SpamReport is the result of scoring a request for spam signals
+type SpamReport struct {
+	// Score is the sum of the signal scores; zero means no signal fired
+	Score float64
+
+	// Signals lists the heuristics that fired, in a fixed order
+	Signals []SpamSignal
+}
+
+// add records a signal
+func (r *SpamReport) add(code string, score float64, format string, args ...interface{}) {
+	r.Score += score
+	r.Signals = append(r.Signals, SpamSignal{Code: code, Score: score, Message: fmt.Sprintf(format, args...)})
+}
+
+// SpamScorer scores requests for signals that spam filters commonly
+// penalize.
+type SpamScorer struct {
+	// Phrases are the trigger phrases looked for, case-insensitively, in
+	// the subject and body
+	Phrases []string
+}
+
+// DefaultSpamScorer is the scorer used by ScoreSpamSignals
+var DefaultSpamScorer = SpamScorer{Phrases: DefaultSpamPhrases}
+
+// ScoreSpamSignals scores req with DefaultSpamScorer.
+func ScoreSpamSignals(req *EmailRequest) SpamReport {
+	return DefaultSpamScorer.Score(req)
+}
+
+// Score computes local spam heuristics for req, each adding its weight to
+// the report: a subject mostly in capitals, excessive exclamation marks,
+// trigger phrases, an image-heavy HTML body, many links or links through
+// URL shorteners, marketing email (MessageClassMarketing) without an
+// unsubscribe link, and a Text body that differs from the HTML body.
+//
+// The score is a rough guide rather than a prediction of any particular
+// filter; a score of 5 or more has several strong signals. Score makes no
+// network calls.
+func (s SpamScorer) Score(req *EmailRequest) SpamReport {
+	var r SpamReport
+	if req == nil {
+		return r
+	}
+	text := visibleText(req.HTML)
+	if req.HTML == "" {
+		text = req.Text
+	}
+
+	if ratio, letters := capsRatio(req.Subject); letters >= 4 && ratio > 0.5 {
+		r.add("subject_caps", SpamWeightSubjectCaps*ratio, "%.0f%% of the subject's letters are capitals", ratio*100)
+	}
+
+	excess := max(strings.Count(req.Subject, "!")-1, 0) + max(strings.Count(text, "!")-3, 0)
+	if excess > 0 {
+		r.add("exclamation_marks", SpamWeightExclamation*float64(min(excess, 3)), "%d more exclamation marks than usual", excess)
+	}
+
+	content := strings.ToLower(req.Subject + "\n" + text)
+	found := 0
+	for _, phrase := range s.Phrases {
+		if phrase = strings.ToLower(strings.TrimSpace(phrase)); phrase != "" && strings.Contains(content, phrase) {
+			if found < 3 {
+				r.add("trigger_phrase", SpamWeightPhrase, "contains the trigger phrase %q", phrase)
+			}
+			found++
+		}
+	}
+
+	if images := len(imgTagRegex.FindAllString(req.HTML, -1)); images > 0 {
+		words := len(strings.Fields(text))
+		switch {
+		case words == 0:
+			r.add("image_heavy", 2*SpamWeightImageHeavy, "HTML has %d images and no text", images)
+		case words < images*spamWordsPerImage:
+			r.add("image_heavy", SpamWeightImageHeavy, "HTML has %d images for %d words of text", images, words)
+		}
+	}
+
+	links := hrefValues(req.HTML)
+	if len(links) > SpamMaxLinks {
+		r.add("many_links", SpamWeightManyLinks, "HTML has %d links", len(links))
+	}
+	shorteners := make(map[string]bool)
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		if urlShorteners[host] && !shorteners[host] {
+			shorteners[host] = true
+			r.add("shortened_url", SpamWeightShortenedURL, "links through the URL shortener %s", host)
+		}
+	}
+
+	if req.MessageClass == MessageClassMarketing && !hasUnsubscribe(req) {
+		r.add("missing_unsubscribe", SpamWeightMissingUnsubscribe, "marketing email has no unsubscribe link or List-Unsubscribe header")
+	}
+
+	if req.HTML != "" && req.Text != "" {
+		if overlap := wordOverlap(text, req.Text); overlap < 0.5 {
+			r.add("text_html_divergence", SpamWeightTextDivergence, "Text and HTML bodies share only %.0f%% of their words", overlap*100)
+		}
+	}
+	return r
+}
+
+// capsRatio returns the share of capitals among the letters of s, and the
+// number of letters
+func capsRatio(s string) (float64, int) {
+	letters, upper := 0, 0
+	for _, c := range s {
+		if unicode.IsLetter(c) {
+			letters++
+			if unicode.IsUpper(c) {
+				upper++
+			}
+		}
+	}
+	if letters == 0 {
+		return 0, 0
+	}
+	return float64(upper) / float64(letters), letters
+}
+
+// hasUnsubscribe reports whether req has an unsubscribe link or header
+func hasUnsubscribe(req *EmailRequest) bool {
+	return hasHeader(req.Headers, "List-Unsubscribe") ||
+		strings.Contains(strings.ToLower(req.HTML), "unsubscribe") ||
+		strings.Contains(strings.ToLower(req.Text), "unsubscribe")
+}
+
+// wordOverlap returns the Jaccard similarity of the sets of words of a and b
+func wordOverlap(a, b string) float64 {
+	words := func(s string) map[string]bool {
+		set := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			set[w] = true
+		}
+		return set
+	}
+	wa, wb := words(a), words(b)
+	union := len(wa)
+	shared := 0
+	for w := range wb {
+		if wa[w] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(shared) / float64(union)
+}
+
+// WithSpamScoreThreshold reports a warning with code "spam_score" listing
+// the signals of a send whose ScoreSpamSignals score is at least
+// threshold. The send is still made; see WithSpamScoreBlockThreshold.
+func WithSpamScoreThreshold(threshold float64) Option {
+	return clientOption(func(c *Client) error {
+		if threshold <= 0 || math.IsNaN(threshold) {
+			return NewValidationError(fmt.Sprintf("spam score threshold must be positive, got %v", threshold), nil)
+		}
+		c.spamWarnThreshold = threshold
+		return nil
+	})
+}
+
+// WithSpamScoreBlockThreshold fails a send whose ScoreSpamSignals score is
+// at least threshold with a ValidationError listing its signals, without
+// contacting the API. It can be combined with a lower
+// WithSpamScoreThreshold.
+func WithSpamScoreBlockThreshold(threshold float64) Option {
+	return clientOption(func(c *Client) error {
+		if threshold <= 0 || math.IsNaN(threshold) {
+			return NewValidationError(fmt.Sprintf("spam score threshold must be positive, got %v", threshold), nil)
+		}
+		c.spamBlockThreshold = threshold
+		return nil
+	})
+}
+
+// checkSpamScore applies the spam score thresholds to req, scored with the
+// client's default message class when req has none
+func (c *Client) checkSpamScore(ctx context.Context, req *EmailRequest) error {
+	if req.MessageClass == "" && c.defaultMessageClass != "" {
+		classed := *req
+		classed.MessageClass = c.defaultMessageClass
+		req = &classed
+	}
+	report := ScoreSpamSignals(req)
+
+	codes := make([]string, len(report.Signals))
+	for i, signal := range report.Signals {
+		codes[i] = signal.Code
+	}
+	message := fmt.Sprintf("spam score %.1f (%s)", report.Score, strings.Join(codes, ", "))
+
+	if c.spamBlockThreshold > 0 && report.Score >= c.spamBlockThreshold {
+		return NewValidationError(message+" reaches the blocking threshold", nil)
+	}
+	if c.spamWarnThreshold > 0 && report.Score >= c.spamWarnThreshold {
+		c.warn(ctx, Warning{Code: "spam_score", Message: message})
+	}
+	return nil
+}
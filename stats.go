@@ -0,0 +1,133 @@
+package mailnow
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SendStatsCapacity is the number of recent sends the client keeps for
+// ErrorRate and LatencyPercentile
+const SendStatsCapacity = 4096
+
+// sendOutcome is the result of one send
+type sendOutcome struct {
+	// at is when the send finished, in Unix nanoseconds
+	at int64
+
+	// latency is how long the send took, including retries and fallbacks
+	latency time.Duration
+
+	// failed records whether the send returned an error
+	failed bool
+}
+
+// sendStats keeps the outcomes of the most recent sends in a ring buffer
+type sendStats struct {
+	mu       sync.Mutex
+	outcomes []sendOutcome
+	next     int
+}
+
+// add records an outcome, replacing the oldest once the buffer is full
+func (s *sendStats) add(o sendOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.outcomes) < SendStatsCapacity {
+		s.outcomes = append(s.outcomes, o)
+		return
+	}
+	s.outcomes[s.next] = o
+	s.next = (s.next + 1) % SendStatsCapacity
+}
+
+// since returns the outcomes of sends that finished at or after start
+func (s *sendStats) since(start time.Time) []sendOutcome {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := start.UnixNano()
+	var recent []sendOutcome
+	for _, o := range s.outcomes {
+		if o.at >= cutoff {
+			recent = append(recent, o)
+		}
+	}
+	return recent
+}
+
+// startSendStats starts timing a send and returns the function recording
+// its outcome
+func (c *Client) startSendStats(ctx context.Context) func(err error) {
+	start, err := c.currentTime(ctx)
+	if err != nil {
+		return func(error) {}
+	}
+	return func(sendErr error) {
+		end, err := c.currentTime(ctx)
+		if err != nil {
+			return
+		}
+		c.state.stats.add(sendOutcome{at: end.UnixNano(), latency: end.Sub(start), failed: sendErr != nil})
+	}
+}
+
+// recentSends returns the outcomes of the sends that finished within
+// window of the client clock
+func (c *Client) recentSends(window time.Duration) []sendOutcome {
+	now, err := c.currentTime(context.Background())
+	if err != nil {
+		return nil
+	}
+	return c.state.stats.since(now.Add(-window))
+}
+
+// ErrorRate returns the fraction of sends that failed among those that
+// finished within window, or 0 if there were none. A send is counted once
+// however many times it was retried, and only once it was sent to the API:
+// requests rejected by validation or the client's own checks are not
+// counted.
+//
+// Only the last SendStatsCapacity sends are kept, so for a window holding
+// more sends the rate is that of the most recent ones.
+func (c *Client) ErrorRate(window time.Duration) float64 {
+	recent := c.recentSends(window)
+	if len(recent) == 0 {
+		return 0
+	}
+	failed := 0
+	for _, o := range recent {
+		if o.failed {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(recent))
+}
+
+// LatencyPercentile returns the latency below which the fraction p, between
+// 0 and 1, of the sends that finished within window completed, or 0 if
+// there were none. For example LatencyPercentile(5*time.Minute, 0.99) is
+// the p99 latency of the last five minutes. Latency includes retries and
+// fallbacks, and the same sends are counted as for ErrorRate.
+//
+// The percentile is computed by the nearest-rank method over at most the
+// last SendStatsCapacity sends.
+func (c *Client) LatencyPercentile(window time.Duration, p float64) time.Duration {
+	recent := c.recentSends(window)
+	if len(recent) == 0 {
+		return 0
+	}
+	latencies := make([]time.Duration, len(recent))
+	for i, o := range recent {
+		latencies[i] = o.latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	p = math.Max(0, math.Min(1, p))
+	rank := int(math.Ceil(p * float64(len(latencies))))
+	if rank < 1 {
+		rank = 1
+	}
+	return latencies[rank-1]
+}
@@ -0,0 +1,37 @@
+package mailnow
+
+import (
+	"fmt"
+	"mime"
+)
+
+// MaxEncodedSubjectLength is the maximum length of a subject after RFC 2047
+// encoding, the RFC 5322 limit for a header line
+const MaxEncodedSubjectLength = 998
+
+// EncodeSubjectRFC2047 encodes a subject containing non-ASCII characters as
+// RFC 2047 Q-encoded words, each at most 75 characters long and separated
+// by spaces. Pure-ASCII subjects are returned unchanged.
+func EncodeSubjectRFC2047(s string) string {
+	return mime.QEncoding.Encode("utf-8", s)
+}
+
+// WithEncodedSubjects makes the client send non-ASCII subjects RFC 2047
+// encoded. The request passed to SendEmail keeps its UTF-8 subject; only
+// the copy sent to the API is encoded.
+func WithEncodedSubjects() Option {
+	return clientOption(func(c *Client) error {
+		c.encodeSubjects = true
+		return nil
+	})
+}
+
+// encodeSubject returns the RFC 2047 encoding of subject, checking that it
+// stays within MaxEncodedSubjectLength
+func encodeSubject(subject string) (string, error) {
+	encoded := EncodeSubjectRFC2047(subject)
+	if len(encoded) > MaxEncodedSubjectLength {
+		return "", NewValidationError(fmt.Sprintf("encoded subject is %d characters, exceeding the limit of %d", len(encoded), MaxEncodedSubjectLength), nil)
+	}
+	return encoded, nil
+}
@@ -0,0 +1,51 @@
+package mailnow
+
+import "context"
+
+// SuppressionReasonUnsubscribe is the suppression reason of a recipient
+// who unsubscribed
+const SuppressionReasonUnsubscribe = "unsubscribe"
+
+// suppressionRequest represents the body of a suppression request
+type suppressionRequest struct {
+	Email  string `json:"email"`
+	ListID string `json:"list_id,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Unsubscribe records that email opted out of the mailing list listID, so
+// that later sends to it from the list are suppressed. An empty listID
+// unsubscribes the recipient from every list.
+//
+// Use it to handle RFC 8058 one-click unsubscribe requests, or let a
+// SuppressionSyncer call it for unsubscribed delivery callbacks.
+func (c *Client) Unsubscribe(ctx context.Context, email, listID string) error {
+	if err := ValidateEmailAddress(email); err != nil {
+		return err
+	}
+
+	_, err := c.requestAPI().Unsubscribe(ctx, email, listID)
+	return err
+}
+
+// SuppressionSyncer records the opt-outs reported by delivery callbacks,
+// closing the loop on one-click unsubscribes. Pass its HandleEvent method
+// to DeliveryCallbackHandler.
+type SuppressionSyncer struct {
+	client *Client
+}
+
+// NewSuppressionSyncer creates a SuppressionSyncer that records opt-outs
+// with client
+func NewSuppressionSyncer(client *Client) *SuppressionSyncer {
+	return &SuppressionSyncer{client: client}
+}
+
+// HandleEvent records the opt-out of an unsubscribed event with
+// Client.Unsubscribe. Other events are ignored.
+func (s *SuppressionSyncer) HandleEvent(ctx context.Context, event *DeliveryEvent) error {
+	if event.Event != CallbackEventUnsubscribed {
+		return nil
+	}
+	return s.client.Unsubscribe(ctx, event.Recipient, event.ListID)
+}
@@ -0,0 +1,124 @@
+package mailnow
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// templateVariableRegex matches a variable name, including dotted paths
+// such as "user.name"
+var templateVariableRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+
+// Delimiters are the markers surrounding template variables.
+type Delimiters struct {
+	Left  string
+	Right string
+}
+
+// DefaultDelimiters are the {{var}}-style delimiters used by Mailnow
+// templates.
+var DefaultDelimiters = Delimiters{Left: "{{", Right: "}}"}
+
+// ExtractTemplateVariables returns the names of the {{var}}-style
+// placeholders in content, in order of first appearance and without
+// duplicates.
+func ExtractTemplateVariables(content string) []string {
+	return DefaultDelimiters.ExtractVariables(content)
+}
+
+// ExtractVariables returns the names of the placeholders in content, in
+// order of first appearance and without duplicates.
+//
+// Whitespace inside the delimiters is ignored. Dotted names such as
+// {{user.name}} are supported; placeholders that are not valid names, such
+// as template directives, are skipped. Delimiters with an empty Left or
+// Right match nothing, and nil is returned.
+func (d Delimiters) ExtractVariables(content string) []string {
+	if d.Left == "" || d.Right == "" {
+		return nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+
+	for {
+		start := strings.Index(content, d.Left)
+		if start < 0 {
+			break
+		}
+		content = content[start+len(d.Left):]
+
+		end := strings.Index(content, d.Right)
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSpace(content[:end])
+		content = content[end+len(d.Right):]
+
+		if templateVariableRegex.MatchString(name) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// VerifyTemplateVariables checks that every {{var}}-style placeholder in
+// content has a value in data.
+//
+// Dotted names are resolved either as a flat key ("user.name") or through
+// nested maps (data["user"]["name"]). Returns a ValidationError listing all
+// missing variables, and the sorted top-level keys of data that no
+// placeholder uses, which usually indicate a typo.
+func VerifyTemplateVariables(content string, data map[string]interface{}) (unused []string, err error) {
+	names := ExtractTemplateVariables(content)
+
+	used := make(map[string]bool)
+	var missing []string
+	for _, name := range names {
+		key, ok := lookupTemplateVariable(data, name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		used[key] = true
+	}
+
+	for key := range data {
+		if !used[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+
+	if len(missing) > 0 {
+		return unused, NewValidationError("missing template variables: "+strings.Join(missing, ", "), nil)
+	}
+
+	return unused, nil
+}
+
+// lookupTemplateVariable resolves name in data and returns the top-level
+// key it was found under
+func lookupTemplateVariable(data map[string]interface{}, name string) (string, bool) {
+	if _, ok := data[name]; ok {
+		return name, true
+	}
+
+	parts := strings.Split(name, ".")
+	var current interface{} = data
+	for _, part := range parts {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		current, ok = m[part]
+		if !ok {
+			return "", false
+		}
+	}
+
+	return parts[0], true
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// recordingArchiver collects archived messages and fails when err is set
+type recordingArchiver struct {
+	mu       sync.Mutex
+	messages []mailnow.ArchivedMessage
+	err      error
+	delay    time.Duration
+}
+
+func (a *recordingArchiver) Archive(ctx context.Context, msg mailnow.ArchivedMessage) error {
+	time.Sleep(a.delay)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.messages = append(a.messages, msg)
+	return a.err
+}
+
+func (a *recordingArchiver) Messages() []mailnow.ArchivedMessage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]mailnow.ArchivedMessage(nil), a.messages...)
+}
+
+func TestArchiveOnSuccessAndFailure(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("bounce.test"), mailnowtest.Fail(http.StatusBadRequest, "recipient_rejected", "rejected"))
+	archiver := &recordingArchiver{}
+	client := newTestClient(t, server.URL, mailnow.WithArchiver(archiver))
+
+	req := &mailnow.EmailRequest{
+		From:        "sender@example.com",
+		To:          "recipient@example.com",
+		Subject:     "Invoice",
+		HTML:        "<p>Attached</p>",
+		Attachments: []mailnow.Attachment{{Filename: "a.txt", Content: "SGVsbG8=", ContentType: "text/plain"}},
+	}
+	resp, err := client.SendEmail(context.Background(), req)
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if _, err := sendTo(client, "nobody@bounce.test", "Hello"); err == nil {
+		t.Fatal("expected bounce to fail")
+	}
+
+	messages := archiver.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 archived messages, got %d", len(messages))
+	}
+
+	ok := messages[0]
+	if ok.Response == nil || ok.Response.Data.MessageID != resp.Data.MessageID || ok.Err != nil {
+		t.Errorf("unexpected archived success %+v", ok)
+	}
+	if len(ok.Attachments) != 1 || string(ok.Attachments[0].Content) != "Hello" {
+		t.Errorf("expected decoded attachment, got %+v", ok.Attachments)
+	}
+	if ok.Request == req || ok.Request.Attachments[0].Content != "" || ok.Request.Attachments[0].Filename != "a.txt" {
+		t.Errorf("expected a copy of the request without the attachment content, got %+v", ok.Request)
+	}
+	if req.Attachments[0].Content != "SGVsbG8=" {
+		t.Error("archiving modified the caller's attachment")
+	}
+	if ok.StartedAt.IsZero() || ok.FinishedAt.Before(ok.StartedAt) {
+		t.Errorf("unexpected timestamps %v, %v", ok.StartedAt, ok.FinishedAt)
+	}
+
+	failed := messages[1]
+	var validationErr *mailnow.ValidationError
+	if failed.Response != nil || !errors.As(failed.Err, &validationErr) || failed.Request.To != "nobody@bounce.test" {
+		t.Errorf("unexpected archived failure %+v", failed)
+	}
+}
+
+func TestArchiverErrorsDoNotFailSend(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var logs bytes.Buffer
+	archiver := &recordingArchiver{err: errors.New("disk full")}
+	client := newTestClient(t, server.URL, mailnow.WithArchiver(archiver), mailnow.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "failed to archive email") || !strings.Contains(logs.String(), "disk full") {
+		t.Errorf("expected archiver error to be logged, got %q", logs.String())
+	}
+}
+
+func TestArchiveTimeout(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	archiver := &recordingArchiver{delay: 200 * time.Millisecond}
+	client := newTestClient(t, server.URL, mailnow.WithArchiver(archiver), mailnow.WithArchiveTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected send not to wait for the archiver, took %v", elapsed)
+	}
+
+	// Archiving finishes in the background
+	time.Sleep(300 * time.Millisecond)
+	if len(archiver.Messages()) != 1 {
+		t.Error("expected the message to be archived in the background")
+	}
+}
+
+func TestDirArchiver(t *testing.T) {
+	dir := t.TempDir()
+	archiver := mailnow.NewDirArchiver(dir)
+
+	msg := mailnow.ArchivedMessage{
+		Request:   &mailnow.EmailRequest{From: "sender@example.com", To: "../../etc/passwd@example.com", Subject: "Hi", HTML: "<p>Hi</p>"},
+		Response:  &mailnow.EmailResponse{Data: mailnow.Data{MessageID: "msg/1", Status: "queued"}},
+		StartedAt: time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for i := 0; i < 3; i++ {
+		if err := archiver.Archive(context.Background(), msg); err != nil {
+			t.Fatalf("archive %d failed: %v", i, err)
+		}
+	}
+	failed := msg
+	failed.Response = nil
+	failed.Err = errors.New("rejected")
+	if err := archiver.Archive(context.Background(), failed); err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 files, got %d", len(entries))
+	}
+
+	names := make(map[string]bool)
+	for _, e := range entries {
+		names[e.Name()] = true
+		if strings.ContainsAny(e.Name(), "/\\") || strings.HasPrefix(e.Name(), ".") {
+			t.Errorf("unsafe file name %q", e.Name())
+		}
+	}
+	base := "20260401T120000.000000000Z_msg_1_.._.._etc_passwd_example.com"
+	for _, name := range []string{base + ".json", base + "-1.json", base + "-2.json"} {
+		if !names[name] {
+			t.Errorf("expected file %s, got %v", name, names)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "20260401T120000.000000000Z_failed_.._.._etc_passwd_example.com.json"))
+	if err != nil {
+		t.Fatalf("failed to read failure record: %v", err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil || record["error"] != "rejected" {
+		t.Errorf("unexpected failure record %s", data)
+	}
+}
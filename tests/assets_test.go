@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestUploadAsset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+		if r.URL.Path != "/v1/assets" {
+			t.Errorf("expected path /v1/assets, got %s", r.URL.Path)
+		}
+		if r.Header.Get("X-API-Key") == "" {
+			t.Errorf("expected X-API-Key header to be set")
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("failed to read multipart file: %v", err)
+		}
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+
+		if header.Filename != "terms.pdf" {
+			t.Errorf("expected filename terms.pdf, got %s", header.Filename)
+		}
+		if header.Header.Get("Content-Type") != "application/pdf" {
+			t.Errorf("expected content type application/pdf, got %s", header.Header.Get("Content-Type"))
+		}
+		if string(content) != "%PDF-1.4 terms" {
+			t.Errorf("unexpected file content %q", content)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "status_code": 201, "message": "asset uploaded", "data": {"asset_id": "asset_abc123", "filename": "terms.pdf", "content_type": "application/pdf", "size": 14}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	asset, err := client.UploadAsset(context.Background(), "terms.pdf", strings.NewReader("%PDF-1.4 terms"))
+	if err != nil {
+		t.Fatalf("UploadAsset() unexpected error: %v", err)
+	}
+
+	if asset.AssetID != "asset_abc123" {
+		t.Errorf("expected asset ID asset_abc123, got %s", asset.AssetID)
+	}
+	if asset.Filename != "terms.pdf" || asset.ContentType != "application/pdf" || asset.Size != 14 {
+		t.Errorf("unexpected asset %+v", asset)
+	}
+}
+
+func TestUploadAssetErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"code": "unauthorized", "message": "Invalid API key"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+
+	_, err = client.UploadAsset(ctx, "", strings.NewReader("data"))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for empty filename, got %T: %v", err, err)
+	}
+
+	_, err = client.UploadAsset(ctx, "terms.pdf", nil)
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for nil reader, got %T: %v", err, err)
+	}
+
+	_, err = client.UploadAsset(ctx, "terms.pdf", strings.NewReader("data"))
+	var authErr *mailnow.AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("expected AuthError, got %T: %v", err, err)
+	}
+}
+
+func TestSendEmailWithAssetAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+
+		attachments, _ := raw["attachments"].([]interface{})
+		if len(attachments) != 1 {
+			t.Fatalf("expected 1 attachment, got %v", raw["attachments"])
+		}
+		attachment := attachments[0].(map[string]interface{})
+		if attachment["asset_id"] != "asset_abc123" {
+			t.Errorf("expected asset_id asset_abc123, got %v", attachment["asset_id"])
+		}
+		if _, ok := attachment["content"]; ok {
+			t.Errorf("expected content to be omitted, got %v", attachment["content"])
+		}
+		if _, ok := attachment["url"]; ok {
+			t.Errorf("expected url to be omitted, got %v", attachment["url"])
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "data": {"message_id": "msg_12345", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	resp, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Your invoice",
+		HTML:    "<p>Invoice attached</p>",
+		Attachments: []mailnow.Attachment{
+			{Filename: "terms.pdf", AssetID: "asset_abc123", ContentType: "application/pdf"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if resp.Data.MessageID != "msg_12345" {
+		t.Errorf("expected message ID msg_12345, got %s", resp.Data.MessageID)
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestMakeRequestBodyCloseOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := mailnow.MakeRequest(context.Background(), client, "GET", server.URL, "mn_test_abc123", nil)
+	if err != nil {
+		t.Fatalf("MakeRequest() unexpected error: %v", err)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		t.Errorf("first Close() unexpected error: %v", err)
+	}
+	if err := resp.Body.Close(); !errors.Is(err, mailnow.ErrResponseBodyClosed) {
+		t.Errorf("second Close() error = %v, want ErrResponseBodyClosed", err)
+	}
+	if _, err := resp.Body.Read(make([]byte, 1)); !errors.Is(err, mailnow.ErrResponseBodyClosed) {
+		t.Errorf("Read() after Close() error = %v, want ErrResponseBodyClosed", err)
+	}
+}
+
+func TestLeakDetection(t *testing.T) {
+	mailnow.SetLeakDetection(true)
+	defer mailnow.SetLeakDetection(false)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := mailnow.MakeRequest(context.Background(), client, "GET", server.URL, "mn_test_abc123", nil)
+	if err != nil {
+		t.Fatalf("MakeRequest() unexpected error: %v", err)
+	}
+
+	stacks := mailnow.UnclosedResponseBodies()
+	if len(stacks) != 1 {
+		t.Fatalf("expected 1 unclosed body, got %d", len(stacks))
+	}
+	if !strings.Contains(stacks[0], "TestLeakDetection") {
+		t.Errorf("expected creation stack to include the test, got %s", stacks[0])
+	}
+
+	resp.Body.Close()
+	if stacks := mailnow.UnclosedResponseBodies(); len(stacks) != 0 {
+		t.Errorf("expected no unclosed bodies after Close, got %d", len(stacks))
+	}
+}
+
+func TestFailingSendsDoNotLeakConnections(t *testing.T) {
+	mailnow.SetLeakDetection(true)
+	defer mailnow.SetLeakDetection(false)
+
+	var newConns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": {"code": "internal_error", "message": "` + strings.Repeat("x", 8<<10) + `"}}`))
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	fdsBefore := openFileDescriptors()
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test Subject",
+		HTML:    "<h1>Test</h1>",
+	}
+	for i := 0; i < 500; i++ {
+		_, err := client.SendEmail(context.Background(), req)
+		var serverErr *mailnow.ServerError
+		if !errors.As(err, &serverErr) {
+			t.Fatalf("send %d: expected ServerError, got %T: %v", i+1, err, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&newConns); n > 5 {
+		t.Errorf("expected failing sends to reuse connections, server saw %d new connections", n)
+	}
+	if stacks := mailnow.UnclosedResponseBodies(); len(stacks) != 0 {
+		t.Errorf("expected no unclosed bodies, got %d:\n%s", len(stacks), stacks[0])
+	}
+	if fdsBefore >= 0 {
+		if fdsAfter := openFileDescriptors(); fdsAfter-fdsBefore > 20 {
+			t.Errorf("file descriptors grew from %d to %d", fdsBefore, fdsAfter)
+		}
+	}
+}
+
+// openFileDescriptors returns the number of open file descriptors of the
+// process, or -1 when it cannot be determined
+func openFileDescriptors() int {
+	if runtime.GOOS != "linux" {
+		return -1
+	}
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
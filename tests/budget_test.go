@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestSendBudgetConcurrent(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithSendBudget(25, 24*time.Hour, nil))
+
+	var wg sync.WaitGroup
+	var sent, exceeded atomic.Int32
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sendTo(client, "recipient@example.com", "Hello")
+			var budgetErr *mailnow.BudgetExceededError
+			switch {
+			case err == nil:
+				sent.Add(1)
+			case errors.As(err, &budgetErr):
+				exceeded.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sent.Load() != 25 || exceeded.Load() != 15 {
+		t.Errorf("expected 25 sent and 15 refused, got %d and %d", sent.Load(), exceeded.Load())
+	}
+	if got := len(server.Requests()); got != 25 {
+		t.Errorf("expected 25 requests to reach the server, got %d", got)
+	}
+}
+
+func TestSendBudgetSkipsFailedSends(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("bounce.test"), mailnowtest.Fail(http.StatusBadRequest, "recipient_rejected", "rejected"))
+	client := newTestClient(t, server.URL, mailnow.WithSendBudget(2, 24*time.Hour, nil))
+
+	for i := 0; i < 3; i++ {
+		if _, err := sendTo(client, "nobody@bounce.test", "Hello"); err == nil {
+			t.Fatal("expected bounce to fail")
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+			t.Fatalf("send %d failed: %v", i, err)
+		}
+	}
+
+	_, err := sendTo(client, "recipient@example.com", "Hello")
+	var budgetErr *mailnow.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if budgetErr.Used != 2 || budgetErr.Limit != 2 || budgetErr.ResetAt.IsZero() {
+		t.Errorf("unexpected budget details %+v", budgetErr)
+	}
+}
+
+func TestSendBudgetWarningAndRollover(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)}
+	var warnings []mailnow.Warning
+	client := newTestClient(t, server.URL,
+		mailnow.WithSendBudget(10, 24*time.Hour, nil),
+		mailnow.WithClock(clock.Now),
+		mailnow.WithBudgetWarningThreshold(0.7),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }),
+	)
+
+	for i := 1; i <= 10; i++ {
+		if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+			t.Fatalf("send %d failed: %v", i, err)
+		}
+		want := 0
+		if i >= 7 {
+			want = 1
+		}
+		if len(warnings) != want {
+			t.Fatalf("after send %d expected %d warnings, got %d", i, want, len(warnings))
+		}
+	}
+	if warnings[0].Code != "budget_threshold" {
+		t.Errorf("unexpected warning %+v", warnings[0])
+	}
+
+	_, err := sendTo(client, "recipient@example.com", "Hello")
+	var budgetErr *mailnow.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC); !budgetErr.ResetAt.Equal(want) {
+		t.Errorf("expected reset at %v, got %v", want, budgetErr.ResetAt)
+	}
+
+	// The next period starts with a fresh budget
+	clock.Advance(time.Hour)
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("expected send after rollover to succeed, got %v", err)
+	}
+}
+
+func TestSendBudgetWeeklyRollover(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	// Weekly periods are aligned to the Unix epoch, a Thursday
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL,
+		mailnow.WithClock(clock.Now),
+		mailnow.WithSendBudget(1, 7*24*time.Hour, nil),
+	)
+
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("first send failed: %v", err)
+	}
+	_, err := sendTo(client, "recipient@example.com", "Hello")
+	var budgetErr *mailnow.BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if want := time.Date(2026, 3, 19, 0, 0, 0, 0, time.UTC); !budgetErr.ResetAt.Equal(want) {
+		t.Errorf("expected reset at %v, got %v", want, budgetErr.ResetAt)
+	}
+
+	// Monday is still in the same period
+	clock.Advance(48 * time.Hour)
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError on Monday, got %v", err)
+	}
+
+	clock.Advance(84 * time.Hour)
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("expected send after rollover to succeed, got %v", err)
+	}
+}
+
+func TestSendBudgetOptionErrors(t *testing.T) {
+	opts := map[string]mailnow.Option{
+		"zero limit":       mailnow.WithSendBudget(0, time.Hour, nil),
+		"zero period":      mailnow.WithSendBudget(10, 0, nil),
+		"zero threshold":   mailnow.WithBudgetWarningThreshold(0),
+		"large threshold":  mailnow.WithBudgetWarningThreshold(1.5),
+		"nil clock":        mailnow.WithClock(nil),
+		"nil warn handler": mailnow.WithWarningHandler(nil),
+	}
+	for name, opt := range opts {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opt); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	store := mailnow.NewMemoryBudgetStore()
+	period := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, ok, _ := store.Reserve(context.Background(), period, 1); !ok {
+		t.Fatal("expected first reservation to succeed")
+	}
+	if used, ok, _ := store.Reserve(context.Background(), period, 1); ok || used != 1 {
+		t.Errorf("expected second reservation to fail with 1 used, got %d, %v", used, ok)
+	}
+}
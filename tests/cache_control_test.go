@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// headerRecorder records the cache headers of every request it serves
+type headerRecorder struct {
+	*httptest.Server
+	mu      sync.Mutex
+	headers map[string]http.Header
+}
+
+func newHeaderRecorder() *headerRecorder {
+	h := &headerRecorder{headers: make(map[string]http.Header)}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		h.headers[r.Method+" "+r.URL.Path] = r.Header.Clone()
+		h.mu.Unlock()
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued", "id": "a1"}}`))
+	}))
+	return h
+}
+
+// exerciseEndpoints calls every client method that makes an API request
+func exerciseEndpoints(client *mailnow.Client) {
+	ctx := context.Background()
+	client.SendEmail(ctx, &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hi", HTML: "<p>Hi</p>"})
+	client.RescheduleEmail(ctx, "msg_1", time.Now().Add(time.Hour))
+	client.UploadAsset(ctx, "a.txt", strings.NewReader("hello"))
+	client.CreateTrackingDomain(ctx, "track.example.com")
+	client.GetTrackingDomain(ctx, "track.example.com")
+	client.DeleteTrackingDomain(ctx, "track.example.com")
+	client.ListSenderIdentities(ctx)
+}
+
+func TestDefaultCacheHeaders(t *testing.T) {
+	server := newHeaderRecorder()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	exerciseEndpoints(client)
+
+	resp, err := mailnow.MakeRequest(context.Background(), http.DefaultClient, "GET", server.URL+"/v1/raw", "mn_test_7e59df7ce4a14545b443837804ec9722", nil)
+	if err != nil {
+		t.Fatalf("MakeRequest failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(server.headers) != 8 {
+		t.Fatalf("expected 8 distinct requests, got %d", len(server.headers))
+	}
+	for endpoint, headers := range server.headers {
+		if headers.Get("Cache-Control") != "no-store" || headers.Get("Pragma") != "no-cache" {
+			t.Errorf("%s: expected no-store cache headers, got Cache-Control %q, Pragma %q", endpoint, headers.Get("Cache-Control"), headers.Get("Pragma"))
+		}
+	}
+}
+
+func TestWithCacheControl(t *testing.T) {
+	server := newHeaderRecorder()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL), mailnow.WithCacheControl("no-cache, private"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	exerciseEndpoints(client)
+
+	for endpoint, headers := range server.headers {
+		if headers.Get("Cache-Control") != "no-cache, private" || headers.Get("Pragma") != "" {
+			t.Errorf("%s: unexpected cache headers Cache-Control %q, Pragma %q", endpoint, headers.Get("Cache-Control"), headers.Get("Pragma"))
+		}
+	}
+
+	for _, value := range []string{"", "no-store\r\nX-Evil: 1"} {
+		if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithCacheControl(value)); err == nil {
+			t.Errorf("expected error for Cache-Control %q", value)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestCallbackPanicsProceed(t *testing.T) {
+	tests := map[string]mailnow.Option{
+		mailnow.CallbackWarningHandler:        mailnow.WithWarningHandler(func(mailnow.Warning) { panic("warning handler broke") }),
+		mailnow.CallbackConnectionDiagnostics: mailnow.WithConnectionDiagnostics(func(mailnow.ConnDiagnostics) { panic("diagnostics broke") }),
+	}
+
+	for callback, opt := range tests {
+		t.Run(callback, func(t *testing.T) {
+			server := mailnowtest.NewServer()
+			defer server.Close()
+
+			// A budget of one warns on the first send
+			var logs bytes.Buffer
+			client := newTestClient(t, server.URL, mailnow.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))), opt, mailnow.WithSendBudget(1, time.Hour, nil))
+
+			if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+				t.Fatalf("expected send to proceed, got %v", err)
+			}
+			if !strings.Contains(logs.String(), "recovered panic in user callback") || !strings.Contains(logs.String(), "callback="+callback) {
+				t.Errorf("expected panic to be logged, got %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestErrorMapperPanic(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("example.com"), mailnowtest.Fail(http.StatusTooManyRequests, "rate_limited", "slow down"))
+
+	var logs bytes.Buffer
+	client := newTestClient(t, server.URL, mailnow.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		mailnow.WithErrorMapper(func(int, []byte, error) error { panic("mapper broke") }),
+		mailnow.WithCallbackStackTraces(),
+	)
+
+	_, err := sendTo(client, "recipient@example.com", "Hello")
+	var panicErr *mailnow.CallbackPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected CallbackPanicError, got %v", err)
+	}
+	if panicErr.Callback != mailnow.CallbackErrorMapper || panicErr.Value != "mapper broke" {
+		t.Errorf("unexpected panic details %q, %v", panicErr.Callback, panicErr.Value)
+	}
+	if !strings.Contains(panicErr.Stack, "panic") {
+		t.Errorf("expected stack trace, got %q", panicErr.Stack)
+	}
+
+	// The default error is still available
+	var rateLimitErr *mailnow.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Errorf("expected wrapped RateLimitError, got %v", err)
+	}
+}
+
+func TestClockPanic(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client := newTestClient(t, server.URL, mailnow.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		mailnow.WithClock(func() time.Time { panic("clock broke") }),
+		mailnow.WithSendBudget(10, time.Hour, nil),
+	)
+
+	_, err := sendTo(client, "recipient@example.com", "Hello")
+	var panicErr *mailnow.CallbackPanicError
+	if !errors.As(err, &panicErr) || panicErr.Callback != mailnow.CallbackClock {
+		t.Fatalf("expected clock CallbackPanicError, got %v", err)
+	}
+	if panicErr.Stack != "" {
+		t.Errorf("expected no stack trace by default, got %q", panicErr.Stack)
+	}
+	if len(server.Requests()) != 0 {
+		t.Error("expected send not to reach the server")
+	}
+
+	// The client survives the panic and fails the same way on the next send
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err == nil {
+		t.Error("expected the clock to fail again")
+	}
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// checksumServer records the checksum header of each request and answers
+// its first requests, up to the given count, with a checksum mismatch
+type checksumServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	headers   []string
+	bodies    [][]byte
+	mismatch  int
+	requested int
+}
+
+func newChecksumServer(t *testing.T, mismatches int) *checksumServer {
+	s := &checksumServer{mismatch: mismatches}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.headers = append(s.headers, r.Header.Get(mailnow.PayloadChecksumHeader))
+		s.bodies = append(s.bodies, body)
+		s.requested++
+		n := s.requested
+		s.mu.Unlock()
+
+		if n <= s.mismatch {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error": {"code": "checksum_mismatch", "message": "body does not match checksum"}}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestPayloadChecksumHeader(t *testing.T) {
+	server := newChecksumServer(t, 0)
+	client := newTestClient(t, server.URL, mailnow.WithPayloadChecksum())
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+
+	if len(server.headers) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(server.headers))
+	}
+	sum := sha256.Sum256(server.bodies[0])
+	if want := hex.EncodeToString(sum[:]); server.headers[0] != want {
+		t.Errorf("checksum header = %q, want %q", server.headers[0], want)
+	}
+	if got := mailnow.PayloadChecksum(server.bodies[0]); got != server.headers[0] {
+		t.Errorf("PayloadChecksum() = %q, want %q", got, server.headers[0])
+	}
+}
+
+func TestPayloadChecksumRetriesMismatchOnce(t *testing.T) {
+	// One mismatch is retried even without a retry policy
+	server := newChecksumServer(t, 1)
+	client := newTestClient(t, server.URL, mailnow.WithPayloadChecksum())
+
+	resp, err := client.SendEmail(context.Background(), newRetryRequest())
+	if err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if resp.Data.MessageID != "msg_123" {
+		t.Errorf("MessageID = %q, want msg_123", resp.Data.MessageID)
+	}
+	if server.requested != 2 {
+		t.Fatalf("expected 2 requests, got %d", server.requested)
+	}
+	if server.headers[0] != server.headers[1] || string(server.bodies[0]) != string(server.bodies[1]) {
+		t.Error("retry did not resend the same body and checksum")
+	}
+
+	// A second mismatch is reported
+	server = newChecksumServer(t, 2)
+	client = newTestClient(t, server.URL, mailnow.WithPayloadChecksum())
+	_, err = client.SendEmail(context.Background(), newRetryRequest())
+	if code := mailnow.ErrorCode(err); code != "checksum_mismatch" {
+		t.Errorf("ErrorCode() = %q, want checksum_mismatch (err %v)", code, err)
+	}
+	if server.requested != 2 {
+		t.Errorf("expected 2 requests, got %d", server.requested)
+	}
+}
+
+func TestPayloadChecksumDisabled(t *testing.T) {
+	server := newChecksumServer(t, 1)
+	client := newTestClient(t, server.URL)
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err == nil {
+		t.Fatal("expected the checksum mismatch to be reported")
+	}
+	if server.requested != 1 {
+		t.Errorf("expected 1 request, got %d", server.requested)
+	}
+	if server.headers[0] != "" {
+		t.Errorf("checksum header = %q, want none", server.headers[0])
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// sendWithHTML sends an email with the given HTML body
+func sendWithHTML(client *mailnow.Client, html string) error {
+	_, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Hello",
+		HTML:    html,
+	})
+	return err
+}
+
+func TestWithOptionsSharesConnections(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	parent, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	child, err := parent.WithOptions(mailnow.WithDefaultMessageClass(mailnow.MessageClassNotification))
+	if err != nil {
+		t.Fatalf("WithOptions() error = %v", err)
+	}
+
+	for _, client := range []*mailnow.Client{parent, child, parent, child} {
+		if err := sendWithHTML(client, "<p>Hello</p>"); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("server accepted %d connections, want 1 shared connection", n)
+	}
+
+	// Closing the child closes the parent too
+	if err := child.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := sendWithHTML(parent, "<p>Hello</p>"); !errors.Is(err, mailnow.ErrClientClosed) {
+		t.Errorf("SendEmail() on the parent error = %v, want ErrClientClosed", err)
+	}
+}
+
+func TestWithOptionsLayering(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	parent, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithDefaultMessageClass(mailnow.MessageClassTransactional),
+		mailnow.WithContentPolicy(phrasePolicy("wire transfer")))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	marketing, err := parent.WithOptions(
+		mailnow.WithDefaultMessageClass(mailnow.MessageClassMarketing),
+		mailnow.WithContentPolicy(phrasePolicy("guaranteed")))
+	if err != nil {
+		t.Fatalf("WithOptions() error = %v", err)
+	}
+	// A later sibling neither sees nor changes the first child's options
+	billing, err := parent.WithOptions(mailnow.WithContentPolicy(phrasePolicy("overdue")))
+	if err != nil {
+		t.Fatalf("WithOptions() error = %v", err)
+	}
+
+	// Scalar defaults are overridden
+	for _, client := range []*mailnow.Client{parent, marketing, billing} {
+		if err := sendWithHTML(client, "<p>Hello</p>"); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+	requests := server.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("server received %d sends, want 3", len(requests))
+	}
+	for i, want := range []string{"", "bulk", ""} {
+		if got := requests[i].Headers["Precedence"]; got != want {
+			t.Errorf("send %d Precedence = %q, want %q", i, got, want)
+		}
+		if got := requests[i].Headers["Auto-Submitted"]; got != "auto-generated" {
+			t.Errorf("send %d Auto-Submitted = %q", i, got)
+		}
+	}
+
+	// Content policies are added to the parent's
+	var policyErr *mailnow.PolicyViolationError
+	blocked := []struct {
+		client *mailnow.Client
+		phrase string
+		want   bool
+	}{
+		{parent, "wire transfer", true},
+		{parent, "guaranteed", false},
+		{parent, "overdue", false},
+		{marketing, "wire transfer", true},
+		{marketing, "guaranteed", true},
+		{marketing, "overdue", false},
+		{billing, "guaranteed", false},
+		{billing, "overdue", true},
+	}
+	for i, b := range blocked {
+		err := sendWithHTML(b.client, "<p>"+b.phrase+"</p>")
+		if got := errors.As(err, &policyErr); got != b.want {
+			t.Errorf("case %d: %q blocked = %v, want %v (error %v)", i, b.phrase, got, b.want, err)
+		}
+	}
+}
+
+func TestWithOptionsRejectsConnectionOptions(t *testing.T) {
+	parent, err := mailnow.NewClient(mailnowtest.TestAPIKey())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var validationErr *mailnow.ValidationError
+	for name, opt := range map[string]mailnow.Option{
+		"local address":      mailnow.WithLocalAddr("127.0.0.1"),
+		"pinned certificate": mailnow.WithPinnedCertificates("deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"),
+		"connectivity check": mailnow.WithEagerConnectivityCheck(),
+	} {
+		if _, err := parent.WithOptions(opt); !errors.As(err, &validationErr) {
+			t.Errorf("WithOptions(%s) error = %v, want ValidationError", name, err)
+		}
+	}
+	if _, err := parent.WithOptions(mailnow.WithDefaultMessageClass("newsletter")); err == nil {
+		t.Error("expected WithOptions to reject an invalid option")
+	}
+}
@@ -282,7 +282,7 @@ func TestSendEmailHTTPIntegration(t *testing.T) {
 		{
 			name:         "successful response",
 			statusCode:   200,
-			responseBody: `{"success": true, "message_id": "msg_12345", "status": "sent"}`,
+			responseBody: `{"success": true, "data": {"message_id": "msg_12345", "status": "sent"}}`,
 			expectError:  false,
 		},
 		{
@@ -425,3 +425,62 @@ func TestSendEmailHTTPIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClientWithBaseURL(t *testing.T) {
+	_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(""))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for empty base URL, got %T: %v", err, err)
+	}
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL("http://localhost:8080/"))
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if client == nil {
+		t.Errorf("expected non-nil client on success")
+	}
+}
+
+func TestSendEmailDuplicateCampaignSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		if reqBody["campaign_id"] != "spring-sale" {
+			t.Errorf("expected campaign_id spring-sale, got %v", reqBody["campaign_id"])
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": {"code": "duplicate_send", "message": "already sent to this recipient in campaign"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	resp, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:       "sender@example.com",
+		To:         "test@example.com",
+		Subject:    "Test Subject",
+		HTML:       "<h1>Test</h1>",
+		CampaignID: "spring-sale",
+	})
+	if resp != nil {
+		t.Errorf("expected nil response when error occurs, got %v", resp)
+	}
+
+	var dupErr *mailnow.DuplicateSendError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expected DuplicateSendError, got %T: %v", err, err)
+	}
+	if dupErr.CampaignID != "spring-sale" {
+		t.Errorf("expected campaign ID spring-sale, got %s", dupErr.CampaignID)
+	}
+	if dupErr.Recipient != "test@example.com" {
+		t.Errorf("expected recipient test@example.com, got %s", dupErr.Recipient)
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// TestClientConcurrentUse hammers a client configured with the stateful
+// options from many goroutines; run with -race to check for data races.
+func TestClientConcurrentUse(t *testing.T) {
+	server := mailnowtest.NewServer().FailRate(0.2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	archiver := &recordingArchiver{}
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		mailnow.WithMaxInFlight(8),
+		mailnow.WithSendBudget(1000000, time.Hour, mailnow.NewMemoryBudgetStore()),
+		mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()),
+		mailnow.WithVerifiedSenderCheck(time.Minute),
+		mailnow.WithResponseValidation(),
+		mailnow.WithArchiver(archiver),
+		mailnow.WithSendTrace(func(mailnow.SendTrace) {}),
+		mailnow.WithWarningHandler(func(mailnow.Warning) {}),
+		mailnow.WithConnectionDiagnostics(func(mailnow.ConnDiagnostics) {}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	const goroutines, sends = 50, 10
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < sends; i++ {
+				var opts []mailnow.SendOption
+				if i%2 == 0 {
+					// Keys repeat across goroutines to contend on the store
+					opts = append(opts, mailnow.WithIdempotencyKey(fmt.Sprintf("key-%d", i)))
+				}
+				client.SendEmail(context.Background(), newRetryRequest(), opts...)
+				client.InMaintenance()
+				client.InFlight()
+				if i == sends/2 {
+					client.RefreshVerifiedSenders(context.Background())
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := client.InFlight(); n != 0 {
+		t.Errorf("InFlight() after all sends = %d, want 0", n)
+	}
+	if len(archiver.Messages()) == 0 {
+		t.Error("expected archived messages")
+	}
+}
+
+// TestClientPoolConcurrentUse gets clients for a few keys from many
+// goroutines while sending with them
+func TestClientPoolConcurrentUse(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	pool := mailnow.NewClientPool(mailnow.WithBaseURL(server.URL))
+	pool.SetMaxSize(3)
+	keys := []string{mailnowtest.TestAPIKey(), mailnowtest.TestAPIKey(), mailnowtest.TestAPIKey(), mailnowtest.TestAPIKey()}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			client, err := pool.Get(keys[g%len(keys)])
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+			if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+				t.Errorf("SendEmail() error = %v", err)
+			}
+			pool.Len()
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestWithBaseURLValidation(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"htps://api.mailnow.xyz", `not "htps"`},
+		{"api.mailnow.xyz", "must use http or https"},
+		{"https://", "has no host"},
+		{"http:///v1", "has no host"},
+	}
+
+	for _, tt := range tests {
+		_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(tt.url))
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("WithBaseURL(%q) error = %v, want ValidationError containing %q", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestEagerConnectivityCheckReachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL), mailnow.WithEagerConnectivityCheck()); err != nil {
+		t.Errorf("NewClient() error = %v", err)
+	}
+
+	// The check goes through the client's HTTP client
+	transport := &recordingTransport{}
+	if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithHTTPClient(&http.Client{Transport: transport}),
+		mailnow.WithEagerConnectivityCheck()); err != nil {
+		t.Errorf("NewClient() with an HTTP client error = %v", err)
+	}
+	if got := transport.recorded(); len(got) != 1 || !strings.HasPrefix(got[0], "HEAD ") {
+		t.Errorf("transport saw %v, want one HEAD request", got)
+	}
+}
+
+func TestEagerConnectivityCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	start := time.Now()
+	_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL), mailnow.WithEagerConnectivityCheck())
+
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) || !strings.Contains(err.Error(), "NewClient") {
+		t.Fatalf("NewClient() error = %v, want ConnectionError naming NewClient", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("check took %v, want a quick failure", elapsed)
+	}
+
+	// Without the option the client is created
+	if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL)); err != nil {
+		t.Errorf("NewClient() without the check error = %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestInlineCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "class selector",
+			html: `<style>.note { color: red; }</style><p class="note big">Hi</p><p>Plain</p>`,
+			want: `<p class="note big" style="color: red">Hi</p><p>Plain</p>`,
+		},
+		{
+			name: "element selector",
+			html: `<style>p { margin: 0; font-family: "Helvetica", sans-serif }</style><p>Hi</p><br/>`,
+			want: `<p style="margin: 0; font-family: &#34;Helvetica&#34;, sans-serif">Hi</p><br/>`,
+		},
+		{
+			name: "specificity beats source order",
+			html: `<style>#intro { color: blue } .note { color: red } p { color: green; padding: 1px }</style><p id="intro" class="note">Hi</p>`,
+			want: `<p id="intro" class="note" style="color: blue; padding: 1px">Hi</p>`,
+		},
+		{
+			name: "later rule wins at equal specificity",
+			html: `<style>.a { color: red } .b { color: blue }</style><p class="b a">Hi</p>`,
+			want: `<p class="b a" style="color: blue">Hi</p>`,
+		},
+		{
+			name: "existing inline style wins",
+			html: `<style>p { color: red; margin: 0 }</style><p style="color: blue">Hi</p>`,
+			want: `<p style="color: blue; margin: 0">Hi</p>`,
+		},
+		{
+			name: "important beats inline style",
+			html: `<style>p { color: red !important }</style><p style="color: blue">Hi</p>`,
+			want: `<p style="color: red !important">Hi</p>`,
+		},
+		{
+			name: "descendant and child combinators",
+			html: `<style>table td { padding: 4px } .box > p { margin: 0 }</style><table><tr><td>1</td></tr></table><div class="box"><p>a</p><div><p>b</p></div></div>`,
+			want: `<table><tr><td style="padding: 4px">1</td></tr></table><div class="box"><p style="margin: 0">a</p><div><p>b</p></div></div>`,
+		},
+		{
+			name: "attribute selector",
+			html: `<style>a[target="_blank"] { color: red }</style><a href="x" target="_blank">x</a><a href="y">y</a>`,
+			want: `<a href="x" target="_blank" style="color: red">x</a><a href="y">y</a>`,
+		},
+		{
+			name: "media queries and pseudo-classes are kept",
+			html: `<style type="text/css">p { margin: 0 } a:hover { color: red } @media (max-width: 600px) { p { margin: 4px } }</style><p>Hi</p>`,
+			want: "<style type=\"text/css\">\na:hover { color: red }\n@media (max-width: 600px) { p { margin: 4px } }\n</style><p style=\"margin: 0\">Hi</p>",
+		},
+		{
+			name: "malformed css is tolerated",
+			html: `<style>/* comment */ p { color red; margin: 0;; } .x { color: } div { padding: 2px</style><p>Hi</p><div>D</div>`,
+			want: `<p style="margin: 0">Hi</p><div style="padding: 2px">D</div>`,
+		},
+		{
+			name: "script contents are not styled",
+			html: `<style>p { margin: 0 }</style><script>var s = "<p>x</p>";</script><p>Hi</p>`,
+			want: `<script>var s = "<p>x</p>";</script><p style="margin: 0">Hi</p>`,
+		},
+		{
+			name: "no style elements",
+			html: `<p class="x">Hi</p>`,
+			want: `<p class="x">Hi</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mailnow.InlineCSS(tt.html)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineCSSUnterminatedStyle(t *testing.T) {
+	_, err := mailnow.InlineCSS(`<style>p { margin: 0 }<p>Hi</p>`)
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestWithCSSInlining(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithCSSInlining())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	html := `<style>p { margin: 0 }</style><p>Hello</p>`
+	req := &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hello", HTML: html}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	if got := server.Requests()[0].HTML; got != `<p style="margin: 0">Hello</p>` {
+		t.Errorf("unexpected sent HTML %q", got)
+	}
+	if req.HTML != html || !strings.Contains(req.HTML, "<style>") {
+		t.Error("expected the caller's request to be left unchanged")
+	}
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+const callbackSecret = "4f1c9e2a7b3d8e6f0a5c"
+
+// newCallbackRequest creates an alert with a delivery callback
+func newCallbackRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "alerts@example.com",
+		To:      "oncall@example.net",
+		Subject: "Disk usage at 95%",
+		HTML:    "<p>Disk usage on db-1 is at 95%.</p>",
+		DeliveryCallback: &mailnow.CallbackSpec{
+			URL:    "https://hooks.example.com/mailnow/alerts",
+			Secret: callbackSecret,
+			Events: []string{mailnow.CallbackEventDelivered, mailnow.CallbackEventBounced},
+		},
+	}
+}
+
+func TestDeliveryCallbackSerialization(t *testing.T) {
+	var body map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), newCallbackRequest()); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	want := `{"url":"https://hooks.example.com/mailnow/alerts","secret":"` + callbackSecret + `","events":["delivered","bounced"]}`
+	if got := string(body["delivery_callback"]); got != want {
+		t.Errorf("delivery_callback = %s, want %s", got, want)
+	}
+
+	// Without a callback the field is omitted, and without events so are they
+	raw, _ := json.Marshal(&mailnow.EmailRequest{From: "a@example.com", To: "b@example.com"})
+	if strings.Contains(string(raw), "delivery_callback") {
+		t.Errorf("request without a callback = %s", raw)
+	}
+	raw, _ = json.Marshal(&mailnow.CallbackSpec{URL: "https://hooks.example.com", Secret: callbackSecret})
+	if strings.Contains(string(raw), "events") {
+		t.Errorf("callback without events = %s", raw)
+	}
+}
+
+func TestDeliveryCallbackValidation(t *testing.T) {
+	tests := map[string]func(*mailnow.CallbackSpec){
+		"http URL":      func(s *mailnow.CallbackSpec) { s.URL = "http://hooks.example.com/mailnow" },
+		"relative URL":  func(s *mailnow.CallbackSpec) { s.URL = "/mailnow" },
+		"invalid URL":   func(s *mailnow.CallbackSpec) { s.URL = "https://hooks example.com/%zz" },
+		"no secret":     func(s *mailnow.CallbackSpec) { s.Secret = "" },
+		"short secret":  func(s *mailnow.CallbackSpec) { s.Secret = "hunter2" },
+		"unknown event": func(s *mailnow.CallbackSpec) { s.Events = []string{"opened"} },
+		"repeated event": func(s *mailnow.CallbackSpec) {
+			s.Events = []string{mailnow.CallbackEventBounced, mailnow.CallbackEventBounced}
+		},
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := newCallbackRequest()
+			modify(req.DeliveryCallback)
+			var validationErr *mailnow.ValidationError
+			if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+				t.Errorf("ValidateEmailRequest() error = %v, want ValidationError", err)
+			}
+		})
+	}
+
+	if err := mailnow.ValidateEmailRequest(newCallbackRequest()); err != nil {
+		t.Errorf("ValidateEmailRequest() of a valid callback error = %v", err)
+	}
+}
+
+func TestCallbackUnreachableError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": "callback_unreachable", "message": "callback URL did not respond"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), newCallbackRequest())
+	var callbackErr *mailnow.CallbackUnreachableError
+	if !errors.As(err, &callbackErr) {
+		t.Fatalf("SendEmail() error = %T %v, want CallbackUnreachableError", err, err)
+	}
+	if callbackErr.URL != "https://hooks.example.com/mailnow/alerts" {
+		t.Errorf("URL = %q", callbackErr.URL)
+	}
+	if mailnow.ErrorStatusCode(err) != http.StatusBadRequest || mailnow.ErrorCode(err) != "callback_unreachable" || mailnow.IsRetryable(err) {
+		t.Errorf("status %d, code %q, retryable %v", mailnow.ErrorStatusCode(err), mailnow.ErrorCode(err), mailnow.IsRetryable(err))
+	}
+
+	// Other 400s are still validation errors
+	err = mailnow.DefaultErrorMapper(http.StatusBadRequest, []byte(`{"error": {"code": "invalid_request", "message": "bad"}}`))
+	if !errors.As(err, new(*mailnow.ValidationError)) {
+		t.Errorf("DefaultErrorMapper() = %T, want ValidationError", err)
+	}
+}
+
+func TestDeliveryCallbackHandler(t *testing.T) {
+	secrets := map[string]string{"msg_1": callbackSecret, "msg_2": "a-different-secret-value"}
+	var received []*mailnow.DeliveryEvent
+	handler := mailnow.DeliveryCallbackHandler(
+		func(ctx context.Context, messageID string) (string, error) {
+			return secrets[messageID], nil
+		},
+		func(ctx context.Context, event *mailnow.DeliveryEvent) error {
+			received = append(received, event)
+			return nil
+		})
+
+	post := func(body, signature string) int {
+		r := httptest.NewRequest(http.MethodPost, "/mailnow/alerts", strings.NewReader(body))
+		if signature != "" {
+			r.Header.Set(mailnow.DeliveryCallbackSignatureHeader, signature)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	body := `{"message_id": "msg_1", "recipient": "oncall@example.net", "event": "bounced", "reason": "550 5.1.1 user unknown", "timestamp": "2026-03-14T09:00:00Z"}`
+	if code := post(body, mailnow.SignDeliveryCallback(callbackSecret, []byte(body))); code != http.StatusNoContent {
+		t.Fatalf("signed callback status = %d, want 204", code)
+	}
+	if len(received) != 1 || received[0].MessageID != "msg_1" || received[0].Event != mailnow.CallbackEventBounced || received[0].Reason != "550 5.1.1 user unknown" {
+		t.Fatalf("received = %+v", received)
+	}
+
+	// The secret of another message does not verify, nor does a tampered body
+	other := strings.Replace(body, "msg_1", "msg_2", 1)
+	for name, tt := range map[string]struct{ body, signature string }{
+		"other message's secret": {other, mailnow.SignDeliveryCallback(callbackSecret, []byte(other))},
+		"tampered body":          {strings.Replace(body, "bounced", "delivered", 1), mailnow.SignDeliveryCallback(callbackSecret, []byte(body))},
+		"unknown message":        {strings.Replace(body, "msg_1", "msg_3", 1), mailnow.SignDeliveryCallback(callbackSecret, []byte(body))},
+		"no signature":           {body, ""},
+	} {
+		if code := post(tt.body, tt.signature); code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, code)
+		}
+	}
+	if code := post(`{"message_id":`, "sha256=00"); code != http.StatusBadRequest {
+		t.Errorf("malformed callback status = %d, want 400", code)
+	}
+	if len(received) != 1 {
+		t.Errorf("unverified callbacks were handled: %+v", received[1:])
+	}
+}
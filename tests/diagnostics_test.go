@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestConnectionDiagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "data": {"message_id": "msg_12345", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var diagnostics []mailnow.ConnDiagnostics
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithConnectionDiagnostics(func(d mailnow.ConnDiagnostics) {
+			mu.Lock()
+			diagnostics = append(diagnostics, d)
+			mu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test Subject",
+		HTML:    "<h1>Test</h1>",
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendEmail(context.Background(), req); err != nil {
+			t.Fatalf("send %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(diagnostics))
+	}
+
+	first, second := diagnostics[0], diagnostics[1]
+	if first.Reused {
+		t.Error("expected first request to use a new connection")
+	}
+	if first.Connect <= 0 {
+		t.Errorf("expected non-zero connect duration on first request, got %v", first.Connect)
+	}
+	if !second.Reused {
+		t.Error("expected second request to reuse the connection")
+	}
+	if second.Connect != 0 {
+		t.Errorf("expected no connect on reused connection, got %v", second.Connect)
+	}
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	for i, d := range diagnostics {
+		if d.RemoteAddr != addr {
+			t.Errorf("request %d: expected remote address %s, got %s", i+1, addr, d.RemoteAddr)
+		}
+	}
+}
+
+func TestConnectionDiagnosticsNilCallback(t *testing.T) {
+	_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithConnectionDiagnostics(nil))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for nil callback, got %T: %v", err, err)
+	}
+}
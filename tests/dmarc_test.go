@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func newAlignmentRequest(from string, replyTo ...string) *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    from,
+		To:      "customer@example.net",
+		Subject: "Your order",
+		HTML:    "<p>Thanks</p>",
+		ReplyTo: replyTo,
+	}
+}
+
+func TestCheckDMARCAlignment(t *testing.T) {
+	verified := []string{"example.com", "mail.example.org"}
+
+	tests := []struct {
+		name  string
+		req   *mailnow.EmailRequest
+		codes []string
+		fails bool
+	}{
+		{"aligned", newAlignmentRequest("billing@example.com", "support@example.com"), nil, false},
+		{"aligned case-insensitive", newAlignmentRequest("billing@Example.COM"), nil, false},
+		{"strict mismatch", newAlignmentRequest("billing@example-shop.com"), []string{"from_domain_not_verified"}, true},
+		{"relaxed subdomain", newAlignmentRequest("news@news.example.com"), []string{"relaxed_alignment"}, false},
+		{"relaxed parent domain", newAlignmentRequest("news@example.org"), []string{"relaxed_alignment"}, false},
+		{"freemail reply-to", newAlignmentRequest("billing@example.com", "billing.team@gmail.com"), []string{"freemail_reply_to"}, false},
+		{"freemail from and reply-to", newAlignmentRequest("someone@gmail.com", "someone@yahoo.com"), []string{"from_domain_not_verified"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := mailnow.CheckDMARCAlignment(tt.req, verified)
+			if len(issues) != len(tt.codes) {
+				t.Fatalf("issues = %+v, want codes %v", issues, tt.codes)
+			}
+			fails := false
+			for i, issue := range issues {
+				if issue.Code != tt.codes[i] {
+					t.Errorf("issue %d code = %q, want %q", i, issue.Code, tt.codes[i])
+				}
+				fails = fails || issue.Fails
+			}
+			if fails != tt.fails {
+				t.Errorf("fails = %v, want %v", fails, tt.fails)
+			}
+		})
+	}
+
+	// Without verified domains only Reply-To is checked
+	if issues := mailnow.CheckDMARCAlignment(newAlignmentRequest("billing@example-shop.com"), nil); len(issues) != 0 {
+		t.Errorf("issues without verified domains = %+v, want none", issues)
+	}
+}
+
+func TestLintFreemailReplyTo(t *testing.T) {
+	report := mailnow.LintEmailRequest(newAlignmentRequest("billing@example.com", "billing.team@gmail.com"))
+	found := false
+	for _, w := range report.Warnings {
+		if w.Code == "freemail_reply_to" && w.Field == "ReplyTo" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("warnings = %+v, want freemail_reply_to", report.Warnings)
+	}
+	if report.HasErrors() {
+		t.Errorf("errors = %+v, want none", report.Errors)
+	}
+}
+
+func TestDMARCAlignmentCheck(t *testing.T) {
+	server := newIdentityServer(testIdentities)
+	defer server.Close()
+
+	var warnings []mailnow.Warning
+	client := newTestClient(t, server.URL, mailnow.WithVerifiedSenderCheck(time.Hour),
+		mailnow.WithDMARCAlignmentCheck(),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+
+	// A fully aligned send passes silently
+	if _, err := client.SendEmail(context.Background(), newAlignmentRequest("alerts@example.com")); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+
+	// A freemail Reply-To is sent with a warning
+	if _, err := client.SendEmail(context.Background(), newAlignmentRequest("alerts@example.com", "alerts.team@outlook.com")); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Code != "freemail_reply_to" {
+		t.Errorf("warnings = %v, want freemail_reply_to", warnings)
+	}
+
+	// Unverified senders are still rejected by the verified sender check
+	_, err := client.SendEmail(context.Background(), newAlignmentRequest("alerts@example-shop.com"))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("SendEmail() error = %v, want ValidationError", err)
+	}
+	if n := server.sends.Load(); n != 2 {
+		t.Errorf("server received %d sends, want 2", n)
+	}
+}
+
+func TestDMARCAlignmentCheckRequiresVerifiedSenderCheck(t *testing.T) {
+	var validationErr *mailnow.ValidationError
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithDMARCAlignmentCheck()); !errors.As(err, &validationErr) {
+		t.Errorf("NewClient() error = %v, want ValidationError", err)
+	}
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.WithOptions(mailnow.WithDMARCAlignmentCheck()); !errors.As(err, &validationErr) {
+		t.Errorf("WithOptions() error = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func newTestEncryptor(t *testing.T, keyID string, fill byte) *mailnow.AESGCMEncryptor {
+	t.Helper()
+	e, err := mailnow.NewAESGCMEncryptor(keyID, bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatalf("NewAESGCMEncryptor() error = %v", err)
+	}
+	return e
+}
+
+func TestAESGCMEncryptorRoundTrip(t *testing.T) {
+	e := newTestEncryptor(t, "k1", 1)
+	plaintext := []byte(`{"to": "recipient@example.com"}`)
+
+	data, err := e.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !mailnow.IsEncrypted(data) || bytes.Contains(data, plaintext) {
+		t.Error("expected an envelope without the plaintext")
+	}
+	got, err := e.Decrypt(data)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Decrypt() = %q, %v, want the plaintext", got, err)
+	}
+
+	// Each encryption uses a fresh nonce
+	again, _ := e.Encrypt(plaintext)
+	if bytes.Equal(data, again) {
+		t.Error("expected different ciphertexts for the same plaintext")
+	}
+}
+
+func TestAESGCMEncryptorTampered(t *testing.T) {
+	e := newTestEncryptor(t, "k1", 1)
+	data, _ := e.Encrypt([]byte("secret"))
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := e.Decrypt(tampered); err == nil {
+		t.Error("expected error for modified ciphertext")
+	}
+	if _, err := e.Decrypt(data[:len(data)-20]); err == nil {
+		t.Error("expected error for truncated ciphertext")
+	}
+
+	// Renaming the key in the header is detected too
+	other := newTestEncryptor(t, "k2", 1)
+	renamed := bytes.Replace(data, []byte("k1"), []byte("k2"), 1)
+	if _, err := other.Decrypt(renamed); err == nil {
+		t.Error("expected error for a modified key ID")
+	}
+}
+
+func TestEncryptorLegacyPlaintext(t *testing.T) {
+	e := newTestEncryptor(t, "k1", 1)
+	legacy := []byte(`{"request": {}}`)
+	if got, err := e.Decrypt(legacy); err != nil || !bytes.Equal(got, legacy) {
+		t.Errorf("Decrypt(plaintext) = %q, %v, want it unchanged", got, err)
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	oldKey := newTestEncryptor(t, "2025", 1)
+	newKey := newTestEncryptor(t, "2026", 2)
+	oldData, _ := oldKey.Encrypt([]byte("old"))
+
+	ring, err := mailnow.NewKeyRing(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+	if got, err := ring.Decrypt(oldData); err != nil || string(got) != "old" {
+		t.Errorf("Decrypt(old) = %q, %v", got, err)
+	}
+
+	newData, err := ring.Encrypt([]byte("new"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if _, err := oldKey.Decrypt(newData); !errors.Is(err, mailnow.ErrUnknownEncryptionKey) {
+		t.Errorf("old key Decrypt(new) error = %v, want ErrUnknownEncryptionKey", err)
+	}
+	if got, err := newKey.Decrypt(newData); err != nil || string(got) != "new" {
+		t.Errorf("new key Decrypt(new) = %q, %v", got, err)
+	}
+
+	if _, err := mailnow.NewKeyRing(newKey, newTestEncryptor(t, "2026", 3)); err == nil {
+		t.Error("expected error for duplicate key IDs")
+	}
+}
+
+func TestNewAESGCMEncryptorInvalid(t *testing.T) {
+	if _, err := mailnow.NewAESGCMEncryptor("k1", make([]byte, 16)); err == nil {
+		t.Error("expected error for a 16-byte key")
+	}
+	if _, err := mailnow.NewAESGCMEncryptor("", make([]byte, 32)); err == nil {
+		t.Error("expected error for an empty key ID")
+	}
+}
+
+func TestEncryptedDirArchiver(t *testing.T) {
+	dir := t.TempDir()
+	msg := mailnow.ArchivedMessage{
+		Request:   &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Private", HTML: "<p>Secret</p>"},
+		Response:  &mailnow.EmailResponse{Data: mailnow.Data{MessageID: "msg_1", Status: "queued"}},
+		StartedAt: time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// A file written before encryption was enabled
+	if err := mailnow.NewDirArchiver(dir).Archive(context.Background(), msg); err != nil {
+		t.Fatalf("plain archive failed: %v", err)
+	}
+
+	archiver, err := mailnow.NewEncryptedDirArchiver(dir, newTestEncryptor(t, "k1", 1))
+	if err != nil {
+		t.Fatalf("NewEncryptedDirArchiver() error = %v", err)
+	}
+	if err := archiver.Archive(context.Background(), msg); err != nil {
+		t.Fatalf("encrypted archive failed: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		data, _ := os.ReadFile(filepath.Join(dir, entry.Name()))
+		encrypted := strings.HasSuffix(entry.Name(), ".json.enc")
+		if encrypted == bytes.Contains(data, []byte("Secret")) {
+			t.Errorf("file %s: encrypted = %v but plaintext visible = %v", entry.Name(), encrypted, !encrypted)
+		}
+
+		loaded, err := archiver.Load(entry.Name())
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", entry.Name(), err)
+		}
+		if loaded.Request.Subject != "Private" || loaded.Response.Data.MessageID != "msg_1" {
+			t.Errorf("Load(%s) = %+v", entry.Name(), loaded)
+		}
+	}
+}
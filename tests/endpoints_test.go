@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// newGatewayServer returns a server that only serves the send endpoint at
+// a nonstandard route and the default tracking domains route
+func newGatewayServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gateway/mail/send", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	})
+	mux.HandleFunc(mailnow.TrackingDomainsEndpoint+"/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"domain": "links.example.com"}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithEndpointsSendPath(t *testing.T) {
+	server := newGatewayServer(t)
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithEndpoints(mailnow.Endpoints{SendEmail: "/gateway/mail/send"}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Errorf("SendEmail() error = %v", err)
+	}
+
+	// Other endpoints keep their default paths
+	if _, err := client.GetTrackingDomain(context.Background(), "links.example.com"); err != nil {
+		t.Errorf("GetTrackingDomain() error = %v", err)
+	}
+}
+
+func TestAPIEndpoints(t *testing.T) {
+	server := newGatewayServer(t)
+	api := &mailnow.API{
+		APIKey:    "mn_test_7e59df7ce4a14545b443837804ec9722",
+		BaseURL:   server.URL,
+		Endpoints: mailnow.Endpoints{SendEmail: "/gateway/mail/send"},
+	}
+
+	if _, _, err := api.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Errorf("SendEmail() error = %v", err)
+	}
+	if _, _, err := api.GetTrackingDomain(context.Background(), "links.example.com"); err != nil {
+		t.Errorf("GetTrackingDomain() error = %v", err)
+	}
+}
+
+func TestWithEndpointsInvalid(t *testing.T) {
+	for _, endpoints := range []mailnow.Endpoints{
+		{SendEmail: "gateway/send"},
+		{SendEmail: "https://evil.example.com/send"},
+		{Assets: "//evil.example.com/assets"},
+		{Email: "/v1/email?debug=1"},
+		{TrackingDomains: "/v1/domains#frag"},
+	} {
+		if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithEndpoints(endpoints)); err == nil {
+			t.Errorf("WithEndpoints(%+v) expected error", endpoints)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestDecodeEnvelope(t *testing.T) {
+	t.Run("success envelope", func(t *testing.T) {
+		body := []byte(`{"success": true, "message": "email queued", "status_code": 202, "data": {"message_id": "msg_1", "status": "queued"}}`)
+
+		data, env, err := mailnow.DecodeEnvelope[mailnow.Data](body)
+		if err != nil {
+			t.Fatalf("DecodeEnvelope() unexpected error: %v", err)
+		}
+		if data.MessageID != "msg_1" || data.Status != "queued" {
+			t.Errorf("unexpected data %+v", data)
+		}
+		if !env.Success || env.Message != "email queued" || env.StatusCode != 202 {
+			t.Errorf("unexpected envelope %+v", env)
+		}
+	})
+
+	t.Run("success false surfaces the message", func(t *testing.T) {
+		body := []byte(`{"success": false, "message": "sender domain is suspended", "status_code": 200, "data": null}`)
+
+		data, env, err := mailnow.DecodeEnvelope[mailnow.Data](body)
+		var serverErr *mailnow.ServerError
+		if !errors.As(err, &serverErr) {
+			t.Fatalf("expected ServerError, got %T: %v", err, err)
+		}
+		if err.Error() != "sender domain is suspended" {
+			t.Errorf("expected envelope message as error text, got %q", err.Error())
+		}
+		if data != nil {
+			t.Errorf("expected nil data, got %+v", data)
+		}
+		if env == nil || env.Success {
+			t.Errorf("expected envelope to be returned, got %+v", env)
+		}
+	})
+
+	t.Run("missing data", func(t *testing.T) {
+		for _, body := range []string{
+			`{"success": true, "message": "ok"}`,
+			`{"success": true, "message": "ok", "data": null}`,
+		} {
+			_, _, err := mailnow.DecodeEnvelope[mailnow.Data]([]byte(body))
+			var serverErr *mailnow.ServerError
+			if !errors.As(err, &serverErr) {
+				t.Errorf("expected ServerError for %s, got %T: %v", body, err, err)
+			}
+		}
+	})
+
+	t.Run("array data", func(t *testing.T) {
+		body := []byte(`{"success": true, "data": [{"domain": "a.example.com"}, {"domain": "b.example.com"}]}`)
+
+		data, _, err := mailnow.DecodeEnvelope[[]mailnow.TrackingDomain](body)
+		if err != nil {
+			t.Fatalf("DecodeEnvelope() unexpected error: %v", err)
+		}
+		if len(*data) != 2 || (*data)[1].Domain != "b.example.com" {
+			t.Errorf("unexpected data %+v", *data)
+		}
+	})
+
+	t.Run("data of the wrong shape", func(t *testing.T) {
+		body := []byte(`{"success": true, "data": "not an object"}`)
+
+		_, _, err := mailnow.DecodeEnvelope[mailnow.Data](body)
+		var serverErr *mailnow.ServerError
+		if !errors.As(err, &serverErr) {
+			t.Errorf("expected ServerError, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, _, err := mailnow.DecodeEnvelope[mailnow.Data]([]byte(`not json`))
+		var serverErr *mailnow.ServerError
+		if !errors.As(err, &serverErr) {
+			t.Errorf("expected ServerError, got %T: %v", err, err)
+		}
+	})
+}
+
+func TestSendEmailEnvelopeShapes(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantMessageID string
+	}{
+		{name: "flat shape", body: `{"success": true, "message_id": "msg_flat", "status": "sent"}`, wantMessageID: "msg_flat"},
+		{name: "success false", body: `{"success": false, "message": "sender domain is suspended", "data": {"message_id": "msg_1", "status": "failed"}}`, wantMessageID: "msg_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			// By default the body is accepted as it is
+			client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			resp, err := client.SendEmail(context.Background(), newRetryRequest())
+			if err != nil {
+				t.Fatalf("SendEmail() error = %v", err)
+			}
+			if resp.Data.MessageID != tt.wantMessageID {
+				t.Errorf("MessageID = %q, want %q", resp.Data.MessageID, tt.wantMessageID)
+			}
+
+			// Strict envelopes reject it
+			client, err = mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithStrictEnvelopes())
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			_, err = client.SendEmail(context.Background(), newRetryRequest())
+			var serverErr *mailnow.ServerError
+			if !errors.As(err, &serverErr) {
+				t.Errorf("SendEmail() with strict envelopes error = %v, want ServerError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// fieldError is a custom error for 422 responses with field-level errors
+type fieldError struct {
+	Fields map[string]string
+}
+
+func (e *fieldError) Error() string {
+	return "invalid fields"
+}
+
+// gatewayMapper maps 422 responses to fieldError and delegates the rest
+func gatewayMapper(statusCode int, body []byte, defaultErr error) error {
+	if statusCode != http.StatusUnprocessableEntity {
+		return defaultErr
+	}
+	var resp struct {
+		Fields map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return defaultErr
+	}
+	return &fieldError{Fields: resp.Fields}
+}
+
+// newStatusServer returns a server that responds with status and body
+func newStatusServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+// sendWithMapper sends a valid email to server with mapper configured
+func sendWithMapper(t *testing.T, server *httptest.Server, mapper mailnow.ErrorMapper) error {
+	t.Helper()
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL), mailnow.WithErrorMapper(mapper))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Hello",
+		HTML:    "<p>Hello</p>",
+	})
+	return err
+}
+
+func TestErrorMapperOverride(t *testing.T) {
+	server := newStatusServer(http.StatusUnprocessableEntity, `{"fields": {"to": "unknown domain"}}`)
+	defer server.Close()
+
+	err := sendWithMapper(t, server, gatewayMapper)
+	var fieldErr *fieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("expected fieldError, got %T: %v", err, err)
+	}
+	if fieldErr.Fields["to"] != "unknown domain" {
+		t.Errorf("unexpected fields %v", fieldErr.Fields)
+	}
+}
+
+func TestErrorMapperDelegates(t *testing.T) {
+	server := newStatusServer(http.StatusTooManyRequests, `{"error": {"code": "rate_limited", "message": "slow down"}}`)
+	defer server.Close()
+
+	err := sendWithMapper(t, server, gatewayMapper)
+	var rateLimitErr *mailnow.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected RateLimitError, got %T: %v", err, err)
+	}
+	if mailnow.ErrorCode(err) != "rate_limited" {
+		t.Errorf("expected code rate_limited, got %q", mailnow.ErrorCode(err))
+	}
+}
+
+func TestErrorMapperRawBody(t *testing.T) {
+	server := newStatusServer(http.StatusPaymentRequired, "quota exhausted")
+	defer server.Close()
+
+	var gotBody string
+	var gotDefault error
+	err := sendWithMapper(t, server, func(statusCode int, body []byte, defaultErr error) error {
+		gotBody = string(body)
+		gotDefault = defaultErr
+		return nil
+	})
+
+	if gotBody != "quota exhausted" {
+		t.Errorf("expected raw body, got %q", gotBody)
+	}
+	// A nil result keeps the default error
+	if err == nil || err != gotDefault {
+		t.Errorf("expected default error %v, got %v", gotDefault, err)
+	}
+	var serverErr *mailnow.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Errorf("expected ServerError, got %T", err)
+	}
+}
+
+func TestDefaultErrorMapper(t *testing.T) {
+	err := mailnow.DefaultErrorMapper(http.StatusBadRequest, []byte(`{"error": {"code": "invalid_to", "message": "bad recipient"}}`))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+	if err.Error() != "bad recipient" || mailnow.ErrorCode(err) != "invalid_to" || mailnow.ErrorStatusCode(err) != 400 {
+		t.Errorf("unexpected error details: %v, %q, %d", err, mailnow.ErrorCode(err), mailnow.ErrorStatusCode(err))
+	}
+
+	if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithErrorMapper(nil)); err == nil {
+		t.Error("expected error for nil mapper")
+	}
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// newSenderServer returns a server that rejects sends from the addresses
+// in rejected with the given status and code, and records every sender
+func newSenderServer(t *testing.T, status int, code string, rejected ...string) (*httptest.Server, *[]string) {
+	var senders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		senders = append(senders, req.From)
+		for _, addr := range rejected {
+			if req.From == addr {
+				w.WriteHeader(status)
+				w.Write([]byte(`{"error": {"code": "` + code + `", "message": "sender rejected"}}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &senders
+}
+
+func newFallbackRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "primary@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    "<p>Test</p>",
+	}
+}
+
+func TestFromFallbackUsed(t *testing.T) {
+	server, senders := newSenderServer(t, http.StatusForbidden, "sender_not_verified", "primary@example.com")
+	client := newTestClient(t, server.URL, mailnow.WithFromFallbacks("backup@example.com", "last@example.com"))
+
+	req := newFallbackRequest()
+	resp, err := client.SendEmail(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if resp.FallbackFrom != "backup@example.com" {
+		t.Errorf("expected FallbackFrom backup@example.com, got %q", resp.FallbackFrom)
+	}
+	if got := strings.Join(*senders, ","); got != "primary@example.com,backup@example.com" {
+		t.Errorf("senders = %s, want primary then backup", got)
+	}
+	if req.From != "primary@example.com" {
+		t.Errorf("SendEmail() modified the request sender to %s", req.From)
+	}
+}
+
+func TestFromFallbackIdempotencyKeyAndRecords(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		keys = append(keys, r.Header.Get(mailnow.IdempotencyKeyHeader))
+		if req.From == "primary@example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": {"code": "sender_not_verified", "message": "sender rejected"}}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	archiver := &recordingArchiver{}
+	history, err := mailnow.NewMemoryHistoryStore(10)
+	if err != nil {
+		t.Fatalf("failed to create history store: %v", err)
+	}
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithFromFallbacks("backup@example.com"),
+		mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()),
+		mailnow.WithArchiver(archiver),
+		mailnow.WithHistory(history, time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newFallbackRequest(), mailnow.WithIdempotencyKey("order-1042")); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if got := strings.Join(keys, ","); got != "order-1042,order-1042:backup@example.com" {
+		t.Errorf("idempotency keys = %s, want a derived key for the fallback", got)
+	}
+
+	msgs := archiver.Messages()
+	if len(msgs) != 1 || msgs[0].Request.From != "backup@example.com" {
+		t.Errorf("archived messages = %+v, want one from the fallback sender", msgs)
+	}
+	entries, err := client.History(context.Background(), mailnow.HistoryFilter{})
+	if err != nil || len(entries) != 1 || entries[0].FallbackFrom != "backup@example.com" {
+		t.Errorf("History() = %+v, %v, want one entry with the fallback sender", entries, err)
+	}
+}
+
+func TestFromFallbacksExhausted(t *testing.T) {
+	server, senders := newSenderServer(t, http.StatusForbidden, "sender_not_verified",
+		"primary@example.com", "backup@example.com", "last@example.com")
+	client := newTestClient(t, server.URL, mailnow.WithFromFallbacks("backup@example.com", "last@example.com"))
+
+	_, err := client.SendEmail(context.Background(), newFallbackRequest())
+	var authErr *mailnow.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("SendEmail() expected AuthError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "primary@example.com, backup@example.com, last@example.com") {
+		t.Errorf("SendEmail() error %q does not list the attempted senders", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Error("SendEmail() error does not wrap the original error")
+	}
+	if code := mailnow.ErrorCode(err); code != "sender_not_verified" {
+		t.Errorf("ErrorCode() = %q, want sender_not_verified", code)
+	}
+	if len(*senders) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(*senders))
+	}
+}
+
+func TestFromFallbackNotUsedForOtherErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+	}{
+		{name: "other 403", status: http.StatusForbidden, code: "forbidden"},
+		{name: "server error", status: http.StatusInternalServerError, code: "sender_not_verified"},
+		{name: "validation error", status: http.StatusBadRequest, code: "validation_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, senders := newSenderServer(t, tt.status, tt.code, "primary@example.com")
+			client := newTestClient(t, server.URL, mailnow.WithFromFallbacks("backup@example.com", "last@example.com"))
+
+			resp, err := client.SendEmail(context.Background(), newFallbackRequest())
+			if err == nil {
+				t.Fatalf("SendEmail() expected error, got response %+v", resp)
+			}
+			if len(*senders) != 1 {
+				t.Errorf("expected no fallback, got senders %v", *senders)
+			}
+		})
+	}
+}
+
+func TestWithFromFallbacksInvalid(t *testing.T) {
+	for _, addrs := range [][]string{nil, {"not-an-email"}, {"backup@example.com", ""}} {
+		_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithFromFallbacks(addrs...))
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("WithFromFallbacks(%q) expected ValidationError, got %v", addrs, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// postStatuses sends n plain POSTs to the server and counts the statuses
+func postStatuses(t *testing.T, server *mailnowtest.Server, n int) map[int]int {
+	t.Helper()
+	statuses := make(map[int]int)
+	for i := 0; i < n; i++ {
+		resp, err := http.Post(server.URL+"/v1/email/send", "application/json", strings.NewReader(`{"to": "recipient@example.com"}`))
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+		statuses[resp.StatusCode]++
+	}
+	return statuses
+}
+
+func TestServerFailRate(t *testing.T) {
+	server := mailnowtest.NewServer().Seed(42).FailRate(0.3, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	statuses := postStatuses(t, server, 1000)
+	if failed := statuses[http.StatusServiceUnavailable]; failed < 250 || failed > 350 {
+		t.Errorf("expected about 300 of 1000 requests to fail, got %d", failed)
+	}
+	if statuses[http.StatusOK]+statuses[http.StatusServiceUnavailable] != 1000 {
+		t.Errorf("unexpected statuses %v", statuses)
+	}
+	if len(server.Requests()) != statuses[http.StatusOK] {
+		t.Errorf("expected only accepted requests to be recorded, got %d", len(server.Requests()))
+	}
+
+	// The same seed reproduces the same faults
+	again := mailnowtest.NewServer().Seed(42).FailRate(0.3, http.StatusServiceUnavailable)
+	defer again.Close()
+	if got := postStatuses(t, again, 1000); got[http.StatusServiceUnavailable] != statuses[http.StatusServiceUnavailable] {
+		t.Errorf("reseeded server failed %d requests, want %d", got[http.StatusServiceUnavailable], statuses[http.StatusServiceUnavailable])
+	}
+
+	// Faults can be changed at runtime
+	server.ResetFaults()
+	if got := postStatuses(t, server, 50); got[http.StatusOK] != 50 {
+		t.Errorf("expected all requests to succeed after ResetFaults, got %v", got)
+	}
+}
+
+func TestServerScenarios(t *testing.T) {
+	server := mailnowtest.NewServer().Seed(7).Apply(mailnowtest.RateLimitStorm())
+	defer server.Close()
+
+	statuses := postStatuses(t, server, 200)
+	if limited := statuses[http.StatusTooManyRequests]; limited < 140 || limited > 180 {
+		t.Errorf("expected about 160 of 200 requests to be rate limited, got %d", limited)
+	}
+
+	server.ResetFaults().Apply(mailnowtest.Rolling502s())
+	statuses = postStatuses(t, server, 100)
+	if failed := statuses[http.StatusBadGateway]; failed < 15 || failed > 45 {
+		t.Errorf("expected about 30 of 100 requests to fail with 502, got %d", failed)
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	server := mailnowtest.NewServer().Seed(1).Latency(20*time.Millisecond, 20*time.Millisecond)
+	defer server.Close()
+
+	start := time.Now()
+	postStatuses(t, server, 10)
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("expected 10 requests to take about 300ms, took %s", elapsed)
+	}
+}
+
+func TestServerSlowBody(t *testing.T) {
+	server := mailnowtest.NewServer().SlowBody(500)
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The success body is about 120 bytes, so it takes about 200ms at 500 B/s
+	start := time.Now()
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected a slow response body, took %s", elapsed)
+	}
+}
+
+func TestServerDropConnections(t *testing.T) {
+	server := mailnowtest.NewServer().DropConnections(1)
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.SendEmail(context.Background(), newRetryRequest())
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("SendEmail() expected ConnectionError, got %T: %v", err, err)
+	}
+	if len(server.Requests()) != 0 {
+		t.Errorf("expected no recorded requests, got %d", len(server.Requests()))
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func fieldErrorsOf(t *testing.T, body string) (*mailnow.ValidationError, map[string]string) {
+	t.Helper()
+	var validationErr *mailnow.ValidationError
+	if err := mailnow.DefaultErrorMapper(http.StatusBadRequest, []byte(body)); !errors.As(err, &validationErr) {
+		t.Fatalf("DefaultErrorMapper() = %v, want ValidationError", err)
+	}
+	return validationErr, validationErr.FieldErrors()
+}
+
+func TestFieldErrorsObjectForm(t *testing.T) {
+	err, fields := fieldErrorsOf(t, `{"error": {"code": "validation_error", "message": "invalid request",
+		"details": {"fields": {"to": "invalid", "subject": "too long"}}}}`)
+
+	if len(fields) != 2 || fields["to"] != "invalid" || fields["subject"] != "too long" {
+		t.Errorf("FieldErrors() = %v", fields)
+	}
+	if want := "invalid request (subject: too long, to: invalid)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestFieldErrorsArrayForm(t *testing.T) {
+	_, fields := fieldErrorsOf(t, `{"error": {"code": "validation_error", "message": "invalid request",
+		"details": {"errors": [{"field": "from", "message": "not verified"}, {"field": "html", "message": "required"}]}}}`)
+
+	if len(fields) != 2 || fields["from"] != "not verified" || fields["html"] != "required" {
+		t.Errorf("FieldErrors() = %v", fields)
+	}
+}
+
+func TestFieldErrorsMalformed(t *testing.T) {
+	_, fields := fieldErrorsOf(t, `{"error": {"code": "validation_error", "message": "invalid request",
+		"details": {"fields": [{"field": "to", "message": {"message": "nested"}}, "junk", {"message": "no field"}, {"field": 3}],
+		"errors": {"cc": {"deep": [1, 2]}, "bcc": null}}}}`)
+
+	want := map[string]string{"to": "nested", "cc": "map[deep:[1 2]]", "bcc": "invalid"}
+	if len(fields) != len(want) {
+		t.Fatalf("FieldErrors() = %v, want %v", fields, want)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("FieldErrors()[%q] = %q, want %q", k, fields[k], v)
+		}
+	}
+}
+
+func TestFieldErrorsAbsent(t *testing.T) {
+	err, fields := fieldErrorsOf(t, `{"error": {"code": "validation_error", "message": "invalid request"}}`)
+	if fields != nil {
+		t.Errorf("FieldErrors() = %v, want nil", fields)
+	}
+	if err.Error() != "invalid request" {
+		t.Errorf("Error() = %q, want the API message", err.Error())
+	}
+
+	if fields := mailnow.NewValidationError("local", nil).FieldErrors(); fields != nil {
+		t.Errorf("FieldErrors() of a local error = %v, want nil", fields)
+	}
+}
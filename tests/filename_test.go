@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"clean ASCII", "invoice-2026.pdf", "invoice-2026.pdf", false},
+		{"traversal", "../../etc/passwd", "passwd", false},
+		{"windows path", `C:\Users\anna\report.docx`, "report.docx", false},
+		{"non-ASCII", "Rechnung Müller – März.pdf", "Rechnung Müller – März.pdf", false},
+		{"emoji", "🎉 party invite.ics", "🎉 party invite.ics", false},
+		{"only directories", "../..", "", true},
+		{"control character", "report\x00.pdf", "", true},
+		{"newline", "report\r\n.pdf", "", true},
+		{"reserved character", "what?.pdf", "", true},
+		{"overlong", strings.Repeat("a", mailnow.MaxFilenameLength) + ".pdf", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mailnow.SanitizeFilename(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SanitizeFilename() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var validationErr *mailnow.ValidationError
+			if err != nil && !errors.As(err, &validationErr) {
+				t.Errorf("SanitizeFilename() error type = %T, want ValidationError", err)
+			}
+			if got != tt.want {
+				t.Errorf("SanitizeFilename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	a := mailnow.Attachment{Filename: "../../etc/passwd", Content: "SGVsbG8="}
+	if name, err := a.SanitizedFilename(); err != nil || name != "passwd" {
+		t.Errorf("SanitizedFilename() = %q, %v", name, err)
+	}
+	if err := mailnow.ValidateAttachment(mailnow.Attachment{Filename: "a\x07.pdf", Content: "SGVsbG8="}); err == nil {
+		t.Error("ValidateAttachment() accepted a control character in the filename")
+	}
+}
+
+func TestEncodeFilenameRFC2231(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"invoice.pdf", "invoice.pdf"},
+		{"Rechnung Müller – März.pdf", "UTF-8''Rechnung%20M%C3%BCller%20%E2%80%93%20M%C3%A4rz.pdf"},
+		{"🎉.ics", "UTF-8''%F0%9F%8E%89.ics"},
+	}
+	for _, tt := range tests {
+		if got := mailnow.EncodeFilenameRFC2231(tt.input); got != tt.want {
+			t.Errorf("EncodeFilenameRFC2231(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestWireFilenames(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	newRequest := func() *mailnow.EmailRequest {
+		return &mailnow.EmailRequest{
+			From:    "sender@example.com",
+			To:      "recipient@example.com",
+			Subject: "Your invoice",
+			HTML:    "<p>Attached</p>",
+			Attachments: []mailnow.Attachment{
+				{Filename: "../../exports/Rechnung März.pdf", Content: "SGVsbG8=", ContentType: "application/pdf"},
+				{Filename: "terms.pdf", Content: "SGVsbG8=", ContentType: "application/pdf"},
+			},
+		}
+	}
+
+	plain := newTestClient(t, server.URL)
+	req := newRequest()
+	if _, err := plain.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if req.Attachments[0].Filename != "../../exports/Rechnung März.pdf" {
+		t.Errorf("SendEmail() modified the caller's attachment filename to %q", req.Attachments[0].Filename)
+	}
+
+	encoding, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithEncodedFilenames())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := encoding.SendEmail(context.Background(), newRequest()); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("server received %d sends, want 2", len(requests))
+	}
+	if got := requests[0].Attachments[0].Filename; got != "Rechnung März.pdf" {
+		t.Errorf("sent filename = %q, want the sanitized name", got)
+	}
+	if got := requests[1].Attachments[0].Filename; got != "UTF-8''Rechnung%20M%C3%A4rz.pdf" {
+		t.Errorf("sent filename = %q, want the encoded name", got)
+	}
+	if got := requests[1].Attachments[1].Filename; got != "terms.pdf" {
+		t.Errorf("sent filename = %q, want it unchanged", got)
+	}
+}
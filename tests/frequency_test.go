@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestFrequencyCap(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithFrequencyCap(3, 24*time.Hour, nil))
+
+	// Addresses with the same canonical form share a count
+	for _, to := range []string{"jane.doe@gmail.com", "JaneDoe+alerts@gmail.com", "janedoe@googlemail.com"} {
+		if _, err := sendTo(client, to, "Alert"); err != nil {
+			t.Fatalf("SendEmail(%s) error = %v", to, err)
+		}
+	}
+
+	_, err := sendTo(client, "jane.doe@gmail.com", "Alert")
+	var capErr *mailnow.FrequencyCapError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("SendEmail() over the cap error = %v, want FrequencyCapError", err)
+	}
+	if capErr.Count != 3 || capErr.Limit != 3 || capErr.Recipient != "jane.doe@gmail.com" || !capErr.ResetAt.Equal(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("FrequencyCapError = %+v", capErr)
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Errorf("server received %d sends, want 3", n)
+	}
+
+	// Other recipients are not affected
+	if _, err := sendTo(client, "john@example.com", "Alert"); err != nil {
+		t.Errorf("SendEmail() to another recipient error = %v", err)
+	}
+
+	// The cap resets with the next window
+	clock.Advance(15 * time.Hour)
+	if _, err := sendTo(client, "jane.doe@gmail.com", "Alert"); err != nil {
+		t.Errorf("SendEmail() in the next window error = %v", err)
+	}
+}
+
+func TestFrequencyCapWeeklyWindow(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	// Weekly windows are aligned to the Unix epoch, a Thursday
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithClock(clock.Now),
+		mailnow.WithFrequencyCap(1, 7*24*time.Hour, nil))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := sendTo(client, "jane@example.com", "Digest"); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	_, err = sendTo(client, "jane@example.com", "Digest")
+	var capErr *mailnow.FrequencyCapError
+	if !errors.As(err, &capErr) || !capErr.ResetAt.Equal(time.Date(2026, 3, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("SendEmail() over the cap error = %v, want FrequencyCapError resetting on Thursday", err)
+	}
+
+	// Monday is still in the same window
+	clock.Advance(48 * time.Hour)
+	if _, err := sendTo(client, "jane@example.com", "Digest"); !errors.As(err, &capErr) {
+		t.Errorf("SendEmail() on Monday error = %v, want FrequencyCapError", err)
+	}
+
+	clock.Advance(84 * time.Hour)
+	if _, err := sendTo(client, "jane@example.com", "Digest"); err != nil {
+		t.Errorf("SendEmail() in the next window error = %v", err)
+	}
+}
+
+func TestFrequencyCapSkipsFailedSends(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	server := newStatsServer(t, clock)
+	store := mailnow.NewMemoryFrequencyStore()
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithFrequencyCap(3, 24*time.Hour, store))
+
+	for i := 0; i < 5; i++ {
+		if _, err := sendTo(client, "fail-0@example.com", "Alert"); errors.As(err, new(*mailnow.FrequencyCapError)) {
+			t.Fatalf("failed send %d was counted: %v", i, err)
+		}
+	}
+
+	// A client sharing the store shares the counts
+	other := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithFrequencyCap(3, 24*time.Hour, store))
+	for i := 0; i < 3; i++ {
+		if _, err := sendTo(client, "ok-0@example.com", "Alert"); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+	if _, err := sendTo(other, "ok-0@example.com", "Alert"); !errors.As(err, new(*mailnow.FrequencyCapError)) {
+		t.Errorf("SendEmail() from a client sharing the store error = %v, want FrequencyCapError", err)
+	}
+}
+
+func TestFrequencyCapKeepsUnknownOutcomes(t *testing.T) {
+	// The response is cut off after the request was written, so the email
+	// may have been sent
+	truncating, _ := newTruncatingServer(t)
+
+	// The API accepted the send but the response fails validation
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "unexpected", "status": "queued"}}`))
+	}))
+	defer invalid.Close()
+
+	for name, url := range map[string]string{"truncated response": truncating.URL, "invalid response": invalid.URL} {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+			client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+				mailnow.WithBaseURL(url),
+				mailnow.WithClock(clock.Now),
+				mailnow.WithResponseValidation(),
+				mailnow.WithFrequencyCap(1, 24*time.Hour, nil),
+				mailnow.WithSendBudget(1, 24*time.Hour, nil))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if _, err := sendTo(client, "jane@example.com", "Alert"); err == nil {
+				t.Fatal("expected the first send to fail")
+			}
+
+			_, err = sendTo(client, "jane@example.com", "Alert")
+			var capErr *mailnow.FrequencyCapError
+			if !errors.As(err, &capErr) {
+				t.Errorf("SendEmail() to the same recipient error = %v, want FrequencyCapError", err)
+			}
+			_, err = sendTo(client, "john@example.com", "Alert")
+			var budgetErr *mailnow.BudgetExceededError
+			if !errors.As(err, &budgetErr) {
+				t.Errorf("SendEmail() to another recipient error = %v, want BudgetExceededError", err)
+			}
+		})
+	}
+}
+
+func TestFrequencyCapOptionErrors(t *testing.T) {
+	for name, opt := range map[string]mailnow.Option{
+		"zero cap":    mailnow.WithFrequencyCap(0, time.Hour, nil),
+		"zero window": mailnow.WithFrequencyCap(3, 0, nil),
+	} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opt); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
+
+func TestFrequencyCapConcurrentSends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithFrequencyCap(3, 24*time.Hour, nil))
+
+	var wg sync.WaitGroup
+	var sent, capped atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sendTo(client, "jane.doe@gmail.com", "Alert")
+			switch {
+			case err == nil:
+				sent.Add(1)
+			case errors.As(err, new(*mailnow.FrequencyCapError)):
+				capped.Add(1)
+			default:
+				t.Errorf("SendEmail() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if sent.Load() != 3 || capped.Load() != 7 {
+		t.Errorf("sent %d and capped %d, want 3 and 7", sent.Load(), capped.Load())
+	}
+}
+
+func TestMemoryFrequencyStore(t *testing.T) {
+	ctx := context.Background()
+	store := mailnow.NewMemoryFrequencyStore()
+	for i := 1; i <= 2; i++ {
+		if n, _ := store.Incr(ctx, "a", time.Hour); n != i {
+			t.Fatalf("Incr() = %d, want %d", n, i)
+		}
+	}
+	store.Decr(ctx, "a")
+	if n, _ := store.Count(ctx, "a"); n != 1 {
+		t.Errorf("Count() after Decr = %d, want 1", n)
+	}
+
+	// Expired keys start over
+	store.Incr(ctx, "b", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if n, _ := store.Count(ctx, "b"); n != 0 {
+		t.Errorf("Count() of an expired key = %d, want 0", n)
+	}
+	if n, _ := store.Incr(ctx, "b", time.Hour); n != 1 {
+		t.Errorf("Incr() of an expired key = %d, want 1", n)
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// guardAllowed is an environment guard covering both environments
+var guardAllowed = map[mailnow.KeyEnvironment][]string{
+	mailnow.EnvironmentLive: {"example.com"},
+	mailnow.EnvironmentTest: {"*.staging.example.com", "example.test"},
+}
+
+// sendFrom sends an email from the given address with client
+func sendFrom(client *mailnow.Client, from string) error {
+	_, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    from,
+		To:      "recipient@example.com",
+		Subject: "Hello",
+		HTML:    "<p>Hello</p>",
+	})
+	return err
+}
+
+func TestEnvironmentGuard(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	live, err := mailnow.NewClient(mailnowtest.LiveAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithEnvironmentGuard(guardAllowed))
+	if err != nil {
+		t.Fatalf("failed to create live client: %v", err)
+	}
+	test, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithEnvironmentGuard(guardAllowed))
+	if err != nil {
+		t.Fatalf("failed to create test client: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		client  *mailnow.Client
+		from    string
+		wantErr bool
+	}{
+		{"live key prod domain", live, "sender@example.com", false},
+		{"live key prod domain case", live, "sender@EXAMPLE.com", false},
+		{"live key staging domain", live, "sender@eu.staging.example.com", true},
+		{"test key prod domain", test, "sender@example.com", true},
+		{"test key wildcard", test, "sender@eu.staging.example.com", false},
+		{"test key wildcard base", test, "sender@staging.example.com", true},
+		{"test key wildcard suffix", test, "sender@notstaging.example.com", true},
+		{"test key exact", test, "sender@example.test", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sendFrom(tt.client, tt.from)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("expected send to succeed, got %v", err)
+				}
+				return
+			}
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "is not allowed with a") {
+				t.Errorf("expected mismatch explanation, got %q", err.Error())
+			}
+		})
+	}
+
+	// Rejected sends never reach the API
+	if got := len(server.Requests()); got != 4 {
+		t.Errorf("expected 4 requests to reach the server, got %d", got)
+	}
+}
+
+func TestEnvironmentGuardWarnOnly(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithEnvironmentGuard(map[mailnow.KeyEnvironment][]string{mailnow.EnvironmentLive: {"example.com"}}),
+		mailnow.WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "does not cover test keys") {
+		t.Errorf("expected warning about the unguarded test environment, got %q", logs.String())
+	}
+
+	// The test environment is not checked
+	if err := sendFrom(client, "sender@anything.example"); err != nil {
+		t.Errorf("expected unguarded send to succeed, got %v", err)
+	}
+}
+
+func TestEnvironmentGuardOptionErrors(t *testing.T) {
+	tests := map[string]map[mailnow.KeyEnvironment][]string{
+		"empty":           {},
+		"unknown env":     {"staging": {"example.com"}},
+		"no domains":      {mailnow.EnvironmentLive: nil},
+		"invalid domain":  {mailnow.EnvironmentLive: {"https://example.com"}},
+		"invalid pattern": {mailnow.EnvironmentTest: {"*.com"}},
+	}
+	for name, allowed := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithEnvironmentGuard(allowed)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if env := mailnow.APIKeyEnvironment(mailnowtest.LiveAPIKey()); env != mailnow.EnvironmentLive {
+		t.Errorf("expected live environment, got %q", env)
+	}
+}
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// newTestClient creates a client for the test server at url with opts
+func newTestClient(t *testing.T, url string, opts ...mailnow.Option) *mailnow.Client {
+	t.Helper()
+	opts = append([]mailnow.Option{mailnow.WithBaseURL(url)}, opts...)
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opts...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+// fakeClock is a settable clock for WithClock
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// newHistoryStore creates an empty history store for a test
+func newHistoryStore(t *testing.T) *mailnow.MemoryHistoryStore {
+	t.Helper()
+	store, err := mailnow.NewMemoryHistoryStore(100)
+	if err != nil {
+		t.Fatalf("NewMemoryHistoryStore() error = %v", err)
+	}
+	return store
+}
+
+func sendHistoryEmail(t *testing.T, client *mailnow.Client, to, subject, campaign string, opts ...mailnow.SendOption) error {
+	t.Helper()
+	_, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:       "sender@example.com",
+		To:         to,
+		Subject:    subject,
+		HTML:       "<p>Hi</p>",
+		CampaignID: campaign,
+	}, opts...)
+	return err
+}
+
+func TestHistoryRecordsSuccessAndFailure(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.SubjectContains("reject"), mailnowtest.Fail(http.StatusBadRequest, "validation_error", "rejected"))
+
+	client := newTestClient(t, server.URL, mailnow.WithHistory(newHistoryStore(t), time.Hour))
+	if err := sendHistoryEmail(t, client, "ada@example.com", "Welcome", ""); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if err := sendHistoryEmail(t, client, "ada@example.com", "Please reject", ""); err == nil {
+		t.Fatal("SendEmail() expected error")
+	}
+
+	entries, err := client.History(context.Background(), mailnow.HistoryFilter{})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	var ok, failed mailnow.HistoryEntry
+	for _, e := range entries {
+		if e.Status == mailnow.HistoryStatusFailed {
+			failed = e
+		} else {
+			ok = e
+		}
+	}
+	if ok.MessageID == "" || ok.Status != "queued" || ok.Subject != "Welcome" {
+		t.Errorf("success entry = %+v", ok)
+	}
+	if failed.MessageID != "" || !strings.Contains(failed.Error, "rejected") {
+		t.Errorf("failure entry = %+v", failed)
+	}
+}
+
+func TestHistoryFilters(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.SubjectContains("reject"), mailnowtest.Fail(http.StatusBadRequest, "validation_error", "rejected"))
+
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithHistory(newHistoryStore(t), 24*time.Hour))
+	start := clock.Now()
+	sendHistoryEmail(t, client, "ada@example.com", "One", "spring", mailnow.WithCategory("marketing"))
+	clock.Advance(time.Hour)
+	sendHistoryEmail(t, client, "grace@example.com", "Two", "spring")
+	clock.Advance(time.Hour)
+	sendHistoryEmail(t, client, "ADA@example.com", "Three reject", "")
+
+	tests := []struct {
+		name   string
+		filter mailnow.HistoryFilter
+		want   []string
+	}{
+		{"recipient", mailnow.HistoryFilter{Recipient: "ada@example.com"}, []string{"Three reject", "One"}},
+		{"category", mailnow.HistoryFilter{Category: "marketing"}, []string{"One"}},
+		{"campaign", mailnow.HistoryFilter{CampaignID: "spring"}, []string{"Two", "One"}},
+		{"status", mailnow.HistoryFilter{Status: mailnow.HistoryStatusFailed}, []string{"Three reject"}},
+		{"time range", mailnow.HistoryFilter{Since: start.Add(30 * time.Minute), Until: start.Add(90 * time.Minute)}, []string{"Two"}},
+		{"limit", mailnow.HistoryFilter{Limit: 1}, []string{"Three reject"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := client.History(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Subject)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("History() subjects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithHistory(newHistoryStore(t), time.Hour))
+	sendHistoryEmail(t, client, "ada@example.com", "Old", "")
+	clock.Advance(2 * time.Hour)
+	sendHistoryEmail(t, client, "ada@example.com", "New", "")
+
+	entries, _ := client.History(context.Background(), mailnow.HistoryFilter{})
+	if len(entries) != 1 || entries[0].Subject != "New" {
+		t.Errorf("History() = %+v, want only the new entry", entries)
+	}
+}
+
+func TestHistoryRecipientHashing(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, mailnow.WithHistory(newHistoryStore(t), time.Hour))
+	sendHistoryEmail(t, client, "ada@example.com", "Hi", "")
+	entries, _ := client.History(context.Background(), mailnow.HistoryFilter{})
+	if len(entries) != 1 || entries[0].Recipient != mailnow.HashRecipient("ada@example.com") || strings.Contains(entries[0].Recipient, "ada") {
+		t.Errorf("recipient = %q, want the hash", entries[0].Recipient)
+	}
+
+	client = newTestClient(t, server.URL, mailnow.WithHistory(newHistoryStore(t), time.Hour), mailnow.WithHistoryPlaintextRecipients())
+	sendHistoryEmail(t, client, "ada@example.com", "Hi", "")
+	entries, _ = client.History(context.Background(), mailnow.HistoryFilter{Recipient: "ada@example.com"})
+	if len(entries) != 1 || entries[0].Recipient != "ada@example.com" {
+		t.Errorf("History() = %+v, want the plaintext recipient", entries)
+	}
+}
+
+func TestMemoryHistoryStoreCapacity(t *testing.T) {
+	store, _ := mailnow.NewMemoryHistoryStore(2)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, subject := range []string{"a", "b", "c"} {
+		store.Add(context.Background(), mailnow.HistoryEntry{Subject: subject, SentAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	entries, _ := store.Query(context.Background(), mailnow.HistoryFilter{})
+	if len(entries) != 2 || entries[0].Subject != "c" || entries[1].Subject != "b" {
+		t.Errorf("Query() = %+v, want c and b", entries)
+	}
+
+	store.Prune(context.Background(), base.Add(2*time.Minute))
+	entries, _ = store.Query(context.Background(), mailnow.HistoryFilter{})
+	if len(entries) != 1 || entries[0].Subject != "c" {
+		t.Errorf("Query() after prune = %+v, want c", entries)
+	}
+}
+
+func TestHistoryNotEnabled(t *testing.T) {
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.History(context.Background(), mailnow.HistoryFilter{}); err == nil {
+		t.Error("History() expected error without WithHistory")
+	}
+}
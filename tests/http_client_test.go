@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// recordingTransport records the requests it sends, as instrumentation
+// would
+type recordingTransport struct {
+	mu         sync.Mutex
+	paths      []string
+	idleClosed bool
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.paths = append(rt.paths, req.Method+" "+req.URL.Path)
+	rt.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (rt *recordingTransport) CloseIdleConnections() {
+	rt.mu.Lock()
+	rt.idleClosed = true
+	rt.mu.Unlock()
+}
+
+func (rt *recordingTransport) recorded() []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return append([]string(nil), rt.paths...)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	httpClient := &http.Client{Transport: transport}
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithHTTPClient(httpClient))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	resp, err := mailnow.MakeRequest(context.Background(), httpClient, http.MethodPost, server.URL+mailnow.EmailSendEndpoint, mailnowtest.TestAPIKey(), &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Hello",
+		HTML:    "<p>Hello</p>",
+	})
+	if err != nil {
+		t.Fatalf("MakeRequest() error = %v", err)
+	}
+	resp.Body.Close()
+
+	want := "POST " + mailnow.EmailSendEndpoint
+	if got := transport.recorded(); len(got) != 2 || got[0] != want || got[1] != want {
+		t.Errorf("transport saw %v, want two sends", got)
+	}
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("server received %d sends, want 2", n)
+	}
+
+	// Close leaves the caller's connections alone
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if transport.idleClosed {
+		t.Error("Close closed the idle connections of the caller's client")
+	}
+}
+
+func TestWithHTTPClientErrors(t *testing.T) {
+	var validationErr *mailnow.ValidationError
+	for name, opts := range map[string][]mailnow.Option{
+		"nil client": {mailnow.WithHTTPClient(nil)},
+		"with TLS config": {
+			mailnow.WithHTTPClient(&http.Client{}),
+			mailnow.WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+		},
+		"with local address": {
+			mailnow.WithHTTPClient(&http.Client{}),
+			mailnow.WithLocalAddr("127.0.0.1"),
+		},
+	} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opts...); !errors.As(err, &validationErr) {
+			t.Errorf("%s: NewClient() error = %v, want ValidationError", name, err)
+		}
+	}
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.WithOptions(mailnow.WithHTTPClient(&http.Client{})); !errors.As(err, &validationErr) {
+		t.Errorf("WithOptions(WithHTTPClient) error = %v, want ValidationError", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -268,6 +269,66 @@ func TestHandleResponseErrorMapping(t *testing.T) {
 			},
 			wantErrType: &mailnow.AuthError{},
 		},
+		{
+			name:       "403 Forbidden - ServerError",
+			statusCode: http.StatusForbidden,
+			errorBody: mailnow.ErrorResponse{
+				Error: struct {
+					Code    string                 `json:"code"`
+					Message string                 `json:"message"`
+					Details map[string]interface{} `json:"details,omitempty"`
+				}{
+					Code:    "forbidden",
+					Message: "API key cannot access this resource",
+				},
+			},
+			wantErrType: &mailnow.ServerError{},
+		},
+		{
+			name:       "403 Unknown Sub-account - AuthError",
+			statusCode: http.StatusForbidden,
+			errorBody: mailnow.ErrorResponse{
+				Error: struct {
+					Code    string                 `json:"code"`
+					Message string                 `json:"message"`
+					Details map[string]interface{} `json:"details,omitempty"`
+				}{
+					Code:    "subaccount_not_found",
+					Message: "Sub-account not found",
+				},
+			},
+			wantErrType: &mailnow.AuthError{},
+		},
+		{
+			name:       "408 Request Timeout - ConnectionError",
+			statusCode: http.StatusRequestTimeout,
+			errorBody: mailnow.ErrorResponse{
+				Error: struct {
+					Code    string                 `json:"code"`
+					Message string                 `json:"message"`
+					Details map[string]interface{} `json:"details,omitempty"`
+				}{
+					Code:    "request_timeout",
+					Message: "Request timed out",
+				},
+			},
+			wantErrType: &mailnow.ConnectionError{},
+		},
+		{
+			name:       "425 Too Early - ServerError",
+			statusCode: http.StatusTooEarly,
+			errorBody: mailnow.ErrorResponse{
+				Error: struct {
+					Code    string                 `json:"code"`
+					Message string                 `json:"message"`
+					Details map[string]interface{} `json:"details,omitempty"`
+				}{
+					Code:    "too_early",
+					Message: "Request sent too early",
+				},
+			},
+			wantErrType: &mailnow.ServerError{},
+		},
 		{
 			name:       "429 Too Many Requests - RateLimitError",
 			statusCode: http.StatusTooManyRequests,
@@ -372,6 +433,11 @@ func TestHandleResponseErrorMapping(t *testing.T) {
 				if !errors.As(err, &se) {
 					t.Errorf("handleResponse() error type = %T, want ServerError", err)
 				}
+			case *mailnow.ConnectionError:
+				var ce *mailnow.ConnectionError
+				if !errors.As(err, &ce) {
+					t.Errorf("handleResponse() error type = %T, want ConnectionError", err)
+				}
 			}
 
 			// Verify error message contains the expected message
@@ -427,3 +493,60 @@ func TestConnectionError(t *testing.T) {
 		t.Errorf("makeRequest() error type = %T, want ConnectionError", err)
 	}
 }
+
+// TestConnectionErrorDeadlineHints tests that connection errors describe the request that failed
+func TestConnectionErrorDeadlineHints(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	apiKey := "mn_test_secret_key_that_must_not_leak"
+	client := &http.Client{Timeout: 5 * time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := mailnow.MakeRequest(ctx, client, "POST", server.URL, apiKey, nil)
+	if err == nil {
+		t.Fatal("MakeRequest() expected connection error, got nil")
+	}
+
+	var ce *mailnow.ConnectionError
+	if !errors.As(err, &ce) {
+		t.Fatalf("MakeRequest() error type = %T, want ConnectionError", err)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if ce.Host != host {
+		t.Errorf("Host = %q, want %q", ce.Host, host)
+	}
+	if ce.Attempt != 1 {
+		t.Errorf("Attempt = %d, want 1", ce.Attempt)
+	}
+	if ce.Elapsed < 50*time.Millisecond {
+		t.Errorf("Elapsed = %v, want at least 50ms", ce.Elapsed)
+	}
+	if ce.Deadline.IsZero() {
+		t.Error("Deadline should be set when the context has a deadline")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+
+	msg := err.Error()
+	if !strings.Contains(msg, host) {
+		t.Errorf("error message %q should contain host %q", msg, host)
+	}
+	if !strings.Contains(msg, "deadline 50ms") {
+		t.Errorf("error message %q should contain the effective deadline", msg)
+	}
+	if strings.Contains(msg, apiKey) {
+		t.Errorf("error message %q must not contain the API key", msg)
+	}
+}
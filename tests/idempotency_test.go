@@ -0,0 +1,373 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// sendReceipt sends a receipt email with the idempotency key
+func sendReceipt(client *mailnow.Client, to, key string) (*mailnow.EmailResponse, error) {
+	return client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "billing@example.com",
+		To:      to,
+		Subject: "Payment receipt",
+		HTML:    "<p>Thanks for your payment</p>",
+	}, mailnow.WithIdempotencyKey(key))
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()))
+
+	first, err := sendReceipt(client, "customer@example.com", "payment-1")
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	replay, err := sendReceipt(client, "customer@example.com", "payment-1")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if replay.Data.MessageID != first.Data.MessageID {
+		t.Errorf("expected replay to return message %s, got %s", first.Data.MessageID, replay.Data.MessageID)
+	}
+	if got := len(server.Requests()); got != 1 {
+		t.Errorf("expected 1 API call, got %d", got)
+	}
+
+	// A different key sends again
+	if _, err := sendReceipt(client, "customer@example.com", "payment-2"); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if got := len(server.Requests()); got != 2 {
+		t.Errorf("expected 2 API calls, got %d", got)
+	}
+}
+
+func TestIdempotencyConcurrentSends(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("example.com"), mailnowtest.Delay(50*time.Millisecond))
+	client := newTestClient(t, server.URL, mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()))
+
+	var wg sync.WaitGroup
+	var sent, inUse atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sendReceipt(client, "customer@example.com", "payment-1")
+			switch {
+			case err == nil:
+				sent.Add(1)
+			case errors.Is(err, mailnow.ErrIdempotencyKeyInUse):
+				inUse.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(server.Requests()); got != 1 {
+		t.Errorf("expected exactly 1 API call, got %d", got)
+	}
+	if sent.Load()+inUse.Load() != 10 || sent.Load() < 1 {
+		t.Errorf("unexpected outcomes: %d sent, %d in use", sent.Load(), inUse.Load())
+	}
+}
+
+func TestIdempotencyReleaseOnFailure(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("bounce.test"), mailnowtest.Fail(http.StatusBadRequest, "recipient_rejected", "rejected"))
+	store := mailnow.NewMemoryIdempotencyStore()
+	client := newTestClient(t, server.URL, mailnow.WithIdempotencyStore(store))
+
+	// A definite failure releases the key so a corrected send can proceed
+	if _, err := sendReceipt(client, "nobody@bounce.test", "payment-1"); err == nil {
+		t.Fatal("expected bounce to fail")
+	}
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err != nil {
+		t.Fatalf("expected retry after release to succeed, got %v", err)
+	}
+}
+
+func TestIdempotencyCrashBetweenReserveAndComplete(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	store := mailnow.NewMemoryIdempotencyStore()
+
+	// A previous process reserved the key and crashed before completing
+	if done, _, err := store.Reserve(context.Background(), "payment-1"); done || err != nil {
+		t.Fatalf("unexpected reserve result %v, %v", done, err)
+	}
+
+	client := newTestClient(t, server.URL, mailnow.WithIdempotencyStore(store))
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); !errors.Is(err, mailnow.ErrIdempotencyKeyInUse) {
+		t.Fatalf("expected ErrIdempotencyKeyInUse, got %v", err)
+	}
+
+	// After checking the email was not sent, releasing lets it proceed
+	if err := store.Release(context.Background(), "payment-1"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err != nil {
+		t.Fatalf("expected send after release to succeed, got %v", err)
+	}
+	if got := len(server.Requests()); got != 1 {
+		t.Errorf("expected 1 API call, got %d", got)
+	}
+}
+
+func TestIdempotencyUnknownOutcomeKeepsKey(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("example.com"), mailnowtest.FailWith(mailnow.NewConnectionError("reset", nil)))
+	store := mailnow.NewMemoryIdempotencyStore()
+	client := newTestClient(t, server.URL, mailnow.WithIdempotencyStore(store))
+
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err == nil {
+		t.Fatal("expected connection failure")
+	}
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); !errors.Is(err, mailnow.ErrIdempotencyKeyInUse) {
+		t.Errorf("expected the key to stay reserved, got %v", err)
+	}
+}
+
+func TestIdempotencyUndecodableResponseKeepsKey(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"success": true, "data": `))
+	}))
+	defer server.Close()
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The API accepted the send, so the key must not be reused
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err == nil {
+		t.Fatal("expected the malformed response to fail")
+	}
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); !errors.Is(err, mailnow.ErrIdempotencyKeyInUse) {
+		t.Errorf("expected the key to stay reserved, got %v", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server received %d sends, want 1", n)
+	}
+}
+
+func TestIdempotencyUnsentFailureReleasesKey(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+	store := mailnow.NewMemoryIdempotencyStore()
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithIdempotencyStore(store),
+		mailnow.WithMaxInFlight(1))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// Hold the only in-flight slot
+	done := make(chan error)
+	go func() {
+		_, err := sendReceipt(client, "first@example.com", "payment-0")
+		done <- err
+	}()
+	for client.InFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A send cancelled while waiting for a slot was never written
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.SendEmail(ctx, &mailnow.EmailRequest{
+		From:    "billing@example.com",
+		To:      "customer@example.com",
+		Subject: "Payment receipt",
+		HTML:    "<p>Thanks for your payment</p>",
+	}, mailnow.WithIdempotencyKey("payment-1"))
+	if err == nil {
+		t.Fatal("expected the in-flight wait to fail")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first send error = %v", err)
+	}
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err != nil {
+		t.Errorf("send after an unsent failure error = %v, want the key released", err)
+	}
+}
+
+func TestIdempotencyKeyRequiresStore(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	_, err := sendReceipt(client, "customer@example.com", "payment-1")
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestIdempotencyAPIReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"flagged success", http.StatusOK, `{"success": true, "status_code": 200, "idempotent_replay": true, "data": {"message_id": "msg_original", "status": "sent"}}`},
+		{"conflict with original ID", http.StatusConflict, `{"error": {"code": "idempotent_replay", "message": "idempotency key already used", "details": {"message_id": "msg_original", "status": "sent"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			var key atomic.Value
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				key.Store(r.Header.Get(mailnow.IdempotencyKeyHeader))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			// The local store does not know the key, as after a restart
+			store := mailnow.NewMemoryIdempotencyStore()
+			client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithIdempotencyStore(store))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			resp, err := sendReceipt(client, "customer@example.com", "payment-1")
+			if err != nil {
+				t.Fatalf("SendEmail() error = %v", err)
+			}
+			if !resp.Replayed || resp.Data.MessageID != "msg_original" || resp.Data.Status != "sent" {
+				t.Errorf("response = %+v, want a replay of msg_original", resp)
+			}
+			if got := key.Load(); got != "payment-1" {
+				t.Errorf("%s header = %v, want payment-1", mailnow.IdempotencyKeyHeader, got)
+			}
+			if n := client.ReplayedSends(); n != 1 {
+				t.Errorf("ReplayedSends() = %d, want 1", n)
+			}
+
+			// The replay completed the key in the local store
+			again, err := sendReceipt(client, "customer@example.com", "payment-1")
+			if err != nil || !again.Replayed || again.Data.MessageID != "msg_original" {
+				t.Errorf("second SendEmail() = %+v, %v", again, err)
+			}
+			if n := hits.Load(); n != 1 {
+				t.Errorf("server received %d sends, want 1", n)
+			}
+			if n := client.ReplayedSends(); n != 2 {
+				t.Errorf("ReplayedSends() = %d, want 2", n)
+			}
+		})
+	}
+}
+
+// forgetfulStore is an IdempotencyStore that never remembers a key, as
+// when the local entry has expired before the API's
+type forgetfulStore struct{}
+
+func (forgetfulStore) Reserve(context.Context, string) (bool, *mailnow.EmailResponse, error) {
+	return false, nil, nil
+}
+
+func (forgetfulStore) Complete(context.Context, string, *mailnow.EmailResponse) error {
+	return nil
+}
+
+func (forgetfulStore) Release(context.Context, string) error {
+	return nil
+}
+
+func TestIdempotencyAPIReplayWithResponseValidation(t *testing.T) {
+	replays := map[string]struct {
+		status int
+		body   string
+	}{
+		"flagged success":           {http.StatusOK, `{"success": true, "status_code": 200, "idempotent_replay": true, "data": {"message_id": "msg_original", "status": "sent"}}`},
+		"conflict with original ID": {http.StatusConflict, `{"error": {"code": "idempotent_replay", "message": "idempotency key already used", "details": {"message_id": "msg_original", "status": "sent"}}}`},
+	}
+	for name, replay := range replays {
+		t.Run(name, func(t *testing.T) {
+			var hits atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if hits.Add(1) == 1 {
+					w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_original", "status": "queued"}}`))
+					return
+				}
+				w.WriteHeader(replay.status)
+				w.Write([]byte(replay.body))
+			}))
+			defer server.Close()
+
+			client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+				mailnow.WithBaseURL(server.URL),
+				mailnow.WithIdempotencyStore(forgetfulStore{}),
+				mailnow.WithResponseValidation())
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err != nil {
+				t.Fatalf("first SendEmail() error = %v", err)
+			}
+			resp, err := sendReceipt(client, "customer@example.com", "payment-1")
+			if err != nil {
+				t.Fatalf("retried SendEmail() error = %v, want a replay", err)
+			}
+			if !resp.Replayed || resp.Data.MessageID != "msg_original" {
+				t.Errorf("response = %+v, want a replay of msg_original", resp)
+			}
+		})
+	}
+}
+
+func TestIdempotencyFreshSendNotReplayed(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()))
+
+	resp, err := sendReceipt(client, "customer@example.com", "payment-1")
+	if err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if resp.Replayed || client.ReplayedSends() != 0 {
+		t.Errorf("fresh send Replayed = %v, ReplayedSends() = %d", resp.Replayed, client.ReplayedSends())
+	}
+
+	// A 409 without the replay code is still an error
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": {"code": "conflict", "message": "conflict", "details": {"message_id": "msg_1"}}}`))
+	}))
+	defer plain.Close()
+	client, err = mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(plain.URL), mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := sendReceipt(client, "customer@example.com", "payment-1"); err == nil {
+		t.Error("expected a 409 without the replay code to fail")
+	}
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestParseInboundEmailJSON(t *testing.T) {
+	payload := `{
+		"from": "customer@example.com",
+		"to": "support@example.org",
+		"subject": "Re: Your order",
+		"headers": {"Message-ID": "<reply-1@example.com>", "In-Reply-To": "<msg-1@example.org>"},
+		"text": "Thanks!",
+		"html": "<p>Thanks!</p>",
+		"attachments": [{"filename": "photo.png", "content": "iVBORw0KGgo=", "content_type": "image/png"}],
+		"raw": "RnJvbTogY3VzdG9tZXJAZXhhbXBsZS5jb20="
+	}`
+	r := httptest.NewRequest("POST", "/inbound", strings.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	email, err := mailnow.ParseInboundEmail(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email.From != "customer@example.com" || email.To != "support@example.org" || email.Subject != "Re: Your order" {
+		t.Errorf("unexpected addressing %+v", email)
+	}
+	if email.MessageID != "<reply-1@example.com>" || email.InReplyTo != "<msg-1@example.org>" {
+		t.Errorf("expected IDs from headers, got %q, %q", email.MessageID, email.InReplyTo)
+	}
+	if len(email.Attachments) != 1 || email.Attachments[0].Filename != "photo.png" {
+		t.Errorf("unexpected attachments %+v", email.Attachments)
+	}
+	if string(email.RawMIME) != "From: customer@example.com" {
+		t.Errorf("unexpected raw MIME %q", email.RawMIME)
+	}
+}
+
+func TestParseInboundEmailMultipart(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("from", "customer@example.com")
+	w.WriteField("subject", "Re: Invoice")
+	w.WriteField("in_reply_to", "<msg-2@example.org>")
+	w.WriteField("text", "See attached")
+
+	first, _ := w.CreateFormFile("attachment1", "notes.txt")
+	first.Write([]byte("hello"))
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="attachment2"; filename="scan.pdf"`)
+	header.Set("Content-Type", "application/pdf")
+	second, _ := w.CreatePart(header)
+	second.Write([]byte("%PDF"))
+	w.Close()
+
+	r := httptest.NewRequest("POST", "/inbound", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	email, err := mailnow.ParseInboundEmail(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email.From != "customer@example.com" || email.Text != "See attached" || email.InReplyTo != "<msg-2@example.org>" {
+		t.Errorf("unexpected fields %+v", email)
+	}
+
+	want := []mailnow.Attachment{
+		{Filename: "notes.txt", Content: "aGVsbG8=", ContentType: "application/octet-stream"},
+		{Filename: "scan.pdf", Content: "JVBERg==", ContentType: "application/pdf"},
+	}
+	if len(email.Attachments) != len(want) {
+		t.Fatalf("expected %d attachments, got %d", len(want), len(email.Attachments))
+	}
+	for i := range want {
+		if email.Attachments[i] != want[i] {
+			t.Errorf("attachment %d: got %+v, want %+v", i, email.Attachments[i], want[i])
+		}
+		if err := mailnow.ValidateAttachment(email.Attachments[i]); err != nil {
+			t.Errorf("attachment %d is not valid for sending: %v", i, err)
+		}
+	}
+}
+
+func TestParseInboundEmailMissingFields(t *testing.T) {
+	r := httptest.NewRequest("POST", "/inbound", strings.NewReader(`{"from": "customer@example.com"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	email, err := mailnow.ParseInboundEmail(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email.From != "customer@example.com" || email.Subject != "" || email.MessageID != "" || email.Attachments != nil || email.RawMIME != nil {
+		t.Errorf("unexpected email %+v", email)
+	}
+}
+
+// repeatReader is an endless reader of one byte
+type repeatReader byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestParseInboundEmailErrors(t *testing.T) {
+	oversized := io.MultiReader(strings.NewReader(`{"text": "`), io.LimitReader(repeatReader('a'), mailnow.MaxInboundPayloadSize+1), strings.NewReader(`"}`))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        io.Reader
+		wantMessage string
+	}{
+		{"oversized", "application/json", oversized, "exceeds"},
+		{"invalid json", "application/json", strings.NewReader(`{"from": `), "invalid inbound JSON payload"},
+		{"unsupported type", "text/plain", strings.NewReader("hi"), "unsupported"},
+		{"missing type", "", strings.NewReader("hi"), "content type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/inbound", tt.body)
+			r.Header.Set("Content-Type", tt.contentType)
+
+			_, err := mailnow.ParseInboundEmail(r)
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("expected %q in %q", tt.wantMessage, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// newSlowServer returns a server that holds each request until release is
+// closed and records the peak number of concurrent handlers
+func newSlowServer(t *testing.T, release <-chan struct{}) (*httptest.Server, *int32, *int32) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &current, &peak
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server, current, peak := newSlowServer(t, release)
+	client := newTestClient(t, server.URL, mailnow.WithMaxInFlight(3))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+				t.Errorf("SendEmail() error = %v", err)
+			}
+		}()
+	}
+
+	waitFor(t, func() bool { return atomic.LoadInt32(current) == 3 })
+	if n := client.InFlight(); n != 3 {
+		t.Errorf("InFlight() = %d, want 3", n)
+	}
+	close(release)
+	wg.Wait()
+
+	if p := atomic.LoadInt32(peak); p != 3 {
+		t.Errorf("peak concurrent requests = %d, want 3", p)
+	}
+	if n := client.InFlight(); n != 0 {
+		t.Errorf("InFlight() after sends = %d, want 0", n)
+	}
+}
+
+func TestMaxInFlightWait(t *testing.T) {
+	release := make(chan struct{})
+	server, current, _ := newSlowServer(t, release)
+	defer close(release)
+	client := newTestClient(t, server.URL, mailnow.WithMaxInFlight(1), mailnow.WithMaxInFlightWait(20*time.Millisecond))
+
+	go client.SendEmail(context.Background(), newRetryRequest())
+	waitFor(t, func() bool { return atomic.LoadInt32(current) == 1 })
+
+	_, err := client.SendEmail(context.Background(), newRetryRequest())
+	var inFlightErr *mailnow.TooManyInFlightError
+	if !errors.As(err, &inFlightErr) {
+		t.Fatalf("SendEmail() error = %v, want TooManyInFlightError", err)
+	}
+	if inFlightErr.Limit != 1 || inFlightErr.Waited < 20*time.Millisecond {
+		t.Errorf("error = %+v, want limit 1 after waiting 20ms", inFlightErr)
+	}
+	if mailnow.IsRetryable(err) {
+		t.Error("TooManyInFlightError should not be retryable")
+	}
+}
+
+func TestMaxInFlightCancelWhileQueued(t *testing.T) {
+	release := make(chan struct{})
+	server, current, _ := newSlowServer(t, release)
+	defer close(release)
+	client := newTestClient(t, server.URL, mailnow.WithMaxInFlight(1))
+
+	go client.SendEmail(context.Background(), newRetryRequest())
+	waitFor(t, func() bool { return atomic.LoadInt32(current) == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.SendEmail(ctx, newRetryRequest())
+	if !errors.Is(err, context.DeadlineExceeded) || errors.As(err, new(*mailnow.ConnectionError)) {
+		t.Errorf("SendEmail() error = %v, want context.DeadlineExceeded and no ConnectionError", err)
+	}
+	if n := client.InFlight(); n != 1 {
+		t.Errorf("InFlight() = %d, want 1", n)
+	}
+}
+
+func TestMaxInFlightInvalid(t *testing.T) {
+	for _, opt := range []mailnow.Option{mailnow.WithMaxInFlight(0), mailnow.WithMaxInFlightWait(0)} {
+		if _, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", opt); err == nil {
+			t.Error("expected an option error")
+		}
+	}
+}
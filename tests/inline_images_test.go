@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// pngDataURI returns a data URI for a fake PNG image of size bytes
+func pngDataURI(fill byte, size int) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(fill), size)))
+}
+
+func TestExtractInlineImagesDeduplicates(t *testing.T) {
+	logo := pngDataURI('a', 64)
+	html := `<img src="` + logo + `" alt="logo"><p>Hi</p><IMG alt="logo again" SRC='` + logo + `'><img src=` + pngDataURI('b', 64) + `>`
+
+	out, attachments, err := mailnow.ExtractInlineImages(html)
+	if err != nil {
+		t.Fatalf("ExtractInlineImages() error = %v", err)
+	}
+	if len(attachments) != 2 {
+		t.Fatalf("got %d attachments, want 2: %+v", len(attachments), attachments)
+	}
+	first, second := attachments[0], attachments[1]
+	if first.ContentID == "" || first.ContentID == second.ContentID {
+		t.Errorf("content IDs = %q and %q, want two distinct IDs", first.ContentID, second.ContentID)
+	}
+	if first.ContentType != "image/png" || !strings.HasSuffix(first.Filename, ".png") {
+		t.Errorf("attachment = %+v, want a PNG", first)
+	}
+	if err := mailnow.ValidateAttachment(first); err != nil {
+		t.Errorf("ValidateAttachment() error = %v", err)
+	}
+
+	want := `<img src="cid:` + first.ContentID + `" alt="logo"><p>Hi</p><IMG alt="logo again" SRC="cid:` + first.ContentID + `"><img src="cid:` + second.ContentID + `">`
+	if out != want {
+		t.Errorf("ExtractInlineImages() HTML = %q, want %q", out, want)
+	}
+}
+
+func TestExtractInlineImagesLeavesOtherSources(t *testing.T) {
+	html := `<img src="https://cdn.example.com/logo.png"><img src="cid:chart"><img data-src="` + pngDataURI('a', 8) + `">` +
+		`<img src="data:text/plain;base64,aGk="><img src="data:image/png,raw"><a href="` + pngDataURI('a', 8) + `">x</a>` +
+		`<img src="` + pngDataURI('c', 8) + `">`
+
+	out, attachments, err := mailnow.ExtractInlineImages(html)
+	if err != nil {
+		t.Fatalf("ExtractInlineImages() error = %v", err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1: %+v", len(attachments), attachments)
+	}
+	want := strings.Replace(html, pngDataURI('c', 8), "cid:"+attachments[0].ContentID, 1)
+	if out != want {
+		t.Errorf("ExtractInlineImages() HTML = %q, want %q", out, want)
+	}
+}
+
+func TestExtractInlineImagesMalformed(t *testing.T) {
+	for _, src := range []string{
+		"data:image/png;base64,not*base64",
+		"data:image/gif;base64",
+		"data:image/jpeg;base64,QUJD=",
+	} {
+		_, _, err := mailnow.ExtractInlineImages(`<p>x</p><img src="` + src + `">`)
+		if !errors.As(err, new(*mailnow.ValidationError)) {
+			t.Errorf("ExtractInlineImages(%q) error = %v, want a validation error", src, err)
+		}
+	}
+
+	// Whitespace inside the data, as wrapped by some editors, is ignored
+	wrapped := "data:image/png;base64,QUJD\n  REVG"
+	if _, attachments, err := mailnow.ExtractInlineImages(`<img src="` + wrapped + `">`); err != nil || len(attachments) != 1 {
+		t.Errorf("ExtractInlineImages() of wrapped data = %d attachments, %v", len(attachments), err)
+	}
+}
+
+func TestInlineImageExtractorMinSize(t *testing.T) {
+	small, large := pngDataURI('s', 99), pngDataURI('l', 100)
+	html := `<img src="` + small + `"><img src="` + large + `">`
+
+	out, attachments, err := mailnow.InlineImageExtractor{MinSize: 100}.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(attachments))
+	}
+	if want := `<img src="` + small + `"><img src="cid:` + attachments[0].ContentID + `">`; out != want {
+		t.Errorf("Extract() HTML = %q, want %q", out, want)
+	}
+}
+
+func TestWithInlineImageExtraction(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithHTMLSanitization(mailnow.DefaultSanitizePolicy))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "reports@example.com",
+		To:      "team@example.net",
+		Subject: "Weekly chart",
+		HTML:    `<p>This week:</p><img src="` + pngDataURI('a', 32) + `">`,
+		Attachments: []mailnow.Attachment{
+			{Filename: "report.csv", Content: base64.StdEncoding.EncodeToString([]byte("a,b")), ContentType: "text/csv"},
+		},
+	}
+	original := req.HTML
+	if _, err := client.SendEmail(context.Background(), req, mailnow.WithInlineImageExtraction(0)); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	sent := server.Requests()[0]
+	if len(sent.Attachments) != 2 || sent.Attachments[1].ContentID == "" {
+		t.Fatalf("sent attachments = %+v, want the report and an inline image", sent.Attachments)
+	}
+	// The sanitizer keeps the cid: URL
+	if want := `<p>This week:</p><img src="cid:` + sent.Attachments[1].ContentID + `">`; sent.HTML != want {
+		t.Errorf("sent HTML = %q, want %q", sent.HTML, want)
+	}
+	if req.HTML != original || len(req.Attachments) != 1 {
+		t.Error("SendEmail modified the caller's request")
+	}
+
+	if _, err := client.SendEmail(context.Background(), req, mailnow.WithInlineImageExtraction(-1)); !errors.As(err, new(*mailnow.ValidationError)) {
+		t.Errorf("SendEmail() with a negative minimum size error = %v, want a validation error", err)
+	}
+}
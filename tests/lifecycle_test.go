@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestCloseLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	server := mailnowtest.NewServer()
+	archiver := &recordingArchiver{delay: 100 * time.Millisecond}
+	client := newTestClient(t, server.URL, mailnow.WithArchiver(archiver), mailnow.WithArchiveTimeout(time.Millisecond))
+
+	// The slow archiver keeps running after SendEmail returns
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if n := len(archiver.Messages()); n != 1 {
+		t.Errorf("archived %d messages before Close returned, want 1", n)
+	}
+
+	server.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines after Close, %d before:\n%s", runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRequestsAfterClose(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); !errors.Is(err, mailnow.ErrClientClosed) {
+		t.Errorf("SendEmail() error = %v, want ErrClientClosed", err)
+	}
+	if _, err := client.ListSenderIdentities(context.Background()); !errors.Is(err, mailnow.ErrClientClosed) {
+		t.Errorf("ListSenderIdentities() error = %v, want ErrClientClosed", err)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("server received %d sends, want 0", n)
+	}
+
+	// Closing again is harmless
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestCloseWaitsForInFlightSends(t *testing.T) {
+	release := make(chan struct{})
+	server, current, _ := newSlowServer(t, release)
+	client := newTestClient(t, server.URL)
+
+	sendErr := make(chan error, 1)
+	go func() {
+		_, err := client.SendEmail(context.Background(), newRetryRequest())
+		sendErr <- err
+	}()
+	waitFor(t, func() bool { return atomic.LoadInt32(current) == 1 })
+
+	var closed atomic.Bool
+	closeErr := make(chan error, 1)
+	go func() {
+		closeErr <- client.Close(context.Background())
+		closed.Store(true)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if closed.Load() {
+		t.Fatal("Close() returned while a send was in flight")
+	}
+	close(release)
+
+	if err := <-sendErr; err != nil {
+		t.Errorf("in-flight SendEmail() error = %v, want it to complete", err)
+	}
+	if err := <-closeErr; err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestCloseContextExpires(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server, current, _ := newSlowServer(t, release)
+	client := newTestClient(t, server.URL)
+
+	go client.SendEmail(context.Background(), newRetryRequest())
+	waitFor(t, func() bool { return atomic.LoadInt32(current) == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCloseLetsSubRequestsOfInFlightSendsFinish(t *testing.T) {
+	release := make(chan struct{})
+	var first atomic.Bool
+	server, senders := newSenderServer(t, http.StatusForbidden, "sender_not_verified", "primary@example.com")
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if first.CompareAndSwap(false, true) {
+			<-release
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer blocked.Close()
+	client := newTestClient(t, blocked.URL, mailnow.WithFromFallbacks("backup@example.com", "last@example.com"))
+
+	// The send is rejected once Close has begun, so it falls back to the
+	// backup sender with a second request
+	sent := make(chan error, 1)
+	go func() {
+		_, err := client.SendEmail(context.Background(), newFallbackRequest())
+		sent <- err
+	}()
+	for !first.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close(context.Background()) }()
+	for {
+		if _, err := client.ValidateRecipientsBulk(context.Background(), nil); errors.Is(err, mailnow.ErrClientClosed) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	if err := <-sent; err != nil {
+		t.Errorf("SendEmail() in flight during Close error = %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if got := strings.Join(*senders, ","); got != "primary@example.com,backup@example.com" {
+		t.Errorf("senders = %s, want primary then backup", got)
+	}
+}
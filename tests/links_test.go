@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestValidateLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		code string
+	}{
+		{"empty", `<a href="">x</a>`, "empty_link"},
+		{"unparseable", `<a href="https://exa mple.com/%zz">x</a>`, "invalid_link"},
+		{"relative", `<a href="/account">x</a>`, "invalid_link"},
+		{"http", `<a href="http://example.com/">x</a>`, "insecure_link"},
+		{"localhost", `<a href="https://localhost:8080/">x</a>`, "private_link"},
+		{"private IP", `<a href='https://10.0.0.5/admin'>x</a>`, "private_link"},
+		{"loopback over http", `<a href=http://127.0.0.1/>x</a>`, "private_link"},
+		{"javascript", `<a href="JavaScript:alert(1)">x</a>`, "javascript_link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := mailnow.ValidateLinks(tt.html)
+			if len(issues) != 1 || issues[0].Code != tt.code {
+				t.Errorf("ValidateLinks() = %+v, want one %s issue", issues, tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateLinksSkipped(t *testing.T) {
+	html := `<a href="https://example.com/a?b=1&amp;c=2">ok</a>
+		<a href="mailto:help@example.com">mail</a>
+		<a href="tel:+15550100">call</a>
+		<img src="cid:logo">
+		<a href="#top">top</a>
+		<a href="{{unsubscribe_url}}">unsubscribe</a>`
+	if issues := mailnow.ValidateLinks(html); len(issues) != 0 {
+		t.Errorf("ValidateLinks() = %+v, want no issues", issues)
+	}
+}
+
+// linkServerClient returns a client that connects to server for every
+// host, so that links can use public host names
+func linkServerClient(server *httptest.Server) *http.Client {
+	addr := server.Listener.Addr().String()
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestCheckLinks(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+		}
+
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/redirect":
+			http.Redirect(w, r, "/missing", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	const base = "http://links.example.com"
+	html := `<a href="` + base + `/ok">a</a> <a href="` + base + `/ok">again</a>
+		<a href="` + base + `/missing">b</a> <a href="` + base + `/no-head">c</a>
+		<a href="` + base + `/redirect">r</a> <a href="mailto:help@example.com">d</a>
+		<a href="` + server.URL + `/internal">i</a>`
+	for i := 0; i < 5; i++ {
+		html += `<a href="` + base + `/page` + string(rune('0'+i)) + `">p</a>`
+	}
+
+	issues := mailnow.CheckLinks(context.Background(), linkServerClient(server), html, 2)
+	if len(issues) != 1 || issues[0].Code != "broken_link" || !strings.HasSuffix(issues[0].URL, "/missing") {
+		t.Fatalf("CheckLinks() = %+v, want one broken link to /missing", issues)
+	}
+	if !strings.Contains(issues[0].Message, "404") {
+		t.Errorf("message = %q, want the status", issues[0].Message)
+	}
+	if requests["HEAD /ok"] != 1 {
+		t.Errorf("HEAD /ok requested %d times, want 1", requests["HEAD /ok"])
+	}
+	if requests["GET /no-head"] != 0 {
+		t.Error("links must never be requested with GET")
+	}
+	if requests["HEAD /missing"] != 1 {
+		t.Errorf("HEAD /missing requested %d times, want 1: the redirect must not be followed", requests["HEAD /missing"])
+	}
+	if requests["HEAD /internal"] != 0 {
+		t.Error("a link to a private address was requested")
+	}
+	if p := atomic.LoadInt32(&peak); p > 2 {
+		t.Errorf("peak concurrent checks = %d, want at most 2", p)
+	}
+}
+
+func TestCheckLinksCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	issues := mailnow.CheckLinks(ctx, nil, `<a href="https://example.com/">x</a>`, 1)
+	if len(issues) != 1 || issues[0].Code != "broken_link" {
+		t.Errorf("CheckLinks() = %+v, want the unchecked link reported", issues)
+	}
+}
+
+func TestWithLinkValidation(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	req := newRetryRequest()
+	req.HTML = `<a href="http://example.com/">x</a> <a href="javascript:void(0)">y</a>`
+
+	// Warn mode sends and reports each issue
+	var mu sync.Mutex
+	var warnings []mailnow.Warning
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL),
+		mailnow.WithLinkValidation(mailnow.LinkValidationWarn),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) {
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, w)
+		}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if len(warnings) != 2 || warnings[0].Code != "insecure_link" || warnings[1].Code != "javascript_link" {
+		t.Errorf("warnings = %+v, want insecure_link and javascript_link", warnings)
+	}
+
+	// Strict mode refuses the send
+	client, err = mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL),
+		mailnow.WithLinkValidation(mailnow.LinkValidationStrict))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), req)
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "does not use https") || !strings.Contains(err.Error(), "runs JavaScript") {
+		t.Errorf("SendEmail() error = %v, want a ValidationError listing both links", err)
+	}
+	if n := len(server.Requests()); n != 1 {
+		t.Errorf("server received %d sends, want 1", n)
+	}
+}
+
+func TestWithLinkValidationInvalid(t *testing.T) {
+	for _, opt := range []mailnow.Option{mailnow.WithLinkValidation(0), mailnow.WithLinkReachabilityCheck(0)} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opt); err == nil {
+			t.Error("expected an option error")
+		}
+	}
+}
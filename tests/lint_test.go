@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func newLintRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Your order has shipped",
+		HTML:    "<p>Your order is on its way.</p>",
+	}
+}
+
+// issueCodes returns the codes and fields of issues as "code@field"
+func issueCodes(issues []mailnow.LintIssue) []string {
+	codes := make([]string, len(issues))
+	for i, issue := range issues {
+		codes[i] = issue.Code + "@" + issue.Field
+	}
+	return codes
+}
+
+func TestLintEmailRequestClean(t *testing.T) {
+	report := mailnow.LintEmailRequest(newLintRequest())
+	if report.HasErrors() || len(report.Warnings) != 0 {
+		t.Errorf("LintEmailRequest() for a clean request = errors %v, warnings %v", issueCodes(report.Errors), issueCodes(report.Warnings))
+	}
+}
+
+func TestLintEmailRequestErrors(t *testing.T) {
+	req := &mailnow.EmailRequest{
+		From:         "",
+		To:           "invalid@",
+		Attachments:  []mailnow.Attachment{{Filename: "a.pdf"}},
+		CampaignID:   "spring sale",
+		MessageClass: "newsletter",
+		DeliveryCallback: &mailnow.CallbackSpec{
+			URL:    "http://hooks.example.com/mailnow",
+			Secret: "4f1c9e2a7b3d8e6f0a5c",
+		},
+	}
+
+	report := mailnow.LintEmailRequest(req)
+	want := []string{
+		"missing_field@From",
+		"invalid_address@To",
+		"invalid_message_class@MessageClass",
+		"missing_field@Subject",
+		"missing_field@HTML",
+		"invalid_campaign_id@CampaignID",
+		"invalid_delivery_callback@DeliveryCallback",
+		"invalid_attachment@Attachments[0]",
+	}
+	if got := issueCodes(report.Errors); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("LintEmailRequest() errors = %v, want %v", got, want)
+	}
+
+	// Errors agree with ValidateEmailRequest
+	if mailnow.ValidateEmailRequest(req) == nil {
+		t.Error("ValidateEmailRequest() accepted a request with lint errors")
+	}
+	if report := mailnow.LintEmailRequest(nil); !report.HasErrors() {
+		t.Error("LintEmailRequest(nil) reported no errors")
+	}
+}
+
+func TestLintEmailRequestWarnings(t *testing.T) {
+	largeContent := base64.StdEncoding.EncodeToString(make([]byte, mailnow.LintLargeAttachmentSize+1))
+
+	tests := []struct {
+		name   string
+		modify func(req *mailnow.EmailRequest)
+		want   string
+	}{
+		{
+			name:   "all-caps subject",
+			modify: func(req *mailnow.EmailRequest) { req.Subject = "HUGE SALE TODAY!!!" },
+			want:   "subject_all_caps@Subject",
+		},
+		{
+			name:   "long subject",
+			modify: func(req *mailnow.EmailRequest) { req.Subject = strings.Repeat("Long subject ", 12) },
+			want:   "subject_too_long@Subject",
+		},
+		{
+			name: "many attachments",
+			modify: func(req *mailnow.EmailRequest) {
+				for i := 0; i < 6; i++ {
+					req.Attachments = append(req.Attachments, mailnow.Attachment{Filename: "a.pdf", AssetID: "asset_1"})
+				}
+			},
+			want: "too_many_attachments@Attachments",
+		},
+		{
+			name: "large attachment",
+			modify: func(req *mailnow.EmailRequest) {
+				req.Attachments = []mailnow.Attachment{{Filename: "big.bin", Content: largeContent}}
+			},
+			want: "large_attachment@Attachments[0]",
+		},
+		{
+			name: "image-only HTML",
+			modify: func(req *mailnow.EmailRequest) {
+				req.HTML = `<div><!-- banner --><img src="https://cdn.example.com/sale.png">&nbsp;</div>`
+			},
+			want: "image_only_html@HTML",
+		},
+		{
+			name:   "campaign without unsubscribe",
+			modify: func(req *mailnow.EmailRequest) { req.CampaignID = "spring-sale" },
+			want:   "missing_unsubscribe@HTML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newLintRequest()
+			tt.modify(req)
+
+			report := mailnow.LintEmailRequest(req)
+			if report.HasErrors() {
+				t.Fatalf("LintEmailRequest() unexpected errors %v", issueCodes(report.Errors))
+			}
+			if got := issueCodes(report.Warnings); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("LintEmailRequest() warnings = %v, want [%s]", got, tt.want)
+			}
+			if report.Warnings[0].Message == "" {
+				t.Error("LintEmailRequest() warning has no message")
+			}
+		})
+	}
+
+	// Campaign email with an unsubscribe link and images with text is clean
+	req := newLintRequest()
+	req.CampaignID = "spring-sale"
+	req.HTML = `<img src="https://cdn.example.com/logo.png"><p>Sale!</p><a href="https://example.com/u">Unsubscribe</a>`
+	if report := mailnow.LintEmailRequest(req); len(report.Warnings) != 0 {
+		t.Errorf("LintEmailRequest() unexpected warnings %v", issueCodes(report.Warnings))
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestLocalAddr(t *testing.T) {
+	var mu sync.Mutex
+	var remote string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		remote = r.RemoteAddr
+		mu.Unlock()
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithLocalAddr("127.0.0.1"),
+		mailnow.WithEagerConnectivityCheck())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := sendTo(client, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil || host != "127.0.0.1" {
+		t.Errorf("server saw remote address %q, want 127.0.0.1", remote)
+	}
+}
+
+func TestLocalAddrValidation(t *testing.T) {
+	var validationErr *mailnow.ValidationError
+	for _, ip := range []string{"not-an-ip", "0.0.0.0", "192.0.2.123"} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithLocalAddr(ip)); !errors.As(err, &validationErr) {
+			t.Errorf("NewClient() with local address %s error = %v, want ValidationError", ip, err)
+		}
+	}
+
+	// The interface check can be skipped, leaving failures to connect time
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL("http://127.0.0.1:1"),
+		mailnow.WithLocalAddr("192.0.2.123"),
+		mailnow.WithoutLocalAddrCheck())
+	if err != nil {
+		t.Fatalf("NewClient() without the interface check error = %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hello", HTML: "<p>Hello</p>"})
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("SendEmail() from an unassigned address error = %v, want ConnectionError", err)
+	}
+}
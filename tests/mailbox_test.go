@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestCanonicalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		// Gmail ignores dots and plus tags, and googlemail.com is an alias
+		{"john.doe+promo@gmail.com", "johndoe@gmail.com"},
+		{"John.Doe@GMail.com", "johndoe@gmail.com"},
+		{"j.o.h.n.doe@googlemail.com", "johndoe@gmail.com"},
+		// Outlook ignores plus tags but keeps dots
+		{"jane.doe+news@outlook.com", "jane.doe@outlook.com"},
+		{"Jane.Doe@Hotmail.com", "jane.doe@hotmail.com"},
+		// Unknown domains only have the domain lowercased
+		{"Jane.Doe+tag@Example.COM", "Jane.Doe+tag@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := mailnow.CanonicalizeEmail(tt.email, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeEmailCustomRules(t *testing.T) {
+	rules := mailnow.DefaultProviderRules()
+	rules["corp.example"] = mailnow.ProviderRule{StripPlusTags: true, CaseInsensitive: true}
+
+	got, err := mailnow.CanonicalizeEmail("Ops+Alerts@corp.example", rules)
+	if err != nil || got != "ops@corp.example" {
+		t.Errorf("got %q, %v", got, err)
+	}
+
+	// Built-in rules are unaffected by extending a copy
+	if got, _ := mailnow.CanonicalizeEmail("Ops+Alerts@corp.example", nil); got != "Ops+Alerts@corp.example" {
+		t.Errorf("expected default rules to be unchanged, got %q", got)
+	}
+}
+
+func TestCanonicalizeEmailErrors(t *testing.T) {
+	for _, email := range []string{"", "not-an-email", "+tag@gmail.com", "...@gmail.com"} {
+		_, err := mailnow.CanonicalizeEmail(email, nil)
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%q: expected ValidationError, got %v", email, err)
+		}
+	}
+}
+
+func TestSameMailbox(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"john.doe+promo@gmail.com", "johndoe@gmail.com", true},
+		{"jane.doe+x@outlook.com", "janedoe@outlook.com", false},
+		{"User@Example.com", "User@example.com", true},
+		{"User@example.com", "user@example.com", false},
+		{"invalid", "invalid", false},
+	}
+	for _, tt := range tests {
+		if got := mailnow.SameMailbox(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameMailbox(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func newDiffRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:       "sender@example.com",
+		To:         "recipient@example.com",
+		Subject:    "Your invoice",
+		HTML:       "<p>Hello</p>\n<p>World</p>",
+		CampaignID: "invoices",
+		Attachments: []mailnow.Attachment{
+			{Filename: "invoice.pdf", Content: "SGVsbG8=", ContentType: "application/pdf"},
+		},
+	}
+}
+
+func TestDiffRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(got *mailnow.EmailRequest)
+		opts   []mailnowtest.DiffOption
+		want   string
+	}{
+		{
+			name:   "equal",
+			modify: func(got *mailnow.EmailRequest) {},
+			want:   "",
+		},
+		{
+			name:   "top-level field",
+			modify: func(got *mailnow.EmailRequest) { got.Subject = "Your receipt" },
+			want:   "Subject: want \"Your invoice\", got \"Your receipt\"\n",
+		},
+		{
+			name:   "nested field",
+			modify: func(got *mailnow.EmailRequest) { got.Attachments[0].ContentType = "text/plain" },
+			want:   "Attachments[0].ContentType: want \"application/pdf\", got \"text/plain\"\n",
+		},
+		{
+			name: "extra attachment",
+			modify: func(got *mailnow.EmailRequest) {
+				got.Attachments = append(got.Attachments, mailnow.Attachment{Filename: "terms.pdf", AssetID: "asset_1"})
+			},
+			want: "Attachments[1]: unexpected {Filename:terms.pdf Content: URL: AssetID:asset_1 ContentType: ContentID:}\n",
+		},
+		{
+			name:   "missing attachment",
+			modify: func(got *mailnow.EmailRequest) { got.Attachments = nil },
+			want:   "Attachments[0]: missing {Filename:invoice.pdf Content:SGVsbG8= URL: AssetID: ContentType:application/pdf ContentID:}\n",
+		},
+		{
+			name: "multiple fields",
+			modify: func(got *mailnow.EmailRequest) {
+				got.To = "other@example.com"
+				got.CampaignID = ""
+			},
+			want: "To: want \"recipient@example.com\", got \"other@example.com\"\n" +
+				"CampaignID: want \"invoices\", got \"\"\n",
+		},
+		{
+			name:   "ignored field",
+			modify: func(got *mailnow.EmailRequest) { got.CampaignID = "invoices-2" },
+			opts:   []mailnowtest.DiffOption{mailnowtest.IgnoreFields("CampaignID")},
+			want:   "",
+		},
+		{
+			name:   "ignored nested field",
+			modify: func(got *mailnow.EmailRequest) { got.Attachments[0].ContentType = "text/plain" },
+			opts:   []mailnowtest.DiffOption{mailnowtest.IgnoreFields("Attachments.ContentType")},
+			want:   "",
+		},
+		{
+			name:   "HTML whitespace differs",
+			modify: func(got *mailnow.EmailRequest) { got.HTML = "  <p>Hello</p><p>World</p>\n" },
+			want:   "HTML: want \"<p>Hello</p>\\n<p>World</p>\", got \"  <p>Hello</p><p>World</p>\\n\"\n",
+		},
+		{
+			name:   "HTML whitespace normalized",
+			modify: func(got *mailnow.EmailRequest) { got.HTML = "  <p>Hello</p><p>World</p>\n" },
+			opts:   []mailnowtest.DiffOption{mailnowtest.NormalizeHTMLWhitespace()},
+			want:   "",
+		},
+		{
+			name:   "HTML text still compared when normalized",
+			modify: func(got *mailnow.EmailRequest) { got.HTML = "<p>Hello</p> <p>there</p>" },
+			opts:   []mailnowtest.DiffOption{mailnowtest.NormalizeHTMLWhitespace()},
+			want:   "HTML: want \"<p>Hello</p><p>World</p>\", got \"<p>Hello</p><p>there</p>\"\n",
+		},
+		{
+			name:   "attachments compared by hash",
+			modify: func(got *mailnow.EmailRequest) { got.Attachments[0].Content = "V29ybGQ=" },
+			opts:   []mailnowtest.DiffOption{mailnowtest.CompareAttachmentsByHash()},
+			want: "Attachments[0].Content: want \"sha256:50e7ab41c843a6b034d6d1d3bff2bbd7ac3e693e857075f6a2035b39fca88588\", " +
+				"got \"sha256:7d4f728174e6d63c5d293cf62a9d2e2d785d8509fb7f8fba59ef44070906b554\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := newDiffRequest()
+			got := newDiffRequest()
+			tt.modify(got)
+
+			if diff := mailnowtest.DiffRequests(want, got, tt.opts...); diff != tt.want {
+				t.Errorf("DiffRequests() =\n%s\nwant\n%s", diff, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffRequestsNil(t *testing.T) {
+	if diff := mailnowtest.DiffRequests(nil, nil); diff != "" {
+		t.Errorf("DiffRequests(nil, nil) = %q, want empty", diff)
+	}
+	if diff := mailnowtest.DiffRequests(newDiffRequest(), nil); diff == "" {
+		t.Error("DiffRequests(req, nil) = empty, want a difference")
+	}
+}
+
+// recordingTB captures failures reported by assertion helpers
+type recordingTB struct {
+	testing.TB
+	failures []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Cleanup(fn func()) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+// runCleanups runs the registered cleanup functions as the test would
+func (r *recordingTB) runCleanups() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+	r.cleanups = nil
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSent(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	sent := newDiffRequest()
+	resp, err := client.SendEmail(context.Background(), sent)
+	if err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if resp.Data.MessageID == "" {
+		t.Error("expected the fake server to return a message ID")
+	}
+	if requests := server.Requests(); len(requests) != 1 {
+		t.Fatalf("expected 1 recorded request, got %d", len(requests))
+	}
+
+	mailnowtest.AssertSent(t, server, mailnowtest.Equal(newDiffRequest()))
+	mailnowtest.AssertSent(t, server, mailnowtest.SentTo("recipient@example.com"))
+
+	rec := &recordingTB{TB: t}
+	mailnowtest.AssertSent(rec, server, mailnowtest.SentTo("other@example.com"))
+	if len(rec.failures) != 1 || !strings.Contains(rec.failures[0], "sender@example.com -> recipient@example.com: Your invoice") {
+		t.Errorf("AssertSent() failures = %q, want one summarizing the recorded request", rec.failures)
+	}
+}
+
+func TestGeneratedAPIKeys(t *testing.T) {
+	testKey := mailnowtest.TestAPIKey()
+	if err := mailnow.ValidateAPIKey(testKey); err != nil {
+		t.Errorf("TestAPIKey() %q is invalid: %v", testKey, err)
+	}
+	if !mailnow.IsTestAPIKey(testKey) || mailnow.IsLiveAPIKey(testKey) {
+		t.Errorf("TestAPIKey() %q is not classified as a test key", testKey)
+	}
+	if testKey == mailnowtest.TestAPIKey() {
+		t.Error("TestAPIKey() returned the same key twice")
+	}
+
+	liveKey := mailnowtest.LiveAPIKey()
+	if err := mailnow.ValidateAPIKey(liveKey); err != nil {
+		t.Errorf("LiveAPIKey() %q is invalid: %v", liveKey, err)
+	}
+	if !mailnow.IsLiveAPIKey(liveKey) || mailnow.IsTestAPIKey(liveKey) {
+		t.Errorf("LiveAPIKey() %q is not classified as a live key", liveKey)
+	}
+}
+
+func TestExpectNoSends(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	mailnowtest.ExpectNoSends(rec)
+
+	// Nothing is reported until cleanup, and nothing at all without sends
+	if len(rec.cleanups) != 1 {
+		t.Fatalf("expected 1 cleanup function, got %d", len(rec.cleanups))
+	}
+	rec.runCleanups()
+	if len(rec.failures) != 0 {
+		t.Errorf("failures = %q, want none", rec.failures)
+	}
+}
+
+func TestExpectNoSendsReportsSends(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	client := mailnowtest.ExpectNoSends(rec)
+
+	if _, err := client.SendEmail(context.Background(), newDiffRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if len(rec.failures) != 0 {
+		t.Fatalf("failures reported before cleanup: %q", rec.failures)
+	}
+
+	rec.runCleanups()
+	if len(rec.failures) != 1 {
+		t.Fatalf("failures = %q, want 1", rec.failures)
+	}
+	failure := rec.failures[0]
+	for _, want := range []string{
+		"expected no emails to be sent, but 1 were",
+		"email 1: sender@example.com -> recipient@example.com: Your invoice",
+		`"subject": "Your invoice"`,
+	} {
+		if !strings.Contains(failure, want) {
+			t.Errorf("failure does not contain %q:\n%s", want, failure)
+		}
+	}
+	if strings.Contains(failure, "SendEmail call") {
+		t.Errorf("failure includes stacks without CaptureStacks:\n%s", failure)
+	}
+}
+
+func TestExpectNoSendsCaptureStacks(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	client := mailnowtest.ExpectNoSends(rec, mailnowtest.CaptureStacks())
+
+	// Validation failures never reach the server and are not reported
+	client.SendEmail(context.Background(), &mailnow.EmailRequest{})
+	if _, err := client.SendEmail(context.Background(), newDiffRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+
+	rec.runCleanups()
+	if len(rec.failures) != 1 {
+		t.Fatalf("failures = %q, want 1", rec.failures)
+	}
+	failure := rec.failures[0]
+	if !strings.Contains(failure, "SendEmail call 1:") || strings.Contains(failure, "SendEmail call 2:") {
+		t.Errorf("failure should include exactly one stack:\n%s", failure)
+	}
+	if !strings.Contains(failure, "TestExpectNoSendsCaptureStacks") {
+		t.Errorf("stack does not include the calling test:\n%s", failure)
+	}
+}
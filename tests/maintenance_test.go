@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// newMaintenanceServer returns a server that reports maintenance with the
+// given Retry-After header for the first n requests and then accepts sends
+func newMaintenanceServer(t *testing.T, n int32, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= n {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "maintenance", "message": "scheduled maintenance until 02:00 UTC"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestMaintenanceErrorDetection(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		retryAfter     string
+		wantMaint      bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:           "maintenance with seconds",
+			status:         http.StatusServiceUnavailable,
+			body:           `{"error": {"code": "maintenance", "message": "down for maintenance"}}`,
+			retryAfter:     "120",
+			wantMaint:      true,
+			wantRetryAfter: 2 * time.Minute,
+		},
+		{
+			name:      "maintenance without Retry-After",
+			status:    http.StatusServiceUnavailable,
+			body:      `{"error": {"code": "maintenance", "message": "down for maintenance"}}`,
+			wantMaint: true,
+		},
+		{
+			name:       "maintenance with invalid Retry-After",
+			status:     http.StatusServiceUnavailable,
+			body:       `{"error": {"code": "maintenance", "message": "down for maintenance"}}`,
+			retryAfter: "soon",
+			wantMaint:  true,
+		},
+		{
+			name:   "503 with another code",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error": {"code": "overloaded", "message": "try again"}}`,
+		},
+		{
+			name:   "maintenance code on another status",
+			status: http.StatusInternalServerError,
+			body:   `{"error": {"code": "maintenance", "message": "down for maintenance"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			_, err = mailnow.HandleResponse(resp)
+
+			var maintErr *mailnow.MaintenanceError
+			if got := errors.As(err, &maintErr); got != tt.wantMaint {
+				t.Fatalf("HandleResponse() error %T, MaintenanceError = %v, want %v", err, got, tt.wantMaint)
+			}
+			if !mailnow.IsRetryable(err) {
+				t.Errorf("IsRetryable() = false for %v", err)
+			}
+			if tt.wantMaint && maintErr.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("RetryAfter = %s, want %s", maintErr.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+
+	// Retry-After may be an HTTP date
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	server, _ := newMaintenanceServer(t, 1, date)
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	_, err = mailnow.HandleResponse(resp)
+	var maintErr *mailnow.MaintenanceError
+	if !errors.As(err, &maintErr) || maintErr.RetryAfter < 59*time.Minute || maintErr.RetryAfter > time.Hour {
+		t.Errorf("HandleResponse() with HTTP date Retry-After = %v", err)
+	}
+}
+
+func TestMaintenanceRetryHonorsRetryAfter(t *testing.T) {
+	server, calls := newMaintenanceServer(t, 1, "1")
+
+	// A backoff this long would time the test out if it were used
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Minute}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 5*time.Second {
+		t.Errorf("expected a retry after the server's 1s Retry-After, took %s", elapsed)
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestMaintenanceRetryAfterCappedAtMaxDelay(t *testing.T) {
+	server, calls := newMaintenanceServer(t, 1, "3600")
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the 1h Retry-After to be capped at MaxDelay, took %s", elapsed)
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestClientInMaintenance(t *testing.T) {
+	server, _ := newMaintenanceServer(t, 2, "")
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	if client.InMaintenance() {
+		t.Error("new client reports maintenance")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.SendEmail(ctx, newRetryRequest())
+		var maintErr *mailnow.MaintenanceError
+		if !errors.As(err, &maintErr) {
+			t.Fatalf("SendEmail() expected MaintenanceError, got %v", err)
+		}
+		if !client.InMaintenance() {
+			t.Error("client does not report maintenance after a maintenance response")
+		}
+	}
+
+	if _, err := client.SendEmail(ctx, newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if client.InMaintenance() {
+		t.Error("client still reports maintenance after a successful response")
+	}
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+const markdownDocument = `# Welcome, *Ada*
+
+Your order **#1234** has shipped.
+Track it [here](https://example.com/track?id=1&ref=mail "Tracking").
+
+## Items
+
+- Widget ` + "`x2`" + `
+- Gadget with a
+  long description
+
+3. First
+4. Second
+
+> Note: keep this
+> for your records.
+
+` + "```go\nfmt.Println(\"<hi>\")\n```" + `
+
+---
+
+Use snake_case_names and 2 * 3 = 6. \*literal\* [bad](javascript:alert(1))
+`
+
+const markdownGolden = `<h1>Welcome, <em>Ada</em></h1>
+<p>Your order <strong>#1234</strong> has shipped.
+Track it <a href="https://example.com/track?id=1&amp;ref=mail" title="Tracking">here</a>.</p>
+<h2>Items</h2>
+<ul>
+<li>Widget <code>x2</code></li>
+<li>Gadget with a
+long description</li>
+</ul>
+<ol start="3">
+<li>First</li>
+<li>Second</li>
+</ol>
+<blockquote>
+<p>Note: keep this
+for your records.</p>
+</blockquote>
+<pre><code class="language-go">fmt.Println(&#34;&lt;hi&gt;&#34;)
+</code></pre>
+<hr>
+<p>Use snake_case_names and 2 * 3 = 6. *literal* bad</p>
+`
+
+func TestRenderMarkdown(t *testing.T) {
+	if got := mailnow.RenderMarkdown(markdownDocument, mailnow.MarkdownOptions{}); got != markdownGolden {
+		t.Errorf("RenderMarkdown() =\n%s\nwant\n%s", got, markdownGolden)
+	}
+}
+
+func TestRenderMarkdownRawHTML(t *testing.T) {
+	markdown := "Hello <b>there</b> & `<code>`"
+
+	if got, want := mailnow.RenderMarkdown(markdown, mailnow.MarkdownOptions{}), "<p>Hello &lt;b&gt;there&lt;/b&gt; &amp; <code>&lt;code&gt;</code></p>\n"; got != want {
+		t.Errorf("escaped = %q, want %q", got, want)
+	}
+	if got, want := mailnow.RenderMarkdown(markdown, mailnow.MarkdownOptions{AllowRawHTML: true}), "<p>Hello <b>there</b> & <code>&lt;code&gt;</code></p>\n"; got != want {
+		t.Errorf("raw = %q, want %q", got, want)
+	}
+}
+
+func TestSendEmailMarkdown(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hi", Markdown: "**Hi** <there>"}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if got := server.Requests()[0].HTML; got != "<p><strong>Hi</strong> &lt;there&gt;</p>\n" {
+		t.Errorf("sent HTML = %q", got)
+	}
+	if req.HTML != "" {
+		t.Error("SendEmail should not modify the request")
+	}
+
+	client, err = mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL), mailnow.WithMarkdownRawHTML())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if got := server.Requests()[1].HTML; got != "<p><strong>Hi</strong> <there></p>\n" {
+		t.Errorf("sent HTML with raw HTML allowed = %q", got)
+	}
+}
+
+func TestMarkdownExclusiveWithHTML(t *testing.T) {
+	req := &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hi", HTML: "<p>Hi</p>", Markdown: "Hi"}
+
+	var validationErr *mailnow.ValidationError
+	if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+		t.Errorf("ValidateEmailRequest() error = %v, want ValidationError", err)
+	}
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL("http://127.0.0.1:0"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), req); !errors.As(err, &validationErr) {
+		t.Errorf("SendEmail() error = %v, want ValidationError", err)
+	}
+
+	report := mailnow.LintEmailRequest(req)
+	if !report.HasErrors() || report.Errors[0].Code != "conflicting_body" {
+		t.Errorf("LintEmailRequest() errors = %+v, want conflicting_body", report.Errors)
+	}
+}
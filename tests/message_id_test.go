@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestValidateCustomMessageID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"order-1234.reply@example.com", false},
+		{"a+b=c/d@EXAMPLE.com", false},
+		{"order-1234@other.com", true},
+		{"order-1234@sub.example.com", true},
+		{"no-domain", true},
+		{"two@at@example.com", true},
+		{".leading@example.com", true},
+		{"double..dot@example.com", true},
+		{"trailing.@example.com", true},
+		{"with space@example.com", true},
+		{"<order-1234@example.com>", true},
+	}
+
+	for _, tt := range tests {
+		err := mailnow.ValidateCustomMessageID(tt.id, "sender@example.com")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateCustomMessageID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCustomMessageIDRequestValidation(t *testing.T) {
+	req := newRetryRequest()
+	req.CustomMessageID = "order-1234@attacker.com"
+	if err := mailnow.ValidateEmailRequest(req); err == nil {
+		t.Error("expected error for a custom message ID outside the sender domain")
+	}
+	if report := mailnow.LintEmailRequest(req); !report.HasErrors() || report.Errors[0].Field != "CustomMessageID" {
+		t.Errorf("LintEmailRequest() errors = %+v, want a CustomMessageID error", report.Errors)
+	}
+
+	req.CustomMessageID = "order-1234@example.com"
+	req.Headers = map[string]string{"message-id": "<x@example.com>"}
+	if err := mailnow.ValidateEmailRequest(req); err == nil {
+		t.Error("expected error for a custom message ID with a Message-ID header")
+	}
+}
+
+func TestCustomMessageIDSerialization(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued", "custom_message_id": "order-1234@example.com"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := newRetryRequest()
+	req.CustomMessageID = "order-1234@example.com"
+	resp, err := client.SendEmail(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if got["custom_message_id"] != "order-1234@example.com" {
+		t.Errorf("custom_message_id = %v, want order-1234@example.com", got["custom_message_id"])
+	}
+	if resp.Data.CustomMessageID != "order-1234@example.com" {
+		t.Errorf("response CustomMessageID = %q", resp.Data.CustomMessageID)
+	}
+}
+
+func TestGetEmailByCustomID(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method = %s, want GET", r.Method)
+		}
+		path = r.URL.EscapedPath()
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "delivered", "custom_message_id": "a/b?c@example.com"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	resp, err := client.GetEmailByCustomID(context.Background(), "a/b?c@example.com")
+	if err != nil {
+		t.Fatalf("GetEmailByCustomID() error = %v", err)
+	}
+	if want := mailnow.EmailEndpoint + "/custom/a%2Fb%3Fc@example.com"; path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+	if resp.Data.MessageID != "msg_123" || resp.Data.Status != "delivered" {
+		t.Errorf("response data = %+v", resp.Data)
+	}
+
+	var validationErr *mailnow.ValidationError
+	if _, err := client.GetEmailByCustomID(context.Background(), ""); !errors.As(err, &validationErr) {
+		t.Errorf("GetEmailByCustomID(\"\") error = %v, want ValidationError", err)
+	}
+}
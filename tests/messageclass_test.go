@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestMessageClassHeaders(t *testing.T) {
+	tests := []struct {
+		class mailnow.MessageClass
+		want  map[string]string
+	}{
+		{mailnow.MessageClassTransactional, map[string]string{"Auto-Submitted": "auto-generated"}},
+		{mailnow.MessageClassNotification, map[string]string{"Auto-Submitted": "auto-generated", "X-Auto-Response-Suppress": "All"}},
+		{mailnow.MessageClassMarketing, map[string]string{"Auto-Submitted": "auto-generated", "Precedence": "bulk"}},
+	}
+	for _, tt := range tests {
+		headers, err := mailnow.MessageClassHeaders(tt.class)
+		if err != nil || !reflect.DeepEqual(headers, tt.want) {
+			t.Errorf("MessageClassHeaders(%s) = %v, %v, want %v", tt.class, headers, err, tt.want)
+		}
+	}
+
+	var validationErr *mailnow.ValidationError
+	if _, err := mailnow.MessageClassHeaders("newsletter"); !errors.As(err, &validationErr) {
+		t.Errorf("MessageClassHeaders(newsletter) error = %v, want ValidationError", err)
+	}
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithDefaultMessageClass("newsletter")); err == nil {
+		t.Error("expected NewClient to reject an unknown message class")
+	}
+	req := &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hi", HTML: "<p>Hi</p>", MessageClass: "newsletter"}
+	if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+		t.Errorf("ValidateEmailRequest() error = %v, want ValidationError", err)
+	}
+}
+
+func TestMessageClassSend(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	newRequest := func(class mailnow.MessageClass, headers map[string]string) *mailnow.EmailRequest {
+		return &mailnow.EmailRequest{
+			From:         "alerts@example.com",
+			To:           "oncall@example.com",
+			Subject:      "Disk almost full",
+			HTML:         "<p>Disk almost full</p>",
+			Headers:      headers,
+			MessageClass: class,
+		}
+	}
+
+	plain := newTestClient(t, server.URL)
+	withDefault, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithDefaultMessageClass(mailnow.MessageClassTransactional))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	userHeaders := map[string]string{"auto-submitted": "auto-replied", "X-Team": "ops"}
+	sends := []struct {
+		client *mailnow.Client
+		req    *mailnow.EmailRequest
+		want   map[string]string
+	}{
+		{plain, newRequest("", nil), nil},
+		{plain, newRequest(mailnow.MessageClassMarketing, nil), map[string]string{"Auto-Submitted": "auto-generated", "Precedence": "bulk"}},
+		{plain, newRequest(mailnow.MessageClassNotification, userHeaders), map[string]string{"auto-submitted": "auto-replied", "X-Team": "ops", "X-Auto-Response-Suppress": "All"}},
+		{withDefault, newRequest("", nil), map[string]string{"Auto-Submitted": "auto-generated"}},
+		{withDefault, newRequest(mailnow.MessageClassMarketing, nil), map[string]string{"Auto-Submitted": "auto-generated", "Precedence": "bulk"}},
+	}
+	for _, s := range sends {
+		if _, err := s.client.SendEmail(context.Background(), s.req); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+
+	requests := server.Requests()
+	if len(requests) != len(sends) {
+		t.Fatalf("server received %d sends, want %d", len(requests), len(sends))
+	}
+	for i, s := range sends {
+		if !reflect.DeepEqual(requests[i].Headers, s.want) {
+			t.Errorf("send %d headers = %v, want %v", i, requests[i].Headers, s.want)
+		}
+	}
+	if len(userHeaders) != 2 {
+		t.Errorf("SendEmail() modified the caller's headers: %v", userHeaders)
+	}
+}
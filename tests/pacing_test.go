@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// arrivalServer records when a send to each recipient arrived
+type arrivalServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	arrivals map[string]time.Time
+}
+
+func newArrivalServer(t *testing.T) *arrivalServer {
+	s := &arrivalServer{arrivals: make(map[string]time.Time)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
+			s.mu.Lock()
+			s.arrivals[req.To] = time.Now()
+			s.mu.Unlock()
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *arrivalServer) arrival(to string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.arrivals[to]
+}
+
+func TestRecipientDomainPacing(t *testing.T) {
+	server := newArrivalServer(t)
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+
+	// 600 per minute spaces yahoo.com sends 100ms apart
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithRecipientDomainPolicies(map[string]mailnow.DomainPolicy{
+		"Yahoo.com": {MaxPerMinute: 600},
+	}))
+
+	recipients := []string{
+		"a@yahoo.com", "one@example.com", "b@YAHOO.com", "two@example.org", "c@yahoo.com", "three@example.net",
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, to := range recipients {
+		wg.Add(1)
+		go func(to string) {
+			defer wg.Done()
+			if _, err := sendTo(client, to, "Hello"); err != nil {
+				t.Errorf("SendEmail(%s) error = %v", to, err)
+			}
+		}(to)
+		// Start the yahoo.com sends in order
+		if i%2 == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Two yahoo.com sends wait while the others go through
+	waitFor(t, func() bool {
+		pacing := client.DomainPacing()
+		return len(pacing) == 1 && pacing[0].Domain == "yahoo.com" && pacing[0].Waiting == 2
+	})
+	if next := client.DomainPacing()[0].NextSlot; !next.Equal(clock.Now().Add(300 * time.Millisecond)) {
+		t.Errorf("NextSlot = %v, want 300ms after the clock", next)
+	}
+	wg.Wait()
+
+	for _, to := range []string{"one@example.com", "two@example.org", "three@example.net"} {
+		if d := server.arrival(to).Sub(start); d > 80*time.Millisecond {
+			t.Errorf("send to %s arrived after %s, want immediately", to, d)
+		}
+	}
+	first, second, third := server.arrival("a@yahoo.com"), server.arrival("b@YAHOO.com"), server.arrival("c@yahoo.com")
+	if d := second.Sub(first); d < 80*time.Millisecond {
+		t.Errorf("second yahoo.com send arrived %s after the first, want about 100ms", d)
+	}
+	if d := third.Sub(first); d < 180*time.Millisecond {
+		t.Errorf("third yahoo.com send arrived %s after the first, want about 200ms", d)
+	}
+	if pacing := client.DomainPacing(); pacing[0].Waiting != 0 {
+		t.Errorf("Waiting = %d after the sends, want 0", pacing[0].Waiting)
+	}
+}
+
+func TestRecipientDomainDefaultPolicy(t *testing.T) {
+	server := newArrivalServer(t)
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithRecipientDomainPolicies(map[string]mailnow.DomainPolicy{
+		mailnow.AllDomains: {MaxPerMinute: 1},
+		"example.com":      {},
+	}))
+
+	// Domains under the default policy are paced separately
+	for _, to := range []string{"a@example.org", "b@example.net", "c@example.com", "d@example.com"} {
+		if _, err := sendTo(client, to, "Hello"); err != nil {
+			t.Fatalf("SendEmail(%s) error = %v", to, err)
+		}
+	}
+	pacing := client.DomainPacing()
+	if len(pacing) != 3 {
+		t.Fatalf("DomainPacing() = %+v, want 3 domains", pacing)
+	}
+	for _, p := range pacing {
+		want := clock.Now().Add(time.Minute)
+		if p.Domain == "example.com" {
+			want = clock.Now()
+		}
+		if !p.NextSlot.Equal(want) {
+			t.Errorf("%s NextSlot = %v, want %v", p.Domain, p.NextSlot, want)
+		}
+	}
+
+	// A second send to a paced domain waits for its slot
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.SendEmail(ctx, &mailnow.EmailRequest{From: "sender@example.com", To: "e@example.org", Subject: "Hello", HTML: "<p>Hello</p>"})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.As(err, new(*mailnow.ConnectionError)) {
+		t.Errorf("SendEmail() error = %v, want an interrupted wait", err)
+	}
+}
+
+func TestRecipientDomainAllowedHours(t *testing.T) {
+	server := newArrivalServer(t)
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 20, 30, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithRecipientDomainPolicies(map[string]mailnow.DomainPolicy{
+		"yahoo.com":   {StartHour: 9, EndHour: 17},
+		"outlook.com": {StartHour: 18, EndHour: 6},
+	}))
+
+	_, err := sendTo(client, "a@yahoo.com", "Hello")
+	var windowErr *mailnow.SendWindowError
+	if !errors.As(err, &windowErr) {
+		t.Fatalf("SendEmail() error = %v, want SendWindowError", err)
+	}
+	if windowErr.Domain != "yahoo.com" || !windowErr.OpensAt.Equal(time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Domain = %q, OpensAt = %v", windowErr.Domain, windowErr.OpensAt)
+	}
+	if mailnow.IsRetryable(err) {
+		t.Error("SendWindowError should not be retryable")
+	}
+
+	// Windows can wrap past midnight
+	if _, err := sendTo(client, "b@outlook.com", "Hello"); err != nil {
+		t.Errorf("SendEmail() in a wrapped window error = %v", err)
+	}
+
+	clock.Advance(13 * time.Hour)
+	if _, err := sendTo(client, "a@yahoo.com", "Hello"); err != nil {
+		t.Errorf("SendEmail() in the allowed hours error = %v", err)
+	}
+	if _, err := sendTo(client, "b@outlook.com", "Hello"); !errors.As(err, &windowErr) || !strings.Contains(err.Error(), "not allowed until") {
+		t.Errorf("SendEmail() outside a wrapped window error = %v", err)
+	}
+
+	for name, policy := range map[string]mailnow.DomainPolicy{
+		"negative rate": {MaxPerMinute: -1},
+		"bad hour":      {StartHour: 24},
+		"empty window":  {StartHour: 9, EndHour: 9},
+	} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithRecipientDomainPolicies(map[string]mailnow.DomainPolicy{"yahoo.com": policy})); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
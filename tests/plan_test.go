@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestPlanFeatureErrorMapping(t *testing.T) {
+	body := []byte(`{"error": {"code": "feature_not_available", "message": "templates are not on your plan", "details": {"feature": "templates", "plan": "starter"}}}`)
+
+	for _, status := range []int{http.StatusPaymentRequired, http.StatusForbidden} {
+		err := mailnow.DefaultErrorMapper(status, body)
+
+		var planErr *mailnow.PlanFeatureError
+		if !errors.As(err, &planErr) {
+			t.Fatalf("status %d: expected PlanFeatureError, got %T: %v", status, err, err)
+		}
+		if planErr.Feature != mailnow.FeatureTemplates || planErr.Plan != "starter" {
+			t.Errorf("status %d: feature = %q, plan = %q", status, planErr.Feature, planErr.Plan)
+		}
+		if mailnow.ErrorStatusCode(err) != status || mailnow.ErrorCode(err) != "feature_not_available" {
+			t.Errorf("status %d: got status %d, code %q", status, mailnow.ErrorStatusCode(err), mailnow.ErrorCode(err))
+		}
+		if mailnow.IsRetryable(err) {
+			t.Errorf("status %d: PlanFeatureError should not be retryable", status)
+		}
+	}
+}
+
+func TestPlanFeatureErrorOtherCodes(t *testing.T) {
+	// A 403 with another code is still an unexpected status
+	err := mailnow.DefaultErrorMapper(http.StatusForbidden, []byte(`{"error": {"code": "forbidden", "message": "no access"}}`))
+	var serverErr *mailnow.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Errorf("expected ServerError, got %T: %v", err, err)
+	}
+
+	// A 402 without details has no feature or plan
+	err = mailnow.DefaultErrorMapper(http.StatusPaymentRequired, []byte(`{"error": {"code": "feature_not_available", "message": "upgrade required"}}`))
+	var planErr *mailnow.PlanFeatureError
+	if !errors.As(err, &planErr) {
+		t.Fatalf("expected PlanFeatureError, got %T: %v", err, err)
+	}
+	if planErr.Feature != "" || planErr.Plan != "" {
+		t.Errorf("feature = %q, plan = %q, want empty", planErr.Feature, planErr.Plan)
+	}
+}
+
+func TestGetPlan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != mailnow.PlanEndpoint {
+			t.Errorf("request = %s %s, want GET %s", r.Method, r.URL.Path, mailnow.PlanEndpoint)
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {
+			"name": "starter",
+			"features": {"validation": true, "templates": false},
+			"quotas": {"emails_per_month": 10000}
+		}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	plan, err := client.GetPlan(context.Background())
+	if err != nil {
+		t.Fatalf("GetPlan() error = %v", err)
+	}
+	if plan.Name != "starter" {
+		t.Errorf("Name = %q, want starter", plan.Name)
+	}
+	if plan.Quotas["emails_per_month"] != 10000 {
+		t.Errorf("Quotas = %v", plan.Quotas)
+	}
+
+	tests := map[string]bool{
+		mailnow.FeatureValidation:   true,
+		mailnow.FeatureTemplates:    false,
+		mailnow.FeatureDedicatedIPs: false,
+	}
+	for feature, want := range tests {
+		if got := plan.Has(feature); got != want {
+			t.Errorf("Has(%q) = %v, want %v", feature, got, want)
+		}
+	}
+
+	var nilPlan *mailnow.Plan
+	if nilPlan.Has(mailnow.FeatureValidation) {
+		t.Error("nil plan should have no features")
+	}
+}
+
+func TestSendEmailPlanFeatureErrorNotRetried(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte(`{"error": {"code": "feature_not_available", "message": "dedicated IPs are not on your plan", "details": {"feature": "dedicated_ips", "plan": "free"}}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.SendEmail(context.Background(), newRetryRequest())
+	var planErr *mailnow.PlanFeatureError
+	if !errors.As(err, &planErr) {
+		t.Fatalf("expected PlanFeatureError, got %T: %v", err, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// phrasePolicy blocks emails whose HTML contains phrase
+func phrasePolicy(phrase string) mailnow.ContentPolicy {
+	return func(ctx context.Context, req *mailnow.EmailRequest) error {
+		if i := strings.Index(strings.ToLower(req.HTML), phrase); i >= 0 {
+			return mailnow.NewPolicyViolationError("blocked phrase", "phrase:"+phrase, req.HTML[i:i+len(phrase)], nil)
+		}
+		return nil
+	}
+}
+
+// attachmentPolicy blocks attachments with the given file extensions
+func attachmentPolicy(exts ...string) mailnow.ContentPolicy {
+	return func(ctx context.Context, req *mailnow.EmailRequest) error {
+		for _, a := range req.Attachments {
+			for _, ext := range exts {
+				if strings.EqualFold(path.Ext(a.Filename), ext) {
+					return mailnow.NewPolicyViolationError("blocked attachment type", "attachment:"+ext, a.Filename, nil)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func TestContentPolicyPhrase(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithContentPolicy(phrasePolicy("wire transfer")))
+
+	req := newRetryRequest()
+	req.HTML = "<p>Please send a Wire Transfer today</p>"
+	_, err := client.SendEmail(context.Background(), req)
+
+	var policyErr *mailnow.PolicyViolationError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("SendEmail() error = %v, want PolicyViolationError", err)
+	}
+	if policyErr.Rule != "phrase:wire transfer" || policyErr.Excerpt != "Wire Transfer" {
+		t.Errorf("Rule = %q, Excerpt = %q", policyErr.Rule, policyErr.Excerpt)
+	}
+	var validationErr *mailnow.ValidationError
+	if errors.As(err, &validationErr) {
+		t.Error("policy violation should not be a ValidationError")
+	}
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Errorf("SendEmail() of allowed content error = %v", err)
+	}
+	if n := len(server.Requests()); n != 1 {
+		t.Errorf("server received %d sends, want 1", n)
+	}
+}
+
+func TestContentPolicyAttachmentType(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithContentPolicy(attachmentPolicy(".exe", ".js")))
+
+	req := newRetryRequest()
+	req.Attachments = []mailnow.Attachment{
+		{Filename: "report.pdf", Content: "aGVsbG8=", ContentType: "application/pdf"},
+		{Filename: "setup.EXE", Content: "aGVsbG8=", ContentType: "application/octet-stream"},
+	}
+	_, err := client.SendEmail(context.Background(), req)
+
+	var policyErr *mailnow.PolicyViolationError
+	if !errors.As(err, &policyErr) || policyErr.Rule != "attachment:.exe" || policyErr.Excerpt != "setup.EXE" {
+		t.Errorf("SendEmail() error = %v, want attachment policy violation", err)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("server received %d sends, want 0", n)
+	}
+}
+
+func TestContentPolicyOrder(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var calls []string
+	record := func(name string, err error) mailnow.ContentPolicy {
+		return func(ctx context.Context, req *mailnow.EmailRequest) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	client := newTestClient(t, server.URL,
+		mailnow.WithContentPolicy(record("first", nil)),
+		mailnow.WithContentPolicy(record("second", mailnow.NewPolicyViolationError("blocked", "second", "", nil))),
+		mailnow.WithContentPolicy(record("third", nil)))
+
+	_, err := client.SendEmail(context.Background(), newRetryRequest())
+	var policyErr *mailnow.PolicyViolationError
+	if !errors.As(err, &policyErr) || policyErr.Rule != "second" {
+		t.Errorf("SendEmail() error = %v, want the second policy's violation", err)
+	}
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("policies called = %v, want first,second", calls)
+	}
+}
+
+func TestContentPolicyPanic(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithContentPolicy(func(ctx context.Context, req *mailnow.EmailRequest) error {
+		panic("rules not loaded")
+	}))
+
+	_, err := client.SendEmail(context.Background(), newRetryRequest())
+	var panicErr *mailnow.CallbackPanicError
+	if !errors.As(err, &panicErr) || panicErr.Callback != mailnow.CallbackContentPolicy {
+		t.Errorf("SendEmail() error = %v, want CallbackPanicError", err)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("server received %d sends, want 0", n)
+	}
+}
+
+// requestIDKey is the context key of the request ID set by the caller
+type requestIDKey struct{}
+
+func TestContentPolicyContext(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var gotID interface{}
+	var gotReq *mailnow.EmailRequest
+	client := newTestClient(t, server.URL, mailnow.WithContentPolicy(func(ctx context.Context, req *mailnow.EmailRequest) error {
+		gotID = ctx.Value(requestIDKey{})
+		gotReq = req
+		return nil
+	}))
+
+	// Policies see the request after normalization
+	req := newRetryRequest()
+	req.ReplyTo = []string{"a@example.com, b@example.com", "a@example.com"}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	if _, err := client.SendEmail(ctx, req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if gotID != "req-42" {
+		t.Errorf("policy context request ID = %v, want req-42", gotID)
+	}
+	if gotReq == nil || len(gotReq.ReplyTo) != 2 {
+		t.Errorf("policy request ReplyTo = %v, want normalized", gotReq)
+	}
+
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithContentPolicy(nil)); err == nil {
+		t.Error("expected NewClient to reject a nil content policy")
+	}
+}
+
+func TestContentPolicyCheckedBeforeReservations(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL,
+		mailnow.WithSendBudget(1, 24*time.Hour, nil),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{mailnow.DefaultCategory: {Rate: 1.0 / 3600, Burst: 1}}),
+		mailnow.WithContentPolicy(phrasePolicy("prize")))
+
+	blocked := newRetryRequest()
+	blocked.HTML = "<p>Claim your prize</p>"
+	var policyErr *mailnow.PolicyViolationError
+	if _, err := client.SendEmail(context.Background(), blocked); !errors.As(err, &policyErr) {
+		t.Fatalf("SendEmail() error = %v, want PolicyViolationError", err)
+	}
+
+	// The rejected send used neither the budget nor the rate limit token
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.SendEmail(ctx, newRetryRequest()); err != nil {
+		t.Errorf("SendEmail() after a rejected send error = %v", err)
+	}
+}
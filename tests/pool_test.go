@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestClientPoolCaching(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	pool := mailnow.NewClientPool(mailnow.WithBaseURL(server.URL))
+
+	key := mailnowtest.TestAPIKey()
+	first, err := pool.Get(key)
+	if err != nil {
+		t.Fatalf("failed to get client: %v", err)
+	}
+	second, err := pool.Get(key)
+	if err != nil {
+		t.Fatalf("failed to get client: %v", err)
+	}
+	if first != second {
+		t.Error("expected the cached client to be returned")
+	}
+
+	other, err := pool.Get(mailnowtest.LiveAPIKey())
+	if err != nil {
+		t.Fatalf("failed to get client: %v", err)
+	}
+	if other == first {
+		t.Error("expected a separate client per key")
+	}
+
+	// Pool options apply to every client
+	if _, err := sendTo(other, "recipient@example.com", "Hello"); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(server.Requests()) != 1 {
+		t.Errorf("expected the send to reach the test server")
+	}
+
+	pool.Remove(key)
+	again, err := pool.Get(key)
+	if err != nil {
+		t.Fatalf("failed to get client: %v", err)
+	}
+	if again == first {
+		t.Error("expected a new client after Remove")
+	}
+}
+
+func TestClientPoolEviction(t *testing.T) {
+	pool := mailnow.NewClientPool()
+	pool.SetMaxSize(2)
+
+	a, b, c := mailnowtest.TestAPIKey(), mailnowtest.TestAPIKey(), mailnowtest.TestAPIKey()
+	clientA, _ := pool.Get(a)
+	clientB, _ := pool.Get(b)
+
+	// Using a makes b the least recently used
+	pool.Get(a)
+	pool.Get(c)
+	if pool.Len() != 2 {
+		t.Fatalf("expected 2 cached clients, got %d", pool.Len())
+	}
+	if got, _ := pool.Get(a); got != clientA {
+		t.Error("expected a to stay cached")
+	}
+	if got, _ := pool.Get(b); got == clientB {
+		t.Error("expected b to have been evicted")
+	}
+}
+
+func TestClientPoolInvalidKey(t *testing.T) {
+	pool := mailnow.NewClientPool()
+	_, err := pool.Get("invalid")
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+	if pool.Len() != 0 {
+		t.Error("expected invalid keys not to be cached")
+	}
+}
+
+func TestClientPoolConcurrentGet(t *testing.T) {
+	pool := mailnow.NewClientPool()
+	key := mailnowtest.TestAPIKey()
+
+	var wg sync.WaitGroup
+	clients := make([]*mailnow.Client, 50)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = pool.Get(key)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, client := range clients {
+		if client == nil || client != clients[0] {
+			t.Fatalf("client %d differs from the first", i)
+		}
+	}
+}
+
+func TestClientPoolGetDoesNotWaitForNewClients(t *testing.T) {
+	release := make(chan struct{})
+	var heads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if heads.Add(1) > 1 {
+			<-release
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	pool := mailnow.NewClientPool(mailnow.WithBaseURL(server.URL), mailnow.WithEagerConnectivityCheck())
+	first := mailnowtest.TestAPIKey()
+	if _, err := pool.Get(first); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// A client whose connectivity check hangs does not block cached clients
+	go pool.Get("mn_test_0b5a2f3c9d8e4f7a6b1c2d3e4f5a6b7c")
+	for heads.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		pool.Get(first)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Get() of a cached client waited for another client's creation")
+	}
+}
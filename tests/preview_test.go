@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// getPreview fetches path from the preview server, checking the status
+func getPreview(t *testing.T, server *mailnowtest.PreviewServer, path string, wantStatus int) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Get(server.URL() + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("GET %s status = %d, want %d: %s", path, resp.StatusCode, wantStatus, body)
+	}
+	return resp, string(body)
+}
+
+func TestPreviewServer(t *testing.T) {
+	server, err := mailnowtest.NewPreviewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewPreviewServer() unexpected error: %v", err)
+	}
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Welcome <aboard>",
+		HTML:    "<h1>Hello</h1>",
+		Headers: map[string]string{"X-Ticket": "42"},
+		Attachments: []mailnow.Attachment{
+			{Filename: "hello.txt", Content: "SGVsbG8=", ContentType: "text/plain"},
+			{Filename: "terms.pdf", URL: "https://cdn.example.com/terms.pdf", ContentType: "application/pdf"},
+		},
+	}
+	resp, err := client.SendEmail(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	id := resp.Data.MessageID
+
+	// JSON list and get
+	_, body := getPreview(t, server, "/api/messages", http.StatusOK)
+	var list []mailnowtest.PreviewMessage
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("failed to decode message list: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != id {
+		t.Fatalf("message list = %+v, want the sent message %s", list, id)
+	}
+
+	_, body = getPreview(t, server, "/api/messages/"+id, http.StatusOK)
+	var message mailnowtest.PreviewMessage
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+	if diff := mailnowtest.DiffRequests(req, message.Request); diff != "" {
+		t.Errorf("stored request differs from sent request:\n%s", diff)
+	}
+	getPreview(t, server, "/api/messages/msg_missing", http.StatusNotFound)
+
+	// UI pages
+	_, body = getPreview(t, server, "/", http.StatusOK)
+	if !strings.Contains(body, "Welcome &lt;aboard&gt;") || !strings.Contains(body, "/messages/"+id) {
+		t.Errorf("index page does not list the message:\n%s", body)
+	}
+	_, body = getPreview(t, server, "/messages/"+id, http.StatusOK)
+	if !strings.Contains(body, "X-Ticket") || !strings.Contains(body, "hello.txt") {
+		t.Errorf("message page is missing headers or attachments:\n%s", body)
+	}
+	httpResp, body := getPreview(t, server, "/messages/"+id+"/html", http.StatusOK)
+	if body != "<h1>Hello</h1>" || httpResp.Header.Get("Content-Security-Policy") != "sandbox" {
+		t.Errorf("HTML preview = %q with CSP %q", body, httpResp.Header.Get("Content-Security-Policy"))
+	}
+
+	// Attachments
+	httpResp, body = getPreview(t, server, "/messages/"+id+"/attachments/0", http.StatusOK)
+	if body != "Hello" || httpResp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("attachment = %q with type %q, want Hello as text/plain", body, httpResp.Header.Get("Content-Type"))
+	}
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	redirect, err := noRedirect.Get(server.URL() + "/messages/" + id + "/attachments/1")
+	if err != nil {
+		t.Fatalf("GET URL attachment failed: %v", err)
+	}
+	redirect.Body.Close()
+	if redirect.StatusCode != http.StatusFound || redirect.Header.Get("Location") != "https://cdn.example.com/terms.pdf" {
+		t.Errorf("URL attachment = %d to %q, want a redirect to the URL", redirect.StatusCode, redirect.Header.Get("Location"))
+	}
+	getPreview(t, server, "/messages/"+id+"/attachments/2", http.StatusNotFound)
+}
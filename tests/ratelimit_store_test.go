@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// checkpointLimits allows three sends an hour, so that a restored bucket
+// does not refill during a test
+var checkpointLimits = map[string]mailnow.RateLimit{mailnow.DefaultCategory: {Rate: 1.0 / 3600, Burst: 3}}
+
+// sendNoWait sends without waiting for the rate limit
+func sendNoWait(client *mailnow.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.SendEmail(ctx, rateLimitTestRequest())
+	return err
+}
+
+func TestRateLimitStoreRestart(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	store := mailnow.NewMemoryRateLimitStore()
+	var warnings []mailnow.Warning
+
+	first := newTestClient(t, server.URL,
+		mailnow.WithCategoryRateLimits(checkpointLimits),
+		mailnow.WithRateLimitStore(store, time.Hour),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+	for i := 0; i < 2; i++ {
+		if err := sendNoWait(first); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+	first.Close(context.Background())
+
+	// The restarted client has only the token the first one left
+	second := newTestClient(t, server.URL,
+		mailnow.WithCategoryRateLimits(checkpointLimits),
+		mailnow.WithRateLimitStore(store, time.Hour),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+	if err := sendNoWait(second); err != nil {
+		t.Fatalf("SendEmail() after restart error = %v", err)
+	}
+	if err := sendNoWait(second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendEmail() over the limit error = %v, want context.DeadlineExceeded", err)
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Errorf("server received %d sends, want 3", n)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+}
+
+func TestRateLimitStoreInvalidCheckpoint(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	for name, checkpoint := range map[string]mailnow.RateLimitCheckpoint{
+		"corrupt": {Tokens: math.NaN(), At: time.Now()},
+		"undated": {Tokens: 3},
+		"future":  {Tokens: 3, At: time.Now().Add(24 * time.Hour)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := mailnow.NewMemoryRateLimitStore()
+			store.Save(context.Background(), mailnow.DefaultCategory, checkpoint)
+			var warnings []mailnow.Warning
+
+			client := newTestClient(t, server.URL,
+				mailnow.WithCategoryRateLimits(checkpointLimits),
+				mailnow.WithRateLimitStore(store, time.Hour),
+				mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+			if len(warnings) != 1 || warnings[0].Code != "rate_limit_checkpoint_invalid" || !strings.Contains(warnings[0].Message, mailnow.DefaultCategory) {
+				t.Fatalf("warnings = %v, want rate_limit_checkpoint_invalid", warnings)
+			}
+			// The bucket starts empty rather than full
+			if err := sendNoWait(client); err == nil {
+				t.Error("expected the first send to wait for the rate limit")
+			}
+		})
+	}
+}
+
+func TestRateLimitStoreClockSkew(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	// A checkpoint slightly in the future is not refilled
+	store := mailnow.NewMemoryRateLimitStore()
+	store.Save(context.Background(), mailnow.DefaultCategory, mailnow.RateLimitCheckpoint{Tokens: 1, At: time.Now().Add(30 * time.Second)})
+	var warnings []mailnow.Warning
+	client := newTestClient(t, server.URL,
+		mailnow.WithCategoryRateLimits(checkpointLimits),
+		mailnow.WithRateLimitStore(store, time.Hour),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+	if err := sendNoWait(client); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if err := sendNoWait(client); err == nil {
+		t.Error("expected the second send to wait for the rate limit")
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+}
+
+func TestSendBudgetRestart(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	store := mailnow.NewMemoryBudgetStore()
+
+	first := newTestClient(t, server.URL, mailnow.WithSendBudget(3, 24*time.Hour, store))
+	for i := 0; i < 2; i++ {
+		if _, err := first.SendEmail(context.Background(), rateLimitTestRequest()); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+
+	second := newTestClient(t, server.URL, mailnow.WithSendBudget(3, 24*time.Hour, store))
+	sent := 0
+	for i := 0; i < 3; i++ {
+		if _, err := second.SendEmail(context.Background(), rateLimitTestRequest()); err == nil {
+			sent++
+		}
+	}
+	if sent != 1 || len(server.Requests()) != 3 {
+		t.Errorf("restarted client sent %d, server received %d, want 1 and 3", sent, len(server.Requests()))
+	}
+}
+
+func TestRateLimitStoreOptionErrors(t *testing.T) {
+	store := mailnow.NewMemoryRateLimitStore()
+	for name, opts := range map[string][]mailnow.Option{
+		"without rate limits": {mailnow.WithRateLimitStore(store, 0)},
+		"nil store":           {mailnow.WithRateLimitStore(nil, 0)},
+		"negative interval":   {mailnow.WithRateLimitStore(store, -time.Second)},
+	} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opts...); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
+
+func TestRateLimitStoreSavesFirstSendAfterRestore(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	store := mailnow.NewMemoryRateLimitStore()
+	store.Save(context.Background(), mailnow.DefaultCategory, mailnow.RateLimitCheckpoint{Tokens: 2, At: time.Now()})
+	var warnings []mailnow.Warning
+
+	// The first client crashes, without Close, after one send
+	first := newTestClient(t, server.URL,
+		mailnow.WithCategoryRateLimits(checkpointLimits),
+		mailnow.WithRateLimitStore(store, time.Hour),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+	if err := sendNoWait(first); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	checkpoint, _, _ := store.Load(context.Background(), mailnow.DefaultCategory)
+	if checkpoint.Tokens > 1.01 {
+		t.Errorf("saved tokens = %v, want the send saved at once", checkpoint.Tokens)
+	}
+}
+
+// blockingRateLimitStore is a RateLimitStore whose saves wait for release
+type blockingRateLimitStore struct {
+	*mailnow.MemoryRateLimitStore
+	saving  chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingRateLimitStore) Save(ctx context.Context, name string, checkpoint mailnow.RateLimitCheckpoint) error {
+	s.saving <- struct{}{}
+	<-s.release
+	return s.MemoryRateLimitStore.Save(ctx, name, checkpoint)
+}
+
+func TestRateLimitStoreSlowSaveDoesNotBlockSends(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	store := &blockingRateLimitStore{
+		MemoryRateLimitStore: mailnow.NewMemoryRateLimitStore(),
+		saving:               make(chan struct{}, 10),
+		release:              make(chan struct{}),
+	}
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{mailnow.DefaultCategory: {Rate: 1, Burst: 10}}),
+		mailnow.WithRateLimitStore(store, 0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The first send saves and is stuck in the store
+	first := make(chan error, 1)
+	go func() { first <- sendNoWait(client) }()
+	<-store.saving
+
+	if err := sendNoWait(client); err != nil {
+		t.Fatalf("SendEmail() during a save error = %v", err)
+	}
+
+	// The stuck save saves once more for the second send
+	close(store.release)
+	if err := <-first; err != nil {
+		t.Fatalf("first SendEmail() error = %v", err)
+	}
+	if n := len(store.saving); n != 1 {
+		t.Errorf("saves after the first = %d, want 1", n)
+	}
+	checkpoint, _, _ := store.Load(context.Background(), mailnow.DefaultCategory)
+	if checkpoint.Tokens > 8.5 {
+		t.Errorf("saved tokens = %v, want both sends saved", checkpoint.Tokens)
+	}
+}
+
+func TestRateLimitStoreWithOptions(t *testing.T) {
+	store := mailnow.NewMemoryRateLimitStore()
+	limits := map[string]mailnow.RateLimit{mailnow.DefaultCategory: {Rate: 1, Burst: 3}}
+	plain, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithCategoryRateLimits(limits))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stored, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithCategoryRateLimits(limits),
+		mailnow.WithRateLimitStore(store, time.Minute))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var validationErr *mailnow.ValidationError
+	if _, err := plain.WithOptions(mailnow.WithRateLimitStore(store, time.Minute)); !errors.As(err, &validationErr) {
+		t.Errorf("WithOptions(WithRateLimitStore) error = %v, want ValidationError", err)
+	}
+	if _, err := stored.WithOptions(mailnow.WithCategoryRateLimits(limits)); !errors.As(err, &validationErr) {
+		t.Errorf("WithOptions(WithCategoryRateLimits) with a store error = %v, want ValidationError", err)
+	}
+	if _, err := plain.WithOptions(mailnow.WithCategoryRateLimits(limits)); err != nil {
+		t.Errorf("WithOptions(WithCategoryRateLimits) without a store error = %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func newRateLimitTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "data": {"message_id": "msg_12345", "status": "queued"}}`))
+	}))
+}
+
+func rateLimitTestRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test Subject",
+		HTML:    "<h1>Test</h1>",
+	}
+}
+
+func TestCategoryRateLimits(t *testing.T) {
+	server := newRateLimitTestServer()
+	defer server.Close()
+
+	var logs bytes.Buffer
+	var logsMu sync.Mutex
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{w: &logs, mu: &logsMu}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithLogger(logger),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{
+			"marketing":     {Rate: 5, Burst: 1},
+			"transactional": {Rate: 1000, Burst: 100},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Marketing sends are throttled to one every 200ms
+	marketingDone := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			if _, err := client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("marketing")); err != nil {
+				t.Errorf("marketing send %d: unexpected error: %v", i+1, err)
+			}
+		}
+		marketingDone <- time.Since(start)
+	}()
+
+	// Transactional sends proceed while marketing is waiting
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("transactional")); err != nil {
+			t.Errorf("transactional send %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("transactional sends should not be throttled, took %v", elapsed)
+	}
+
+	if elapsed := <-marketingDone; elapsed < 500*time.Millisecond {
+		t.Errorf("marketing sends should be throttled to 5/s, 4 sends took %v", elapsed)
+	}
+
+	logsMu.Lock()
+	defer logsMu.Unlock()
+	if !strings.Contains(logs.String(), "category=marketing") {
+		t.Errorf("expected rate limit wait to be logged with the category, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "category=transactional") {
+		t.Errorf("transactional sends should not wait, got %q", logs.String())
+	}
+}
+
+func TestCategoryRateLimitsOverallLimit(t *testing.T) {
+	server := newRateLimitTestServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{
+			mailnow.AllCategories: {Rate: 5, Burst: 1},
+			"transactional":       {Rate: 1000, Burst: 100},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	if _, err := client.SendEmail(ctx, rateLimitTestRequest()); err != nil {
+		t.Fatalf("uncategorized send: unexpected error: %v", err)
+	}
+	if _, err := client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("transactional")); err != nil {
+		t.Fatalf("transactional send: unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("overall limit should apply to every category, 2 sends took %v", elapsed)
+	}
+}
+
+func TestCategoryRateLimitsOverallWaitRefundsCategory(t *testing.T) {
+	server := newRateLimitTestServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{
+			mailnow.AllCategories: {Rate: 20, Burst: 1},
+			"marketing":           {Rate: 1.0 / 3600, Burst: 1},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), rateLimitTestRequest()); err != nil {
+		t.Fatalf("uncategorized send: unexpected error: %v", err)
+	}
+
+	// The overall bucket is empty, so the marketing send fails after
+	// taking the marketing token
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("marketing")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("marketing send: error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The marketing token was returned
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("marketing")); err != nil {
+		t.Errorf("marketing send after the failed one: unexpected error: %v", err)
+	}
+}
+
+func TestCategoryRateLimitsContextCancellation(t *testing.T) {
+	server := newRateLimitTestServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{
+			"marketing": {Rate: 0.5, Burst: 1},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), rateLimitTestRequest(), mailnow.WithCategory("marketing")); err != nil {
+		t.Fatalf("first send: unexpected error: %v", err)
+	}
+
+	// Cancellation while waiting for a token
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("marketing"))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled wait should return promptly, took %v", elapsed)
+	}
+	if errors.As(err, new(*mailnow.ConnectionError)) {
+		t.Errorf("a local wait should not fail with a ConnectionError, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error to wrap context.Canceled, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "marketing") {
+		t.Errorf("expected error to name the category, got %q", err.Error())
+	}
+
+	// A deadline shorter than the wait fails without waiting
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("marketing"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("wait exceeding the deadline should fail fast, took %v", elapsed)
+	}
+}
+
+func TestCategoryRateLimitsUseClientClock(t *testing.T) {
+	server := newRateLimitTestServer()
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithClock(clock.Now),
+		mailnow.WithCategoryRateLimits(map[string]mailnow.RateLimit{
+			"marketing": {Rate: 1, Burst: 1},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), rateLimitTestRequest(), mailnow.WithCategory("marketing")); err != nil {
+		t.Fatalf("first send: unexpected error: %v", err)
+	}
+
+	// The bucket stays empty however long the real clock runs while the
+	// client clock stands still
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := client.SendEmail(ctx, rateLimitTestRequest(), mailnow.WithCategory("marketing")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second send to exceed its deadline, got %v", err)
+	}
+
+	// Advancing the client clock refills the bucket without waiting
+	clock.Advance(time.Second)
+	start := time.Now()
+	if _, err := client.SendEmail(context.Background(), rateLimitTestRequest(), mailnow.WithCategory("marketing")); err != nil {
+		t.Fatalf("send after advancing the clock: unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("send after advancing the clock should not wait, took %v", elapsed)
+	}
+}
+
+func TestCategoryRateLimitsValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits map[string]mailnow.RateLimit
+	}{
+		{name: "zero rate", limits: map[string]mailnow.RateLimit{"marketing": {Rate: 0, Burst: 1}}},
+		{name: "zero burst", limits: map[string]mailnow.RateLimit{"marketing": {Rate: 1, Burst: 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithCategoryRateLimits(tt.limits))
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("expected ValidationError, got %T: %v", err, err)
+			}
+		})
+	}
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), rateLimitTestRequest(), mailnow.WithCategory(""))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError for empty category, got %T: %v", err, err)
+	}
+}
+
+// lockedWriter serializes writes to w
+type lockedWriter struct {
+	w  *bytes.Buffer
+	mu *sync.Mutex
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
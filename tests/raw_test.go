@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestAPISendEmail(t *testing.T) {
+	var got mailnow.EmailRequest
+	var subaccount string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != mailnow.EmailSendEndpoint {
+			t.Errorf("request = %s %s, want POST %s", r.Method, r.URL.Path, mailnow.EmailSendEndpoint)
+		}
+		subaccount = r.Header.Get(mailnow.SubaccountHeader)
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("X-Request-Id", "req_1")
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	api := &mailnow.API{
+		APIKey:  "mn_test_7e59df7ce4a14545b443837804ec9722",
+		BaseURL: server.URL,
+		Header:  http.Header{mailnow.SubaccountHeader: {"sub_1"}},
+	}
+
+	// The raw layer does not validate, so an email without a body is sent as is
+	resp, meta, err := api.SendEmail(context.Background(), &mailnow.EmailRequest{From: "sender@example.com", To: "not-an-address"})
+	if err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if resp.Data.MessageID != "msg_123" {
+		t.Errorf("MessageID = %q, want msg_123", resp.Data.MessageID)
+	}
+	if got.To != "not-an-address" {
+		t.Errorf("server got To = %q, want the request unchanged", got.To)
+	}
+	if subaccount != "sub_1" {
+		t.Errorf("sub-account header = %q, want sub_1", subaccount)
+	}
+	if meta.StatusCode != http.StatusOK || meta.Header.Get("X-Request-Id") != "req_1" {
+		t.Errorf("meta = %+v, want status 200 and the response headers", meta)
+	}
+}
+
+func TestAPIGetTrackingDomain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != mailnow.TrackingDomainsEndpoint+"/links.example.com" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"domain": "links.example.com", "verified": true}}`))
+	}))
+	defer server.Close()
+
+	api := &mailnow.API{APIKey: "mn_test_7e59df7ce4a14545b443837804ec9722", BaseURL: server.URL}
+	td, meta, err := api.GetTrackingDomain(context.Background(), "links.example.com")
+	if err != nil {
+		t.Fatalf("GetTrackingDomain() error = %v", err)
+	}
+	if td.Domain != "links.example.com" || !td.Verified {
+		t.Errorf("tracking domain = %+v", td)
+	}
+	if meta.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", meta.StatusCode)
+	}
+}
+
+func TestAPIAccountEndpoints(t *testing.T) {
+	var requests []string
+	var unsubscribe map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case mailnow.PlanEndpoint:
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"name": "growth", "features": {"templates": true}}}`))
+		case mailnow.SuppressionsEndpoint:
+			json.NewDecoder(r.Body).Decode(&unsubscribe)
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {}}`))
+		case mailnow.EmailEndpoint + "/custom/order-42@example.com":
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_42", "status": "delivered"}}`))
+		case mailnow.BulkValidateEndpoint:
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": [{"email": "a@example.com", "valid": true}]}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	api := &mailnow.API{APIKey: "mn_test_7e59df7ce4a14545b443837804ec9722", BaseURL: server.URL}
+	if plan, _, err := api.GetPlan(ctx); err != nil || !plan.Has(mailnow.FeatureTemplates) {
+		t.Errorf("GetPlan() = %+v, %v", plan, err)
+	}
+	// The raw layer does not validate, so the address is sent as is
+	if _, err := api.Unsubscribe(ctx, "not-an-address", "weekly"); err != nil {
+		t.Errorf("Unsubscribe() error = %v", err)
+	}
+	if unsubscribe["email"] != "not-an-address" || unsubscribe["list_id"] != "weekly" || unsubscribe["reason"] != mailnow.SuppressionReasonUnsubscribe {
+		t.Errorf("unsubscribe body = %v", unsubscribe)
+	}
+	if resp, _, err := api.GetEmailByCustomID(ctx, "order-42@example.com"); err != nil || resp.Data.MessageID != "msg_42" {
+		t.Errorf("GetEmailByCustomID() = %+v, %v", resp, err)
+	}
+	validations, meta, err := api.ValidateRecipients(ctx, []string{"a@example.com"})
+	if err != nil || len(validations) != 1 || !validations[0].Valid || meta.StatusCode != http.StatusOK {
+		t.Errorf("ValidateRecipients() = %+v, %+v, %v", validations, meta, err)
+	}
+
+	want := []string{
+		"GET " + mailnow.PlanEndpoint,
+		"POST " + mailnow.SuppressionsEndpoint,
+		"GET " + mailnow.EmailEndpoint + "/custom/order-42@example.com",
+		"POST " + mailnow.BulkValidateEndpoint,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestAPIDoesNotRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": "rate_limited", "message": "slow down"}}`))
+	}))
+	defer server.Close()
+
+	api := &mailnow.API{APIKey: "mn_test_7e59df7ce4a14545b443837804ec9722", BaseURL: server.URL}
+	_, meta, err := api.SendEmail(context.Background(), newRetryRequest())
+
+	var rateLimitErr *mailnow.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("SendEmail() error = %v, want RateLimitError", err)
+	}
+	if mailnow.ErrorCode(err) != "rate_limited" || meta.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("code = %q, status = %d", mailnow.ErrorCode(err), meta.StatusCode)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestAPIErrorMapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": "sender_not_verified", "message": "sender not verified"}}`))
+	}))
+	defer server.Close()
+
+	sentinel := errors.New("mapped")
+	api := &mailnow.API{
+		APIKey:  "mn_test_7e59df7ce4a14545b443837804ec9722",
+		BaseURL: server.URL,
+		ErrorMapper: func(statusCode int, body []byte, defaultErr error) error {
+			return sentinel
+		},
+	}
+	if _, err := api.DeleteTrackingDomain(context.Background(), "links.example.com"); !errors.Is(err, sentinel) {
+		t.Errorf("DeleteTrackingDomain() error = %v, want the mapped error", err)
+	}
+}
+
+func TestAPIConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	api := &mailnow.API{APIKey: "mn_test_7e59df7ce4a14545b443837804ec9722", BaseURL: server.URL}
+	_, meta, err := api.ListSenderIdentities(context.Background())
+
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("ListSenderIdentities() error = %v, want ConnectionError", err)
+	}
+	if meta.StatusCode != 0 {
+		t.Errorf("StatusCode = %d, want 0 without a response", meta.StatusCode)
+	}
+}
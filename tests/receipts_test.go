@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestReadReceiptHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		receiptTo string
+		want      string
+	}{
+		{"explicit address", "receipts@example.com", "receipts@example.com"},
+		{"defaults to from", "", "sender@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mailnowtest.NewServer()
+			defer server.Close()
+			client := newTestClient(t, server.URL)
+
+			req := &mailnow.EmailRequest{
+				From:               "sender@example.com",
+				To:                 "recipient@example.com",
+				Subject:            "Compliance notice",
+				HTML:               "<p>Please confirm</p>",
+				Headers:            map[string]string{"X-Notice": "1"},
+				RequestReadReceipt: true,
+				ReceiptTo:          tt.receiptTo,
+			}
+			if _, err := client.SendEmail(context.Background(), req); err != nil {
+				t.Fatalf("send failed: %v", err)
+			}
+
+			headers := server.Requests()[0].Headers
+			for _, name := range []string{mailnow.DispositionNotificationHeader, mailnow.ReturnReceiptHeader} {
+				if headers[name] != tt.want {
+					t.Errorf("expected %s: %s, got %q", name, tt.want, headers[name])
+				}
+			}
+			if headers["X-Notice"] != "1" {
+				t.Error("expected custom headers to be kept")
+			}
+			if len(req.Headers) != 1 {
+				t.Error("expected the caller's headers to be left unchanged")
+			}
+		})
+	}
+}
+
+func TestReadReceiptValidation(t *testing.T) {
+	base := func() *mailnow.EmailRequest {
+		return &mailnow.EmailRequest{From: "sender@example.com", To: "recipient@example.com", Subject: "Hi", HTML: "<p>Hi</p>"}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*mailnow.EmailRequest)
+	}{
+		{"header conflict", func(r *mailnow.EmailRequest) {
+			r.RequestReadReceipt = true
+			r.Headers = map[string]string{"disposition-notification-to": "other@example.com"}
+		}},
+		{"return receipt conflict", func(r *mailnow.EmailRequest) {
+			r.RequestReadReceipt = true
+			r.Headers = map[string]string{"Return-Receipt-To": "other@example.com"}
+		}},
+		{"invalid receipt address", func(r *mailnow.EmailRequest) {
+			r.RequestReadReceipt = true
+			r.ReceiptTo = "not-an-address"
+		}},
+		{"receipt address without receipt", func(r *mailnow.EmailRequest) {
+			r.ReceiptTo = "receipts@example.com"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base()
+			tt.modify(req)
+			var validationErr *mailnow.ValidationError
+			if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+				t.Errorf("expected ValidationError, got %v", err)
+			}
+			if !mailnow.LintEmailRequest(req).HasErrors() {
+				t.Error("expected lint errors")
+			}
+		})
+	}
+
+	// Receipt headers without RequestReadReceipt are plain custom headers
+	req := base()
+	req.Headers = map[string]string{mailnow.DispositionNotificationHeader: "receipts@example.com"}
+	if err := mailnow.ValidateEmailRequest(req); err != nil {
+		t.Errorf("expected custom receipt header to be allowed, got %v", err)
+	}
+}
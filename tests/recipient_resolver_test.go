@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// addressBook maps aliases to their members and counts lookups
+type addressBook struct {
+	groups  map[string][]string
+	lookups map[string]int
+}
+
+// newAddressBook creates an address book of groups
+func newAddressBook(groups map[string][]string) *addressBook {
+	return &addressBook{groups: groups, lookups: make(map[string]int)}
+}
+
+func (b *addressBook) resolve(ctx context.Context, alias string) ([]string, error) {
+	b.lookups[alias]++
+	if alias == "team:broken" {
+		return nil, errors.New("directory unavailable")
+	}
+	return b.groups[alias], nil
+}
+
+// aliasRequest creates a request to and with replies to the given entries
+func aliasRequest(to string, replyTo ...string) *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "payments@example.com",
+		To:      to,
+		Subject: "Settlement report",
+		HTML:    "<p>The daily settlement report is ready.</p>",
+		ReplyTo: replyTo,
+	}
+}
+
+func TestRecipientResolverExpands(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	book := newAddressBook(map[string][]string{
+		"oncall:payments": {"dana@example.com"},
+		"team:payments":   {"dana@example.com", "lee@example.com"},
+		"team:billing":    {"Lee@example.com", "sam@example.com", "team:finance"},
+		"team:finance":    {"sam@example.com", "ari@example.com"},
+	})
+	client := newTestClient(t, server.URL, mailnow.WithRecipientResolver(book.resolve))
+
+	req := aliasRequest("oncall:payments", "team:payments, team:billing", "ari@example.com", "team:finance")
+	original := *req
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	sent := server.Requests()[0]
+	if sent.To != "dana@example.com" {
+		t.Errorf("To = %q, want dana@example.com", sent.To)
+	}
+	want := []string{"dana@example.com", "lee@example.com", "sam@example.com", "ari@example.com"}
+	if !reflect.DeepEqual(sent.ReplyTo, want) {
+		t.Errorf("ReplyTo = %v, want %v", sent.ReplyTo, want)
+	}
+	if book.lookups["team:finance"] != 1 {
+		t.Errorf("team:finance was resolved %d times, want once", book.lookups["team:finance"])
+	}
+	if !reflect.DeepEqual(*req, original) {
+		t.Error("SendEmail modified the caller's request")
+	}
+
+	// Requests without aliases are not resolved
+	if _, err := sendTo(client, "kim@example.com", "Hello"); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if len(book.lookups) != 4 {
+		t.Errorf("lookups = %v", book.lookups)
+	}
+}
+
+func TestRecipientResolverErrors(t *testing.T) {
+	groups := map[string][]string{
+		"team:payments": {"dana@example.com", "lee@example.com"},
+		"team:empty":    {},
+		"group:a":       {"dana@example.com", "group:b"},
+		"group:b":       {"group:c"},
+		"group:c":       {"group:a"},
+	}
+	// level:0 nests one level more than allowed, level:1 exactly as many
+	for i := 0; i < mailnow.MaxRecipientAliasDepth; i++ {
+		groups[fmt.Sprintf("level:%d", i)] = []string{fmt.Sprintf("level:%d", i+1)}
+	}
+	groups[fmt.Sprintf("level:%d", mailnow.MaxRecipientAliasDepth)] = []string{"deep@example.com"}
+
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithRecipientResolver(newAddressBook(groups).resolve))
+
+	tests := []struct {
+		name string
+		req  *mailnow.EmailRequest
+		want string
+	}{
+		{"resolver error", aliasRequest("team:broken"), "directory unavailable"},
+		{"unknown alias", aliasRequest("dana@example.com", "team:unknown"), "resolves to no addresses"},
+		{"empty group", aliasRequest("dana@example.com", "team:empty"), "resolves to no addresses"},
+		{"cycle", aliasRequest("dana@example.com", "group:a"), "group:a -> group:b -> group:c -> group:a"},
+		{"too deep", aliasRequest("dana@example.com", "level:0"), "more than"},
+		{"several To addresses", aliasRequest("team:payments"), "resolves to 2 addresses"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.SendEmail(context.Background(), tt.req)
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SendEmail() error = %v, want ValidationError containing %q", err, tt.want)
+			}
+		})
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("server received %d sends, want 0", n)
+	}
+
+	// Aliases nested up to the limit resolve
+	if _, err := client.SendEmail(context.Background(), aliasRequest("dana@example.com", "level:1")); err != nil {
+		t.Errorf("SendEmail() at the depth limit error = %v", err)
+	}
+}
+
+func TestRecipientResolverPanic(t *testing.T) {
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithRecipientResolver(func(ctx context.Context, addr string) ([]string, error) {
+			panic("address book not loaded")
+		}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), aliasRequest("team:payments"))
+	var panicErr *mailnow.CallbackPanicError
+	if !errors.As(err, &panicErr) || panicErr.Callback != mailnow.CallbackRecipientResolver {
+		t.Errorf("SendEmail() error = %v, want CallbackPanicError", err)
+	}
+
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithRecipientResolver(nil)); err == nil {
+		t.Error("expected NewClient to fail with a nil resolver")
+	}
+}
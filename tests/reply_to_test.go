@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestValidateEmailRequestReplyTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		replyTo []string
+		headers map[string]string
+		wantErr bool
+	}{
+		{name: "multiple addresses", replyTo: []string{"agent@example.com", "support@example.com"}},
+		{name: "comma-separated entry", replyTo: []string{"agent@example.com, support@example.com"}},
+		{name: "duplicates", replyTo: []string{"agent@example.com", "Agent@Example.com"}},
+		{name: "one invalid entry", replyTo: []string{"agent@example.com", "support@"}, wantErr: true},
+		{name: "invalid entry in list", replyTo: []string{"agent@example.com,support"}, wantErr: true},
+		{name: "other headers", replyTo: []string{"agent@example.com"}, headers: map[string]string{"X-Ticket": "42"}},
+		{name: "header conflict", replyTo: []string{"agent@example.com"}, headers: map[string]string{"reply-to": "other@example.com"}, wantErr: true},
+		{name: "reply-to header only", headers: map[string]string{"Reply-To": "other@example.com"}},
+		{name: "invalid header name", headers: map[string]string{"X Ticket": "42"}, wantErr: true},
+		{name: "header injection", headers: map[string]string{"X-Ticket": "42\r\nBcc: victim@example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &mailnow.EmailRequest{
+				From:    "sender@example.com",
+				To:      "recipient@example.com",
+				Subject: "Test",
+				HTML:    "<p>Test</p>",
+				ReplyTo: tt.replyTo,
+				Headers: tt.headers,
+			}
+			err := mailnow.ValidateEmailRequest(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateEmailRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var validationErr *mailnow.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Errorf("ValidateEmailRequest() error type = %T, want ValidationError", err)
+				}
+			}
+			if report := mailnow.LintEmailRequest(req); report.HasErrors() != tt.wantErr {
+				t.Errorf("LintEmailRequest() errors = %v, wantErr %v", report.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHeadersDeterministic(t *testing.T) {
+	headers := map[string]string{
+		"X-Ticket":   "42\r\nBcc: victim@example.com",
+		"A Header":   "1",
+		"X-Priority": "1\n",
+		"Z Header":   "2",
+	}
+	for i := 0; i < 20; i++ {
+		err := mailnow.ValidateHeaders(headers)
+		if err == nil || !strings.Contains(err.Error(), `"A Header"`) {
+			t.Fatalf("ValidateHeaders() error = %v, want the error for the first header name", err)
+		}
+	}
+}
+
+func TestSendEmailReplyToNormalized(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		got = req.ReplyTo
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    "<p>Test</p>",
+		ReplyTo: []string{"agent@example.com, support@example.com", "AGENT@example.com"},
+	}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+
+	if strings.Join(got, ",") != "agent@example.com,support@example.com" {
+		t.Errorf("server received reply-to %q, want agent and support once each", got)
+	}
+	if len(req.ReplyTo) != 2 {
+		t.Errorf("SendEmail() modified the request reply-to to %q", req.ReplyTo)
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestResponseValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		bodies      []string
+		expectError []bool
+		errContains string
+	}{
+		{
+			name:        "valid response",
+			statusCode:  200,
+			bodies:      []string{`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`},
+			expectError: []bool{false},
+		},
+		{
+			name:        "missing status code echo is accepted",
+			statusCode:  200,
+			bodies:      []string{`{"success": true, "data": {"message_id": "msg_1", "status": "queued"}}`},
+			expectError: []bool{false},
+		},
+		{
+			name:        "message ID prefix mismatch",
+			statusCode:  200,
+			bodies:      []string{`{"success": true, "status_code": 200, "data": {"message_id": "abc_1", "status": "queued"}}`},
+			expectError: []bool{true},
+			errContains: "prefix",
+		},
+		{
+			name:        "status code mismatch",
+			statusCode:  202,
+			bodies:      []string{`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`},
+			expectError: []bool{true},
+			errContains: "status code 200",
+		},
+		{
+			name:       "duplicate message ID across sends",
+			statusCode: 200,
+			bodies: []string{
+				`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`,
+				`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`,
+			},
+			expectError: []bool{false, true},
+			errContains: "replayed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.bodies[n-1]))
+			}))
+			defer server.Close()
+
+			client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+				mailnow.WithBaseURL(server.URL),
+				mailnow.WithResponseValidation(),
+			)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			req := &mailnow.EmailRequest{
+				From:    "sender@example.com",
+				To:      "recipient@example.com",
+				Subject: "Test Subject",
+				HTML:    "<h1>Test</h1>",
+			}
+
+			for i, wantErr := range tt.expectError {
+				resp, err := client.SendEmail(context.Background(), req)
+				if !wantErr {
+					if err != nil {
+						t.Fatalf("send %d: expected no error but got: %v", i+1, err)
+					}
+					continue
+				}
+
+				var serverErr *mailnow.ServerError
+				if !errors.As(err, &serverErr) {
+					t.Fatalf("send %d: expected ServerError, got %T: %v", i+1, err, err)
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("send %d: expected error to contain %q, got %q", i+1, tt.errContains, err.Error())
+				}
+				if resp != nil {
+					t.Errorf("send %d: expected nil response when error occurs, got %v", i+1, resp)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseValidationDisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 201, "data": {"message_id": "abc_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test Subject",
+		HTML:    "<h1>Test</h1>",
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendEmail(context.Background(), req); err != nil {
+			t.Fatalf("expected no error without response validation, got: %v", err)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func newRetryRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    "<p>Test</p>",
+	}
+}
+
+// newSequenceServer returns a server that fails with each status in
+// failures in turn and then accepts sends
+func newSequenceServer(t *testing.T, failures ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n <= len(failures) {
+			w.WriteHeader(failures[n-1])
+			fmt.Fprintf(w, `{"error": {"code": "transient", "message": "attempt %d failed"}}`, n)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRetryRequestTimeoutThenSuccess(t *testing.T) {
+	server, calls := newSequenceServer(t, http.StatusRequestTimeout)
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	resp, err := client.SendEmail(context.Background(), newRetryRequest())
+	if err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if resp.Data.MessageID != "msg_123" {
+		t.Errorf("expected message ID msg_123, got %s", resp.Data.MessageID)
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestRetryTooEarlyUsesFixedDelay(t *testing.T) {
+	server, calls := newSequenceServer(t, http.StatusTooEarly, http.StatusTooEarly)
+
+	// A backoff this long would time the test out if it were used
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*mailnow.TooEarlyRetryDelay || elapsed > 5*time.Second {
+		t.Errorf("expected two fixed delays of %s, took %s", mailnow.TooEarlyRetryDelay, elapsed)
+	}
+	if n := atomic.LoadInt32(calls); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  []int
+		wantCalls int32
+	}{
+		{name: "attempts exhausted", failures: []int{503, 503, 503}, wantCalls: 3},
+		{name: "not retryable", failures: []int{400}, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newSequenceServer(t, tt.failures...)
+			client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+				mailnow.WithBaseURL(server.URL),
+				mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if _, err := client.SendEmail(context.Background(), newRetryRequest()); err == nil {
+				t.Fatal("SendEmail() expected error, got nil")
+			}
+			if n := atomic.LoadInt32(calls); n != tt.wantCalls {
+				t.Errorf("expected %d attempts, got %d", tt.wantCalls, n)
+			}
+		})
+	}
+}
+
+func TestWithRetryInvalid(t *testing.T) {
+	policies := []mailnow.RetryPolicy{
+		{MaxAttempts: 0},
+		{MaxAttempts: 3, BaseDelay: -time.Second},
+		{MaxAttempts: 3, MaxDelay: -time.Second},
+	}
+	for _, policy := range policies {
+		_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithRetry(policy))
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("WithRetry(%+v) expected ValidationError, got %v", policy, err)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "connection error", err: mailnow.NewConnectionError("connection reset", nil), want: true},
+		{name: "rate limit", err: mailnow.NewRateLimitError("slow down", nil), want: true},
+		{name: "server error without status", err: mailnow.NewServerError("unexpected response", nil), want: false},
+		{name: "validation error", err: mailnow.NewValidationError("bad request", nil), want: false},
+		{name: "auth error", err: mailnow.NewAuthError("bad key", nil), want: false},
+		{name: "context canceled", err: mailnow.NewConnectionError("failed to send request", context.Canceled), want: false},
+		{name: "ambiguous result", err: mailnow.NewAmbiguousResultError("may have been sent", "abc", mailnow.NewConnectionError("connection reset", nil)), want: false},
+		{name: "plain error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mailnow.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Status codes are classified from API responses
+	for status, want := range map[int]bool{408: true, 425: true, 429: true, 500: true, 503: true, 400: false, 404: false, 409: false} {
+		server, _ := newSequenceServer(t, status)
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		_, err = mailnow.HandleResponse(resp)
+		if got := mailnow.IsRetryable(err); got != want {
+			t.Errorf("IsRetryable() for HTTP %d = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestIsRetryableLocalServerErrors(t *testing.T) {
+	// A successful response that cannot be decoded was still accepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`not json`))
+	}))
+	defer server.Close()
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), newRetryRequest())
+	var serverErr *mailnow.ServerError
+	if !errors.As(err, &serverErr) || mailnow.IsRetryable(err) {
+		t.Errorf("SendEmail() error = %v, want a non-retryable ServerError", err)
+	}
+
+	// Errors built by an error mapper keep the response status
+	failing, _ := newSequenceServer(t, http.StatusBadGateway)
+	client, err = mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(failing.URL),
+		mailnow.WithErrorMapper(func(statusCode int, body []byte, defaultErr error) error {
+			return mailnow.NewServerError("upstream unavailable", nil)
+		}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	_, err = client.SendEmail(context.Background(), newRetryRequest())
+	if !mailnow.IsRetryable(err) || mailnow.ErrorStatusCode(err) != http.StatusBadGateway {
+		t.Errorf("SendEmail() error = %v, want a retryable error with status 502", err)
+	}
+}
+
+// newTruncatingServer returns a server whose first response is cut off
+// after the request was processed, and that accepts later sends
+func newTruncatingServer(t *testing.T) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte(`{"success": true,`))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRetryAmbiguousResult(t *testing.T) {
+	policy := mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	t.Run("without idempotency key", func(t *testing.T) {
+		server, calls := newTruncatingServer(t)
+		client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL), policy)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		req := newRetryRequest()
+		_, err = client.SendEmail(context.Background(), req)
+		var ambiguousErr *mailnow.AmbiguousResultError
+		if !errors.As(err, &ambiguousErr) {
+			t.Fatalf("SendEmail() error = %v, want AmbiguousResultError", err)
+		}
+		body, _ := mailnow.MarshalEmailRequest(req)
+		if ambiguousErr.Fingerprint != mailnow.PayloadChecksum(body) {
+			t.Errorf("Fingerprint = %q, want the payload checksum", ambiguousErr.Fingerprint)
+		}
+		var connErr *mailnow.ConnectionError
+		if !errors.As(err, &connErr) || !connErr.RequestWritten {
+			t.Errorf("SendEmail() error = %v, want a written ConnectionError", err)
+		}
+		if n := atomic.LoadInt32(calls); n != 1 {
+			t.Errorf("expected 1 attempt, got %d", n)
+		}
+	})
+
+	t.Run("with idempotency key", func(t *testing.T) {
+		server, calls := newTruncatingServer(t)
+		client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+			mailnow.WithBaseURL(server.URL), policy, mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if _, err := client.SendEmail(context.Background(), newRetryRequest(), mailnow.WithIdempotencyKey("order-1042")); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+		if n := atomic.LoadInt32(calls); n != 2 {
+			t.Errorf("expected 2 attempts, got %d", n)
+		}
+	})
+
+	t.Run("unsafe retries", func(t *testing.T) {
+		server, calls := newTruncatingServer(t)
+		client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+			mailnow.WithBaseURL(server.URL), policy, mailnow.WithUnsafeRetries())
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+		if n := atomic.LoadInt32(calls); n != 2 {
+			t.Errorf("expected 2 attempts, got %d", n)
+		}
+	})
+}
+
+func TestRetryTimedOutAttempt(t *testing.T) {
+	policy := mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	tests := map[string]struct {
+		option   mailnow.Option
+		sendOpts []mailnow.SendOption
+	}{
+		"with idempotency key": {mailnow.WithIdempotencyStore(mailnow.NewMemoryIdempotencyStore()), []mailnow.SendOption{mailnow.WithIdempotencyKey("order-1042")}},
+		"unsafe retries":       {mailnow.WithUnsafeRetries(), nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The first attempt outlives the HTTP client timeout
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+				w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+			}))
+			defer server.Close()
+
+			client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+				mailnow.WithBaseURL(server.URL),
+				mailnow.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}),
+				policy, tt.option)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if _, err := client.SendEmail(context.Background(), newRetryRequest(), tt.sendOpts...); err != nil {
+				t.Fatalf("SendEmail() error = %v", err)
+			}
+			if n := atomic.LoadInt32(&calls); n != 2 {
+				t.Errorf("expected 2 attempts, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// Nothing was written, so the send is retried without a key
+	_, err = client.SendEmail(context.Background(), newRetryRequest())
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) || connErr.RequestWritten || connErr.Attempt != 3 {
+		t.Fatalf("SendEmail() error = %v, want an unwritten ConnectionError after 3 attempts", err)
+	}
+	var ambiguousErr *mailnow.AmbiguousResultError
+	if errors.As(err, &ambiguousErr) {
+		t.Errorf("SendEmail() error = %v, want no AmbiguousResultError", err)
+	}
+}
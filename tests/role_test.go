@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestIsRoleAccount(t *testing.T) {
+	tests := map[string]bool{
+		"postmaster@example.com":      true,
+		"Abuse@example.com":           true,
+		"noreply@example.com":         true,
+		"no-reply@example.com":        true,
+		"No_Reply@example.com":        true,
+		"no.reply@example.com":        true,
+		"do-not-reply@example.com":    true,
+		"support+tickets@example.com": true,
+		"INFO@example.com":            true,
+		"billing@example.com":         true,
+		"alice@example.com":           false,
+		"information@example.com":     false,
+		"salesforce@example.com":      false,
+		"noreply.bob@example.com":     false,
+	}
+	for email, want := range tests {
+		if got := mailnow.IsRoleAccount(email); got != want {
+			t.Errorf("IsRoleAccount(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
+
+func TestRoleAccountPolicy(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var warnings []mailnow.Warning
+	warnHandler := mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) })
+
+	// Allow is the default
+	client := newTestClient(t, server.URL, warnHandler)
+	if _, err := sendTo(client, "postmaster@example.com", "Hello"); err != nil {
+		t.Errorf("allow: SendEmail() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("allow: warnings = %v, want none", warnings)
+	}
+
+	// Warn sends and reports a warning
+	client = newTestClient(t, server.URL, warnHandler, mailnow.WithRoleAccountPolicy(mailnow.RoleAccountWarn))
+	if _, err := sendTo(client, "no-reply@example.com", "Hello"); err != nil {
+		t.Errorf("warn: SendEmail() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Code != "role_account" || !strings.Contains(warnings[0].Message, "no-reply@example.com") {
+		t.Errorf("warn: warnings = %v, want one role_account warning", warnings)
+	}
+
+	// Block fails without sending
+	client = newTestClient(t, server.URL, mailnow.WithRoleAccountPolicy(mailnow.RoleAccountBlock))
+	_, err := sendTo(client, "abuse@example.com", "Hello")
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "abuse@example.com") {
+		t.Errorf("block: SendEmail() error = %v, want ValidationError naming the address", err)
+	}
+	if _, err := sendTo(client, "alice@example.com", "Hello"); err != nil {
+		t.Errorf("block: SendEmail() to a person error = %v", err)
+	}
+
+	if n := len(server.Requests()); n != 3 {
+		t.Errorf("server received %d sends, want 3", n)
+	}
+}
+
+func TestRoleAccountCustomAdditions(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client := newTestClient(t, server.URL,
+		mailnow.WithRoleAccountPolicy(mailnow.RoleAccountBlock),
+		mailnow.WithRoleAccounts("team", "Careers"))
+
+	for _, to := range []string{"team@example.com", "careers@example.com", "postmaster@example.com"} {
+		if _, err := sendTo(client, to, "Hello"); err == nil {
+			t.Errorf("SendEmail() to %s succeeded, want it blocked", to)
+		}
+	}
+	if _, err := sendTo(client, "alice@example.com", "Hello"); err != nil {
+		t.Errorf("SendEmail() to a person error = %v", err)
+	}
+
+	// Additions are per client
+	if mailnow.IsRoleAccount("team@example.com") {
+		t.Error("IsRoleAccount() includes another client's additions")
+	}
+
+	for name, opt := range map[string]mailnow.Option{
+		"invalid policy": mailnow.WithRoleAccountPolicy(mailnow.RoleAccountPolicy(7)),
+		"empty account":  mailnow.WithRoleAccounts("-"),
+	} {
+		if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opt); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// sendTo sends a simple email to the given recipient
+func sendTo(client *mailnow.Client, to, subject string) (*mailnow.EmailResponse, error) {
+	return client.SendEmail(context.Background(), &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      to,
+		Subject: subject,
+		HTML:    "<p>Hello</p>",
+	})
+}
+
+func TestServerRulesByRecipient(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.RecipientDomain("bounce.test"), mailnowtest.Fail(400, "recipient_rejected", "mailbox does not exist"))
+	server.AddRule(mailnowtest.RecipientDomain("slow.test"), mailnowtest.Delay(50*time.Millisecond))
+	client := newTestClient(t, server.URL)
+
+	_, err := sendTo(client, "nobody@bounce.test", "Hello")
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError for bounce.test, got %v", err)
+	}
+	if code := mailnow.ErrorCode(err); code != "recipient_rejected" {
+		t.Errorf("expected code recipient_rejected, got %q", code)
+	}
+
+	start := time.Now()
+	if _, err := sendTo(client, "someone@slow.test", "Hello"); err != nil {
+		t.Fatalf("expected slow.test send to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected slow.test send to be delayed, took %v", elapsed)
+	}
+
+	if _, err := sendTo(client, "someone@example.com", "Hello"); err != nil {
+		t.Fatalf("expected unmatched send to succeed, got %v", err)
+	}
+
+	// Rejected sends are not recorded
+	if got := len(server.Requests()); got != 2 {
+		t.Errorf("expected 2 recorded requests, got %d", got)
+	}
+}
+
+func TestServerRulesFirstMatchWins(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	server.AddRule(mailnowtest.SubjectContains("[vip]"), mailnowtest.Respond(mailnow.Data{MessageID: "msg_vip", Status: "sent"}))
+	server.AddRule(mailnowtest.RecipientDomain("bounce.test"), mailnowtest.FailWith(mailnow.NewAuthError("blocked", nil)))
+	client := newTestClient(t, server.URL)
+
+	// Both rules match; the first one added wins
+	resp, err := sendTo(client, "nobody@bounce.test", "[vip] Hello")
+	if err != nil {
+		t.Fatalf("expected first rule to win, got %v", err)
+	}
+	if resp.Data.MessageID != "msg_vip" || resp.Data.Status != "sent" {
+		t.Errorf("unexpected response data %+v", resp.Data)
+	}
+
+	_, err = sendTo(client, "nobody@bounce.test", "Hello")
+	var authErr *mailnow.AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("expected AuthError from second rule, got %v", err)
+	}
+
+	server.ClearRules()
+	if _, err := sendTo(client, "nobody@bounce.test", "Hello"); err != nil {
+		t.Errorf("expected send to succeed after ClearRules, got %v", err)
+	}
+}
+
+func TestFailWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(error) bool
+	}{
+		{"rate limit", mailnow.NewRateLimitError("slow down", nil), func(err error) bool {
+			var e *mailnow.RateLimitError
+			return errors.As(err, &e)
+		}},
+		{"maintenance", mailnow.NewMaintenanceError("upgrading", 2*time.Second, nil), func(err error) bool {
+			var e *mailnow.MaintenanceError
+			return errors.As(err, &e) && e.RetryAfter == 2*time.Second
+		}},
+		{"server", mailnow.NewServerError("boom", nil), func(err error) bool {
+			var e *mailnow.ServerError
+			return errors.As(err, &e) && mailnow.ErrorStatusCode(err) == 500
+		}},
+		{"connection", mailnow.NewConnectionError("reset", nil), func(err error) bool {
+			var e *mailnow.ConnectionError
+			return errors.As(err, &e)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mailnowtest.NewServer()
+			defer server.Close()
+			server.AddRule(mailnowtest.RecipientDomain("example.com"), mailnowtest.FailWith(tt.err))
+
+			_, err := sendTo(newTestClient(t, server.URL), "recipient@example.com", "Hello")
+			if !tt.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestServerRulesConcurrent(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			server.AddRule(mailnowtest.RecipientDomain(fmt.Sprintf("d%d.test", i)), mailnowtest.Fail(400, "recipient_rejected", "rejected"))
+			if _, err := sendTo(client, fmt.Sprintf("user@d%d.test", i), "Hello"); err == nil {
+				t.Errorf("expected send to d%d.test to fail", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"context"
+	stdhtml "html"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// newsletterHTML is typical newsletter markup that sanitizing must keep
+const newsletterHTML = `<!DOCTYPE html>
+<html><head><title>Spring news</title>
+<style type="text/css">
+  body { margin: 0; } .cta { background-color: #0a66c2; color: #ffffff; }
+  @media (max-width: 600px) { .col { width: 100% !important; } }
+</style></head>
+<body style="margin:0; padding:0; font-family: Arial, sans-serif;">
+<!--[if mso]><table role="presentation" width="600"><tr><td><![endif]-->
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" bgcolor="#f4f4f4">
+  <tr><td align="center" style="padding: 20px 0;">
+    <img src="https://cdn.example.com/logo.png" alt="Example &amp; Co" width="120" height="40" style="display:block; border:0;">
+  </td></tr>
+  <tr><td class="col" style="background: url('https://cdn.example.com/bg.jpg') no-repeat; padding: 24px;">
+    <h1 style="font-size:24px;">What's new this spring</h1>
+    <p>Prices from 5 &lt; 10 euros, and a <a href="https://example.com/sale?utm_source=news&amp;id=1" class="cta" target="_blank">sale</a>.</p>
+    <p><a href="mailto:help@example.com">Contact us</a> or <a href="#top">back to top</a> or <a href="/account">your account</a>.</p>
+    <img src="cid:chart-1" alt=""/>
+  </td></tr>
+</table>
+<!--[if mso]></td></tr></table><![endif]-->
+<p style="font-size:12px; color:#999999;"><a href="https://example.com/unsubscribe">Unsubscribe</a></p>
+</body></html>`
+
+var (
+	// fuzzTagRegex matches a start tag and captures its name and attributes
+	fuzzTagRegex = regexp.MustCompile(`<([a-zA-Z][^\s/>]*)((?:[^<>"']|"[^"]*"|'[^']*')*)>`)
+
+	// fuzzAttrRegex matches an attribute and its optional value
+	fuzzAttrRegex = regexp.MustCompile(`([^\s"'<>/=]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s>"']+))?`)
+
+	// unsafeTags are the elements that must never survive sanitizing
+	unsafeTags = regexp.MustCompile(`(?i)^(script|iframe|object|embed|applet|frame|base|link|meta|svg|math)$`)
+
+	// unsafeURL matches URLs with a scheme DefaultSanitizePolicy forbids
+	unsafeURL = regexp.MustCompile(`(?i)^(javascript|vbscript|data):`)
+)
+
+// unsafeMarkup returns the first tag or attribute in html that
+// DefaultSanitizePolicy should have removed
+func unsafeMarkup(html string) string {
+	for _, tag := range fuzzTagRegex.FindAllStringSubmatch(html, -1) {
+		if unsafeTags.MatchString(tag[1]) {
+			return tag[0]
+		}
+		for _, attr := range fuzzAttrRegex.FindAllStringSubmatch(tag[2], -1) {
+			name, value := strings.ToLower(attr[1]), strings.Trim(attr[2], `"'`)
+			value = strings.Join(strings.Fields(stdhtml.UnescapeString(value)), "")
+			switch {
+			case strings.HasPrefix(name, "on"),
+				(name == "href" || name == "src" || name == "action") && unsafeURL.MatchString(value),
+				name == "style" && strings.Contains(strings.ToLower(value), "expression("):
+				return attr[0]
+			}
+		}
+	}
+	return ""
+}
+
+func sanitize(t *testing.T, html string) string {
+	t.Helper()
+	out, err := mailnow.SanitizeHTML(html, mailnow.DefaultSanitizePolicy)
+	if err != nil {
+		t.Fatalf("SanitizeHTML() error = %v", err)
+	}
+	return out
+}
+
+func TestSanitizeHTMLKeepsNewsletter(t *testing.T) {
+	if got := sanitize(t, newsletterHTML); got != newsletterHTML {
+		t.Errorf("SanitizeHTML() changed benign HTML:\n%s", got)
+	}
+}
+
+func TestSanitizeHTMLInjections(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"script", `<p>Hi<script>alert(1)</script> there</p>`, `<p>Hi there</p>`},
+		{"script uppercase", `<SCRIPT type="text/javascript">alert(1)</SCRIPT >ok`, `ok`},
+		{"script with tag in string", `<script>var s = "</p><p>";</script><p>ok</p>`, `<p>ok</p>`},
+		{"iframe", `<iframe src="https://evil.example"><p>fallback</p></iframe>ok`, `ok`},
+		{"object and embed", `<object data="x.swf"><embed src="x.swf"></object>ok`, `ok`},
+		{"event handler", `<img src="https://cdn.example.com/a.png" onerror="alert(1)" alt="a">`, `<img src="https://cdn.example.com/a.png" alt="a">`},
+		{"event handler unquoted", `<body onload=alert(1)>`, `<body>`},
+		{"javascript href", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"encoded javascript href", `<a href="&#106;ava&#x73;cript&colon;alert(1)" title="t">x</a>`, `<a title="t">x</a>`},
+		{"javascript with whitespace", "<a href=\" java\tscript:alert(1)\">x</a>", `<a>x</a>`},
+		{"data URL", `<a href="data:text/html,<script>alert(1)</script>">x</a>`, `<a>x</a>`},
+		{"vbscript src", `<img src='vbscript:msgbox(1)' />`, `<img />`},
+		{"css expression", `<div style="color: red; width: expression(alert(1))">x</div>`, `<div style="color: red">x</div>`},
+		{"escaped css expression", `<div style="width: e\78 pression(alert(1))">x</div>`, `<div>x</div>`},
+		{"css in style element", `<style>p { background: url(javascript:alert(1)) }</style><p>x</p>`, `<style></style><p>x</p>`},
+		{"svg", `<svg><a xlink:href="javascript:alert(1)"><text>x</text></a></svg>ok`, `ok`},
+		{"base", `<base href="https://evil.example/">ok`, `ok`},
+		{"link", `<link rel="stylesheet" href="https://evil.example/track.css">ok`, `ok`},
+		{"meta refresh", `<meta http-equiv="refresh" content="0; url=https://evil.example/">ok`, `ok`},
+		{"comment", `<!-- <script>alert(1)</script> -->ok`, `ok`},
+		{"conditional comment", `<!--[if mso]><script>alert(1)</script><p>x</p><![endif]-->`, `<!--[if mso]><p>x</p><![endif]-->`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize(t, tt.html); got != tt.want {
+				t.Errorf("SanitizeHTML(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeHTMLMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"split script tag", `<scr<script>x</script>ipt>alert(1)</script>`, `&lt;script>alert(1)`},
+		{"unterminated script", `<p>a</p><script>alert(1)`, `<p>a</p>`},
+		{"unterminated tag", `<p>a</p><img src=x onerror=alert(1)`, `<p>a</p>&lt;img src=x onerror=alert(1)`},
+		{"nested iframes", `<iframe><iframe></iframe><script>alert(1)</script></iframe>ok`, `ok`},
+		{"stray end tags", `</script></iframe><p>ok</p>`, `<p>ok</p>`},
+		{"unclosed comment", `ok<!-- <script>`, `ok`},
+		{"less than in text", `1 < 2 and <3`, `1 &lt; 2 and &lt;3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitize(t, tt.html)
+			if got != tt.want {
+				t.Errorf("SanitizeHTML(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizePolicy(t *testing.T) {
+	policy := mailnow.SanitizePolicy{
+		RemoveElements:   []string{"form"},
+		RemoveAttributes: []string{"class"},
+		URLSchemes:       []string{"https"},
+	}
+	got, err := mailnow.SanitizeHTML(`<form><input></form><a class="x" href="http://example.com">a</a><script></script>`, policy)
+	if err != nil {
+		t.Fatalf("SanitizeHTML() error = %v", err)
+	}
+	if want := `<a>a</a><script></script>`; got != want {
+		t.Errorf("SanitizeHTML() = %q, want %q", got, want)
+	}
+
+	if _, err := mailnow.SanitizeHTML("", mailnow.SanitizePolicy{RemoveElements: []string{"bad name"}}); err == nil {
+		t.Error("expected an error for an invalid element name")
+	}
+}
+
+func TestWithHTMLSanitization(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithHTMLSanitization(mailnow.DefaultSanitizePolicy))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "forum@example.com",
+		To:      "member@example.net",
+		Subject: "New reply to your post",
+		HTML:    `<p>Reply:</p><p onclick="steal()">Nice post<script>steal()</script></p>`,
+	}
+	original := req.HTML
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if got, want := server.Requests()[0].HTML, `<p>Reply:</p><p>Nice post</p>`; got != want {
+		t.Errorf("sent HTML = %q, want %q", got, want)
+	}
+	if req.HTML != original {
+		t.Error("SendEmail modified the caller's request")
+	}
+}
+
+func FuzzSanitizeHTML(f *testing.F) {
+	for _, seed := range []string{
+		newsletterHTML,
+		`<scr<script>ipt>alert(1)</script>`,
+		`<a href="jav&#x09;ascript:alert(1)">x</a>`,
+		`<img src=x onerror=alert(1)//>`,
+		`<div style="x:expr/**/ession(alert(1))">`,
+		`<!--[if mso]><iframe><![endif]-->`,
+		`<<script>script>alert(1)<</script>/script>`,
+		`<svg/onload=alert(1)>`,
+		`<p title="a>b" onclick='x'>`,
+		`<STYLE>@import 'x';</STYLE>`,
+		`<Aa<sCript!0>0`,
+		`<A 0= onA=>`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, html string) {
+		out, err := mailnow.SanitizeHTML(html, mailnow.DefaultSanitizePolicy)
+		if err != nil {
+			t.Fatalf("SanitizeHTML() error = %v", err)
+		}
+		if m := unsafeMarkup(out); m != "" {
+			t.Fatalf("SanitizeHTML(%q) = %q, still contains %q", html, out, m)
+		}
+		// Sanitizing is idempotent
+		if again, _ := mailnow.SanitizeHTML(out, mailnow.DefaultSanitizePolicy); again != out {
+			t.Fatalf("SanitizeHTML is not idempotent for %q: %q then %q", html, out, again)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestRescheduleEmail(t *testing.T) {
+	newTime := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("expected PATCH method, got %s", r.Method)
+		}
+
+		var body struct {
+			ScheduledAt time.Time `json:"scheduled_at"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+
+		switch r.URL.Path {
+		case "/v1/email/msg_scheduled":
+			if !body.ScheduledAt.Equal(newTime) {
+				t.Errorf("expected scheduled_at %v, got %v", newTime, body.ScheduledAt)
+			}
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":     true,
+				"status_code": 200,
+				"data": map[string]interface{}{
+					"message_id":   "msg_scheduled",
+					"status":       "scheduled",
+					"scheduled_at": body.ScheduledAt,
+				},
+			})
+		case "/v1/email/msg_sent":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error": {"code": "already_sent", "message": "email has already been sent"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "not_found", "message": "email not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := client.RescheduleEmail(ctx, "msg_scheduled", newTime)
+		if err != nil {
+			t.Fatalf("RescheduleEmail() unexpected error: %v", err)
+		}
+		if resp.Data.MessageID != "msg_scheduled" {
+			t.Errorf("expected message ID msg_scheduled, got %s", resp.Data.MessageID)
+		}
+		if resp.Data.ScheduledAt == nil || !resp.Data.ScheduledAt.Equal(newTime) {
+			t.Errorf("expected updated schedule %v, got %v", newTime, resp.Data.ScheduledAt)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := client.RescheduleEmail(ctx, "msg_missing", newTime)
+		var serverErr *mailnow.ServerError
+		if !errors.As(err, &serverErr) {
+			t.Errorf("expected ServerError for 404, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		_, err := client.RescheduleEmail(ctx, "msg_sent", newTime)
+		var sentErr *mailnow.AlreadySentError
+		if !errors.As(err, &sentErr) {
+			t.Fatalf("expected AlreadySentError for 409, got %T: %v", err, err)
+		}
+		if sentErr.MessageID != "msg_sent" {
+			t.Errorf("expected message ID msg_sent, got %s", sentErr.MessageID)
+		}
+	})
+}
+
+func TestRescheduleEmailValidation(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		messageID string
+		newTime   time.Time
+	}{
+		{name: "past time", messageID: "msg_1", newTime: time.Now().Add(-time.Minute)},
+		{name: "zero time", messageID: "msg_1", newTime: time.Time{}},
+		{name: "beyond horizon", messageID: "msg_1", newTime: time.Now().Add(mailnow.MaxScheduleHorizon + time.Hour)},
+		{name: "empty message ID", messageID: "", newTime: time.Now().Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.RescheduleEmail(context.Background(), tt.messageID, tt.newTime)
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("expected ValidationError, got %T: %v", err, err)
+			}
+		})
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Errorf("expected no HTTP calls for invalid input, got %d", n)
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestSendTraceFailThenSucceed(t *testing.T) {
+	server, _ := newSequenceServer(t, http.StatusTooManyRequests)
+
+	var traces []mailnow.SendTrace
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond}),
+		mailnow.WithSendTrace(func(trace mailnow.SendTrace) { traces = append(traces, trace) }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if len(traces) != 1 {
+		t.Fatalf("got %d traces, want 1", len(traces))
+	}
+
+	want := []struct {
+		kind   mailnow.SendStepKind
+		detail string
+	}{
+		{mailnow.StepValidated, ""},
+		{mailnow.StepMarshaled, ""},
+		{mailnow.StepAttempt, "1"},
+		{mailnow.StepResponse, "status 429"},
+		{mailnow.StepBackoff, ""},
+		{mailnow.StepAttempt, "2"},
+		{mailnow.StepResponse, "status 200"},
+		{mailnow.StepCompleted, "msg_123"},
+	}
+	trace := traces[0]
+	if len(trace) != len(want) {
+		t.Fatalf("trace has %d steps, want %d: %+v", len(trace), len(want), trace)
+	}
+	for i, step := range trace {
+		if step.Kind != want[i].kind {
+			t.Errorf("step %d kind = %q, want %q", i, step.Kind, want[i].kind)
+		}
+		if want[i].detail != "" && step.Detail != want[i].detail {
+			t.Errorf("step %d detail = %q, want %q", i, step.Detail, want[i].detail)
+		}
+		if i > 0 && step.At.Before(trace[i-1].At) {
+			t.Errorf("step %d is earlier than step %d", i, i-1)
+		}
+	}
+	if !strings.HasSuffix(trace[1].Detail, " bytes") {
+		t.Errorf("marshaled detail = %q, want a byte count", trace[1].Detail)
+	}
+	if trace[4].Duration <= 0 {
+		t.Errorf("backoff duration = %v, want > 0", trace[4].Duration)
+	}
+}
+
+func TestSendTraceFailure(t *testing.T) {
+	server, _ := newSequenceServer(t, http.StatusBadRequest)
+
+	var trace mailnow.SendTrace
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL),
+		mailnow.WithSendTrace(func(tr mailnow.SendTrace) { trace = tr }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err == nil {
+		t.Fatal("SendEmail() expected error")
+	}
+	if len(trace) == 0 {
+		t.Fatal("expected a trace")
+	}
+	last := trace[len(trace)-1]
+	if last.Kind != mailnow.StepFailed || !strings.Contains(last.Detail, "attempt 1 failed") {
+		t.Errorf("last step = %+v, want a failure with the API message", last)
+	}
+}
+
+func TestSendTraceValidationFailure(t *testing.T) {
+	var trace mailnow.SendTrace
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithSendTrace(func(tr mailnow.SendTrace) { trace = tr }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), &mailnow.EmailRequest{}); err == nil {
+		t.Fatal("SendEmail() expected error")
+	}
+	if len(trace) != 1 || trace[0].Kind != mailnow.StepFailed {
+		t.Errorf("trace = %+v, want a single failed step", trace)
+	}
+}
+
+func TestSendTracePanicIsRecovered(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithBaseURL(server.URL),
+		mailnow.WithSendTrace(func(mailnow.SendTrace) { panic("boom") }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newRetryRequest()); err != nil {
+		t.Errorf("SendEmail() error = %v, want the send result unchanged", err)
+	}
+}
+
+func TestWithSendTraceNil(t *testing.T) {
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithSendTrace(nil)); err == nil {
+		t.Error("WithSendTrace(nil) expected error")
+	}
+}
+
+func benchmarkSendEmail(b *testing.B, opts ...mailnow.Option) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	opts = append([]mailnow.Option{mailnow.WithBaseURL(server.URL)}, opts...)
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(), opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	req := newRetryRequest()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.SendEmail(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSendEmailUntraced(b *testing.B) {
+	benchmarkSendEmail(b)
+}
+
+func BenchmarkSendEmailTraced(b *testing.B) {
+	benchmarkSendEmail(b, mailnow.WithSendTrace(func(mailnow.SendTrace) {}))
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// identityServer serves sender identities and accepts every send
+type identityServer struct {
+	*httptest.Server
+	lists     atomic.Int32
+	sends     atomic.Int32
+	listFails atomic.Bool
+}
+
+func newIdentityServer(identities []mailnow.SenderIdentity) *identityServer {
+	s := &identityServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case mailnow.SenderIdentitiesEndpoint:
+			s.lists.Add(1)
+			if s.listFails.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error": {"code": "server_error", "message": "unavailable"}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "status_code": 200, "data": identities})
+		case mailnow.EmailSendEndpoint:
+			s.sends.Add(1)
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+// newSenderClient creates a client for server with the verified sender check
+var testIdentities = []mailnow.SenderIdentity{
+	{ID: "si_1", Email: "alerts@example.com", Domain: "example.com", Verified: true},
+	{ID: "si_2", Domain: "mail.example.org", Verified: true},
+	{ID: "si_3", Email: "pending@example.net", Domain: "example.net", Verified: false},
+}
+
+func TestVerifiedSenderCheck(t *testing.T) {
+	server := newIdentityServer(testIdentities)
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithVerifiedSenderCheck(time.Hour))
+
+	for _, from := range []string{"alerts@example.com", "Alerts@Example.com", "anyone@mail.example.org"} {
+		if err := sendFrom(client, from); err != nil {
+			t.Errorf("expected send from %s to succeed, got %v", from, err)
+		}
+	}
+
+	for _, from := range []string{"other@example.com", "pending@example.net"} {
+		err := sendFrom(client, from)
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError for %s, got %v", from, err)
+		}
+		if !strings.Contains(err.Error(), "verified senders: alerts@example.com, mail.example.org") {
+			t.Errorf("expected verified options to be listed, got %q", err.Error())
+		}
+	}
+
+	if server.lists.Load() != 1 {
+		t.Errorf("expected identities to be fetched once, got %d", server.lists.Load())
+	}
+	if server.sends.Load() != 3 {
+		t.Errorf("expected 3 sends to reach the API, got %d", server.sends.Load())
+	}
+}
+
+func TestVerifiedSenderCheckTTL(t *testing.T) {
+	server := newIdentityServer(testIdentities)
+	defer server.Close()
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithVerifiedSenderCheck(time.Hour), mailnow.WithClock(clock.Now))
+
+	sendFrom(client, "alerts@example.com")
+	clock.Advance(59 * time.Minute)
+	sendFrom(client, "alerts@example.com")
+	if server.lists.Load() != 1 {
+		t.Fatalf("expected cached identities within the TTL, got %d fetches", server.lists.Load())
+	}
+
+	clock.Advance(time.Minute)
+	sendFrom(client, "alerts@example.com")
+	if server.lists.Load() != 2 {
+		t.Errorf("expected a refetch after the TTL, got %d fetches", server.lists.Load())
+	}
+
+	if err := client.RefreshVerifiedSenders(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if server.lists.Load() != 3 {
+		t.Errorf("expected RefreshVerifiedSenders to fetch, got %d fetches", server.lists.Load())
+	}
+}
+
+func TestVerifiedSenderCheckFailOpen(t *testing.T) {
+	server := newIdentityServer(testIdentities)
+	defer server.Close()
+	server.listFails.Store(true)
+
+	var warnings []mailnow.Warning
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithVerifiedSenderCheck(time.Hour),
+		mailnow.WithClock(clock.Now),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+
+	if err := sendFrom(client, "unknown@example.com"); err != nil {
+		t.Fatalf("expected send to be allowed when identities are unavailable, got %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Code != "verified_senders_unavailable" {
+		t.Errorf("expected a verified_senders_unavailable warning, got %+v", warnings)
+	}
+
+	// The failure is remembered for a while rather than refetched per send
+	server.listFails.Store(false)
+	if err := sendFrom(client, "unknown@example.com"); err != nil || server.lists.Load() != 1 {
+		t.Errorf("send after a recent failure = %v with %d fetches, want allowed without a fetch", err, server.lists.Load())
+	}
+
+	// Once the API recovers the check applies again
+	clock.Advance(30 * time.Second)
+	if err := sendFrom(client, "unknown@example.com"); err == nil {
+		t.Error("expected unverified sender to be rejected after recovery")
+	}
+}
+
+func TestVerifiedSenderCheckSingleFetch(t *testing.T) {
+	server := newIdentityServer(testIdentities)
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithVerifiedSenderCheck(time.Hour))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sendFrom(client, "alerts@example.com"); err != nil {
+				t.Errorf("SendEmail() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := server.lists.Load(); n != 1 {
+		t.Errorf("concurrent sends fetched the identities %d times, want once", n)
+	}
+}
+
+func TestVerifiedSenderCheckWithFallbacks(t *testing.T) {
+	server := newIdentityServer(testIdentities)
+	defer server.Close()
+	client := newTestClient(t, server.URL, mailnow.WithVerifiedSenderCheck(time.Hour), mailnow.WithFromFallbacks("alerts@example.com"))
+
+	// The unverified sender is left to the API, which accepts it here
+	if err := sendFrom(client, "unknown@example.org"); err != nil {
+		t.Errorf("SendEmail() with fallbacks error = %v, want the send passed to the API", err)
+	}
+	if server.sends.Load() != 1 {
+		t.Errorf("server received %d sends, want 1", server.sends.Load())
+	}
+}
+
+func TestRefreshVerifiedSendersDisabled(t *testing.T) {
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.RefreshVerifiedSenders(context.Background()); err == nil {
+		t.Error("expected error when the check is not enabled")
+	}
+}
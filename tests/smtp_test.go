@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+const googleDeferral = "421-4.7.0 [203.0.113.7      15] Our system has detected an unusual rate of\n" +
+	"421-4.7.0 unsolicited mail originating from your IP address. To protect our\n" +
+	"421 4.7.0 users from spam, mail sent from your IP address has been temporarily rate limited. - gsmtp"
+
+func TestParseSMTPResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		code      int
+		status    string
+		message   string
+		category  mailnow.SMTPCategory
+		temporary bool
+	}{
+		{
+			name:      "google deferral",
+			response:  googleDeferral,
+			code:      421,
+			status:    "4.7.0",
+			message:   "[203.0.113.7      15] Our system has detected an unusual rate of unsolicited mail originating from your IP address. To protect our users from spam, mail sent from your IP address has been temporarily rate limited. - gsmtp",
+			category:  mailnow.SMTPCategoryRateLimited,
+			temporary: true,
+		},
+		{
+			name:     "policy block",
+			response: "550 5.7.1 Message rejected due to local policy",
+			code:     550,
+			status:   "5.7.1",
+			message:  "Message rejected due to local policy",
+			category: mailnow.SMTPCategorySpamBlock,
+		},
+		{
+			name:      "greylisting",
+			response:  "451 4.7.1 Greylisted, please try again in 300 seconds",
+			code:      451,
+			status:    "4.7.1",
+			message:   "Greylisted, please try again in 300 seconds",
+			category:  mailnow.SMTPCategoryGreylisting,
+			temporary: true,
+		},
+		{
+			name:     "mailbox full",
+			response: "552 5.2.2 The email account that you tried to reach is over quota",
+			code:     552,
+			status:   "5.2.2",
+			message:  "The email account that you tried to reach is over quota",
+			category: mailnow.SMTPCategoryMailboxFull,
+		},
+		{
+			name:     "no enhanced status",
+			response: "554 Transaction failed",
+			code:     554,
+			message:  "Transaction failed",
+			category: mailnow.SMTPCategoryUnknown,
+		},
+		{
+			name:     "malformed",
+			response: "connection reset by peer\n5.7.1??",
+			message:  "connection reset by peer\n5.7.1??",
+			category: mailnow.SMTPCategoryUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := mailnow.ParseSMTPResponse(tt.response)
+			if resp.Code != tt.code || resp.EnhancedStatus.String() != tt.status || resp.Message != tt.message {
+				t.Errorf("ParseSMTPResponse() = %d %q %q, want %d %q %q", resp.Code, resp.EnhancedStatus, resp.Message, tt.code, tt.status, tt.message)
+			}
+			if got := resp.Category(); got != tt.category {
+				t.Errorf("Category() = %q, want %q", got, tt.category)
+			}
+			if resp.Temporary() != tt.temporary {
+				t.Errorf("Temporary() = %v, want %v", resp.Temporary(), tt.temporary)
+			}
+			var raw string
+			if err := json.Unmarshal(resp.Raw, &raw); err != nil || raw != tt.response {
+				t.Errorf("Raw = %s, want the original response", resp.Raw)
+			}
+		})
+	}
+}
+
+func TestParseEnhancedStatus(t *testing.T) {
+	status, ok := mailnow.ParseEnhancedStatus("5.7.1")
+	if !ok || status != (mailnow.EnhancedStatus{Class: 5, Subject: 7, Detail: 1}) {
+		t.Errorf("ParseEnhancedStatus(5.7.1) = %+v, %v", status, ok)
+	}
+	for _, s := range []string{"", "3.1.1", "5.7", "5.x.1", "550"} {
+		if _, ok := mailnow.ParseEnhancedStatus(s); ok {
+			t.Errorf("ParseEnhancedStatus(%q) succeeded", s)
+		}
+	}
+}
+
+func TestSMTPResponseUnmarshal(t *testing.T) {
+	var event struct {
+		Response mailnow.SMTPResponse `json:"smtp_response"`
+	}
+
+	data := `{"smtp_response": {"code": 550, "enhanced_status": "5.7.1", "message": "Blocked by RBL", "remote_mx": "mx1.example.net", "tls": true, "hint": "x"}}`
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	resp := event.Response
+	if resp.Code != 550 || resp.EnhancedStatus.String() != "5.7.1" || resp.RemoteMX != "mx1.example.net" || !resp.TLS {
+		t.Errorf("object response = %+v", resp)
+	}
+	if resp.Category() != mailnow.SMTPCategorySpamBlock || !resp.Permanent() {
+		t.Errorf("Category() = %q, Permanent() = %v", resp.Category(), resp.Permanent())
+	}
+
+	if err := json.Unmarshal([]byte(`{"smtp_response": "550 5.1.1 User unknown"}`), &event); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if event.Response.Code != 550 || event.Response.Category() != mailnow.SMTPCategoryMailboxUnknown {
+		t.Errorf("string response = %+v", event.Response)
+	}
+
+	// Other shapes are kept in Raw
+	if err := json.Unmarshal([]byte(`{"smtp_response": [421, "try later"]}`), &event); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if event.Response.Code != 0 || string(event.Response.Raw) != `[421, "try later"]` {
+		t.Errorf("unknown response = %+v, Raw = %s", event.Response, event.Response.Raw)
+	}
+}
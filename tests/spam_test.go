@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+// spamCodes returns the codes of the signals in report
+func spamCodes(report mailnow.SpamReport) []string {
+	codes := make([]string, len(report.Signals))
+	for i, s := range report.Signals {
+		codes[i] = s.Code
+	}
+	return codes
+}
+
+func newSpammyRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:         "deals@example.com",
+		To:           "customer@example.net",
+		Subject:      "ACT NOW!!! YOU ARE A WINNER",
+		HTML:         `<img src="https://cdn.example.com/banner.png"><p>Click here!!!! <a href="https://bit.ly/x">Guaranteed</a> cash!</p>`,
+		Text:         "Visit our store for the spring sale",
+		MessageClass: mailnow.MessageClassMarketing,
+	}
+}
+
+func newCleanRequest() *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:         "billing@example.com",
+		To:           "customer@example.net",
+		Subject:      "Your receipt for order 1042",
+		HTML:         `<p>Thanks for your order. Your receipt is below.</p><p><a href="https://example.com/orders/1042">View order 1042</a></p>`,
+		Text:         "Thanks for your order. Your receipt is below. View order 1042: https://example.com/orders/1042",
+		MessageClass: mailnow.MessageClassTransactional,
+	}
+}
+
+func TestScoreSpamSignals(t *testing.T) {
+	spammy := mailnow.ScoreSpamSignals(newSpammyRequest())
+	if spammy.Score < 8 {
+		t.Errorf("spammy Score = %v, want at least 8 (signals %v)", spammy.Score, spamCodes(spammy))
+	}
+	want := []string{"subject_caps", "exclamation_marks", "trigger_phrase", "trigger_phrase", "trigger_phrase", "image_heavy", "shortened_url", "missing_unsubscribe", "text_html_divergence"}
+	if got := spamCodes(spammy); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("spammy signals = %v, want %v", got, want)
+	}
+	for _, s := range spammy.Signals {
+		if s.Message == "" || s.Score <= 0 {
+			t.Errorf("signal %s has Score %v and Message %q", s.Code, s.Score, s.Message)
+		}
+	}
+
+	clean := mailnow.ScoreSpamSignals(newCleanRequest())
+	if clean.Score != 0 || len(clean.Signals) != 0 {
+		t.Errorf("clean Score = %v, signals %v, want none", clean.Score, spamCodes(clean))
+	}
+}
+
+func TestSpamSignals(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *mailnow.EmailRequest)
+		code   string
+	}{
+		{"caps subject", func(r *mailnow.EmailRequest) { r.Subject = "FINAL NOTICE ON YOUR ORDER" }, "subject_caps"},
+		{"exclamation marks", func(r *mailnow.EmailRequest) { r.Subject = "Your receipt!!" }, "exclamation_marks"},
+		{"trigger phrase", func(r *mailnow.EmailRequest) { r.Subject = "A limited time offer inside" }, "trigger_phrase"},
+		{"image only", func(r *mailnow.EmailRequest) { r.HTML = `<img src="https://cdn.example.com/a.png">`; r.Text = "" }, "image_heavy"},
+		{"many links", func(r *mailnow.EmailRequest) {
+			r.HTML = strings.Repeat(`<a href="https://example.com/p">order</a> `, mailnow.SpamMaxLinks+1)
+			r.Text = strings.Repeat("order ", mailnow.SpamMaxLinks+1)
+		}, "many_links"},
+		{"shortened URL", func(r *mailnow.EmailRequest) {
+			r.HTML = strings.Replace(r.HTML, "https://example.com/orders/1042", "https://www.tinyurl.com/abc", 1)
+		}, "shortened_url"},
+		{"marketing without unsubscribe", func(r *mailnow.EmailRequest) { r.MessageClass = mailnow.MessageClassMarketing }, "missing_unsubscribe"},
+		{"divergent text", func(r *mailnow.EmailRequest) { r.Text = "Something else entirely" }, "text_html_divergence"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newCleanRequest()
+			tt.modify(req)
+			report := mailnow.ScoreSpamSignals(req)
+			if got := spamCodes(report); len(got) != 1 || got[0] != tt.code {
+				t.Errorf("signals = %v, want only %s", got, tt.code)
+			}
+		})
+	}
+
+	// A marketing email with a List-Unsubscribe header passes
+	req := newCleanRequest()
+	req.MessageClass = mailnow.MessageClassMarketing
+	req.Headers = map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"}
+	if report := mailnow.ScoreSpamSignals(req); len(report.Signals) != 0 {
+		t.Errorf("signals with List-Unsubscribe = %v, want none", spamCodes(report))
+	}
+
+	// The phrase list is configurable
+	scorer := mailnow.SpamScorer{Phrases: []string{"receipt"}}
+	if report := scorer.Score(newCleanRequest()); len(report.Signals) != 1 || report.Signals[0].Code != "trigger_phrase" {
+		t.Errorf("custom phrase signals = %v, want trigger_phrase", spamCodes(report))
+	}
+}
+
+func TestSpamScoreThreshold(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	var warnings []mailnow.Warning
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithSpamScoreThreshold(1),
+		mailnow.WithSpamScoreBlockThreshold(5),
+		mailnow.WithWarningHandler(func(w mailnow.Warning) { warnings = append(warnings, w) }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.SendEmail(context.Background(), newCleanRequest()); err != nil || len(warnings) != 0 {
+		t.Fatalf("clean SendEmail() error = %v, warnings %v", err, warnings)
+	}
+
+	req := newCleanRequest()
+	req.Subject = "A guaranteed receipt"
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Code != "spam_score" || !strings.Contains(warnings[0].Message, "trigger_phrase") {
+		t.Errorf("warnings = %v, want spam_score", warnings)
+	}
+
+	_, err = client.SendEmail(context.Background(), newSpammyRequest())
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("spammy SendEmail() error = %v, want ValidationError", err)
+	}
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("server received %d sends, want 2", n)
+	}
+
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithSpamScoreThreshold(0)); err == nil {
+		t.Error("expected NewClient to reject a zero threshold")
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func newStablePayloadRequest() *mailnow.EmailRequest {
+	req := newRetryRequest()
+	req.Headers = map[string]string{
+		"X-Campaign": "spring",
+		"X-Tenant":   "acme",
+		"X-Priority": "1",
+		"X-Trace":    "abc",
+		"X-Region":   "eu",
+		"X-Source":   "billing",
+	}
+	return req
+}
+
+func TestMarshalEmailRequestDeterministic(t *testing.T) {
+	req := newStablePayloadRequest()
+
+	want, err := mailnow.MarshalEmailRequest(req)
+	if err != nil {
+		t.Fatalf("MarshalEmailRequest() unexpected error: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		got, err := mailnow.MarshalEmailRequest(req)
+		if err != nil {
+			t.Fatalf("MarshalEmailRequest() unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("marshal %d produced different bytes:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
+
+	// Header keys are sorted
+	if !bytes.Contains(want, []byte(`"headers":{"X-Campaign":"spring","X-Priority":"1","X-Region":"eu","X-Source":"billing","X-Tenant":"acme","X-Trace":"abc"}`)) {
+		t.Errorf("expected sorted headers, got %s", want)
+	}
+}
+
+func TestMarshalEmailRequestNil(t *testing.T) {
+	if _, err := mailnow.MarshalEmailRequest(nil); err == nil {
+		t.Error("expected error for nil request")
+	}
+}
+
+func TestRetriesSendIdenticalBodies(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		n := len(bodies)
+		mu.Unlock()
+
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "transient", "message": "try again"}}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := newStablePayloadRequest()
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(bodies))
+	}
+	want, _ := mailnow.MarshalEmailRequest(req)
+	for i, body := range bodies {
+		if !bytes.Equal(body, want) {
+			t.Errorf("attempt %d body differs:\n%s\nwant:\n%s", i+1, body, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// newStatsServer starts a server that advances clock by the milliseconds
+// in the recipient's local part, such as ok-20@example.com, and rejects
+// sends to fail-N addresses. A flaky-N address fails its first attempt.
+func newStatsServer(t *testing.T, clock *fakeClock) *httptest.Server {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		local, _, _ := strings.Cut(req.To, "@")
+		kind, ms, _ := strings.Cut(local, "-")
+		n, _ := strconv.Atoi(ms)
+		clock.Advance(time.Duration(n) * time.Millisecond)
+
+		mu.Lock()
+		attempts[req.To]++
+		attempt := attempts[req.To]
+		mu.Unlock()
+
+		switch {
+		case kind == "fail":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "recipient_rejected", "message": "mailbox does not exist"}}`))
+		case kind == "flaky" && attempt == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": "unavailable", "message": "try again"}}`))
+		default:
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSendStats(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, newStatsServer(t, clock).URL,
+		mailnow.WithClock(clock.Now),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+
+	if rate, p50 := client.ErrorRate(5*time.Minute), client.LatencyPercentile(5*time.Minute, 0.5); rate != 0 || p50 != 0 {
+		t.Errorf("before any send ErrorRate() = %v, LatencyPercentile() = %v, want 0", rate, p50)
+	}
+
+	for i := 1; i <= 10; i++ {
+		kind := "ok"
+		if i == 3 || i == 7 {
+			kind = "fail"
+		}
+		sendTo(client, kind+"-"+strconv.Itoa(i*10)+"@example.com", "Hello")
+	}
+	// A retried send counts once, with the latency of both attempts
+	if _, err := sendTo(client, "flaky-5@example.com", "Hello"); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	// Sends rejected before reaching the API are not counted
+	sendTo(client, "not an address", "Hello")
+
+	if got, want := client.ErrorRate(5*time.Minute), 2.0/11; got != want {
+		t.Errorf("ErrorRate() = %v, want %v", got, want)
+	}
+	for _, tt := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 10 * time.Millisecond},
+		{0.5, 50 * time.Millisecond},
+		{0.9, 90 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+	} {
+		if got := client.LatencyPercentile(5*time.Minute, tt.p); got != tt.want {
+			t.Errorf("LatencyPercentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	// Sends leave the window as the clock moves on
+	clock.Advance(10 * time.Minute)
+	sendTo(client, "fail-0@example.com", "Hello")
+	if got := client.ErrorRate(5 * time.Minute); got != 1 {
+		t.Errorf("ErrorRate() after the window = %v, want 1", got)
+	}
+	if got := client.ErrorRate(time.Hour); got != 3.0/12 {
+		t.Errorf("ErrorRate() over an hour = %v, want %v", got, 3.0/12)
+	}
+}
+
+func TestSendStatsBounded(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, newStatsServer(t, clock).URL,
+		mailnow.WithClock(clock.Now),
+		mailnow.WithRetry(mailnow.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+
+	for i := 0; i < 100; i++ {
+		sendTo(client, "fail-0@example.com", "Hello")
+	}
+	for i := 0; i < mailnow.SendStatsCapacity; i++ {
+		if _, err := sendTo(client, "ok-0@example.com", "Hello"); err != nil {
+			t.Fatalf("SendEmail() error = %v", err)
+		}
+	}
+
+	// Only the most recent sends are kept, so the failures are gone
+	if got := client.ErrorRate(time.Hour); got != 0 {
+		t.Errorf("ErrorRate() = %v, want 0 once the failures were evicted", got)
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestSubaccountHeader(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(mailnow.SubaccountHeader))
+		w.WriteHeader(http.StatusOK)
+		switch r.URL.Path {
+		case "/v1/email/send":
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+		default:
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"id": "td_1", "domain": "links.example.com"}}`))
+		}
+	}))
+	defer server.Close()
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    "<p>Test</p>",
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		clientOpts []mailnow.Option
+		sendOpts   []mailnow.SendOption
+		wantSend   string
+		wantGet    string
+	}{
+		{
+			name:     "no sub-account",
+			wantSend: "",
+			wantGet:  "",
+		},
+		{
+			name:       "client sub-account",
+			clientOpts: []mailnow.Option{mailnow.WithSubaccount("acme")},
+			wantSend:   "acme",
+			wantGet:    "acme",
+		},
+		{
+			name:     "per-send sub-account",
+			sendOpts: []mailnow.SendOption{mailnow.WithSubaccount("globex")},
+			wantSend: "globex",
+			wantGet:  "",
+		},
+		{
+			name:       "per-send sub-account wins",
+			clientOpts: []mailnow.Option{mailnow.WithSubaccount("acme")},
+			sendOpts:   []mailnow.SendOption{mailnow.WithSubaccount("globex")},
+			wantSend:   "globex",
+			wantGet:    "acme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			opts := append([]mailnow.Option{mailnow.WithBaseURL(server.URL)}, tt.clientOpts...)
+			client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", opts...)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if _, err := client.SendEmail(ctx, req, tt.sendOpts...); err != nil {
+				t.Fatalf("SendEmail() unexpected error: %v", err)
+			}
+			if _, err := client.GetTrackingDomain(ctx, "links.example.com"); err != nil {
+				t.Fatalf("GetTrackingDomain() unexpected error: %v", err)
+			}
+
+			if len(got) != 2 || got[0] != tt.wantSend || got[1] != tt.wantGet {
+				t.Errorf("sub-account headers = %q, want [%q %q]", got, tt.wantSend, tt.wantGet)
+			}
+		})
+	}
+}
+
+func TestWithSubaccountInvalid(t *testing.T) {
+	for _, id := range []string{"", "acme corp", "acme/corp", strings.Repeat("a", 65)} {
+		_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithSubaccount(id))
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("NewClient(WithSubaccount(%q)) expected ValidationError, got %v", id, err)
+		}
+	}
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    "<p>Test</p>",
+	}
+	_, err = client.SendEmail(context.Background(), req, mailnow.WithSubaccount("acme corp"))
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("SendEmail(WithSubaccount(%q)) expected ValidationError, got %v", "acme corp", err)
+	}
+}
+
+func TestSubaccountNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": "subaccount_not_found", "message": "sub-account does not exist"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL), mailnow.WithSubaccount("acme"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    "<p>Test</p>",
+	}
+
+	_, err = client.SendEmail(context.Background(), req, mailnow.WithSubaccount("globex"))
+	var authErr *mailnow.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("SendEmail() expected AuthError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), `"globex"`) {
+		t.Errorf("SendEmail() error %q does not name the sub-account", err)
+	}
+	if code := mailnow.ErrorCode(err); code != "subaccount_not_found" {
+		t.Errorf("ErrorCode() = %q, want subaccount_not_found", code)
+	}
+	if status := mailnow.ErrorStatusCode(err); status != http.StatusForbidden {
+		t.Errorf("ErrorStatusCode() = %d, want 403", status)
+	}
+
+	_, err = client.GetTrackingDomain(context.Background(), "links.example.com")
+	if !errors.As(err, &authErr) || !strings.Contains(err.Error(), `"acme"`) {
+		t.Errorf("GetTrackingDomain() expected AuthError naming the sub-account, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestEncodeSubjectRFC2047(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		want    string
+		words   int
+	}{
+		{
+			name:    "pure ASCII",
+			subject: "Your invoice is ready",
+			want:    "Your invoice is ready",
+			words:   0,
+		},
+		{
+			name:    "accents and emoji",
+			subject: "Résumé 📄 – confirmation",
+			want:    "=?utf-8?q?R=C3=A9sum=C3=A9_=F0=9F=93=84_=E2=80=93_confirmation?=",
+			words:   1,
+		},
+		{
+			name:    "long accented subject",
+			subject: strings.Repeat("Café crème ", 10),
+			words:   4,
+		},
+	}
+
+	dec := new(mime.WordDecoder)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mailnow.EncodeSubjectRFC2047(tt.subject)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("EncodeSubjectRFC2047() = %q, want %q", got, tt.want)
+			}
+
+			words := 0
+			for _, word := range strings.Fields(got) {
+				if strings.HasPrefix(word, "=?") {
+					words++
+					if len(word) > 75 {
+						t.Errorf("encoded word %q is %d characters, want at most 75", word, len(word))
+					}
+				}
+			}
+			if words != tt.words {
+				t.Errorf("EncodeSubjectRFC2047() produced %d encoded words, want %d", words, tt.words)
+			}
+
+			decoded, err := dec.DecodeHeader(got)
+			if err != nil {
+				t.Fatalf("failed to decode %q: %v", got, err)
+			}
+			if decoded != tt.subject {
+				t.Errorf("decoded subject = %q, want %q", decoded, tt.subject)
+			}
+		})
+	}
+}
+
+func TestWithEncodedSubjects(t *testing.T) {
+	var gotSubject string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		gotSubject = req.Subject
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL), mailnow.WithEncodedSubjects())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Résumé 📄 – confirmation",
+		HTML:    "<p>Test</p>",
+	}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if gotSubject != mailnow.EncodeSubjectRFC2047(req.Subject) {
+		t.Errorf("server received subject %q, want the encoded subject", gotSubject)
+	}
+	if req.Subject != "Résumé 📄 – confirmation" {
+		t.Errorf("SendEmail() modified the request subject to %q", req.Subject)
+	}
+
+	// Subjects too long once encoded are rejected before sending
+	req.Subject = strings.Repeat("é", 200)
+	_, err = client.SendEmail(context.Background(), req)
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("SendEmail() expected ValidationError for an over-long encoded subject, got %v", err)
+	}
+}
+
+func TestAllowEmptySubject(t *testing.T) {
+	req := newRetryRequest()
+	req.Subject = ""
+
+	var validationErr *mailnow.ValidationError
+	if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+		t.Fatalf("ValidateEmailRequest() error = %v, want ValidationError", err)
+	}
+
+	req.AllowEmptySubject = true
+	if err := mailnow.ValidateEmailRequest(req); err != nil {
+		t.Fatalf("ValidateEmailRequest() with AllowEmptySubject error = %v", err)
+	}
+
+	// Other checks still apply
+	bad := *req
+	bad.Headers = map[string]string{"X-Note": "a\r\nBcc: victim@example.com"}
+	if err := mailnow.ValidateEmailRequest(&bad); err == nil {
+		t.Error("expected header injection to be rejected with AllowEmptySubject")
+	}
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		json.NewDecoder(r.Body).Decode(&raw)
+		body = raw
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_1", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if !strings.Contains(string(body), `"subject":""`) {
+		t.Errorf("payload = %s, want an explicit empty subject", body)
+	}
+	if strings.Contains(string(body), "allow_empty_subject") || strings.Contains(string(body), "AllowEmptySubject") {
+		t.Errorf("payload = %s, want the flag left out", body)
+	}
+}
+
+func TestLintEmptySubject(t *testing.T) {
+	req := newRetryRequest()
+	req.Subject = ""
+
+	report := mailnow.LintEmailRequest(req)
+	if !report.HasErrors() {
+		t.Error("expected an error for an empty subject without AllowEmptySubject")
+	}
+
+	req.AllowEmptySubject = true
+	report = mailnow.LintEmailRequest(req)
+	if report.HasErrors() {
+		t.Errorf("errors = %+v, want none", report.Errors)
+	}
+	found := false
+	for _, w := range report.Warnings {
+		if w.Code == "empty_subject" && w.Field == "Subject" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("warnings = %+v, want empty_subject", report.Warnings)
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestUnsubscribe(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != mailnow.SuppressionsEndpoint {
+			t.Errorf("request = %s %s, want POST %s", r.Method, r.URL.Path, mailnow.SuppressionsEndpoint)
+		}
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.Unsubscribe(context.Background(), "reader@example.com", "newsletter"); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	want := map[string]string{"email": "reader@example.com", "list_id": "newsletter", "reason": "unsubscribe"}
+	if len(got) != len(want) {
+		t.Errorf("payload = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("payload[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+
+	// Without a list the list ID is omitted
+	if err := client.Unsubscribe(context.Background(), "reader@example.com", ""); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	if _, ok := got["list_id"]; ok {
+		t.Errorf("payload = %v, want no list_id", got)
+	}
+}
+
+func TestUnsubscribeInvalidEmail(t *testing.T) {
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL("http://127.0.0.1:0"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var validationErr *mailnow.ValidationError
+	if err := client.Unsubscribe(context.Background(), "not-an-address", ""); !errors.As(err, &validationErr) {
+		t.Errorf("Unsubscribe() error = %v, want ValidationError", err)
+	}
+}
+
+func TestParseUnsubscribedDeliveryCallback(t *testing.T) {
+	body := `{"message_id": "msg_1", "recipient": "reader@example.com", "event": "unsubscribed", "token": "unsub_4f2a", "list_id": "newsletter", "timestamp": "2026-03-14T09:00:00Z"}`
+	r := httptest.NewRequest(http.MethodPost, "/mailnow/events", strings.NewReader(body))
+	r.Header.Set(mailnow.DeliveryCallbackSignatureHeader, mailnow.SignDeliveryCallback(callbackSecret, []byte(body)))
+
+	event, err := mailnow.ParseDeliveryCallback(r, func(ctx context.Context, messageID string) (string, error) {
+		return callbackSecret, nil
+	})
+	if err != nil {
+		t.Fatalf("ParseDeliveryCallback() error = %v", err)
+	}
+	if event.Event != mailnow.CallbackEventUnsubscribed || event.Recipient != "reader@example.com" || event.Token != "unsub_4f2a" || event.ListID != "newsletter" {
+		t.Errorf("event = %+v", event)
+	}
+
+	// An unsubscribed event must name its recipient
+	body = `{"message_id": "msg_1", "event": "unsubscribed", "token": "unsub_4f2a"}`
+	r = httptest.NewRequest(http.MethodPost, "/mailnow/events", strings.NewReader(body))
+	r.Header.Set(mailnow.DeliveryCallbackSignatureHeader, mailnow.SignDeliveryCallback(callbackSecret, []byte(body)))
+	_, err = mailnow.ParseDeliveryCallback(r, func(ctx context.Context, messageID string) (string, error) {
+		return callbackSecret, nil
+	})
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("ParseDeliveryCallback() error = %v, want ValidationError", err)
+	}
+
+	if err := mailnow.ValidateCallbackSpec(&mailnow.CallbackSpec{
+		URL:    "https://example.com/mailnow/events",
+		Secret: callbackSecret,
+		Events: []string{mailnow.CallbackEventUnsubscribed},
+	}); err != nil {
+		t.Errorf("ValidateCallbackSpec() error = %v, want unsubscribed to be a known event", err)
+	}
+}
+
+func TestSuppressionSyncer(t *testing.T) {
+	var payloads []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != mailnow.SuppressionsEndpoint {
+			t.Errorf("request path = %s, want %s", r.URL.Path, mailnow.SuppressionsEndpoint)
+		}
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads = append(payloads, payload)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	handler := mailnow.DeliveryCallbackHandler(
+		func(ctx context.Context, messageID string) (string, error) {
+			return callbackSecret, nil
+		},
+		mailnow.NewSuppressionSyncer(client).HandleEvent)
+
+	post := func(body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/mailnow/events", strings.NewReader(body))
+		r.Header.Set(mailnow.DeliveryCallbackSignatureHeader, mailnow.SignDeliveryCallback(callbackSecret, []byte(body)))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Other events leave the suppression list alone
+	if code := post(`{"message_id": "msg_1", "recipient": "reader@example.com", "event": "delivered"}`); code != http.StatusNoContent {
+		t.Fatalf("delivered callback status = %d, want 204", code)
+	}
+	if len(payloads) != 0 {
+		t.Fatalf("delivered event recorded suppressions: %v", payloads)
+	}
+
+	if code := post(`{"message_id": "msg_1", "recipient": "reader@example.com", "event": "unsubscribed", "token": "unsub_4f2a", "list_id": "newsletter"}`); code != http.StatusNoContent {
+		t.Fatalf("unsubscribed callback status = %d, want 204", code)
+	}
+	want := map[string]string{"email": "reader@example.com", "list_id": "newsletter", "reason": mailnow.SuppressionReasonUnsubscribe}
+	if len(payloads) != 1 || len(payloads[0]) != len(want) {
+		t.Fatalf("payloads = %v, want [%v]", payloads, want)
+	}
+	for k, v := range want {
+		if payloads[0][k] != v {
+			t.Errorf("payload[%q] = %q, want %q", k, payloads[0][k], v)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestExtractTemplateVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "no variables",
+			content: "<p>Hello</p>",
+			want:    nil,
+		},
+		{
+			name:    "duplicates are reported once in order of appearance",
+			content: "<p>Hi {{first_name}}, {{plan}} renews soon. Thanks {{first_name}}!</p>",
+			want:    []string{"first_name", "plan"},
+		},
+		{
+			name:    "whitespace inside delimiters",
+			content: "<p>Hi {{ first_name }}</p>",
+			want:    []string{"first_name"},
+		},
+		{
+			name:    "dotted names",
+			content: "<p>{{user.name}} at {{user.company.name}}</p>",
+			want:    []string{"user.name", "user.company.name"},
+		},
+		{
+			name:    "directives and invalid names are skipped",
+			content: "{{#if vip}}<p>{{ 1st }}{{name}}</p>{{/if}}",
+			want:    []string{"name"},
+		},
+		{
+			name:    "unterminated placeholder",
+			content: "<p>{{name}} and {{broken</p>",
+			want:    []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mailnow.ExtractTemplateVariables(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractTemplateVariables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelimitersExtractVariables(t *testing.T) {
+	d := mailnow.Delimiters{Left: "[[", Right: "]]"}
+	got := d.ExtractVariables("<p>Hi [[name]], {{ignored}} [[ order.id ]]</p>")
+	want := []string{"name", "order.id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractVariables() = %v, want %v", got, want)
+	}
+
+	// Empty delimiters match nothing
+	for _, d := range []mailnow.Delimiters{{Left: "[["}, {Right: "]]"}, {}} {
+		if got := d.ExtractVariables("<p>Hi [[name]]</p>"); got != nil {
+			t.Errorf("%+v.ExtractVariables() = %v, want nil", d, got)
+		}
+	}
+}
+
+func TestVerifyTemplateVariables(t *testing.T) {
+	content := "<p>Hi {{first_name}}, your order {{order.id}} ships to {{address.city}} via {{carrier}}.</p>"
+
+	t.Run("all variables present", func(t *testing.T) {
+		unused, err := mailnow.VerifyTemplateVariables(content, map[string]interface{}{
+			"first_name":   "Ada",
+			"order":        map[string]interface{}{"id": "A-1"},
+			"address.city": "London",
+			"carrier":      "DHL",
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(unused) != 0 {
+			t.Errorf("expected no unused keys, got %v", unused)
+		}
+	})
+
+	t.Run("missing variables are all listed", func(t *testing.T) {
+		_, err := mailnow.VerifyTemplateVariables(content, map[string]interface{}{
+			"first_name": "Ada",
+			"order":      map[string]interface{}{"number": "A-1"},
+		})
+		var validationErr *mailnow.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected ValidationError, got %T: %v", err, err)
+		}
+		for _, name := range []string{"order.id", "address.city", "carrier"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("expected error to list %q, got %q", name, err.Error())
+			}
+		}
+		if strings.Contains(err.Error(), "first_name") {
+			t.Errorf("error should not list provided variables, got %q", err.Error())
+		}
+	})
+
+	t.Run("unused keys are reported", func(t *testing.T) {
+		unused, err := mailnow.VerifyTemplateVariables("<p>Hi {{frist_name}}</p>", map[string]interface{}{
+			"frist_name": "Ada",
+			"first_name": "Ada",
+			"plan":       "pro",
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		want := []string{"first_name", "plan"}
+		if !reflect.DeepEqual(unused, want) {
+			t.Errorf("unused = %v, want %v", unused, want)
+		}
+	})
+}
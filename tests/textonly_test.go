@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func newTextRequest(to string) *mailnow.EmailRequest {
+	return &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      to,
+		Subject: "Your statement",
+		HTML:    "<p>Your statement is ready.</p>",
+		Text:    "Your statement is ready.",
+	}
+}
+
+func TestTextOnlyOption(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	if _, err := client.SendEmail(context.Background(), newTextRequest("reader@example.com"), mailnow.TextOnly()); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if _, err := client.SendEmail(context.Background(), newTextRequest("other@example.com")); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("server received %d sends, want 2", len(requests))
+	}
+	if requests[0].HTML != "" || requests[0].Text != "Your statement is ready." {
+		t.Errorf("text-only send HTML = %q, Text = %q", requests[0].HTML, requests[0].Text)
+	}
+	if requests[1].HTML == "" || requests[1].Text == "" {
+		t.Errorf("normal send HTML = %q, Text = %q, want both", requests[1].HTML, requests[1].Text)
+	}
+}
+
+func TestRecipientPreferences(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	prefersText := map[string]bool{"screenreader@example.com": true}
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRecipientPreferences(func(email string) mailnow.RecipientPreference {
+			if prefersText[email] {
+				return mailnow.PreferTextOnly
+			}
+			return mailnow.PreferHTML
+		}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	for _, to := range []string{"screenreader@example.com", "visual@example.com"} {
+		if _, err := client.SendEmail(context.Background(), newTextRequest(to)); err != nil {
+			t.Fatalf("SendEmail(%s) error = %v", to, err)
+		}
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("server received %d sends, want 2", len(requests))
+	}
+	if requests[0].To != "screenreader@example.com" || requests[0].HTML != "" {
+		t.Errorf("send to %s HTML = %q, want text only", requests[0].To, requests[0].HTML)
+	}
+	if requests[1].To != "visual@example.com" || requests[1].HTML == "" {
+		t.Errorf("send to %s HTML = %q, want HTML", requests[1].To, requests[1].HTML)
+	}
+
+	// The caller's request is left unchanged
+	req := newTextRequest("screenreader@example.com")
+	client.SendEmail(context.Background(), req)
+	if req.HTML == "" {
+		t.Error("SendEmail() modified the caller's request")
+	}
+}
+
+func TestTextOnlyRequiresText(t *testing.T) {
+	server := mailnowtest.NewServer()
+	defer server.Close()
+
+	client, err := mailnow.NewClient(mailnowtest.TestAPIKey(),
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithRecipientPreferences(func(string) mailnow.RecipientPreference { return mailnow.PreferTextOnly }))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := newTextRequest("reader@example.com")
+	req.Text = ""
+	var validationErr *mailnow.ValidationError
+	if _, err := client.SendEmail(context.Background(), req); !errors.As(err, &validationErr) {
+		t.Errorf("SendEmail() from preference error = %v, want ValidationError", err)
+	}
+
+	plain := newTestClient(t, server.URL)
+	if _, err := plain.SendEmail(context.Background(), req, mailnow.TextOnly()); !errors.As(err, &validationErr) {
+		t.Errorf("SendEmail() with TextOnly error = %v, want ValidationError", err)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("server received %d sends, want 0", n)
+	}
+
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithRecipientPreferences(nil)); err == nil {
+		t.Error("expected NewClient to reject a nil preference function")
+	}
+}
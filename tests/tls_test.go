@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+// newTLSSendServer starts a TLS server accepting sends, configured by
+// configure before it starts
+func newTLSSendServer(t *testing.T, configure func(s *httptest.Server)) (*httptest.Server, *x509.CertPool, []byte) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	if configure != nil {
+		configure(server)
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	fingerprint := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	return server, pool, fingerprint[:]
+}
+
+func sendTLSEmail(t *testing.T, opts ...mailnow.Option) error {
+	t.Helper()
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", opts...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Receipt",
+		HTML:    "<p>Thanks</p>",
+	}
+	_, err = client.SendEmail(context.Background(), req)
+	return err
+}
+
+func TestPinnedCertificates(t *testing.T) {
+	server, pool, fingerprint := newTLSSendServer(t, nil)
+
+	// Correct pin, in hex and base64
+	for _, pin := range []string{hex.EncodeToString(fingerprint), base64.StdEncoding.EncodeToString(fingerprint)} {
+		err := sendTLSEmail(t, mailnow.WithBaseURL(server.URL), mailnow.WithRootCAs(pool), mailnow.WithPinnedCertificates(pin))
+		if err != nil {
+			t.Errorf("SendEmail() with pin %s unexpected error: %v", pin, err)
+		}
+	}
+
+	// Wrong pin
+	wrong := sha256.Sum256([]byte("another key"))
+	err := sendTLSEmail(t, mailnow.WithBaseURL(server.URL), mailnow.WithRootCAs(pool), mailnow.WithPinnedCertificates(hex.EncodeToString(wrong[:])))
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("SendEmail() with wrong pin expected ConnectionError, got %T: %v", err, err)
+	}
+	want := "server certificate public key " + hex.EncodeToString(fingerprint) + " does not match any pinned fingerprint"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("SendEmail() error %q does not contain %q", err, want)
+	}
+}
+
+func TestMinTLSVersion(t *testing.T) {
+	server, pool, _ := newTLSSendServer(t, func(s *httptest.Server) {
+		s.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS10}
+		s.Config.ErrorLog = log.New(io.Discard, "", 0)
+	})
+
+	err := sendTLSEmail(t, mailnow.WithBaseURL(server.URL), mailnow.WithRootCAs(pool), mailnow.WithMinTLSVersion(tls.VersionTLS12))
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("SendEmail() to a TLS 1.0 server expected ConnectionError, got %T: %v", err, err)
+	}
+
+	// A TLS 1.2 server is accepted
+	server, pool, _ = newTLSSendServer(t, nil)
+	if err := sendTLSEmail(t, mailnow.WithBaseURL(server.URL), mailnow.WithRootCAs(pool), mailnow.WithMinTLSVersion(tls.VersionTLS12)); err != nil {
+		t.Errorf("SendEmail() unexpected error: %v", err)
+	}
+
+	// The caller's config is left as it was
+	config := &tls.Config{RootCAs: pool}
+	if err := sendTLSEmail(t, mailnow.WithBaseURL(server.URL), mailnow.WithTLSConfig(config), mailnow.WithMinTLSVersion(tls.VersionTLS12)); err != nil {
+		t.Errorf("SendEmail() with a TLS config unexpected error: %v", err)
+	}
+	if config.MinVersion != 0 {
+		t.Errorf("caller's MinVersion = 0x%04x, want it unchanged", config.MinVersion)
+	}
+}
+
+func TestTLSOptionsInvalid(t *testing.T) {
+	fingerprint := hex.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name string
+		opts []mailnow.Option
+	}{
+		{name: "pins and custom config", opts: []mailnow.Option{mailnow.WithTLSConfig(&tls.Config{}), mailnow.WithPinnedCertificates(fingerprint)}},
+		{name: "custom config and pins", opts: []mailnow.Option{mailnow.WithPinnedCertificates(fingerprint), mailnow.WithTLSConfig(&tls.Config{})}},
+		{name: "no fingerprints", opts: []mailnow.Option{mailnow.WithPinnedCertificates()}},
+		{name: "malformed fingerprint", opts: []mailnow.Option{mailnow.WithPinnedCertificates("abc123")}},
+		{name: "unknown TLS version", opts: []mailnow.Option{mailnow.WithMinTLSVersion(0x0200)}},
+		{name: "nil TLS config", opts: []mailnow.Option{mailnow.WithTLSConfig(nil)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", tt.opts...)
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("NewClient() expected ValidationError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestTrackingDomainCRUD(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/v1/tracking-domains":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode request body: %v", err)
+			}
+			if body["domain"] != "links.example.com" {
+				t.Errorf("expected domain links.example.com, got %s", body["domain"])
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"success": true, "status_code": 201, "data": {"id": "td_1", "domain": "links.example.com", "cname_target": "track.mailnow.xyz", "verified": false, "status": "pending"}}`))
+		case r.Method == "GET" && r.URL.Path == "/v1/tracking-domains/links.example.com":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": true, "status_code": 200, "data": {"id": "td_1", "domain": "links.example.com", "cname_target": "track.mailnow.xyz", "verified": true, "status": "verified"}}`))
+		case r.Method == "DELETE" && r.URL.Path == "/v1/tracking-domains/links.example.com":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" && r.URL.Path == "/v1/tracking-domains/missing.example.com":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "not_found", "message": "tracking domain not found"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	created, err := client.CreateTrackingDomain(ctx, "links.example.com")
+	if err != nil {
+		t.Fatalf("CreateTrackingDomain() unexpected error: %v", err)
+	}
+	if created.ID != "td_1" || created.CNAMETarget != "track.mailnow.xyz" || created.Verified || created.Status != "pending" {
+		t.Errorf("unexpected created tracking domain %+v", created)
+	}
+
+	fetched, err := client.GetTrackingDomain(ctx, "links.example.com")
+	if err != nil {
+		t.Fatalf("GetTrackingDomain() unexpected error: %v", err)
+	}
+	if !fetched.Verified || fetched.Status != "verified" {
+		t.Errorf("unexpected fetched tracking domain %+v", fetched)
+	}
+
+	if err := client.DeleteTrackingDomain(ctx, "links.example.com"); err != nil {
+		t.Errorf("DeleteTrackingDomain() unexpected error: %v", err)
+	}
+
+	if _, err := client.GetTrackingDomain(ctx, "missing.example.com"); err == nil {
+		t.Error("GetTrackingDomain() expected error for missing domain, got nil")
+	}
+}
+
+func TestTrackingDomainValidation(t *testing.T) {
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722", mailnow.WithBaseURL("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	invalid := []string{
+		"",
+		"https://links.example.com",
+		"links.example.com/path",
+		"links.example.com:8080",
+		"localhost",
+		"-links.example.com",
+		"links..example.com",
+	}
+	for _, domain := range invalid {
+		t.Run(domain, func(t *testing.T) {
+			_, err := client.CreateTrackingDomain(context.Background(), domain)
+			var validationErr *mailnow.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("expected ValidationError for %q, got %T: %v", domain, err, err)
+			}
+		})
+	}
+
+	if err := mailnow.ValidateDomain("links.mail.example.co.uk"); err != nil {
+		t.Errorf("ValidateDomain() unexpected error for valid domain: %v", err)
+	}
+}
+
+func TestCheckTrackingDomainDNS(t *testing.T) {
+	resolver := newFakeCNAMEResolver(map[string]string{
+		"links.example.com.": "track.mailnow.xyz.",
+		"wrong.example.com.": "elsewhere.example.net.",
+	})
+	ctx := context.Background()
+
+	td := &mailnow.TrackingDomain{Domain: "links.example.com", CNAMETarget: "track.mailnow.xyz"}
+	if err := mailnow.CheckTrackingDomainDNS(ctx, td, resolver); err != nil {
+		t.Errorf("CheckTrackingDomainDNS() unexpected error for matching record: %v", err)
+	}
+
+	td = &mailnow.TrackingDomain{Domain: "wrong.example.com", CNAMETarget: "track.mailnow.xyz"}
+	err := mailnow.CheckTrackingDomainDNS(ctx, td, resolver)
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError for mismatched record, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "elsewhere.example.net") {
+		t.Errorf("expected error to name the actual target, got %q", err.Error())
+	}
+
+	td = &mailnow.TrackingDomain{Domain: "missing.example.com", CNAMETarget: "track.mailnow.xyz"}
+	err = mailnow.CheckTrackingDomainDNS(ctx, td, resolver)
+	var connErr *mailnow.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Errorf("expected ConnectionError for missing record, got %T: %v", err, err)
+	}
+}
+
+// newFakeCNAMEResolver returns a resolver answering every query for a
+// name in records with a CNAME record, and NXDOMAIN otherwise
+func newFakeCNAMEResolver(records map[string]string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveFakeDNS(server, records)
+			return client, nil
+		},
+	}
+}
+
+// serveFakeDNS answers length-prefixed DNS queries on conn
+func serveFakeDNS(conn net.Conn, records map[string]string) {
+	defer conn.Close()
+	for {
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+			return
+		}
+		query := make([]byte, length)
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		// Parse the question name
+		name, end := "", 12
+		for query[end] != 0 {
+			n := int(query[end])
+			name += string(query[end+1:end+1+n]) + "."
+			end += n + 1
+		}
+		question := query[12 : end+5]
+
+		resp := make([]byte, 12, 512)
+		copy(resp, query[:2])
+		binary.BigEndian.PutUint16(resp[4:], 1) // QDCOUNT
+		target, ok := records[strings.ToLower(name)]
+		if ok {
+			binary.BigEndian.PutUint16(resp[2:], 0x8580) // QR, AA, RD, RA
+			binary.BigEndian.PutUint16(resp[6:], 1)      // ANCOUNT
+		} else {
+			binary.BigEndian.PutUint16(resp[2:], 0x8583) // NXDOMAIN
+		}
+		resp = append(resp, question...)
+
+		if ok {
+			var rdata []byte
+			for _, label := range strings.Split(strings.TrimSuffix(target, "."), ".") {
+				rdata = append(rdata, byte(len(label)))
+				rdata = append(rdata, label...)
+			}
+			rdata = append(rdata, 0)
+
+			resp = append(resp, 0xC0, 0x0C)             // name pointer to question
+			resp = append(resp, 0x00, 0x05, 0x00, 0x01) // CNAME, IN
+			resp = append(resp, 0x00, 0x00, 0x01, 0x2C) // TTL
+			resp = binary.BigEndian.AppendUint16(resp, uint16(len(rdata)))
+			resp = append(resp, rdata...)
+		}
+
+		if err := binary.Write(conn, binary.BigEndian, uint16(len(resp))); err != nil {
+			return
+		}
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
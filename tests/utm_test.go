@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestInjectUTMParams(t *testing.T) {
+	params := map[string]string{"utm_source": "mailnow", "utm_campaign": "spring sale"}
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "plain link",
+			html: `<a href="https://example.com/shop">Shop</a>`,
+			want: `<a href="https://example.com/shop?utm_campaign=spring+sale&utm_source=mailnow">Shop</a>`,
+		},
+		{
+			name: "existing query",
+			html: `<a href="https://example.com/shop?id=42&amp;ref=home">Shop</a>`,
+			want: `<a href="https://example.com/shop?id=42&amp;ref=home&amp;utm_campaign=spring+sale&amp;utm_source=mailnow">Shop</a>`,
+		},
+		{
+			name: "existing query ending in a separator",
+			html: `<a href="https://example.com/shop?id=42&amp;">Shop</a>`,
+			want: `<a href="https://example.com/shop?id=42&amp;utm_campaign=spring+sale&amp;utm_source=mailnow">Shop</a>`,
+		},
+		{
+			name: "fragment",
+			html: `<a href="http://example.com/faq#returns">FAQ</a>`,
+			want: `<a href="http://example.com/faq?utm_campaign=spring+sale&utm_source=mailnow#returns">FAQ</a>`,
+		},
+		{
+			name: "query and fragment",
+			html: `<a href="https://example.com/?q=1#top">Top</a>`,
+			want: `<a href="https://example.com/?q=1&utm_campaign=spring+sale&utm_source=mailnow#top">Top</a>`,
+		},
+		{
+			name: "single-quoted attribute",
+			html: `<a class='btn' href='https://example.com/'>Go</a>`,
+			want: `<a class='btn' href='https://example.com/?utm_campaign=spring+sale&utm_source=mailnow'>Go</a>`,
+		},
+		{
+			name: "unquoted attribute",
+			html: `<a href=https://example.com/>Go</a>`,
+			want: `<a href=https://example.com/?utm_campaign=spring+sale&utm_source=mailnow>Go</a>`,
+		},
+		{
+			name: "parameter already present",
+			html: `<a href="https://example.com/?utm_source=newsletter">Go</a>`,
+			want: `<a href="https://example.com/?utm_source=newsletter&utm_campaign=spring+sale">Go</a>`,
+		},
+		{
+			name: "all parameters present",
+			html: `<a href="https://example.com/?utm_source=a&utm_campaign=b">Go</a>`,
+			want: `<a href="https://example.com/?utm_source=a&utm_campaign=b">Go</a>`,
+		},
+		{
+			name: "skipped schemes",
+			html: `<a href="mailto:help@example.com">Mail</a> <a href="tel:+15551234">Call</a> <a href="#top">Top</a> <img src="cid:logo"> <a href="cid:logo">Logo</a>`,
+			want: `<a href="mailto:help@example.com">Mail</a> <a href="tel:+15551234">Call</a> <a href="#top">Top</a> <img src="cid:logo"> <a href="cid:logo">Logo</a>`,
+		},
+		{
+			name: "tags other than links",
+			html: `<base href="https://example.com/"><link rel="stylesheet" href="https://example.com/mail.css"><abbr href="https://example.com/">Go</abbr>`,
+			want: `<base href="https://example.com/"><link rel="stylesheet" href="https://example.com/mail.css"><abbr href="https://example.com/">Go</abbr>`,
+		},
+		{
+			name: "malformed URL",
+			html: `<a href="https://exa mple.com/%zz">Broken</a>`,
+			want: `<a href="https://exa mple.com/%zz">Broken</a>`,
+		},
+		{
+			name: "href text outside a tag",
+			html: `<p>Set href="https://example.com/" on the link</p>`,
+			want: `<p>Set href="https://example.com/" on the link</p>`,
+		},
+		{
+			name: "other markup untouched",
+			html: "<div  data-x='a>b'>\n  <A HREF=\"https://example.com\" title=\"x\">Go</A>\n</div>",
+			want: "<div  data-x='a>b'>\n  <A HREF=\"https://example.com?utm_campaign=spring+sale&utm_source=mailnow\" title=\"x\">Go</A>\n</div>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mailnow.InjectUTMParams(tt.html, params)
+			if err != nil {
+				t.Fatalf("InjectUTMParams() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("InjectUTMParams() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	_, err := mailnow.InjectUTMParams(`<a href="https://example.com/">Go</a>`, map[string]string{"": "x"})
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("InjectUTMParams() expected ValidationError for an empty parameter name, got %v", err)
+	}
+}
+
+func TestWithUTMParams(t *testing.T) {
+	var gotHTML string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req mailnow.EmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		gotHTML = req.HTML
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": {"message_id": "msg_123", "status": "queued"}}`))
+	}))
+	defer server.Close()
+
+	client, err := mailnow.NewClient("mn_test_7e59df7ce4a14545b443837804ec9722",
+		mailnow.WithBaseURL(server.URL),
+		mailnow.WithUTMParams(map[string]string{"utm_source": "mailnow"}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	req := &mailnow.EmailRequest{
+		From:    "sender@example.com",
+		To:      "recipient@example.com",
+		Subject: "Test",
+		HTML:    `<a href="https://example.com/">Go</a>`,
+	}
+
+	if _, err := client.SendEmail(context.Background(), req); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if want := `<a href="https://example.com/?utm_source=mailnow">Go</a>`; gotHTML != want {
+		t.Errorf("server received HTML %q, want %q", gotHTML, want)
+	}
+
+	// Per-send parameters replace the client's
+	if _, err := client.SendEmail(context.Background(), req, mailnow.WithUTMParams(map[string]string{"utm_campaign": "launch"})); err != nil {
+		t.Fatalf("SendEmail() unexpected error: %v", err)
+	}
+	if want := `<a href="https://example.com/?utm_campaign=launch">Go</a>`; gotHTML != want {
+		t.Errorf("server received HTML %q, want %q", gotHTML, want)
+	}
+
+	if req.HTML != `<a href="https://example.com/">Go</a>` {
+		t.Errorf("SendEmail() modified the request HTML to %q", req.HTML)
+	}
+}
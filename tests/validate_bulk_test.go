@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func syntheticAddresses(n int) []string {
+	emails := make([]string, n)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+	return emails
+}
+
+// newBulkValidateServer returns a server that marks addresses at
+// example.com valid and fails batches for which fail returns true
+func newBulkValidateServer(t *testing.T, fail func(emails []string) bool) (*httptest.Server, *[]int) {
+	var mu sync.Mutex
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != mailnow.BulkValidateEndpoint {
+			t.Errorf("path = %q, want %q", r.URL.Path, mailnow.BulkValidateEndpoint)
+		}
+		var req struct {
+			Emails []string `json:"emails"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		sizes = append(sizes, len(req.Emails))
+		mu.Unlock()
+
+		if fail != nil && fail(req.Emails) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": "invalid_batch", "message": "batch rejected"}}`))
+			return
+		}
+		data := make([]mailnow.RecipientValidation, len(req.Emails))
+		for i, email := range req.Emails {
+			data[i] = mailnow.RecipientValidation{Email: email, Valid: strings.HasSuffix(email, "@example.com")}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "status_code": 200, "data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server, &sizes
+}
+
+func TestValidateRecipientsBulk(t *testing.T) {
+	server, sizes := newBulkValidateServer(t, nil)
+	client := newTestClient(t, server.URL)
+
+	emails := syntheticAddresses(2500)
+	var progress []int
+	result, err := client.ValidateRecipientsBulk(context.Background(), emails,
+		mailnow.WithBulkConcurrency(1),
+		mailnow.WithBulkProgress(func(done, total int) {
+			if total != 2500 {
+				t.Errorf("progress total = %d, want 2500", total)
+			}
+			progress = append(progress, done)
+		}))
+	if err != nil {
+		t.Fatalf("ValidateRecipientsBulk() error = %v", err)
+	}
+
+	if fmt.Sprint(*sizes) != "[1000 1000 500]" {
+		t.Errorf("batch sizes = %v, want [1000 1000 500]", *sizes)
+	}
+	if fmt.Sprint(progress) != "[1000 2000 2500]" {
+		t.Errorf("progress = %v, want [1000 2000 2500]", progress)
+	}
+	if len(result.Results) != 2500 || len(result.Failures) != 0 {
+		t.Fatalf("got %d results and %d failures, want 2500 and 0", len(result.Results), len(result.Failures))
+	}
+	if r := result.Results["user1999@example.com"]; !r.Valid {
+		t.Errorf("result for user1999 = %+v, want valid", r)
+	}
+}
+
+func TestValidateRecipientsBulkFailingChunk(t *testing.T) {
+	server, _ := newBulkValidateServer(t, func(emails []string) bool {
+		return emails[0] == "user1000@example.com"
+	})
+	client := newTestClient(t, server.URL)
+
+	result, err := client.ValidateRecipientsBulk(context.Background(), syntheticAddresses(2500))
+	if err != nil {
+		t.Fatalf("ValidateRecipientsBulk() error = %v", err)
+	}
+	if len(result.Results) != 1500 {
+		t.Errorf("got %d results, want 1500", len(result.Results))
+	}
+	if len(result.Failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(result.Failures))
+	}
+	failure := result.Failures[0]
+	if len(failure.Emails) != 1000 || failure.Emails[0] != "user1000@example.com" {
+		t.Errorf("failed batch starts at %q with %d addresses", failure.Emails[0], len(failure.Emails))
+	}
+	var validationErr *mailnow.ValidationError
+	if !errors.As(failure.Err, &validationErr) {
+		t.Errorf("failure error = %v, want ValidationError", failure.Err)
+	}
+	if _, ok := result.Results["user1500@example.com"]; ok {
+		t.Error("addresses of the failed batch should have no result")
+	}
+}
+
+func TestValidateRecipientsBulkCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, sizes := newBulkValidateServer(t, nil)
+	client := newTestClient(t, server.URL)
+
+	// Cancel once the first batch is done
+	result, err := client.ValidateRecipientsBulk(ctx, syntheticAddresses(2500),
+		mailnow.WithBulkConcurrency(1),
+		mailnow.WithBulkProgress(func(done, total int) { cancel() }))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ValidateRecipientsBulk() error = %v, want context.Canceled", err)
+	}
+	if len(*sizes) != 1 {
+		t.Errorf("server received %d batches, want 1", len(*sizes))
+	}
+	if len(result.Results) != 1000 {
+		t.Errorf("got %d results, want 1000", len(result.Results))
+	}
+	if len(result.Failures) != 2 || !errors.Is(result.Failures[0].Err, context.Canceled) {
+		t.Errorf("failures = %+v, want the two remaining batches cancelled", result.Failures)
+	}
+}
+
+func TestValidateRecipientsBulkOptions(t *testing.T) {
+	client := newTestClient(t, "http://127.0.0.1:0")
+	for _, opt := range []mailnow.BulkValidateOption{
+		mailnow.WithBulkBatchSize(0),
+		mailnow.WithBulkBatchSize(mailnow.MaxBulkValidateBatchSize + 1),
+		mailnow.WithBulkConcurrency(0),
+		mailnow.WithBulkProgress(nil),
+	} {
+		if _, err := client.ValidateRecipientsBulk(context.Background(), []string{"a@example.com"}, opt); err == nil {
+			t.Error("expected an option error")
+		}
+	}
+}
+
+func TestValidateRecipientsBulkKeysByInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Emails []string `json:"emails"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		// The API normalizes addresses and returns the valid ones first
+		var valid, invalid []mailnow.RecipientValidation
+		for _, email := range req.Emails {
+			email = strings.ToLower(strings.Replace(email, "exämple", "xn--exmple-cua", 1))
+			if strings.HasPrefix(email, "bad") {
+				invalid = append(invalid, mailnow.RecipientValidation{Email: email})
+			} else {
+				valid = append(valid, mailnow.RecipientValidation{Email: email, Valid: true})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "status_code": 200, "data": append(valid, invalid...)})
+	}))
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	result, err := client.ValidateRecipientsBulk(context.Background(), []string{"Bad@Example.com", "Dana@Example.com"})
+	if err != nil {
+		t.Fatalf("ValidateRecipientsBulk() error = %v", err)
+	}
+	if len(result.Results) != 2 || !result.Results["Dana@Example.com"].Valid || result.Results["Bad@Example.com"].Valid {
+		t.Errorf("Results = %+v, want them keyed by the addresses as given", result.Results)
+	}
+
+	// Results in a form that cannot be matched are keyed by position
+	result, err = client.ValidateRecipientsBulk(context.Background(), []string{"sam@exämple.com"})
+	if err != nil {
+		t.Fatalf("ValidateRecipientsBulk() error = %v", err)
+	}
+	if v, ok := result.Results["sam@exämple.com"]; !ok || !v.Valid {
+		t.Errorf("Results = %+v, want a result for sam@exämple.com", result.Results)
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Ayobami6/go-mailnow"
+	"github.com/Ayobami6/go-mailnow/mailnowtest"
+)
+
+func TestRecipientValidationCache(t *testing.T) {
+	server, sizes := newBulkValidateServer(t, nil)
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithRecipientValidationCache(100, time.Hour, time.Minute))
+	ctx := context.Background()
+
+	validate := func(email string, wantValid bool, wantCalls int) {
+		t.Helper()
+		v, err := client.ValidateRecipient(ctx, email)
+		if err != nil {
+			t.Fatalf("ValidateRecipient(%q) error = %v", email, err)
+		}
+		if v.Email != email || v.Valid != wantValid {
+			t.Errorf("ValidateRecipient(%q) = %+v, want Valid %v", email, v, wantValid)
+		}
+		if len(*sizes) != wantCalls {
+			t.Errorf("after %q the API was called %d times, want %d", email, len(*sizes), wantCalls)
+		}
+	}
+
+	validate("user@example.com", true, 1)
+	validate("user@example.com", true, 1)
+	validate("John.Doe@gmail.com", false, 2)
+	// Addresses with the same canonical form share a result
+	validate("johndoe+signup@googlemail.com", false, 2)
+
+	if stats := client.RecipientValidationCacheStats(); stats.Hits != 2 || stats.Misses != 2 {
+		t.Errorf("stats = %+v, want 2 hits and 2 misses", stats)
+	}
+
+	// Invalid results expire sooner than valid ones
+	clock.Advance(2 * time.Minute)
+	validate("user@example.com", true, 2)
+	validate("johndoe@gmail.com", false, 3)
+
+	// An address found invalid again is cached twice as long
+	clock.Advance(90 * time.Second)
+	validate("johndoe@gmail.com", false, 3)
+	clock.Advance(time.Minute)
+	validate("johndoe@gmail.com", false, 4)
+
+	clock.Advance(time.Hour)
+	validate("user@example.com", true, 5)
+}
+
+func TestRecipientValidationCacheBypass(t *testing.T) {
+	server, sizes := newBulkValidateServer(t, nil)
+	clock := &fakeClock{now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)}
+	client := newTestClient(t, server.URL, mailnow.WithClock(clock.Now), mailnow.WithRecipientValidationCache(100, time.Hour, time.Minute))
+	ctx := context.Background()
+
+	client.ValidateRecipient(ctx, "user@example.com")
+	if _, err := client.ValidateRecipient(ctx, "user@example.com", mailnow.WithoutValidationCache()); err != nil {
+		t.Fatalf("ValidateRecipient() error = %v", err)
+	}
+	if len(*sizes) != 2 {
+		t.Errorf("API called %d times with the bypass, want 2", len(*sizes))
+	}
+
+	client.InvalidateRecipientValidation("user@EXAMPLE.com")
+	client.ValidateRecipient(ctx, "user@example.com")
+	if len(*sizes) != 3 {
+		t.Errorf("API called %d times after invalidation, want 3", len(*sizes))
+	}
+
+	// Only uncached addresses of a bulk call are sent
+	result, err := client.ValidateRecipientsBulk(ctx, []string{"user@example.com", "other@example.com"})
+	if err != nil || len(result.Results) != 2 {
+		t.Fatalf("ValidateRecipientsBulk() = %+v, %v", result, err)
+	}
+	if got := (*sizes)[len(*sizes)-1]; got != 1 {
+		t.Errorf("bulk call sent %d addresses, want 1", got)
+	}
+
+	if _, err := mailnow.NewClient(mailnowtest.TestAPIKey(), mailnow.WithRecipientValidationCache(0, time.Hour, time.Minute)); err == nil {
+		t.Error("expected NewClient to reject a zero cache size")
+	}
+}
+
+func TestValidateRecipientMissingResult(t *testing.T) {
+	var data string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "status_code": 200, "data": ` + data + `}`))
+	}))
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	// A result for the address in another form is used
+	data = `[{"email": "John.Doe@Example.com", "valid": true}]`
+	v, err := client.ValidateRecipient(context.Background(), "john.doe@example.com")
+	if err != nil || !v.Valid {
+		t.Errorf("ValidateRecipient() = %+v, %v, want a valid result", v, err)
+	}
+
+	data = `[]`
+	_, err = client.ValidateRecipient(context.Background(), "john.doe@example.com")
+	var serverErr *mailnow.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Errorf("ValidateRecipient() without a result error = %v, want ServerError", err)
+	}
+}
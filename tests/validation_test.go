@@ -1,7 +1,14 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/Ayobami6/go-mailnow"
@@ -71,6 +78,56 @@ func TestValidateAPIKey(t *testing.T) {
 	}
 }
 
+func TestMinAPIKeySecretLength(t *testing.T) {
+	// By default any secret is accepted
+	client, err := mailnow.NewClient("mn_live_x")
+	if err != nil {
+		t.Fatalf("NewClient() with the default minimum unexpected error: %v", err)
+	}
+	if _, err := client.WithOptions(mailnow.WithMinAPIKeySecretLength(40)); !errors.As(err, new(*mailnow.ValidationError)) {
+		t.Errorf("WithOptions() raising the minimum error = %v, want ValidationError", err)
+	}
+
+	// The minimum is per client
+	_, err = mailnow.NewClient("mn_live_x", mailnow.WithMinAPIKeySecretLength(mailnow.RecommendedMinAPIKeySecretLength))
+	if !errors.As(err, new(*mailnow.ValidationError)) {
+		t.Errorf("NewClient() with the recommended minimum error = %v, want ValidationError", err)
+	}
+	if _, err := mailnow.NewClient("mn_test_abc123def456", mailnow.WithMinAPIKeySecretLength(mailnow.RecommendedMinAPIKeySecretLength)); err != nil {
+		t.Errorf("NewClient() with a 12 character secret unexpected error: %v", err)
+	}
+	_, err = mailnow.NewClient("mn_live_7e59df7ce4a14545b443837804ec9722", mailnow.WithMinAPIKeySecretLength(40))
+	if !errors.As(err, new(*mailnow.ValidationError)) {
+		t.Errorf("NewClient() with a minimum of 40 error = %v, want ValidationError", err)
+	}
+	if _, err := mailnow.NewClient("mn_live_7e59df7ce4a14545b443837804ec9722", mailnow.WithMinAPIKeySecretLength(-1)); err == nil {
+		t.Error("NewClient() expected error for a negative minimum")
+	}
+}
+
+func TestAPIKeyClassifiers(t *testing.T) {
+	tests := []struct {
+		apiKey   string
+		wantTest bool
+		wantLive bool
+	}{
+		{apiKey: "mn_test_7e59df7ce4a14545b443837804ec9722", wantTest: true},
+		{apiKey: "mn_live_7e59df7ce4a14545b443837804ec9722", wantLive: true},
+		{apiKey: "mn_prod_7e59df7ce4a14545b443837804ec9722"},
+		{apiKey: "MN_TEST_7e59df7ce4a14545b443837804ec9722"},
+		{apiKey: ""},
+	}
+
+	for _, tt := range tests {
+		if got := mailnow.IsTestAPIKey(tt.apiKey); got != tt.wantTest {
+			t.Errorf("IsTestAPIKey(%q) = %v, want %v", tt.apiKey, got, tt.wantTest)
+		}
+		if got := mailnow.IsLiveAPIKey(tt.apiKey); got != tt.wantLive {
+			t.Errorf("IsLiveAPIKey(%q) = %v, want %v", tt.apiKey, got, tt.wantLive)
+		}
+	}
+}
+
 func TestValidateEmailAddress(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -280,3 +337,298 @@ func TestValidateEmailRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAttachment(t *testing.T) {
+	tests := []struct {
+		name       string
+		attachment mailnow.Attachment
+		wantErr    bool
+	}{
+		{
+			name:       "inline content",
+			attachment: mailnow.Attachment{Filename: "a.pdf", Content: "SGVsbG8=", ContentType: "application/pdf"},
+			wantErr:    false,
+		},
+		{
+			name:       "https URL",
+			attachment: mailnow.Attachment{Filename: "a.pdf", URL: "https://cdn.example.com/a.pdf", ContentType: "application/pdf"},
+			wantErr:    false,
+		},
+		{
+			name:       "asset ID",
+			attachment: mailnow.Attachment{Filename: "a.pdf", AssetID: "asset_123", ContentType: "application/pdf"},
+			wantErr:    false,
+		},
+		{
+			name:       "no source",
+			attachment: mailnow.Attachment{Filename: "a.pdf", ContentType: "application/pdf"},
+			wantErr:    true,
+		},
+		{
+			name:       "content and URL",
+			attachment: mailnow.Attachment{Filename: "a.pdf", Content: "SGVsbG8=", URL: "https://cdn.example.com/a.pdf"},
+			wantErr:    true,
+		},
+		{
+			name:       "URL and asset ID",
+			attachment: mailnow.Attachment{Filename: "a.pdf", URL: "https://cdn.example.com/a.pdf", AssetID: "asset_123"},
+			wantErr:    true,
+		},
+		{
+			name:       "http URL",
+			attachment: mailnow.Attachment{Filename: "a.pdf", URL: "http://cdn.example.com/a.pdf"},
+			wantErr:    true,
+		},
+		{
+			name:       "relative URL",
+			attachment: mailnow.Attachment{Filename: "a.pdf", URL: "/files/a.pdf"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mailnow.ValidateAttachment(tt.attachment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAttachment() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				var validationErr *mailnow.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Errorf("ValidateAttachment() error type = %T, want ValidationError", err)
+				}
+			}
+		})
+	}
+
+	// Attachment errors are reported through ValidateEmailRequest
+	req := &mailnow.EmailRequest{
+		From:        "sender@example.com",
+		To:          "recipient@example.com",
+		Subject:     "Test",
+		HTML:        "<p>Test</p>",
+		Attachments: []mailnow.Attachment{{Filename: "a.pdf", URL: "http://cdn.example.com/a.pdf"}},
+	}
+	if err := mailnow.ValidateEmailRequest(req); err == nil {
+		t.Error("ValidateEmailRequest() expected error for invalid attachment, got nil")
+	}
+}
+
+func TestValidateCampaignID(t *testing.T) {
+	tests := []struct {
+		name       string
+		campaignID string
+		wantErr    bool
+	}{
+		{name: "simple slug", campaignID: "spring-sale", wantErr: false},
+		{name: "underscores and digits", campaignID: "launch_2024_q1", wantErr: false},
+		{name: "single character", campaignID: "a", wantErr: false},
+		{name: "64 characters", campaignID: strings.Repeat("a", 64), wantErr: false},
+		{name: "empty", campaignID: "", wantErr: true},
+		{name: "65 characters", campaignID: strings.Repeat("a", 65), wantErr: true},
+		{name: "contains space", campaignID: "spring sale", wantErr: true},
+		{name: "contains slash", campaignID: "spring/sale", wantErr: true},
+		{name: "contains unicode", campaignID: "soldes-été", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mailnow.ValidateCampaignID(tt.campaignID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCampaignID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := &mailnow.EmailRequest{
+		From:       "sender@example.com",
+		To:         "recipient@example.com",
+		Subject:    "Test",
+		HTML:       "<p>Test</p>",
+		CampaignID: "spring sale",
+	}
+	var validationErr *mailnow.ValidationError
+	if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+		t.Errorf("ValidateEmailRequest() expected ValidationError for invalid campaign ID, got %v", err)
+	}
+}
+
+func TestValidateBase64(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantSize int64
+		wantErr  bool
+	}{
+		{name: "empty input", input: "", wantSize: 0},
+		{name: "no padding", input: "SGVsbG8h", wantSize: 6},
+		{name: "one padding character", input: "SGVsbG8=", wantSize: 5},
+		{name: "two padding characters", input: "SGVsbA==", wantSize: 4},
+		{name: "standard alphabet", input: "+/+/", wantSize: 3},
+		{name: "URL-safe alphabet", input: "-_-_", wantSize: 3},
+		{name: "line breaks ignored", input: "SGVs\r\nbG8h\n", wantSize: 6},
+		{name: "missing padding", input: "SGVsbG8", wantErr: true},
+		{name: "too much padding", input: "SGVsb===", wantErr: true},
+		{name: "padding only", input: "====", wantErr: true},
+		{name: "padding in the middle", input: "SG==bG8h", wantErr: true},
+		{name: "illegal character at start", input: "*GVsbG8h", wantErr: true},
+		{name: "illegal character in middle", input: "SGVs bG8h", wantErr: true},
+		{name: "illegal character at end", input: "SGVsbG8!", wantErr: true},
+		{name: "non-ASCII character", input: "SGVsbG8é", wantErr: true},
+		{name: "mixed alphabets", input: "+/-_", wantErr: true},
+		{name: "length not multiple of 4", input: "SGVsbG8hx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := mailnow.ValidateBase64(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateBase64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.As(err, new(*mailnow.ValidationError)) {
+				t.Errorf("ValidateBase64() error type = %T, want *mailnow.ValidationError", err)
+			}
+			if !tt.wantErr && size != tt.wantSize {
+				t.Errorf("ValidateBase64() size = %d, want %d", size, tt.wantSize)
+			}
+		})
+	}
+
+	// Decoded sizes agree with a full decode
+	for n := 0; n < 64; n++ {
+		data := bytes.Repeat([]byte{0xfb}, n)
+		for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding} {
+			size, err := mailnow.ValidateBase64(enc.EncodeToString(data))
+			if err != nil || size != int64(n) {
+				t.Errorf("ValidateBase64() for %d bytes = %d, %v", n, size, err)
+			}
+		}
+	}
+
+	// Attachment content is checked
+	err := mailnow.ValidateAttachment(mailnow.Attachment{Filename: "a.txt", Content: "not base64!"})
+	var validationErr *mailnow.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("ValidateAttachment() expected ValidationError for invalid content, got %v", err)
+	}
+}
+
+func BenchmarkValidateBase64(b *testing.B) {
+	encoded := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("mailnow attachment "), 4<<20/19))
+	b.SetBytes(int64(len(encoded)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := mailnow.ValidateBase64(encoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateBase64DecodeString(b *testing.B) {
+	encoded := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("mailnow attachment "), 4<<20/19))
+	b.SetBytes(int64(len(encoded)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// referenceEmailRegex is the pattern ValidateEmailAddress accepts
+var referenceEmailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// randomAddress builds an address-like string from characters that are
+// interesting to the address grammar
+func randomAddress(rng *rand.Rand) string {
+	const alphabet = "abcXYZ019._%+-@@..!# \n\x00é"
+	if rng.Intn(2) == 0 {
+		// Mostly well-formed addresses with a few mutations
+		b := []byte(fmt.Sprintf("user%d.%c@mail-%d.example.%s", rng.Intn(100), 'a'+rng.Intn(26), rng.Intn(10), []string{"com", "io", "c", "c0m", "", "museum"}[rng.Intn(6)]))
+		for n := rng.Intn(3); n > 0; n-- {
+			b[rng.Intn(len(b))] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+	b := make([]byte, rng.Intn(16))
+	for i := range b {
+		b[i] = alphabet[rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+func TestValidateEmailAddressMatchesRegex(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, cache := range []int{mailnow.DefaultEmailValidationCacheSize, 8, 0} {
+		mailnow.SetEmailValidationCacheSize(cache)
+		for i := 0; i < 50000; i++ {
+			email := randomAddress(rng)
+			want := email != "" && referenceEmailRegex.MatchString(email)
+			// Validate twice to exercise the cache
+			for j := 0; j < 2; j++ {
+				if got := mailnow.ValidateEmailAddress(email) == nil; got != want {
+					t.Fatalf("ValidateEmailAddress(%q) valid = %v, want %v (cache size %d)", email, got, want, cache)
+				}
+			}
+		}
+	}
+	mailnow.SetEmailValidationCacheSize(mailnow.DefaultEmailValidationCacheSize)
+}
+
+func TestValidateEmailAddressConcurrent(t *testing.T) {
+	mailnow.SetEmailValidationCacheSize(16)
+	defer mailnow.SetEmailValidationCacheSize(mailnow.DefaultEmailValidationCacheSize)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < 2000; i++ {
+				email := randomAddress(rng)
+				want := email != "" && referenceEmailRegex.MatchString(email)
+				if got := mailnow.ValidateEmailAddress(email) == nil; got != want {
+					t.Errorf("ValidateEmailAddress(%q) valid = %v, want %v", email, got, want)
+					return
+				}
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+}
+
+func benchmarkValidateEmailAddress(b *testing.B, cacheSize int) {
+	mailnow.SetEmailValidationCacheSize(cacheSize)
+	defer mailnow.SetEmailValidationCacheSize(mailnow.DefaultEmailValidationCacheSize)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := mailnow.ValidateEmailAddress("jane.doe+receipts@mail.example.com"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateEmailAddress(b *testing.B) {
+	benchmarkValidateEmailAddress(b, mailnow.DefaultEmailValidationCacheSize)
+}
+
+func BenchmarkValidateEmailAddressUncached(b *testing.B) {
+	benchmarkValidateEmailAddress(b, 0)
+}
+
+func BenchmarkValidateEmailAddressRegex(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !referenceEmailRegex.MatchString("jane.doe+receipts@mail.example.com") {
+			b.Fatal("no match")
+		}
+	}
+}
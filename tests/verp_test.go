@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami6/go-mailnow"
+)
+
+func TestBuildVERPAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "simple token", base: "bounces@mail.example.com", token: "order123", want: "bounces+order123@mail.example.com"},
+		{name: "token with dots and dashes", base: "bounces@mail.example.com", token: "inv-2024.07_1", want: "bounces+inv-2024.07_1@mail.example.com"},
+		{name: "token needing sanitizing", base: "bounces@mail.example.com", token: "user@example.com/42 +x", want: "bounces+user-example.com-42-x@mail.example.com"},
+		{name: "non-ASCII token", base: "bounces@mail.example.com", token: "commande-été", want: "bounces+commande--t-@mail.example.com"},
+		{name: "local part at limit", base: "bounces@mail.example.com", token: strings.Repeat("a", 56), want: "bounces+" + strings.Repeat("a", 56) + "@mail.example.com"},
+		{name: "local part overflow", base: "bounces@mail.example.com", token: strings.Repeat("a", 57), wantErr: true},
+		{name: "empty token", base: "bounces@mail.example.com", token: "", wantErr: true},
+		{name: "token of unsafe characters", base: "bounces@mail.example.com", token: "@@ ", wantErr: true},
+		{name: "invalid base", base: "bounces", token: "order123", wantErr: true},
+		{name: "base with extension", base: "bounces+x@mail.example.com", token: "order123", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mailnow.BuildVERPAddress(tt.base, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildVERPAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var validationErr *mailnow.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Errorf("BuildVERPAddress() error type = %T, want ValidationError", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BuildVERPAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVERPToken(t *testing.T) {
+	tests := []struct {
+		address   string
+		wantToken string
+		wantOK    bool
+	}{
+		{address: "bounces+order123@mail.example.com", wantToken: "order123", wantOK: true},
+		{address: "bounces+inv+2@mail.example.com", wantToken: "inv+2", wantOK: true},
+		{address: "bounces@mail.example.com", wantOK: false},
+		{address: "bounces+@mail.example.com", wantOK: false},
+		{address: "not-an-address", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		token, ok := mailnow.ParseVERPToken(tt.address)
+		if token != tt.wantToken || ok != tt.wantOK {
+			t.Errorf("ParseVERPToken(%q) = %q, %v, want %q, %v", tt.address, token, ok, tt.wantToken, tt.wantOK)
+		}
+	}
+
+	// Built addresses round-trip
+	address, err := mailnow.BuildVERPAddress("bounces@mail.example.com", "order123")
+	if err != nil {
+		t.Fatalf("BuildVERPAddress() unexpected error: %v", err)
+	}
+	if token, ok := mailnow.ParseVERPToken(address); !ok || token != "order123" {
+		t.Errorf("ParseVERPToken(%q) = %q, %v, want order123", address, token, ok)
+	}
+}
+
+func TestValidateEmailRequestReturnPath(t *testing.T) {
+	req := &mailnow.EmailRequest{
+		From:       "sender@example.com",
+		To:         "recipient@example.com",
+		Subject:    "Test",
+		HTML:       "<p>Test</p>",
+		ReturnPath: "bounces+order123@mail.example.com",
+	}
+	if err := mailnow.ValidateEmailRequest(req); err != nil {
+		t.Errorf("ValidateEmailRequest() unexpected error: %v", err)
+	}
+
+	req.ReturnPath = "bounces+order123@"
+	var validationErr *mailnow.ValidationError
+	if err := mailnow.ValidateEmailRequest(req); !errors.As(err, &validationErr) {
+		t.Errorf("ValidateEmailRequest() expected ValidationError for invalid return path, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package mailnow
+
+import "context"
+
+// RecipientPreference is a recipient's stored preference for the body of
+// the emails they receive
+type RecipientPreference int
+
+const (
+	// PreferHTML sends the email as given. It is the default.
+	PreferHTML RecipientPreference = iota
+
+	// PreferTextOnly sends only the plain-text body, for recipients such
+	// as screen reader users who asked for plain text
+	PreferTextOnly
+)
+
+// TextOnly sends only the plain-text body of the email, leaving the HTML
+// body out of the request. The request must have a Text body.
+func TextOnly() SendOption {
+	return sendOption(func(o *sendOptions) error {
+		o.textOnly = true
+		return nil
+	})
+}
+
+// WithRecipientPreferences looks up the body preference of every
+// recipient with preference, sending text-only as with TextOnly to
+// recipients that prefer plain text. Sends to them without a Text body
+// fail with a ValidationError.
+func WithRecipientPreferences(preference func(email string) RecipientPreference) Option {
+	return clientOption(func(c *Client) error {
+		if preference == nil {
+			return NewValidationError("recipient preference function cannot be nil", nil)
+		}
+		c.recipientPreferences = preference
+		return nil
+	})
+}
+
+// prefersTextOnly reports whether the recipient to prefers plain text,
+// failing if the preference function panics
+func (c *Client) prefersTextOnly(ctx context.Context, to string) (textOnly bool, err error) {
+	err = c.safeCall(ctx, CallbackRecipientPreferences, nil, func() {
+		textOnly = c.recipientPreferences(to) == PreferTextOnly
+	})
+	return textOnly, err
+}
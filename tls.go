@@ -0,0 +1,160 @@
+package mailnow
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// tlsSettings holds the TLS options collected by NewClient
+type tlsSettings struct {
+	// config is the custom TLS config set with WithTLSConfig
+	config *tls.Config
+
+	// minVersion is the minimum TLS version, or 0 for the default
+	minVersion uint16
+
+	// rootCAs replaces the system roots when set
+	rootCAs *x509.CertPool
+
+	// pins are the accepted SPKI SHA-256 fingerprints
+	pins [][]byte
+}
+
+// isSet reports whether any TLS option was used
+func (s tlsSettings) isSet() bool {
+	return s.config != nil || s.minVersion != 0 || s.rootCAs != nil || s.pins != nil
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the API. It
+// cannot be combined with WithPinnedCertificates or WithRootCAs.
+func WithTLSConfig(config *tls.Config) Option {
+	return clientOption(func(c *Client) error {
+		if config == nil {
+			return NewValidationError("TLS config cannot be nil", nil)
+		}
+		c.tls.config = config.Clone()
+		return nil
+	})
+}
+
+// WithMinTLSVersion sets the minimum TLS version, such as tls.VersionTLS12,
+// the client accepts when connecting to the API.
+func WithMinTLSVersion(v uint16) Option {
+	return clientOption(func(c *Client) error {
+		if v < tls.VersionTLS10 || v > tls.VersionTLS13 {
+			return NewValidationError(fmt.Sprintf("unsupported TLS version 0x%04x", v), nil)
+		}
+		c.tls.minVersion = v
+		return nil
+	})
+}
+
+// WithRootCAs sets the certificate authorities used to verify the API's
+// certificate in place of the system roots.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return clientOption(func(c *Client) error {
+		if pool == nil {
+			return NewValidationError("root CA pool cannot be nil", nil)
+		}
+		c.tls.rootCAs = pool
+		return nil
+	})
+}
+
+// WithPinnedCertificates restricts the client to API servers presenting a
+// certificate whose public key (SPKI) has one of the given SHA-256
+// fingerprints, in hex or base64. The certificate chain is still verified
+// as usual; a connection to a server with no pinned key fails with a
+// ConnectionError naming the fingerprint it presented.
+func WithPinnedCertificates(sha256Fingerprints ...string) Option {
+	return clientOption(func(c *Client) error {
+		if len(sha256Fingerprints) == 0 {
+			return NewValidationError("at least one certificate fingerprint is required", nil)
+		}
+		pins := make([][]byte, 0, len(sha256Fingerprints))
+		for _, fp := range sha256Fingerprints {
+			pin, err := parseFingerprint(fp)
+			if err != nil {
+				return err
+			}
+			pins = append(pins, pin)
+		}
+		c.tls.pins = pins
+		return nil
+	})
+}
+
+// configureTLS installs a transport using the client's TLS settings, if
+// any were set
+func (c *Client) configureTLS() error {
+	s := c.tls
+	if !s.isSet() {
+		return nil
+	}
+	if s.config != nil && (s.pins != nil || s.rootCAs != nil) {
+		return NewValidationError("WithTLSConfig cannot be combined with WithPinnedCertificates or WithRootCAs", nil)
+	}
+
+	// Clone so that the settings never reach a config shared elsewhere
+	config := s.config.Clone()
+	if config == nil {
+		config = &tls.Config{}
+	}
+	if s.minVersion != 0 {
+		config.MinVersion = s.minVersion
+	}
+	if s.rootCAs != nil {
+		config.RootCAs = s.rootCAs
+	}
+	if s.pins != nil {
+		config.VerifyPeerCertificate = verifyPins(s.pins)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	c.httpClient.Transport = transport
+	return nil
+}
+
+// verifyPins returns a certificate check accepting only chains that
+// contain a certificate with one of the pinned keys
+func verifyPins(pins [][]byte) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		var presented string
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse server certificate: %w", err)
+			}
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, pin := range pins {
+				if bytes.Equal(sum[:], pin) {
+					return nil
+				}
+			}
+			if presented == "" {
+				presented = hex.EncodeToString(sum[:])
+			}
+		}
+		return errors.New("server certificate public key " + presented + " does not match any pinned fingerprint")
+	}
+}
+
+// parseFingerprint decodes a hex or base64 SHA-256 fingerprint
+func parseFingerprint(fp string) ([]byte, error) {
+	if pin, err := hex.DecodeString(strings.ReplaceAll(fp, ":", "")); err == nil && len(pin) == sha256.Size {
+		return pin, nil
+	}
+	if pin, err := base64.StdEncoding.DecodeString(fp); err == nil && len(pin) == sha256.Size {
+		return pin, nil
+	}
+	return nil, NewValidationError("certificate fingerprint must be a hex or base64 SHA-256 digest: "+fp, nil)
+}
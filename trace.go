@@ -0,0 +1,112 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SendStepKind identifies a step in a SendTrace
+type SendStepKind string
+
+const (
+	// StepValidated follows validation of the request
+	StepValidated SendStepKind = "validated"
+
+	// StepMarshaled follows encoding of the request body
+	StepMarshaled SendStepKind = "marshaled"
+
+	// StepAttempt marks the start of an HTTP attempt
+	StepAttempt SendStepKind = "attempt"
+
+	// StepResponse records the outcome of an HTTP attempt; its duration is
+	// the duration of the attempt
+	StepResponse SendStepKind = "response"
+
+	// StepBackoff records the wait before the next attempt
+	StepBackoff SendStepKind = "backoff"
+
+	// StepCompleted and StepFailed end the trace; their duration is the
+	// duration of the whole send
+	StepCompleted SendStepKind = "completed"
+	StepFailed    SendStepKind = "failed"
+)
+
+// SendStep is a timestamped step of a send
+type SendStep struct {
+	Kind     SendStepKind
+	Detail   string
+	At       time.Time
+	Duration time.Duration
+}
+
+// SendTrace is the chronological list of steps of a single SendEmail call
+type SendTrace []SendStep
+
+// WithSendTrace calls fn with the trace of every SendEmail call once it
+// has returned, listing validation, encoding, each attempt and its
+// response, and retry backoffs. Traces are only collected when this option
+// is set.
+func WithSendTrace(fn func(trace SendTrace)) Option {
+	return clientOption(func(c *Client) error {
+		if fn == nil {
+			return NewValidationError("send trace callback cannot be nil", nil)
+		}
+		c.sendTrace = fn
+		return nil
+	})
+}
+
+// sendTracer collects the steps of a send
+type sendTracer struct {
+	start time.Time
+	steps SendTrace
+}
+
+// sendTracerKey is the context key of the send tracer
+type sendTracerKey struct{}
+
+// newSendTracer returns ctx carrying a new tracer
+func newSendTracer(ctx context.Context) (context.Context, *sendTracer) {
+	t := &sendTracer{start: time.Now(), steps: make(SendTrace, 0, 16)}
+	return context.WithValue(ctx, sendTracerKey{}, t), t
+}
+
+// tracerFrom returns the tracer carried by ctx, or nil
+func tracerFrom(ctx context.Context) *sendTracer {
+	t, _ := ctx.Value(sendTracerKey{}).(*sendTracer)
+	return t
+}
+
+// step records a step; it does nothing on a nil tracer
+func (t *sendTracer) step(kind SendStepKind, detail string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.steps = append(t.steps, SendStep{Kind: kind, Detail: detail, At: time.Now(), Duration: d})
+}
+
+// finishSendTrace records the final step and delivers the trace
+func (c *Client) finishSendTrace(ctx context.Context, t *sendTracer, resp *EmailResponse, err error) {
+	switch {
+	case err != nil:
+		t.step(StepFailed, err.Error(), time.Since(t.start))
+	case resp != nil:
+		t.step(StepCompleted, resp.Data.MessageID, time.Since(t.start))
+	default:
+		t.step(StepCompleted, "", time.Since(t.start))
+	}
+	_ = c.safeCall(ctx, CallbackSendTrace, nil, func() { c.sendTrace(t.steps) })
+}
+
+// responseDetail describes the outcome of an attempt
+func responseDetail(statusCode int, err error) string {
+	switch {
+	case statusCode != 0:
+		return fmt.Sprintf("status %d", statusCode)
+	case err != nil:
+		return err.Error()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,85 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TrackingDomain represents a branded domain used for open and click
+// tracking links.
+//
+// To verify the domain, create a CNAME record pointing Domain at
+// CNAMETarget.
+type TrackingDomain struct {
+	ID          string `json:"id"`
+	Domain      string `json:"domain"`
+	CNAMETarget string `json:"cname_target"`
+	Verified    bool   `json:"verified"`
+	Status      string `json:"status"`
+}
+
+// CreateTrackingDomain registers a tracking domain.
+//
+// The returned TrackingDomain contains the CNAME target to configure in DNS.
+func (c *Client) CreateTrackingDomain(ctx context.Context, domain string) (*TrackingDomain, error) {
+	if err := ValidateDomain(domain); err != nil {
+		return nil, err
+	}
+
+	td, _, err := c.requestAPI().CreateTrackingDomain(ctx, domain)
+	return td, err
+}
+
+// GetTrackingDomain returns a tracking domain and its verification status.
+func (c *Client) GetTrackingDomain(ctx context.Context, domain string) (*TrackingDomain, error) {
+	if err := ValidateDomain(domain); err != nil {
+		return nil, err
+	}
+
+	td, _, err := c.requestAPI().GetTrackingDomain(ctx, domain)
+	return td, err
+}
+
+// DeleteTrackingDomain removes a tracking domain.
+func (c *Client) DeleteTrackingDomain(ctx context.Context, domain string) error {
+	if err := ValidateDomain(domain); err != nil {
+		return err
+	}
+
+	_, err := c.requestAPI().DeleteTrackingDomain(ctx, domain)
+	return err
+}
+
+// CheckTrackingDomainDNS checks that the CNAME record of a tracking domain
+// points at its CNAME target.
+//
+// The check runs locally using resolver, or net.DefaultResolver when
+// resolver is nil, so it can be used to confirm DNS changes before asking
+// the API to verify the domain.
+//
+// Returns nil when the record matches, a ValidationError when the record
+// points elsewhere, or a ConnectionError when the lookup fails.
+func CheckTrackingDomainDNS(ctx context.Context, td *TrackingDomain, resolver *net.Resolver) error {
+	if td == nil {
+		return NewValidationError("tracking domain cannot be nil", nil)
+	}
+	if td.CNAMETarget == "" {
+		return NewValidationError("tracking domain has no CNAME target", nil)
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	cname, err := resolver.LookupCNAME(ctx, td.Domain)
+	if err != nil {
+		return NewConnectionError("failed to look up CNAME for "+td.Domain, err)
+	}
+
+	if !strings.EqualFold(strings.TrimSuffix(cname, "."), strings.TrimSuffix(td.CNAMETarget, ".")) {
+		return NewValidationError(fmt.Sprintf("CNAME for %s points to %s, expected %s", td.Domain, strings.TrimSuffix(cname, "."), td.CNAMETarget), nil)
+	}
+
+	return nil
+}
@@ -1,18 +1,84 @@
 package mailnow
 
+import "time"
+
 // EmailRequest represents an email sending request
 type EmailRequest struct {
 	From        string       `json:"from"`
 	To          string       `json:"to"`
 	Subject     string       `json:"subject"`
-	HTML        string       `json:"html"`
+	HTML        string       `json:"html,omitempty"`
 	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// Markdown is a body written in Markdown, sent instead of HTML. It is
+	// rendered with RenderMarkdown by SendEmail and cannot be combined
+	// with HTML.
+	Markdown string `json:"-"`
+
+	// Text is a plain-text version of the body. It is required for
+	// text-only sends; see TextOnly and WithRecipientPreferences.
+	Text string `json:"text,omitempty"`
+
+	// CampaignID groups emails for reporting. The API sends at most one
+	// email per recipient per campaign.
+	CampaignID string `json:"campaign_id,omitempty"`
+
+	// ReturnPath is the envelope sender that bounces are delivered to,
+	// typically built per message with BuildVERPAddress
+	ReturnPath string `json:"return_path,omitempty"`
+
+	// ReplyTo lists the addresses replies should go to. Entries may also
+	// be comma-separated lists; duplicates are removed before sending.
+	ReplyTo []string `json:"reply_to,omitempty"`
+
+	// Headers are additional email headers, keyed by header name
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// MessageClass adds the headers of the class, see MessageClassHeaders.
+	// Values set in Headers take precedence. When empty, the client default
+	// set with WithDefaultMessageClass is used.
+	MessageClass MessageClass `json:"-"`
+
+	// RequestReadReceipt asks the recipient's mail client for a read
+	// receipt by sending the Disposition-Notification-To and
+	// Return-Receipt-To headers
+	RequestReadReceipt bool `json:"-"`
+
+	// ReceiptTo is the address read receipts are sent to. It defaults to
+	// From and requires RequestReadReceipt.
+	ReceiptTo string `json:"-"`
+
+	// CustomMessageID replaces the Message-ID generated by Mailnow, so that
+	// replies and bounces can be matched to the sending application's own
+	// records. See ValidateCustomMessageID for the allowed form.
+	CustomMessageID string `json:"custom_message_id,omitempty"`
+
+	// AllowEmptySubject accepts an empty Subject, which is otherwise
+	// rejected. The empty subject is sent as is rather than replaced by a
+	// default; LintEmailRequest warns about it, as mail without a subject
+	// is more likely to be filtered as spam.
+	AllowEmptySubject bool `json:"-"`
+
+	// DeliveryCallback registers a URL notified of this email's delivery
+	// outcome, see CallbackSpec. A send whose callback URL the API cannot
+	// reach fails with a CallbackUnreachableError.
+	DeliveryCallback *CallbackSpec `json:"delivery_callback,omitempty"`
 }
 
+// Attachment represents a file attached to an email.
+//
+// Exactly one of Content, URL or AssetID must be set: Content carries the
+// base64-encoded file inline, URL references a file the API fetches over
+// https, and AssetID references a file previously uploaded with
+// Client.UploadAsset. An attachment with a ContentID is sent inline, for
+// the HTML body to reference as cid:<ContentID>.
 type Attachment struct {
 	Filename    string `json:"filename"`
-	Content     string `json:"content"`
+	Content     string `json:"content,omitempty"`
+	URL         string `json:"url,omitempty"`
+	AssetID     string `json:"asset_id,omitempty"`
 	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id,omitempty"`
 }
 
 // EmailResponse represents a successful email sending response
@@ -21,10 +87,23 @@ type EmailResponse struct {
 	Message    string `json:"message"`
 	StatusCode int    `json:"status_code"`
 	Success    bool   `json:"success"`
+
+	// FallbackFrom is the fallback sender the email was sent from when the
+	// request's From address was not verified, or "" if no fallback was used
+	FallbackFrom string `json:"-"`
+
+	// Replayed reports that the response is that of an earlier send with
+	// the same idempotency key, returned by the idempotency store or by
+	// the API, rather than of a new send
+	Replayed bool `json:"-"`
 }
 type Data struct {
-	MessageID string `json:"message_id"`
-	Status    string `json:"status"`
+	MessageID   string     `json:"message_id"`
+	Status      string     `json:"status"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+
+	// CustomMessageID echoes EmailRequest.CustomMessageID when it was set
+	CustomMessageID string `json:"custom_message_id,omitempty"`
 }
 
 // ErrorResponse represents an API error response
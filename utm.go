@@ -0,0 +1,141 @@
+package mailnow
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// tagRegex matches an HTML start tag, allowing '>' inside quoted attributes
+	tagRegex = regexp.MustCompile(`<[a-zA-Z](?:[^>"']|"[^"]*"|'[^']*')*>`)
+
+	// hrefRegex matches an href attribute and its quoted or unquoted value
+	hrefRegex = regexp.MustCompile(`(?i)(\shref\s*=\s*)("[^"]*"|'[^']*'|[^\s>"']+)`)
+
+	// anchorTagRegex matches an <a> start tag, allowing '>' inside quoted
+	// attributes
+	anchorTagRegex = regexp.MustCompile(`<[aA](?:\s(?:[^>"']|"[^"]*"|'[^']*')*)?>`)
+)
+
+// InjectUTMParams appends params to the query string of every http and
+// https <a> link in html that does not already carry them.
+//
+// Existing query parameters and fragments are preserved, parameters a link
+// already has are not overwritten, and mailto:, tel:, cid: and in-page
+// anchor links are skipped, as are links that cannot be parsed. The added
+// parameters are separated with "&amp;" when the link's query already uses
+// it and with "&" otherwise. Other tags with an href, such as <link> and
+// <base>, and everything else in html are left byte-identical.
+func InjectUTMParams(html string, params map[string]string) (string, error) {
+	if err := validateUTMParams(params); err != nil {
+		return "", err
+	}
+	if len(params) == 0 {
+		return html, nil
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return anchorTagRegex.ReplaceAllStringFunc(html, func(tag string) string {
+		return hrefRegex.ReplaceAllStringFunc(tag, func(attr string) string {
+			m := hrefRegex.FindStringSubmatch(attr)
+			prefix, value := m[1], m[2]
+
+			quote := ""
+			if value[0] == '"' || value[0] == '\'' {
+				quote = value[:1]
+				value = value[1 : len(value)-1]
+			}
+
+			return prefix + quote + addLinkParams(value, params, keys) + quote
+		})
+	}), nil
+}
+
+// WithUTMParams appends params to the links in the HTML body of every send,
+// as InjectUTMParams does. The request passed to SendEmail keeps its
+// original HTML; only the copy sent to the API is rewritten.
+//
+// Passed to SendEmail it replaces the client's parameters for that send.
+func WithUTMParams(params map[string]string) ClientSendOption {
+	copied := make(map[string]string, len(params))
+	for k, v := range params {
+		copied[k] = v
+	}
+	return utmOption(copied)
+}
+
+// utmOption sets the UTM parameters for a client or a single send
+type utmOption map[string]string
+
+func (p utmOption) applyClient(c *Client) error {
+	if err := validateUTMParams(p); err != nil {
+		return err
+	}
+	c.utmParams = p
+	return nil
+}
+
+func (p utmOption) applySend(o *sendOptions) error {
+	if err := validateUTMParams(p); err != nil {
+		return err
+	}
+	o.utmParams = p
+	return nil
+}
+
+// validateUTMParams checks that every parameter has a name
+func validateUTMParams(params map[string]string) error {
+	for k := range params {
+		if k == "" {
+			return NewValidationError("UTM parameter name cannot be empty", nil)
+		}
+	}
+	return nil
+}
+
+// addLinkParams returns the raw href value with the missing params added,
+// or unchanged if it is not an http(s) URL
+func addLinkParams(raw string, params map[string]string, keys []string) string {
+	u, err := url.Parse(strings.TrimSpace(html.UnescapeString(raw)))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return raw
+	}
+
+	existing := u.Query()
+	var add []string
+	for _, k := range keys {
+		if _, ok := existing[k]; !ok {
+			add = append(add, url.QueryEscape(k)+"="+url.QueryEscape(params[k]))
+		}
+	}
+	if len(add) == 0 {
+		return raw
+	}
+
+	// Insert before the fragment, extending any existing query with the
+	// separator it already uses
+	base, fragment := raw, ""
+	if i := strings.IndexByte(raw, '#'); i >= 0 {
+		base, fragment = raw[:i], raw[i:]
+	}
+	sep := "&"
+	if strings.Contains(base, "&amp;") {
+		sep = "&amp;"
+	}
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, sep):
+		base += sep
+	}
+
+	return base + strings.Join(add, sep) + fragment
+}
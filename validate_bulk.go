@@ -0,0 +1,239 @@
+package mailnow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaxBulkValidateBatchSize is the largest number of addresses the API
+	// validates in one request
+	MaxBulkValidateBatchSize = 1000
+
+	// DefaultBulkValidateConcurrency is the number of batches validated at
+	// the same time unless WithBulkConcurrency says otherwise
+	DefaultBulkValidateConcurrency = 4
+)
+
+// RecipientValidation is the result of validating one address
+type RecipientValidation struct {
+	Email  string `json:"email"`
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// BulkChunkError reports a batch of ValidateRecipientsBulk that failed
+type BulkChunkError struct {
+	// Emails are the addresses of the batch, none of which have a result
+	Emails []string
+
+	// Err is why the batch failed
+	Err error
+}
+
+// BulkValidationResult is the result of ValidateRecipientsBulk
+type BulkValidationResult struct {
+	// Results holds the result of every validated address, keyed by the
+	// address as given
+	Results map[string]RecipientValidation
+
+	// Failures lists the batches that could not be validated, in input order
+	Failures []BulkChunkError
+}
+
+// BulkValidateOption configures a ValidateRecipientsBulk call
+type BulkValidateOption interface {
+	applyBulkValidate(o *bulkValidateOptions) error
+}
+
+// bulkValidateOption adapts a function to the BulkValidateOption interface
+type bulkValidateOption func(o *bulkValidateOptions) error
+
+func (f bulkValidateOption) applyBulkValidate(o *bulkValidateOptions) error {
+	return f(o)
+}
+
+// bulkValidateOptions holds the settings collected from BulkValidateOptions
+type bulkValidateOptions struct {
+	batchSize   int
+	concurrency int
+	progress    func(done, total int)
+	bypassCache bool
+}
+
+// WithBulkBatchSize sets the number of addresses per request, at most
+// MaxBulkValidateBatchSize, which is the default
+func WithBulkBatchSize(n int) BulkValidateOption {
+	return bulkValidateOption(func(o *bulkValidateOptions) error {
+		if n <= 0 || n > MaxBulkValidateBatchSize {
+			return NewValidationError(fmt.Sprintf("bulk batch size must be between 1 and %d", MaxBulkValidateBatchSize), nil)
+		}
+		o.batchSize = n
+		return nil
+	})
+}
+
+// WithBulkConcurrency sets the number of batches validated at the same time
+func WithBulkConcurrency(n int) BulkValidateOption {
+	return bulkValidateOption(func(o *bulkValidateOptions) error {
+		if n <= 0 {
+			return NewValidationError("bulk concurrency must be positive", nil)
+		}
+		o.concurrency = n
+		return nil
+	})
+}
+
+// WithBulkProgress calls fn after each batch with the number of addresses
+// processed so far, including failed batches, and the total. Calls are
+// never concurrent.
+func WithBulkProgress(fn func(done, total int)) BulkValidateOption {
+	return bulkValidateOption(func(o *bulkValidateOptions) error {
+		if fn == nil {
+			return NewValidationError("bulk progress callback cannot be nil", nil)
+		}
+		o.progress = fn
+		return nil
+	})
+}
+
+// bulkValidateRequest represents the body of a bulk validation request
+type bulkValidateRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// ValidateRecipientsBulk validates emails with the API, splitting them into
+// batches that are sent concurrently.
+//
+// A batch that fails is reported in BulkValidationResult.Failures and the
+// other batches carry on. When ctx is cancelled no further batches are
+// started; the results gathered so far are returned with the context error,
+// and the batches that did not run are reported as failures. Addresses
+// with a result in the cache set by WithRecipientValidationCache are not
+// sent.
+func (c *Client) ValidateRecipientsBulk(ctx context.Context, emails []string, opts ...BulkValidateOption) (*BulkValidationResult, error) {
+	ctx, endOperation, ok := c.beginOperation(ctx)
+	if !ok {
+		return nil, ErrClientClosed
+	}
+	defer endOperation()
+
+	o := bulkValidateOptions{batchSize: MaxBulkValidateBatchSize, concurrency: DefaultBulkValidateConcurrency}
+	for _, opt := range opts {
+		if err := opt.applyBulkValidate(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make(map[string]RecipientValidation, len(emails))
+	pending := emails
+	now, clockErr := c.currentTime(ctx)
+	cache := c.validationCache
+	if clockErr != nil {
+		cache = nil
+	}
+	if cache != nil && !o.bypassCache {
+		pending = nil
+		for _, email := range emails {
+			if v, ok := cache.get(email, now); ok {
+				results[email] = v
+			} else {
+				pending = append(pending, email)
+			}
+		}
+	}
+
+	var chunks [][]string
+	for start := 0; start < len(pending); start += o.batchSize {
+		end := start + o.batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		chunks = append(chunks, pending[start:end])
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		done     = len(emails) - len(pending)
+		failures = make([]error, len(chunks))
+		sem      = make(chan struct{}, o.concurrency)
+	)
+	for i, chunk := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			for j := i; j < len(chunks); j++ {
+				failures[j] = ctx.Err()
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			validations, err := c.validateChunk(ctx, chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[i] = err
+			}
+			for email, v := range matchValidations(chunk, validations) {
+				results[email] = v
+				if cache != nil {
+					cache.put(v, now)
+				}
+			}
+			done += len(chunk)
+			if o.progress != nil {
+				o.progress(done, len(emails))
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	result := &BulkValidationResult{Results: results}
+	for i, err := range failures {
+		if err != nil {
+			result.Failures = append(result.Failures, BulkChunkError{Emails: chunks[i], Err: err})
+		}
+	}
+	return result, ctx.Err()
+}
+
+// matchValidations keys the results of a batch by the addresses as given,
+// as the API may return them in another form. Results are matched by
+// canonical address ignoring case, or by position when the API returned as
+// many results as addresses.
+func matchValidations(emails []string, validations []RecipientValidation) map[string]RecipientValidation {
+	inputs := make(map[string][]string, len(emails))
+	for _, email := range emails {
+		key := strings.ToLower(validationCacheKey(email))
+		inputs[key] = append(inputs[key], email)
+	}
+
+	matched := make(map[string]RecipientValidation, len(emails))
+	for i, v := range validations {
+		given := inputs[strings.ToLower(validationCacheKey(v.Email))]
+		if given == nil && len(validations) == len(emails) {
+			given = emails[i : i+1]
+		}
+		for _, email := range given {
+			matched[email] = v
+		}
+	}
+	return matched
+}
+
+// validateChunk validates one batch of addresses
+func (c *Client) validateChunk(ctx context.Context, emails []string) ([]RecipientValidation, error) {
+	validations, _, err := c.requestAPI().ValidateRecipients(ctx, emails)
+	return validations, err
+}
@@ -1,15 +1,34 @@
 package mailnow
 
 import (
+	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// emailRegex is a regex pattern for validating email addresses
-var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+// domainLabelRegex is a regex pattern for validating a single domain label
+var domainLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?$`)
 
-// ValidateAPIKey validates the API key format
+// headerNameRegex is a regex pattern for validating header field names
+var headerNameRegex = regexp.MustCompile(`^[!-9;-~]+$`)
+
+// slugRegex is a regex pattern for validating campaign and sub-account ID slugs
+var slugRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,64}$`)
+
+// ValidateAPIKey validates the API key format. It applies
+// DefaultMinAPIKeySecretLength, so any secret after the prefix is accepted.
 func ValidateAPIKey(apiKey string) error {
+	return validateAPIKey(apiKey, DefaultMinAPIKeySecretLength)
+}
+
+// validateAPIKey validates the API key format, requiring a secret of at
+// least minLength characters after the prefix
+func validateAPIKey(apiKey string, minLength int) error {
 	if apiKey == "" {
 		return NewValidationError("API key cannot be empty", nil)
 	}
@@ -18,22 +37,144 @@ func ValidateAPIKey(apiKey string) error {
 		return NewValidationError("API key must start with 'mn_live_' or 'mn_test_'", nil)
 	}
 
+	// Both prefixes have the same length
+	secret := apiKey[len(APIKeyPrefixLive):]
+	if len(secret) < minLength {
+		return NewValidationError(fmt.Sprintf("API key secret must be at least %d characters, got %d", minLength, len(secret)), nil)
+	}
+
 	return nil
 }
 
+// IsTestAPIKey reports whether apiKey is a test API key. It checks the
+// prefix only; use ValidateAPIKey to validate the key.
+func IsTestAPIKey(apiKey string) bool {
+	return strings.HasPrefix(apiKey, APIKeyPrefixTest)
+}
+
+// IsLiveAPIKey reports whether apiKey is a live API key. It checks the
+// prefix only; use ValidateAPIKey to validate the key.
+func IsLiveAPIKey(apiKey string) bool {
+	return strings.HasPrefix(apiKey, APIKeyPrefixLive)
+}
+
 // ValidateEmailAddress validates an email address format
 func ValidateEmailAddress(email string) error {
 	if email == "" {
 		return NewValidationError("email address cannot be empty", nil)
 	}
 
-	if !emailRegex.MatchString(email) {
+	if !emailAddressValid(email) {
 		return NewValidationError("invalid email address format: "+email, nil)
 	}
 
 	return nil
 }
 
+// DefaultEmailValidationCacheSize is the number of addresses whose validity
+// ValidateEmailAddress remembers, unless SetEmailValidationCacheSize says
+// otherwise
+const DefaultEmailValidationCacheSize = 4096
+
+// emailValidationCache remembers the validity of recently validated
+// addresses, or is nil when disabled
+var emailValidationCache atomic.Pointer[validityMemo]
+
+func init() {
+	emailValidationCache.Store(newValidityMemo(DefaultEmailValidationCacheSize))
+}
+
+// SetEmailValidationCacheSize sets the number of addresses whose validity
+// ValidateEmailAddress remembers, which defaults to
+// DefaultEmailValidationCacheSize. The cache is emptied when it fills up.
+// Setting it to 0 disables the cache for memory-sensitive programs.
+func SetEmailValidationCacheSize(n int) {
+	if n <= 0 {
+		emailValidationCache.Store(nil)
+		return
+	}
+	emailValidationCache.Store(newValidityMemo(n))
+}
+
+// validityMemo is a concurrent map from inputs to their validity, holding
+// at most limit entries
+type validityMemo struct {
+	limit   int64
+	size    atomic.Int64
+	results sync.Map
+}
+
+// newValidityMemo creates an empty memo for limit entries
+func newValidityMemo(limit int) *validityMemo {
+	return &validityMemo{limit: int64(limit)}
+}
+
+// emailAddressValid reports whether email has the form local@domain.tld,
+// using the cache when it is enabled
+func emailAddressValid(email string) bool {
+	memo := emailValidationCache.Load()
+	if memo == nil {
+		return scanEmailAddress(email)
+	}
+	if valid, ok := memo.results.Load(email); ok {
+		return valid.(bool)
+	}
+
+	valid := scanEmailAddress(email)
+	if memo.size.Add(1) > memo.limit {
+		// Start over rather than track recency, keeping lookups lock-free
+		fresh := newValidityMemo(int(memo.limit))
+		if !emailValidationCache.CompareAndSwap(memo, fresh) {
+			return valid
+		}
+		memo = fresh
+		memo.size.Add(1)
+	}
+	memo.results.Store(email, valid)
+	return valid
+}
+
+// scanEmailAddress reports whether email matches
+// ^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$ without a regular
+// expression: a non-empty local part, one '@', and a domain whose last
+// label is at least two letters and follows a non-empty prefix.
+func scanEmailAddress(email string) bool {
+	at := strings.IndexByte(email, '@')
+	if at <= 0 {
+		return false
+	}
+	for i := 0; i < at; i++ {
+		if c := email[i]; !isAlnum(c) && c != '.' && c != '_' && c != '%' && c != '+' && c != '-' {
+			return false
+		}
+	}
+
+	domain := email[at+1:]
+	dot := -1
+	for i := 0; i < len(domain); i++ {
+		switch c := domain[i]; {
+		case c == '.':
+			dot = i
+		case !isAlnum(c) && c != '-':
+			return false
+		}
+	}
+	if dot <= 0 || len(domain)-dot-1 < 2 {
+		return false
+	}
+	for i := dot + 1; i < len(domain); i++ {
+		if c := domain[i]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlnum reports whether c is an ASCII letter or digit
+func isAlnum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
 // ValidateEmailRequest validates all email request parameters
 func ValidateEmailRequest(req *EmailRequest) error {
 	if req == nil {
@@ -56,15 +197,337 @@ func ValidateEmailRequest(req *EmailRequest) error {
 		return NewValidationError("invalid to address", err)
 	}
 
+	// Validate return path
+	if req.ReturnPath != "" {
+		if err := ValidateEmailAddress(req.ReturnPath); err != nil {
+			return NewValidationError("invalid return path", err)
+		}
+	}
+
+	// Validate reply-to addresses
+	for _, addr := range splitAddressList(req.ReplyTo) {
+		if err := ValidateEmailAddress(addr); err != nil {
+			return NewValidationError("invalid reply-to address", err)
+		}
+	}
+
+	// Validate headers
+	if err := ValidateHeaders(req.Headers); err != nil {
+		return err
+	}
+	if len(req.ReplyTo) > 0 && hasHeader(req.Headers, "Reply-To") {
+		return NewValidationError("Reply-To cannot be set both in ReplyTo and in Headers", nil)
+	}
+	if req.MessageClass != "" {
+		if _, err := MessageClassHeaders(req.MessageClass); err != nil {
+			return err
+		}
+	}
+
+	// Validate read receipt
+	if req.ReceiptTo != "" {
+		if !req.RequestReadReceipt {
+			return NewValidationError("receipt address requires RequestReadReceipt", nil)
+		}
+		if err := ValidateEmailAddress(req.ReceiptTo); err != nil {
+			return NewValidationError("invalid receipt address", err)
+		}
+	}
+	if name := receiptHeaderConflict(req); name != "" {
+		return NewValidationError(name+" cannot be set in Headers when RequestReadReceipt is set", nil)
+	}
+
+	// Validate custom message ID
+	if req.CustomMessageID != "" {
+		if err := ValidateCustomMessageID(req.CustomMessageID, req.From); err != nil {
+			return err
+		}
+		if hasHeader(req.Headers, "Message-ID") {
+			return NewValidationError("Message-ID cannot be set both in CustomMessageID and in Headers", nil)
+		}
+	}
+
 	// Validate subject
-	if req.Subject == "" {
+	if req.Subject == "" && !req.AllowEmptySubject {
 		return NewValidationError("subject is required", nil)
 	}
 
 	// Validate HTML body
-	if req.HTML == "" {
+	if req.HTML != "" && req.Markdown != "" {
+		return NewValidationError("HTML and Markdown bodies cannot both be set", nil)
+	}
+	if req.HTML == "" && req.Markdown == "" {
 		return NewValidationError("HTML body is required", nil)
 	}
 
+	// Validate campaign ID
+	if req.CampaignID != "" {
+		if err := ValidateCampaignID(req.CampaignID); err != nil {
+			return err
+		}
+	}
+
+	// Validate delivery callback
+	if req.DeliveryCallback != nil {
+		if err := ValidateCallbackSpec(req.DeliveryCallback); err != nil {
+			return err
+		}
+	}
+
+	// Validate attachments
+	for i, attachment := range req.Attachments {
+		if err := ValidateAttachment(attachment); err != nil {
+			return NewValidationError(fmt.Sprintf("invalid attachment at index %d", i), err)
+		}
+	}
+
 	return nil
 }
+
+// ValidateHeaders validates custom email headers. Names must be valid
+// header field names and values cannot contain line breaks. Headers are
+// checked in name order, so the same headers always report the same error.
+func ValidateHeaders(headers map[string]string) error {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := headers[name]
+		if !headerNameRegex.MatchString(name) {
+			return NewValidationError(fmt.Sprintf("invalid header name %q", name), nil)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return NewValidationError(fmt.Sprintf("header %s cannot contain line breaks", name), nil)
+		}
+	}
+
+	return nil
+}
+
+// hasHeader reports whether headers contains name, ignoring case
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAddressList splits comma-separated entries of addrs into single
+// addresses, trimming spaces and dropping empty entries
+func splitAddressList(addrs []string) []string {
+	var out []string
+	for _, entry := range addrs {
+		for _, addr := range strings.Split(entry, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				out = append(out, addr)
+			}
+		}
+	}
+	return out
+}
+
+// normalizeReplyTo returns the reply-to addresses of addrs as a flat list
+// without duplicates, which are detected ignoring case
+func normalizeReplyTo(addrs []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, addr := range splitAddressList(addrs) {
+		key := strings.ToLower(addr)
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, addr)
+		}
+	}
+	return out
+}
+
+// ValidateScheduledTime validates a scheduled send time. The time must be
+// in the future and no further ahead than MaxScheduleHorizon.
+func ValidateScheduledTime(t time.Time) error {
+	if t.IsZero() {
+		return NewValidationError("scheduled time is required", nil)
+	}
+
+	now := time.Now()
+	if !t.After(now) {
+		return NewValidationError("scheduled time must be in the future: "+t.Format(time.RFC3339), nil)
+	}
+	if t.After(now.Add(MaxScheduleHorizon)) {
+		return NewValidationError(fmt.Sprintf("scheduled time must be within %s: %s", MaxScheduleHorizon, t.Format(time.RFC3339)), nil)
+	}
+
+	return nil
+}
+
+// ValidateCampaignID validates a campaign ID slug
+func ValidateCampaignID(campaignID string) error {
+	if !slugRegex.MatchString(campaignID) {
+		return NewValidationError("campaign ID must be 1-64 characters of letters, digits, '-' or '_': "+campaignID, nil)
+	}
+
+	return nil
+}
+
+// ValidateSubaccountID validates a sub-account ID slug
+func ValidateSubaccountID(id string) error {
+	if !slugRegex.MatchString(id) {
+		return NewValidationError("sub-account ID must be 1-64 characters of letters, digits, '-' or '_': "+id, nil)
+	}
+
+	return nil
+}
+
+// ValidateDomain validates a bare domain name such as "links.example.com".
+//
+// The domain must not contain a scheme, port or path and must have at least
+// two labels.
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return NewValidationError("domain cannot be empty", nil)
+	}
+	if strings.Contains(domain, "://") {
+		return NewValidationError("domain must not include a scheme: "+domain, nil)
+	}
+	if strings.ContainsAny(domain, "/:?#") {
+		return NewValidationError("domain must not include a port or path: "+domain, nil)
+	}
+	if len(domain) > 253 {
+		return NewValidationError("domain must be at most 253 characters: "+domain, nil)
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return NewValidationError("domain must contain a dot: "+domain, nil)
+	}
+	for _, label := range labels {
+		if !domainLabelRegex.MatchString(label) {
+			return NewValidationError("invalid domain label in "+domain, nil)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAttachment validates that an attachment has exactly one content
+// source and, when it has a filename, that the filename can be sanitized,
+// see SanitizeFilename
+func ValidateAttachment(attachment Attachment) error {
+	if attachment.Filename != "" {
+		if _, err := SanitizeFilename(attachment.Filename); err != nil {
+			return err
+		}
+	}
+
+	sources := 0
+	for _, source := range []string{attachment.Content, attachment.URL, attachment.AssetID} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return NewValidationError("attachment must set exactly one of content, URL or asset ID", nil)
+	}
+
+	if attachment.Content != "" {
+		if _, err := ValidateBase64(attachment.Content); err != nil {
+			return NewValidationError("attachment content must be base64 encoded", err)
+		}
+	}
+
+	if attachment.URL != "" {
+		u, err := url.Parse(attachment.URL)
+		if err != nil {
+			return NewValidationError("invalid attachment URL", err)
+		}
+		if u.Scheme != "https" || u.Host == "" {
+			return NewValidationError("attachment URL must be an absolute https URL: "+attachment.URL, nil)
+		}
+	}
+
+	if strings.ContainsAny(attachment.ContentID, "<> \t\r\n") {
+		return NewValidationError("attachment content ID cannot contain angle brackets or whitespace: "+attachment.ContentID, nil)
+	}
+
+	return nil
+}
+
+// base64 character classes used by ValidateBase64
+const (
+	b64Invalid byte = iota
+	b64Common
+	b64Standard
+	b64URLSafe
+	b64LineBreak
+	b64Padding
+)
+
+// base64Classes maps each byte to its base64 character class
+var base64Classes = func() (classes [256]byte) {
+	for _, ch := range "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789" {
+		classes[ch] = b64Common
+	}
+	classes['+'], classes['/'] = b64Standard, b64Standard
+	classes['-'], classes['_'] = b64URLSafe, b64URLSafe
+	classes['\r'], classes['\n'] = b64LineBreak, b64LineBreak
+	classes['='] = b64Padding
+	return classes
+}()
+
+// ValidateBase64 checks that s is padded base64 in either the standard or
+// the URL-safe alphabet and returns the size of the decoded data.
+//
+// The check runs in a single pass without decoding, so it is cheap even for
+// very large attachments. Line breaks are ignored, as in
+// base64.StdEncoding.DecodeString, but the two alphabets cannot be mixed
+// and padding may only appear at the end of the input. Invalid input is
+// reported with a ValidationError.
+func ValidateBase64(s string) (decodedSize int64, err error) {
+	var (
+		symbols  int64
+		padding  int64
+		alphabet byte
+	)
+
+	for i := 0; i < len(s); i++ {
+		class := base64Classes[s[i]]
+		if class == b64Common && padding == 0 {
+			symbols++
+			continue
+		}
+
+		switch class {
+		case b64LineBreak:
+			continue
+		case b64Padding:
+			padding++
+			if padding > 2 {
+				return 0, NewValidationError(fmt.Sprintf("too much base64 padding at offset %d", i), nil)
+			}
+			continue
+		case b64Invalid:
+			return 0, NewValidationError(fmt.Sprintf("illegal base64 character %q at offset %d", s[i], i), nil)
+		}
+
+		if padding > 0 {
+			return 0, NewValidationError(fmt.Sprintf("base64 data after padding at offset %d", i), nil)
+		}
+		if class != b64Common {
+			if alphabet != 0 && alphabet != class {
+				return 0, NewValidationError(fmt.Sprintf("mixed standard and URL-safe base64 alphabets at offset %d", i), nil)
+			}
+			alphabet = class
+		}
+		symbols++
+	}
+
+	if (symbols+padding)%4 != 0 {
+		return 0, NewValidationError(fmt.Sprintf("base64 length %d is not a multiple of 4", symbols+padding), nil)
+	}
+
+	return (symbols+padding)/4*3 - padding, nil
+}
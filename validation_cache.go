@@ -0,0 +1,202 @@
+package mailnow
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValidationCacheStats counts the lookups of the recipient validation
+// cache
+type ValidationCacheStats struct {
+	// Hits is the number of addresses answered from the cache
+	Hits int64
+
+	// Misses is the number of addresses validated with the API
+	Misses int64
+}
+
+// WithRecipientValidationCache caches the results of ValidateRecipient and
+// ValidateRecipientsBulk for up to size addresses, evicting the least
+// recently used, so that validating the same address again does not use
+// API quota.
+//
+// Valid results are kept for positiveTTL and invalid ones for negativeTTL.
+// Each time an address is found invalid again its time in the cache
+// doubles, up to positiveTTL. Addresses are keyed by their canonical form
+// under DefaultProviderRules, so "John.Doe+x@gmail.com" and
+// "johndoe@gmail.com" share a result. Expiry follows the client clock, see
+// WithClock.
+//
+// WithoutValidationCache skips the cache for one call, and
+// InvalidateRecipientValidation removes an address from it.
+func WithRecipientValidationCache(size int, positiveTTL, negativeTTL time.Duration) Option {
+	return clientOption(func(c *Client) error {
+		if size < 1 {
+			return NewValidationError("validation cache size must be positive", nil)
+		}
+		if positiveTTL <= 0 || negativeTTL <= 0 {
+			return NewValidationError("validation cache TTLs must be positive", nil)
+		}
+		c.validationCache = &validationCache{
+			size:        size,
+			positiveTTL: positiveTTL,
+			negativeTTL: negativeTTL,
+			entries:     make(map[string]*list.Element),
+			order:       list.New(),
+		}
+		return nil
+	})
+}
+
+// WithoutValidationCache validates every address with the API even when
+// the cache has a result for it. The fresh results still replace the
+// cached ones.
+func WithoutValidationCache() BulkValidateOption {
+	return bulkValidateOption(func(o *bulkValidateOptions) error {
+		o.bypassCache = true
+		return nil
+	})
+}
+
+// ValidateRecipient validates one address with the API, using the
+// recipient validation cache when it is enabled. It fails with a
+// ServerError when the API returns no result for the address.
+func (c *Client) ValidateRecipient(ctx context.Context, email string, opts ...BulkValidateOption) (*RecipientValidation, error) {
+	result, err := c.ValidateRecipientsBulk(ctx, []string{email}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Failures) > 0 {
+		return nil, result.Failures[0].Err
+	}
+
+	v, ok := result.Results[email]
+	if !ok {
+		return nil, NewServerError("API returned no validation result for "+email, nil)
+	}
+	return &v, nil
+}
+
+// InvalidateRecipientValidation removes the cached result for email, and
+// for every address sharing its canonical form
+func (c *Client) InvalidateRecipientValidation(email string) {
+	if c.validationCache != nil {
+		c.validationCache.remove(email)
+	}
+}
+
+// RecipientValidationCacheStats returns the lookup counts of the recipient
+// validation cache, which are zero when it is not enabled
+func (c *Client) RecipientValidationCacheStats() ValidationCacheStats {
+	if c.validationCache == nil {
+		return ValidationCacheStats{}
+	}
+	return c.validationCache.stats()
+}
+
+// validationCache is an LRU cache of recipient validation results
+type validationCache struct {
+	size        int
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	hits    int64
+	misses  int64
+}
+
+// validationEntry is a cached result. Expired entries stay in the cache
+// until evicted so that repeated invalid results keep their streak.
+type validationEntry struct {
+	key     string
+	result  RecipientValidation
+	expires time.Time
+
+	// invalidStreak is the number of consecutive invalid results
+	invalidStreak int
+}
+
+// validationCacheKey returns the cache key of email
+func validationCacheKey(email string) string {
+	if canonical, err := CanonicalizeEmail(strings.TrimSpace(email), nil); err == nil {
+		return canonical
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// get returns the unexpired cached result for email, counting the lookup
+func (vc *validationCache) get(email string, now time.Time) (RecipientValidation, bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if elem, ok := vc.entries[validationCacheKey(email)]; ok {
+		entry := elem.Value.(*validationEntry)
+		if now.Before(entry.expires) {
+			vc.order.MoveToFront(elem)
+			vc.hits++
+			result := entry.result
+			result.Email = email
+			return result, true
+		}
+	}
+	vc.misses++
+	return RecipientValidation{}, false
+}
+
+// put caches result, evicting the least recently used entry when full
+func (vc *validationCache) put(result RecipientValidation, now time.Time) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	key := validationCacheKey(result.Email)
+	elem, ok := vc.entries[key]
+	if !ok {
+		elem = vc.order.PushFront(&validationEntry{key: key})
+		vc.entries[key] = elem
+		if vc.order.Len() > vc.size {
+			oldest := vc.order.Back()
+			vc.order.Remove(oldest)
+			delete(vc.entries, oldest.Value.(*validationEntry).key)
+		}
+	}
+	vc.order.MoveToFront(elem)
+
+	entry := elem.Value.(*validationEntry)
+	entry.result = result
+	ttl := vc.positiveTTL
+	if result.Valid {
+		entry.invalidStreak = 0
+	} else {
+		ttl = vc.negativeTTL
+		for i := 0; i < entry.invalidStreak && ttl < vc.positiveTTL; i++ {
+			ttl *= 2
+		}
+		ttl = max(min(ttl, vc.positiveTTL), vc.negativeTTL)
+		entry.invalidStreak++
+	}
+	entry.expires = now.Add(ttl)
+}
+
+// remove deletes the entry for email
+func (vc *validationCache) remove(email string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	key := validationCacheKey(email)
+	if elem, ok := vc.entries[key]; ok {
+		vc.order.Remove(elem)
+		delete(vc.entries, key)
+	}
+}
+
+// stats returns the lookup counts
+func (vc *validationCache) stats() ValidationCacheStats {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return ValidationCacheStats{Hits: vc.hits, Misses: vc.misses}
+}
@@ -0,0 +1,68 @@
+package mailnow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxLocalPartLength is the maximum length of the part of an email address
+// before the '@'
+const MaxLocalPartLength = 64
+
+// verpTokenUnsafeRegex matches runs of characters that are not kept in a
+// VERP token
+var verpTokenUnsafeRegex = regexp.MustCompile(`[^a-zA-Z0-9._\-]+`)
+
+// BuildVERPAddress returns a per-message envelope address made by adding
+// token to base as a plus-extension, for example
+// BuildVERPAddress("bounces@mail.example.com", "order123") returns
+// "bounces+order123@mail.example.com".
+//
+// Characters that are not letters, digits, '.', '_' or '-' are replaced in
+// the token with '-'. The result must be a valid address whose local part
+// is at most MaxLocalPartLength characters.
+func BuildVERPAddress(base, token string) (string, error) {
+	if err := ValidateEmailAddress(base); err != nil {
+		return "", NewValidationError("invalid VERP base address", err)
+	}
+
+	at := strings.LastIndexByte(base, '@')
+	local, domain := base[:at], base[at:]
+	if strings.Contains(local, "+") {
+		return "", NewValidationError("VERP base address already has a plus-extension: "+base, nil)
+	}
+
+	token = verpTokenUnsafeRegex.ReplaceAllString(token, "-")
+	if strings.Trim(token, "-") == "" {
+		return "", NewValidationError("VERP token cannot be empty", nil)
+	}
+
+	local += "+" + token
+	if len(local) > MaxLocalPartLength {
+		return "", NewValidationError(fmt.Sprintf("VERP address local part is %d characters, exceeding the limit of %d", len(local), MaxLocalPartLength), nil)
+	}
+
+	address := local + domain
+	if err := ValidateEmailAddress(address); err != nil {
+		return "", NewValidationError("invalid VERP address", err)
+	}
+
+	return address, nil
+}
+
+// ParseVERPToken returns the plus-extension token of a VERP address built
+// with BuildVERPAddress, and whether the address had one.
+func ParseVERPToken(address string) (string, bool) {
+	at := strings.LastIndexByte(address, '@')
+	if at < 0 {
+		return "", false
+	}
+
+	_, token, found := strings.Cut(address[:at], "+")
+	if !found || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
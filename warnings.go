@@ -0,0 +1,37 @@
+package mailnow
+
+import "context"
+
+// Warning is a condition reported by the client that does not stop a
+// send, such as nearing the send budget
+type Warning struct {
+	// Code identifies the kind of warning, such as "budget_threshold"
+	Code string
+
+	// Message describes the warning
+	Message string
+}
+
+// WithWarningHandler sets a function that receives client warnings. By
+// default warnings are logged at warn level to the logger set with
+// WithLogger, and dropped when there is none.
+func WithWarningHandler(handler func(Warning)) Option {
+	return clientOption(func(c *Client) error {
+		if handler == nil {
+			return NewValidationError("warning handler cannot be nil", nil)
+		}
+		c.warningHandler = handler
+		return nil
+	})
+}
+
+// warn reports w to the warning handler, or logs it
+func (c *Client) warn(ctx context.Context, w Warning) {
+	if c.warningHandler != nil {
+		_ = c.safeCall(ctx, CallbackWarningHandler, nil, func() { c.warningHandler(w) })
+		return
+	}
+	if c.logger != nil {
+		c.logger.WarnContext(ctx, w.Message, "code", w.Code)
+	}
+}